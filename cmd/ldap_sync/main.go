@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log"
+
+	"eduqr-backend/config"
+	"eduqr-backend/internal/database"
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+)
+
+// Synchronise les utilisateurs et leurs groupes avec l'annuaire LDAP / Active Directory configuré (LDAP_*).
+// Les utilisateurs retirés de l'annuaire sont désactivés, pas supprimés. À planifier, par exemple chaque nuit.
+func main() {
+	// Charger la configuration
+	cfg := config.LoadConfig()
+	if cfg.LDAP.URL == "" {
+		log.Fatal("LDAP_URL n'est pas configuré")
+	}
+
+	roleMapping, err := services.ParseClaimMapping(cfg.LDAP.RoleMapping)
+	if err != nil {
+		log.Fatalf("Correspondance des rôles LDAP invalide : %v", err)
+	}
+	for _, role := range roleMapping {
+		if !models.IsValidRole(role) {
+			log.Fatalf("Rôle inconnu dans la correspondance LDAP : %s", role)
+		}
+	}
+	if !models.IsValidRole(cfg.LDAP.DefaultRole) {
+		log.Fatalf("Rôle par défaut LDAP inconnu : %s", cfg.LDAP.DefaultRole)
+	}
+	groupMapping, err := services.ParseClaimMapping(cfg.LDAP.GroupMapping)
+	if err != nil {
+		log.Fatalf("Correspondance des groupes LDAP invalide : %v", err)
+	}
+
+	// Se connecter à la base de données
+	if err := database.ConnectDB(cfg); err != nil {
+		log.Fatalf("Erreur de connexion à la base de données : %v", err)
+	}
+	defer database.CloseDB()
+
+	// Crée les tables utilisées par la synchronisation si le serveur n'a pas encore migré :
+	// comptes, groupes d'étudiants et sessions révoquées lors d'une désactivation ou d'un changement de rôle
+	if err := database.AutoMigrate(&models.User{}, &models.Group{}, &models.GroupMembership{}, &models.Session{}, &models.RefreshToken{}); err != nil {
+		log.Fatalf("Erreur lors de la migration : %v", err)
+	}
+
+	db := database.GetDB()
+	ldapService := services.NewLDAPService(services.LDAPSettings{
+		URL:                cfg.LDAP.URL,
+		StartTLS:           cfg.LDAP.StartTLS,
+		BindDN:             cfg.LDAP.BindDN,
+		BindPassword:       cfg.LDAP.BindPassword,
+		BaseDN:             cfg.LDAP.BaseDN,
+		UserFilter:         cfg.LDAP.UserFilter,
+		IDAttribute:        cfg.LDAP.IDAttribute,
+		EmailAttribute:     cfg.LDAP.EmailAttribute,
+		FirstNameAttribute: cfg.LDAP.FirstNameAttribute,
+		LastNameAttribute:  cfg.LDAP.LastNameAttribute,
+		GroupAttribute:     cfg.LDAP.GroupAttribute,
+		RoleMapping:        roleMapping,
+		GroupMapping:       groupMapping,
+		DefaultRole:        cfg.LDAP.DefaultRole,
+	}, repositories.NewUserRepository(), repositories.NewGroupRepository(db), repositories.NewSessionRepository(db))
+
+	log.Printf("Synchronisation avec l'annuaire %s...", cfg.LDAP.URL)
+	report, err := ldapService.Sync()
+	if err != nil {
+		log.Fatalf("Synchronisation échouée : %v", err)
+	}
+
+	log.Printf("Synchronisation terminée : %d créés, %d mis à jour, %d réactivés, %d désactivés, %d ignorés",
+		report.Created, report.Updated, report.Reactivated, report.Deactivated, report.Skipped)
+}
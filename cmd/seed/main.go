@@ -31,7 +31,7 @@ func main() {
 	userRepo := repositories.NewUserRepository()
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, cfg.JWT.Secret, 24*time.Hour)
+	userService := services.NewUserService(userRepo)
 
 	// Create users
 	log.Println("Creating users...")
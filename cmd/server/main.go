@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"eduqr-backend/internal/repositories"
 	"eduqr-backend/internal/routes"
 	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/mail"
 	"eduqr-backend/pkg/utils"
 
 	"gorm.io/gorm"
@@ -34,7 +36,7 @@ func main() {
 	defer database.CloseDB()
 
 	// Auto migrate models
-	if err := database.AutoMigrate(&models.User{}, &models.Event{}, &models.Room{}, &models.Subject{}, &models.Course{}, &models.AuditLog{}, &models.Absence{}, &models.Presence{}); err != nil {
+	if err := database.AutoMigrate(&models.User{}, &models.Event{}, &models.Equipment{}, &models.Room{}, &models.Subject{}, &models.Group{}, &models.GroupMembership{}, &models.SubjectQuota{}, &models.TeacherAvailability{}, &models.TeacherUnavailability{}, &models.Course{}, &models.CourseStatusHistory{}, &models.Notification{}, &models.CalendarFeedToken{}, &models.RoomBooking{}, &models.Session{}, &models.RefreshToken{}, &models.PasswordResetToken{}, &models.LoginThrottle{}, &models.TwoFactor{}, &models.TwoFactorRecoveryCode{}, &models.OIDCLoginState{}, &models.RolePermissionOverride{}, &models.AuditLog{}, &models.Absence{}, &models.Presence{}); err != nil {
 		log.Fatalf("Failed to auto migrate: %v", err)
 	}
 
@@ -53,41 +55,208 @@ func main() {
 	auditLogRepo := repositories.NewAuditLogRepository()
 	absenceRepo := repositories.NewAbsenceRepository(database.GetDB())
 	presenceRepo := repositories.NewPresenceRepository(database.GetDB())
+	groupRepo := repositories.NewGroupRepository(database.GetDB())
+	notificationRepo := repositories.NewNotificationRepository(database.GetDB())
+	availabilityRepo := repositories.NewTeacherAvailabilityRepository(database.GetDB())
+	calendarFeedRepo := repositories.NewCalendarFeedRepository(database.GetDB())
+	roomBookingRepo := repositories.NewRoomBookingRepository(database.GetDB())
+	sessionRepo := repositories.NewSessionRepository(database.GetDB())
+	passwordResetRepo := repositories.NewPasswordResetRepository(database.GetDB())
+	loginThrottleRepo := repositories.NewLoginThrottleRepository(database.GetDB())
+	twoFactorRepo := repositories.NewTwoFactorRepository(database.GetDB())
+	oidcRepo := repositories.NewOIDCRepository(database.GetDB())
+	permissionRepo := repositories.NewPermissionRepository(database.GetDB())
+	subjectQuotaRepo := repositories.NewSubjectQuotaRepository(database.GetDB())
+
+	// Fuseau horaire de l'établissement
+	schoolLocation, err := time.LoadLocation(cfg.Server.TimeZone)
+	if err != nil {
+		log.Fatalf("Failed to load school time zone: %v", err)
+	}
+	models.SchoolLocation = schoolLocation
 
 	// Parse JWT expiration
 	jwtExpiration, err := time.ParseDuration(cfg.JWT.Expiration)
 	if err != nil {
 		log.Fatalf("Failed to parse JWT expiration: %v", err)
 	}
+	refreshExpiration, err := time.ParseDuration(cfg.JWT.RefreshExpiration)
+	if err != nil {
+		log.Fatalf("Failed to parse JWT refresh expiration: %v", err)
+	}
+	loginBaseDelay, err := time.ParseDuration(cfg.Login.BaseDelay)
+	if err != nil {
+		log.Fatalf("Failed to parse login base delay: %v", err)
+	}
+	loginLockoutDuration, err := time.ParseDuration(cfg.Login.LockoutDuration)
+	if err != nil {
+		log.Fatalf("Failed to parse login lockout duration: %v", err)
+	}
+	var twoFactorRequiredRoles []string
+	for _, role := range strings.Split(cfg.TwoFactor.RequiredRoles, ",") {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			continue
+		}
+		if _, ok := models.RoleHierarchy[role]; !ok {
+			log.Fatalf("Unknown role in two-factor policy: %s", role)
+		}
+		twoFactorRequiredRoles = append(twoFactorRequiredRoles, role)
+	}
+
+	oidcRoleMapping, err := services.ParseClaimMapping(cfg.OIDC.RoleMapping)
+	if err != nil {
+		log.Fatalf("Failed to parse OIDC role mapping: %v", err)
+	}
+	for _, role := range append(mapValues(oidcRoleMapping), cfg.OIDC.DefaultRole) {
+		if _, ok := models.RoleHierarchy[role]; !ok {
+			log.Fatalf("Unknown role in OIDC role mapping: %s", role)
+		}
+	}
+	oidcGroupMapping, err := services.ParseClaimMapping(cfg.OIDC.GroupMapping)
+	if err != nil {
+		log.Fatalf("Failed to parse OIDC group mapping: %v", err)
+	}
+	var oidcScopes []string
+	for _, scope := range strings.Split(cfg.OIDC.Scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			oidcScopes = append(oidcScopes, scope)
+		}
+	}
+	ldapRoleMapping, err := services.ParseClaimMapping(cfg.LDAP.RoleMapping)
+	if err != nil {
+		log.Fatalf("Failed to parse LDAP role mapping: %v", err)
+	}
+	for _, role := range append(mapValues(ldapRoleMapping), cfg.LDAP.DefaultRole) {
+		if _, ok := models.RoleHierarchy[role]; !ok {
+			log.Fatalf("Unknown role in LDAP role mapping: %s", role)
+		}
+	}
+	ldapGroupMapping, err := services.ParseClaimMapping(cfg.LDAP.GroupMapping)
+	if err != nil {
+		log.Fatalf("Failed to parse LDAP group mapping: %v", err)
+	}
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, cfg.JWT.Secret, jwtExpiration)
+	permissionService := services.NewPermissionService(permissionRepo)
+	if err := permissionService.Load(); err != nil {
+		log.Fatalf("Failed to load role permissions: %v", err)
+	}
+	userService := services.NewUserService(userRepo)
 	eventService := services.NewEventService(eventRepo)
 	roomService := services.NewRoomService(roomRepo)
-	subjectService := services.NewSubjectService(subjectRepo)
-	courseService := services.NewCourseService(courseRepo, subjectRepo, userRepo, roomRepo)
+	subjectService := services.NewSubjectService(subjectRepo, userRepo)
+	notificationService := services.NewNotificationService(notificationRepo, groupRepo)
+	courseService := services.NewCourseService(courseRepo, subjectRepo, userRepo, roomRepo, groupRepo, availabilityRepo, notificationService)
+	timetableService := services.NewTimetableService(courseService, courseRepo, subjectRepo, userRepo, roomRepo, groupRepo, availabilityRepo)
+	availabilityService := services.NewTeacherAvailabilityService(availabilityRepo, userRepo, permissionService)
+	reportService := services.NewReportService(courseRepo, userRepo, roomRepo, presenceRepo)
+	userImportService := services.NewUserImportService(userRepo, groupRepo, permissionService)
+	icsImportService := services.NewICSImportService(courseService, eventService, subjectRepo, userRepo, roomRepo, groupRepo)
+	calendarFeedService := services.NewCalendarFeedService(calendarFeedRepo, courseRepo, groupRepo, eventRepo, userRepo, roomRepo)
+	groupService := services.NewGroupService(groupRepo, userRepo, courseRepo)
+	roomBookingService := services.NewRoomBookingService(roomBookingRepo, roomRepo, courseRepo, notificationService, permissionService)
+	curriculumService := services.NewCurriculumService(subjectQuotaRepo, subjectRepo, groupRepo, courseRepo)
 	auditLogService := services.NewAuditLogService(auditLogRepo)
-	absenceService := services.NewAbsenceService(absenceRepo, courseRepo, userRepo)
-	presenceService := services.NewPresenceService(presenceRepo, courseRepo, userRepo)
+	loginThrottleService := services.NewLoginThrottleService(loginThrottleRepo, userRepo, auditLogService, services.LoginThrottleSettings{
+		DelayAfter:         cfg.Login.DelayAfter,
+		BaseDelay:          loginBaseDelay,
+		MaxAccountFailures: cfg.Login.MaxAccountFailures,
+		MaxIPFailures:      cfg.Login.MaxIPFailures,
+		LockoutDuration:    loginLockoutDuration,
+	})
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, cfg.TwoFactor.Issuer, twoFactorRequiredRoles)
+	ldapService := services.NewLDAPService(services.LDAPSettings{
+		URL:                cfg.LDAP.URL,
+		StartTLS:           cfg.LDAP.StartTLS,
+		BindDN:             cfg.LDAP.BindDN,
+		BindPassword:       cfg.LDAP.BindPassword,
+		BaseDN:             cfg.LDAP.BaseDN,
+		UserFilter:         cfg.LDAP.UserFilter,
+		IDAttribute:        cfg.LDAP.IDAttribute,
+		EmailAttribute:     cfg.LDAP.EmailAttribute,
+		FirstNameAttribute: cfg.LDAP.FirstNameAttribute,
+		LastNameAttribute:  cfg.LDAP.LastNameAttribute,
+		GroupAttribute:     cfg.LDAP.GroupAttribute,
+		RoleMapping:        ldapRoleMapping,
+		GroupMapping:       ldapGroupMapping,
+		DefaultRole:        cfg.LDAP.DefaultRole,
+	}, userRepo, groupRepo, sessionRepo)
+	authService := services.NewAuthService(userRepo, sessionRepo, loginThrottleService, twoFactorService, ldapService, cfg.JWT.Secret, jwtExpiration, refreshExpiration)
+	oidcService := services.NewOIDCService(services.OIDCSettings{
+		IssuerURL:    cfg.OIDC.IssuerURL,
+		ClientID:     cfg.OIDC.ClientID,
+		ClientSecret: cfg.OIDC.ClientSecret,
+		RedirectURL:  cfg.OIDC.RedirectURL,
+		Scopes:       oidcScopes,
+		GroupsClaim:  cfg.OIDC.GroupsClaim,
+		RoleMapping:  oidcRoleMapping,
+		GroupMapping: oidcGroupMapping,
+		DefaultRole:  cfg.OIDC.DefaultRole,
+	}, oidcRepo, userRepo, groupRepo, sessionRepo, authService)
+	passwordResetService := services.NewPasswordResetService(userRepo, passwordResetRepo, sessionRepo, auditLogService, newMailSender(cfg.Mail), cfg.Mail.PasswordResetURL)
+	absenceService := services.NewAbsenceService(absenceRepo, courseRepo, userRepo, permissionService)
+	presenceService := services.NewPresenceService(presenceRepo, courseRepo, userRepo, permissionService)
+	deletionService := services.NewDeletionService(userRepo, courseRepo, roomRepo, subjectRepo, permissionService)
 
 	// Initialize controllers
-	userController := controllers.NewUserController(userService)
+	userController := controllers.NewUserController(userService, authService, permissionService)
 	eventController := controllers.NewEventController(eventService)
 	roomController := controllers.NewRoomController(roomService)
-	subjectController := controllers.NewSubjectController(subjectService)
-	courseController := controllers.NewCourseController(courseService)
+	subjectController := controllers.NewSubjectController(subjectService, permissionService)
+	courseController := controllers.NewCourseController(courseService, permissionService)
 	auditLogController := controllers.NewAuditLogController(auditLogService)
 	absenceController := controllers.NewAbsenceController(absenceService)
 	presenceController := controllers.NewPresenceController(presenceService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	timetableController := controllers.NewTimetableController(timetableService)
+	availabilityController := controllers.NewTeacherAvailabilityController(availabilityService)
+	reportController := controllers.NewReportController(reportService, permissionService)
+	calendarFeedController := controllers.NewCalendarFeedController(calendarFeedService)
+	importController := controllers.NewImportController(icsImportService, userImportService, permissionService)
+	groupController := controllers.NewGroupController(groupService)
+	roomBookingController := controllers.NewRoomBookingController(roomBookingService)
+	curriculumController := controllers.NewCurriculumController(curriculumService)
+	passwordResetController := controllers.NewPasswordResetController(passwordResetService)
+	twoFactorController := controllers.NewTwoFactorController(authService, twoFactorService, userService, permissionService)
+	oidcController := controllers.NewOIDCController(oidcService)
+	deletionController := controllers.NewDeletionController(deletionService)
+	permissionController := controllers.NewPermissionController(permissionService)
 
 	// Initialize middleware
-	authMiddleware := middlewares.NewAuthMiddleware(cfg.JWT.Secret)
+	authMiddleware := middlewares.NewAuthMiddleware(cfg.JWT.Secret, authService, permissionService)
 	auditMiddleware := middlewares.NewAuditMiddleware(auditLogService)
+	rateLimits := make(map[string]middlewares.RateLimit)
+	for scope, value := range map[string]string{
+		middlewares.RateLimitGlobal: cfg.RateLimit.Global,
+		middlewares.RateLimitAuth:   cfg.RateLimit.Auth,
+		middlewares.RateLimitScan:   cfg.RateLimit.Scan,
+		middlewares.RateLimitImport: cfg.RateLimit.Import,
+	} {
+		limit, err := middlewares.ParseRateLimit(value)
+		if err != nil {
+			log.Fatalf("Failed to parse %s rate limit: %v", scope, err)
+		}
+		rateLimits[scope] = limit
+	}
+	rateLimitMiddleware := middlewares.NewRateLimitMiddleware(middlewares.NewMemoryRateLimitStore(), rateLimits)
 
 	// Initialize router
-	router := routes.NewRouter(userController, eventController, roomController, subjectController, courseController, auditLogController, absenceController, presenceController, authMiddleware, auditMiddleware)
+	router := routes.NewRouter(userController, eventController, roomController, subjectController, courseController, auditLogController, absenceController, presenceController, notificationController, timetableController, availabilityController, reportController, calendarFeedController, importController, groupController, roomBookingController, curriculumController, passwordResetController, twoFactorController, oidcController, deletionController, permissionController, authMiddleware, auditMiddleware, rateLimitMiddleware)
 	app := router.SetupRoutes()
 
+	// Sans proxy de confiance, ClientIP ignore X-Forwarded-For : un client ne peut pas choisir
+	// l'adresse utilisée par la limitation de débit et la protection de la connexion
+	var trustedProxies []string
+	for _, proxy := range strings.Split(cfg.Server.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := app.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
 	// Create server
 	serverAddr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
 	server := &http.Server{
@@ -121,6 +290,23 @@ func main() {
 	log.Println("Server exited")
 }
 
+// newMailSender choisit l'envoi des e-mails selon la configuration
+func newMailSender(cfg config.MailConfig) mail.Sender {
+	if cfg.Driver == "smtp" {
+		return mail.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	}
+	return mail.NewLogSender()
+}
+
+// mapValues retourne les valeurs d'une correspondance
+func mapValues(mapping map[string]string) []string {
+	values := make([]string, 0, len(mapping))
+	for _, value := range mapping {
+		values = append(values, value)
+	}
+	return values
+}
+
 // createDefaultSuperAdmin crée un super admin par défaut s'il n'existe pas
 func createDefaultSuperAdmin() {
 	db := database.GetDB()
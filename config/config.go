@@ -3,20 +3,29 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	CORS     CORSConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	CORS      CORSConfig
+	Mail      MailConfig
+	Login     LoginConfig
+	RateLimit RateLimitConfig
+	TwoFactor TwoFactorConfig
+	OIDC      OIDCConfig
+	LDAP      LDAPConfig
 }
 
 type ServerConfig struct {
-	Port string
-	Host string
+	Port           string
+	Host           string
+	TrustedProxies string // Proxys autorisés à transmettre l'IP du client (X-Forwarded-For), IP ou CIDR séparés par des virgules
+	TimeZone       string // Fuseau horaire de l'établissement (nom IANA), utilisé pour les disponibilités des enseignants
 }
 
 type DatabaseConfig struct {
@@ -29,14 +38,78 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string
-	Expiration string
+	Secret            string
+	Expiration        string // Durée de validité des jetons d'accès
+	RefreshExpiration string // Durée de validité des jetons de rafraîchissement
 }
 
 type CORSConfig struct {
 	AllowedOrigins string
 }
 
+type MailConfig struct {
+	Driver           string // "log" (développement) ou "smtp"
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUsername     string
+	SMTPPassword     string
+	From             string
+	PasswordResetURL string // Page du frontend qui reçoit le jeton de réinitialisation
+}
+
+// LoginConfig règle la protection contre les attaques par force brute sur la connexion
+type LoginConfig struct {
+	DelayAfter         int    // Nombre d'échecs à partir duquel chaque tentative est retardée
+	BaseDelay          string // Délai après DelayAfter échecs, doublé à chaque échec suivant
+	MaxAccountFailures int    // Nombre d'échecs avant verrouillage d'un compte
+	MaxIPFailures      int    // Nombre d'échecs avant blocage d'une adresse IP
+	LockoutDuration    string // Durée du verrouillage, et fenêtre au-delà de laquelle les échecs sont oubliés
+}
+
+// RateLimitConfig définit les limites de requêtes par portée, au format "<requêtes>/<s|m|h>" ("0" pour désactiver)
+type RateLimitConfig struct {
+	Global string // Toutes les requêtes, par adresse IP
+	Auth   string // Routes /auth, par adresse IP
+	Scan   string // Scan des QR codes de présence, par utilisateur
+	Import string // Imports de fichiers, par utilisateur
+}
+
+type TwoFactorConfig struct {
+	Issuer        string // Nom affiché dans les applications d'authentification
+	RequiredRoles string // Rôles pour lesquels la double authentification est obligatoire, séparés par des virgules
+}
+
+// OIDCConfig décrit le fournisseur d'identité OpenID Connect ; la connexion unique est désactivée sans IssuerURL
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // Page du frontend qui reçoit le code d'autorisation
+	Scopes       string // Séparés par des virgules
+	GroupsClaim  string // Revendication contenant les groupes de l'utilisateur
+	RoleMapping  string // Groupes du fournisseur associés à un rôle, au format "groupe=rôle,groupe=rôle"
+	GroupMapping string // Groupes du fournisseur associés à un groupe d'étudiants, au format "groupe=nom du groupe,..."
+	DefaultRole  string // Rôle des utilisateurs créés sans groupe associé à un rôle
+}
+
+// LDAPConfig décrit l'annuaire LDAP / Active Directory ; l'authentification par annuaire est désactivée sans URL
+type LDAPConfig struct {
+	URL                string // ldap://hôte:389 ou ldaps://hôte:636
+	StartTLS           bool
+	BindDN             string // Compte de service utilisé pour les recherches
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string // Filtre des comptes EduQR
+	IDAttribute        string // Attribut stable identifiant un compte (objectGUID, entryUUID)
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+	GroupAttribute     string // Attribut listant les DN des groupes du compte
+	RoleMapping        string // CN de groupes associés à un rôle, au format "groupe=rôle,groupe=rôle"
+	GroupMapping       string // CN de groupes associés à un groupe d'étudiants, au format "groupe=nom du groupe,..."
+	DefaultRole        string // Rôle des utilisateurs créés sans groupe associé à un rôle
+}
+
 func LoadConfig() *Config {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
@@ -45,8 +118,10 @@ func LoadConfig() *Config {
 
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8081"),
-			Host: getEnv("SERVER_HOST", "localhost"),
+			Port:           getEnv("SERVER_PORT", "8081"),
+			Host:           getEnv("SERVER_HOST", "localhost"),
+			TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+			TimeZone:       getEnv("SCHOOL_TIMEZONE", "Europe/Paris"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -57,12 +132,66 @@ func LoadConfig() *Config {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
-			Expiration: getEnv("JWT_EXPIRATION", "24h"),
+			Secret:            getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
+			Expiration:        getEnv("JWT_EXPIRATION", "15m"),
+			RefreshExpiration: getEnv("JWT_REFRESH_EXPIRATION", "720h"),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:3001"),
 		},
+		Mail: MailConfig{
+			Driver:           getEnv("MAIL_DRIVER", "log"),
+			SMTPHost:         getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:         getEnv("SMTP_PORT", "587"),
+			SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+			From:             getEnv("MAIL_FROM", "no-reply@eduqr.com"),
+			PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		},
+		Login: LoginConfig{
+			DelayAfter:         getEnvInt("LOGIN_DELAY_AFTER", 3),
+			BaseDelay:          getEnv("LOGIN_BASE_DELAY", "1s"),
+			MaxAccountFailures: getEnvInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+			MaxIPFailures:      getEnvInt("LOGIN_MAX_IP_FAILURES", 20),
+			LockoutDuration:    getEnv("LOGIN_LOCKOUT_DURATION", "15m"),
+		},
+		RateLimit: RateLimitConfig{
+			Global: getEnv("RATE_LIMIT_GLOBAL", "600/m"),
+			Auth:   getEnv("RATE_LIMIT_AUTH", "20/m"),
+			Scan:   getEnv("RATE_LIMIT_SCAN", "10/m"),
+			Import: getEnv("RATE_LIMIT_IMPORT", "10/h"),
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:        getEnv("TWO_FACTOR_ISSUER", "EduQR"),
+			RequiredRoles: getEnv("TWO_FACTOR_REQUIRED_ROLES", ""),
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:3000/auth/oidc/callback"),
+			Scopes:       getEnv("OIDC_SCOPES", "openid,profile,email"),
+			GroupsClaim:  getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			RoleMapping:  getEnv("OIDC_ROLE_MAPPING", ""),
+			GroupMapping: getEnv("OIDC_GROUP_MAPPING", ""),
+			DefaultRole:  getEnv("OIDC_DEFAULT_ROLE", "etudiant"),
+		},
+		LDAP: LDAPConfig{
+			URL:                getEnv("LDAP_URL", ""),
+			StartTLS:           getEnvBool("LDAP_START_TLS", false),
+			BindDN:             getEnv("LDAP_BIND_DN", ""),
+			BindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+			BaseDN:             getEnv("LDAP_BASE_DN", ""),
+			UserFilter:         getEnv("LDAP_USER_FILTER", "(objectClass=person)"),
+			IDAttribute:        getEnv("LDAP_ID_ATTRIBUTE", "objectGUID"),
+			EmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+			FirstNameAttribute: getEnv("LDAP_FIRST_NAME_ATTRIBUTE", "givenName"),
+			LastNameAttribute:  getEnv("LDAP_LAST_NAME_ATTRIBUTE", "sn"),
+			GroupAttribute:     getEnv("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+			RoleMapping:        getEnv("LDAP_ROLE_MAPPING", ""),
+			GroupMapping:       getEnv("LDAP_GROUP_MAPPING", ""),
+			DefaultRole:        getEnv("LDAP_DEFAULT_ROLE", "etudiant"),
+		},
 	}
 }
 
@@ -72,3 +201,29 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, using %d", key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid value for %s, using %t", key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
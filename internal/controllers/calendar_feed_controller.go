@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CalendarFeedController struct {
+	feedService *services.CalendarFeedService
+}
+
+func NewCalendarFeedController(feedService *services.CalendarFeedService) *CalendarFeedController {
+	return &CalendarFeedController{
+		feedService: feedService,
+	}
+}
+
+// CreateFeed crée un flux iCalendar pour l'utilisateur connecté
+func (c *CalendarFeedController) CreateFeed(ctx *gin.Context) {
+	var req models.CreateCalendarFeedRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	feed, token, err := c.feedService.CreateFeed(ctx.GetUint("user_id"), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := feed.ToCalendarFeedResponse()
+	response.URL = feedURL(ctx, token)
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    response,
+		"message": "Flux créé avec succès. Conservez cette URL, elle ne sera plus affichée.",
+	})
+}
+
+// GetMyFeeds récupère les flux de l'utilisateur connecté
+func (c *CalendarFeedController) GetMyFeeds(ctx *gin.Context) {
+	feeds, err := c.feedService.GetUserFeeds(ctx.GetUint("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des flux"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    feeds,
+	})
+}
+
+// RevokeFeed révoque un flux de l'utilisateur connecté
+func (c *CalendarFeedController) RevokeFeed(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
+	}
+
+	if err := c.feedService.RevokeFeed(uint(id), ctx.GetUint("user_id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Flux non trouvé"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la révocation du flux"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Flux révoqué avec succès",
+	})
+}
+
+// GetFeed sert le calendrier iCalendar associé à un jeton (sans authentification)
+func (c *CalendarFeedController) GetFeed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	content, err := c.feedService.RenderFeed(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Flux non trouvé"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la génération du flux"})
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=300")
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", content)
+}
+
+// feedURL construit l'URL d'abonnement d'un flux à partir de la requête courante
+func feedURL(ctx *gin.Context, token string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + ctx.Request.Host + "/api/v1/calendar/feeds/" + token + ".ics"
+}
@@ -12,12 +12,14 @@ import (
 )
 
 type CourseController struct {
-	courseService *services.CourseService
+	courseService     *services.CourseService
+	permissionService *services.PermissionService
 }
 
-func NewCourseController(courseService *services.CourseService) *CourseController {
+func NewCourseController(courseService *services.CourseService, permissionService *services.PermissionService) *CourseController {
 	return &CourseController{
-		courseService: courseService,
+		courseService:     courseService,
+		permissionService: permissionService,
 	}
 }
 
@@ -79,38 +81,62 @@ func (c *CourseController) CreateCourse(ctx *gin.Context) {
 
 // UpdateCourse met à jour un cours existant
 func (c *CourseController) UpdateCourse(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, ok := c.parseOwnedCourseID(ctx, models.PermCourseUpdateAny, models.PermCourseUpdateOwn, false, "Vous ne pouvez modifier que vos propres cours")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCourseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	course, err := c.courseService.UpdateCourse(id, &req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Vérifier les permissions
-	userID := ctx.GetUint("user_id")
-	userRole := ctx.GetString("user_role")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    course,
+		"message": "Cours mis à jour avec succès",
+	})
+}
 
-	// Si c'est un professeur, vérifier qu'il modifie son propre cours
-	if userRole == "professeur" {
-		course, err := c.courseService.GetCourseByID(uint(id))
-		if err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Cours non trouvé"})
-			return
-		}
+// DeleteCourse supprime un cours
+func (c *CourseController) DeleteCourse(ctx *gin.Context) {
+	id, ok := c.parseOwnedCourseID(ctx, models.PermCourseDeleteAny, models.PermCourseDeleteOwn, false, "Vous ne pouvez supprimer que vos propres cours")
+	if !ok {
+		return
+	}
 
-		if course.Teacher.ID != userID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez modifier que vos propres cours"})
-			return
-		}
+	if err := c.courseService.DeleteCourse(id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la suppression du cours"})
+		return
 	}
 
-	var req models.UpdateCourseRequest
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Cours supprimé avec succès",
+	})
+}
+
+// CancelCourse annule un cours sans le supprimer
+func (c *CourseController) CancelCourse(ctx *gin.Context) {
+	id, ok := c.parseOwnedCourseID(ctx, models.PermCourseUpdateAny, models.PermCourseUpdateOwn, true, "Vous ne pouvez annuler que vos propres cours")
+	if !ok {
+		return
+	}
+
+	var req models.CancelCourseRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
 		return
 	}
 
-	course, err := c.courseService.UpdateCourse(uint(id), &req)
+	course, err := c.courseService.CancelCourse(id, &req, ctx.GetUint("user_id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -119,12 +145,58 @@ func (c *CourseController) UpdateCourse(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    course,
-		"message": "Cours mis à jour avec succès",
+		"message": "Cours annulé avec succès",
 	})
 }
 
-// DeleteCourse supprime un cours
-func (c *CourseController) DeleteCourse(ctx *gin.Context) {
+// RescheduleCourse déplace un cours sur un nouveau créneau
+func (c *CourseController) RescheduleCourse(ctx *gin.Context) {
+	id, ok := c.parseOwnedCourseID(ctx, models.PermCourseUpdateAny, models.PermCourseUpdateOwn, true, "Vous ne pouvez déplacer que vos propres cours")
+	if !ok {
+		return
+	}
+
+	var req models.RescheduleCourseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	course, err := c.courseService.RescheduleCourse(id, &req, ctx.GetUint("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    course,
+		"message": "Cours déplacé avec succès",
+	})
+}
+
+// CompleteCourse marque un cours comme effectué
+func (c *CourseController) CompleteCourse(ctx *gin.Context) {
+	id, ok := c.parseOwnedCourseID(ctx, models.PermCourseUpdateAny, models.PermCourseUpdateOwn, true, "Vous ne pouvez valider que vos propres cours")
+	if !ok {
+		return
+	}
+
+	course, err := c.courseService.CompleteCourse(id, ctx.GetUint("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    course,
+		"message": "Cours marqué comme effectué",
+	})
+}
+
+// AssignSubstitute affecte un enseignant remplaçant à une occurrence
+func (c *CourseController) AssignSubstitute(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -132,36 +204,104 @@ func (c *CourseController) DeleteCourse(ctx *gin.Context) {
 		return
 	}
 
-	// Vérifier les permissions
-	userID := ctx.GetUint("user_id")
-	userRole := ctx.GetString("user_role")
+	var req models.AssignSubstituteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
 
-	// Si c'est un professeur, vérifier qu'il supprime son propre cours
-	if userRole == "professeur" {
-		course, err := c.courseService.GetCourseByID(uint(id))
-		if err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Cours non trouvé"})
-			return
-		}
+	course, err := c.courseService.AssignSubstitute(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		if course.Teacher.ID != userID {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez supprimer que vos propres cours"})
-			return
-		}
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    course,
+		"message": "Remplaçant affecté avec succès",
+	})
+}
+
+// RemoveSubstitute retire le remplaçant d'une occurrence
+func (c *CourseController) RemoveSubstitute(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
 	}
 
-	err = c.courseService.DeleteCourse(uint(id))
+	course, err := c.courseService.RemoveSubstitute(uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la suppression du cours"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Cours supprimé avec succès",
+		"data":    course,
+		"message": "Remplaçant retiré avec succès",
 	})
 }
 
+// GetCourseHistory récupère l'historique des statuts d'un cours
+func (c *CourseController) GetCourseHistory(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
+	}
+
+	history, err := c.courseService.GetCourseHistory(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    history,
+	})
+}
+
+// parseOwnedCourseID lit l'ID du cours et vérifie que l'utilisateur a la permission anyPermission
+// ou, avec ownPermission (un professeur), qu'il est le titulaire du cours. Avec allowSubstitute, le remplaçant
+// de l'occurrence a les mêmes droits ; la modification et la suppression restent réservées au titulaire,
+// car elles s'appliquent à toute la série quand le cours en est le premier.
+func (c *CourseController) parseOwnedCourseID(ctx *gin.Context, anyPermission, ownPermission string, allowSubstitute bool, forbiddenMessage string) (uint, bool) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return 0, false
+	}
+
+	userRole := ctx.GetString("user_role")
+	if !c.permissionService.HasPermission(userRole, anyPermission) {
+		if !c.permissionService.HasPermission(userRole, ownPermission) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Permissions insuffisantes"})
+			return 0, false
+		}
+
+		course, err := c.courseService.GetCourseByID(uint(id))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Cours non trouvé"})
+			return 0, false
+		}
+
+		userID := ctx.GetUint("user_id")
+		isSubstitute := course.SubstituteTeacher != nil && course.SubstituteTeacher.ID == userID
+		if course.Teacher.ID != userID && !(allowSubstitute && isSubstitute) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": forbiddenMessage})
+			return 0, false
+		}
+	}
+
+	return uint(id), true
+}
+
 // GetCoursesByDateRange récupère les cours dans une plage de dates
 func (c *CourseController) GetCoursesByDateRange(ctx *gin.Context) {
 	startDateStr := ctx.Query("start_date")
@@ -251,6 +391,27 @@ func (c *CourseController) GetCoursesByTeacher(ctx *gin.Context) {
 	})
 }
 
+// FindAvailableRooms recherche les salles libres sur un créneau
+func (c *CourseController) FindAvailableRooms(ctx *gin.Context) {
+	var filter models.AvailableRoomFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Paramètres invalides : start et end sont requis (RFC 3339)"})
+		return
+	}
+
+	rooms, err := c.courseService.FindAvailableRooms(&filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rooms,
+		"total":   len(rooms),
+	})
+}
+
 // CheckConflicts vérifie les conflits pour un cours
 func (c *CourseController) CheckConflicts(ctx *gin.Context) {
 	var req models.CreateCourseRequest
@@ -265,10 +426,17 @@ func (c *CourseController) CheckConflicts(ctx *gin.Context) {
 		return
 	}
 
+	warnings, err := c.courseService.CheckAvailability(&req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la vérification des disponibilités"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"success":       true,
 		"data":          conflicts,
 		"has_conflicts": len(conflicts) > 0,
+		"warnings":      warnings,
 	})
 }
 
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CurriculumController struct {
+	curriculumService *services.CurriculumService
+}
+
+func NewCurriculumController(curriculumService *services.CurriculumService) *CurriculumController {
+	return &CurriculumController{curriculumService: curriculumService}
+}
+
+// GetQuotas récupère les quotas horaires (filtres subject_id, group_id, term)
+func (c *CurriculumController) GetQuotas(ctx *gin.Context) {
+	var filter models.SubjectQuotaFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quotas, err := c.curriculumService.GetQuotas(&filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":  quotas,
+		"total": len(quotas),
+	})
+}
+
+// GetQuotaByID récupère un quota horaire par son ID
+func (c *CurriculumController) GetQuotaByID(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	quota, err := c.curriculumService.GetQuotaByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Quota non trouvé"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": quota})
+}
+
+// CreateQuota fixe le volume horaire d'une matière pour un groupe et une période
+func (c *CurriculumController) CreateQuota(ctx *gin.Context) {
+	var req models.CreateSubjectQuotaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := c.curriculumService.CreateQuota(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": quota})
+}
+
+// UpdateQuota modifie un quota horaire
+func (c *CurriculumController) UpdateQuota(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSubjectQuotaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := c.curriculumService.UpdateQuota(id, &req)
+	if err != nil {
+		respondQuotaError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": quota})
+}
+
+// DeleteQuota supprime un quota horaire
+func (c *CurriculumController) DeleteQuota(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.curriculumService.DeleteQuota(id); err != nil {
+		respondQuotaError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Quota supprimé avec succès"})
+}
+
+// GetProgress compare les heures planifiées et effectuées aux quotas
+// (?date=YYYY-MM-DD, aujourd'hui par défaut ; ?behind_only=true pour les seules matières en retard)
+func (c *CurriculumController) GetProgress(ctx *gin.Context) {
+	var filter models.SubjectQuotaFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, ok := parseOptionalDate(ctx, "date")
+	if !ok {
+		return
+	}
+	at := time.Now()
+	if date != nil {
+		at = *date
+	}
+
+	progress, err := c.curriculumService.GetProgress(&filter, at)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	behind := 0
+	for _, item := range progress {
+		if item.Behind {
+			behind++
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":   progress,
+		"total":  len(progress),
+		"behind": behind,
+	})
+}
+
+func respondQuotaError(ctx *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Quota non trouvé"})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GroupController struct {
+	groupService *services.GroupService
+}
+
+func NewGroupController(groupService *services.GroupService) *GroupController {
+	return &GroupController{groupService: groupService}
+}
+
+// GetAllGroups récupère tous les groupes
+func (c *GroupController) GetAllGroups(ctx *gin.Context) {
+	groups, err := c.groupService.GetAllGroups()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":  groups,
+		"total": len(groups),
+	})
+}
+
+// GetGroupByID récupère un groupe par son ID
+func (c *GroupController) GetGroupByID(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	group, err := c.groupService.GetGroupByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Groupe non trouvé"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": group})
+}
+
+// CreateGroup crée un nouveau groupe
+func (c *GroupController) CreateGroup(ctx *gin.Context) {
+	var req models.CreateGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := c.groupService.CreateGroup(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": group})
+}
+
+// UpdateGroup met à jour un groupe
+func (c *GroupController) UpdateGroup(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := c.groupService.UpdateGroup(id, &req)
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": group})
+}
+
+// DeleteGroup supprime un groupe
+func (c *GroupController) DeleteGroup(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.groupService.DeleteGroup(id); err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Groupe supprimé avec succès"})
+}
+
+// GetMembers récupère les membres d'un groupe à une date (?date=YYYY-MM-DD, aujourd'hui par défaut)
+// ou tout l'historique des appartenances (?history=true)
+func (c *GroupController) GetMembers(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var at *time.Time
+	if ctx.Query("history") != "true" {
+		date, ok := parseOptionalDate(ctx, "date")
+		if !ok {
+			return
+		}
+		now := time.Now()
+		if date == nil {
+			date = &now
+		}
+		at = date
+	}
+
+	members, err := c.groupService.GetMembers(id, at)
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":  members,
+		"total": len(members),
+	})
+}
+
+// AddMembers ajoute des étudiants à un groupe
+func (c *GroupController) AddMembers(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AddGroupMembersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	members, err := c.groupService.AddMembers(id, &req)
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"data":    members,
+		"message": "Étudiants ajoutés au groupe avec succès",
+	})
+}
+
+// RemoveMember retire un étudiant d'un groupe (?end_date=YYYY-MM-DD, aujourd'hui par défaut)
+func (c *GroupController) RemoveMember(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseUintParam(ctx, "userId")
+	if !ok {
+		return
+	}
+	endDate, ok := parseOptionalDate(ctx, "end_date")
+	if !ok {
+		return
+	}
+
+	if err := c.groupService.RemoveMember(id, userID, endDate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Étudiant retiré du groupe avec succès"})
+}
+
+// MoveMember déplace un étudiant vers un autre groupe à une date d'effet
+func (c *GroupController) MoveMember(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req models.MoveGroupMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	membership, err := c.groupService.MoveMember(id, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":    membership,
+		"message": "Étudiant déplacé avec succès",
+	})
+}
+
+// GetGroupTimetable récupère l'emploi du temps d'un groupe sur une période
+func (c *GroupController) GetGroupTimetable(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseReportPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	courses, err := c.groupService.GetGroupTimetable(id, startDate, endDate, ctx.Query("include_subgroups") == "true")
+	if err != nil {
+		respondGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":  courses,
+		"total": len(courses),
+	})
+}
+
+// parseUintParam lit un identifiant numérique dans les paramètres de l'URL
+func parseUintParam(ctx *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(param), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseOptionalDate lit une date facultative (YYYY-MM-DD) dans la query string
+func parseOptionalDate(ctx *gin.Context, key string) (*time.Time, bool) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, true
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Format de date invalide (YYYY-MM-DD)"})
+		return nil, false
+	}
+	return &date, true
+}
+
+func respondGroupError(ctx *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Groupe non trouvé"})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
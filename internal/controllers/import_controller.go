@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize limite la taille des fichiers importés
+const maxImportFileSize = 5 << 20
+
+type ImportController struct {
+	icsImportService  *services.ICSImportService
+	userImportService *services.UserImportService
+	permissionService *services.PermissionService
+}
+
+func NewImportController(icsImportService *services.ICSImportService, userImportService *services.UserImportService, permissionService *services.PermissionService) *ImportController {
+	return &ImportController{
+		icsImportService:  icsImportService,
+		userImportService: userImportService,
+		permissionService: permissionService,
+	}
+}
+
+// ImportCoursesICS importe des cours depuis un fichier .ics (aperçu avec dry_run=true)
+func (c *ImportController) ImportCoursesICS(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+
+	var opts models.ICSImportOptions
+	if err := ctx.ShouldBind(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Paramètres invalides: " + err.Error()})
+		return
+	}
+
+	file, ok := openImportFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	report, err := c.icsImportService.ImportCourses(file, &opts)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+		"message": importMessage(report.DryRun),
+	})
+}
+
+// ImportEventsICS importe des événements personnels depuis un fichier .ics (aperçu avec dry_run=true)
+func (c *ImportController) ImportEventsICS(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+	dryRun := ctx.PostForm("dry_run") == "true" || ctx.Query("dry_run") == "true"
+
+	file, ok := openImportFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	report, err := c.icsImportService.ImportEvents(file, ctx.GetUint("user_id"), dryRun)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+		"message": importMessage(report.DryRun),
+	})
+}
+
+// ImportUsers importe des utilisateurs depuis un fichier CSV ou XLSX (aperçu avec dry_run=true)
+func (c *ImportController) ImportUsers(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+
+	userRole := ctx.GetString("user_role")
+	// Seuls les utilisateurs pouvant gérer au moins les étudiants peuvent importer
+	if !c.permissionService.CanManageRole(userRole, models.RoleEtudiant) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Permissions insuffisantes pour importer des utilisateurs"})
+		return
+	}
+
+	var opts models.UserImportOptions
+	if err := ctx.ShouldBind(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Paramètres invalides: " + err.Error()})
+		return
+	}
+	if mapping := ctx.PostForm("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Mapping des colonnes invalide (objet JSON attendu)"})
+			return
+		}
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Fichier requis (champ \"file\", 5 Mo maximum)"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Impossible de lire le fichier"})
+		return
+	}
+	defer file.Close()
+
+	report, err := c.userImportService.ImportUsers(header.Filename, file, userRole, &opts)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+		"message": importMessage(report.DryRun),
+	})
+}
+
+// openImportFile ouvre le fichier envoyé dans le champ "file" du formulaire
+func openImportFile(ctx *gin.Context) (multipart.File, bool) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Fichier requis (champ \"file\", 5 Mo maximum)"})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Impossible de lire le fichier"})
+		return nil, false
+	}
+	return file, true
+}
+
+// importMessage retourne le message associé à un import ou à un aperçu
+func importMessage(dryRun bool) string {
+	if dryRun {
+		return "Aperçu de l'import : aucune donnée n'a été créée"
+	}
+	return "Import terminé"
+}
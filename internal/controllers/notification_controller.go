@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationController struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationController(notificationService *services.NotificationService) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+	}
+}
+
+// GetMyNotifications récupère les notifications de l'utilisateur connecté
+func (c *NotificationController) GetMyNotifications(ctx *gin.Context) {
+	userID := ctx.GetUint("user_id")
+	unreadOnly := ctx.Query("unread") == "true"
+
+	notifications, err := c.notificationService.GetUserNotifications(userID, unreadOnly)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des notifications"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    notifications,
+	})
+}
+
+// MarkAsRead marque une notification de l'utilisateur connecté comme lue
+func (c *NotificationController) MarkAsRead(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
+	}
+
+	if err := c.notificationService.MarkAsRead(uint(id), ctx.GetUint("user_id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Notification non trouvée"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour de la notification"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification marquée comme lue",
+	})
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// oidcBrowserCookie porte le secret qui lie une connexion OIDC au navigateur qui l'a démarrée
+	oidcBrowserCookie = "eduqr_oidc_login"
+	// oidcBrowserCookieTTL couvre l'aller-retour chez le fournisseur d'identité
+	oidcBrowserCookieTTL = 10 * time.Minute
+)
+
+type OIDCController struct {
+	oidcService *services.OIDCService
+}
+
+func NewOIDCController(oidcService *services.OIDCService) *OIDCController {
+	return &OIDCController{oidcService: oidcService}
+}
+
+// @Summary Start single sign-on
+// @Description Get the identity provider URL to redirect the user to. The provider sends the user back to the configured redirect URL with a code and a state. An HttpOnly cookie binds the login to this browser and must be sent back with the callback.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.OIDCAuthorizationResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /auth/oidc/login [get]
+func (c *OIDCController) Authorize(ctx *gin.Context) {
+	url, browserSecret, err := c.oidcService.AuthorizationURL()
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Le cookie n'est renvoyé qu'aux routes OIDC ; SameSite=Lax l'écarte des requêtes venant d'un autre site
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcBrowserCookie, browserSecret, int(oidcBrowserCookieTTL.Seconds()), path.Dir(ctx.FullPath()), "", ctx.Request.TLS != nil, true)
+	ctx.JSON(http.StatusOK, models.OIDCAuthorizationResponse{AuthorizationURL: url})
+}
+
+// @Summary Complete single sign-on
+// @Description Exchange the code and state received from the identity provider for tokens. The cookie set by the login route must match the state. Unknown users are created on their first login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.OIDCCallbackRequest true "Authorization code and state"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /auth/oidc/callback [post]
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	var req models.OIDCCallbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Sans cookie, la connexion est refusée par le service
+	browserSecret, _ := ctx.Cookie(oidcBrowserCookie)
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcBrowserCookie, "", -1, path.Dir(ctx.FullPath()), "", ctx.Request.TLS != nil, true)
+
+	tokens, user, err := c.oidcService.Login(ctx.Request.Context(), &req, browserSecret, sessionClient(ctx))
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		respondLoginError(ctx, err)
+		return
+	}
+
+	setLoginUserData(ctx, user)
+	ctx.JSON(http.StatusOK, LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
+	})
+}
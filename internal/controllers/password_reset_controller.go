@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"net/http"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetController struct {
+	passwordResetService *services.PasswordResetService
+}
+
+func NewPasswordResetController(passwordResetService *services.PasswordResetService) *PasswordResetController {
+	return &PasswordResetController{passwordResetService: passwordResetService}
+}
+
+// @Summary Forgot password
+// @Description Send a password reset link to the contact e-mail of the account. The response is the same whether the account exists or not.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.ForgotPasswordRequest true "Login e-mail"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /auth/forgot-password [post]
+func (c *PasswordResetController) ForgotPassword(ctx *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.passwordResetService.RequestReset(req.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Si un compte correspond à cette adresse, un lien de réinitialisation a été envoyé"})
+}
+
+// @Summary Reset password
+// @Description Set a new password with the token received by e-mail. All sessions of the user are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /auth/reset-password [post]
+func (c *PasswordResetController) ResetPassword(ctx *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate password strength
+	strength := models.ValidatePasswordStrength(req.NewPassword)
+	if !strength.IsValid {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":    "Le mot de passe ne respecte pas les critères de sécurité",
+			"strength": strength,
+		})
+		return
+	}
+
+	if err := c.passwordResetService.ResetPassword(&req, ctx.ClientIP(), ctx.GetHeader("User-Agent")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Mot de passe réinitialisé avec succès"})
+}
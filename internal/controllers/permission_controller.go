@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionController struct {
+	permissionService *services.PermissionService
+}
+
+func NewPermissionController(permissionService *services.PermissionService) *PermissionController {
+	return &PermissionController{permissionService: permissionService}
+}
+
+// @Summary Get the permission registry
+// @Description List every permission and the permissions granted to each role
+// @Tags permissions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.PermissionRegistryResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/permissions [get]
+func (c *PermissionController) GetRegistry(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.permissionService.GetRegistry())
+}
+
+// @Summary Update the permissions of a role
+// @Description Replace the permissions granted to a role. The super admin always keeps the permission to manage permissions.
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param body body models.UpdateRolePermissionsRequest true "Permissions granted to the role"
+// @Success 200 {object} models.RolePermissionsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/permissions/roles/{role} [put]
+func (c *PermissionController) UpdateRolePermissions(ctx *gin.Context) {
+	var req models.UpdateRolePermissionsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := c.permissionService.UpdateRolePermissions(ctx.Param("role"), req.Permissions)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController struct {
+	reportService     *services.ReportService
+	permissionService *services.PermissionService
+}
+
+func NewReportController(reportService *services.ReportService, permissionService *services.PermissionService) *ReportController {
+	return &ReportController{
+		reportService:     reportService,
+		permissionService: permissionService,
+	}
+}
+
+// GetTeacherWorkload récupère la charge d'enseignement des professeurs (filtre teacher_id optionnel)
+func (c *ReportController) GetTeacherWorkload(ctx *gin.Context) {
+	startDate, endDate, ok := parseReportPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	var teacherID uint64
+	if teacherIDStr := ctx.Query("teacher_id"); teacherIDStr != "" {
+		var err error
+		teacherID, err = strconv.ParseUint(teacherIDStr, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID enseignant invalide"})
+			return
+		}
+	}
+
+	workloads, err := c.reportService.GetTeacherWorkload(startDate, endDate, uint(teacherID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    workloads,
+	})
+}
+
+// GetMyTeacherWorkload récupère la charge d'enseignement d'un professeur (lui-même ou un utilisateur qui consulte les rapports)
+func (c *ReportController) GetMyTeacherWorkload(ctx *gin.Context) {
+	teacherID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID enseignant invalide"})
+		return
+	}
+
+	if !c.permissionService.HasPermission(ctx.GetString("user_role"), models.PermReportReadAny) && ctx.GetUint("user_id") != uint(teacherID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez consulter que votre propre charge d'enseignement"})
+		return
+	}
+
+	startDate, endDate, ok := parseReportPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	workloads, err := c.reportService.GetTeacherWorkload(startDate, endDate, uint(teacherID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    workloads[0],
+	})
+}
+
+// GetRoomOccupancy calcule l'occupation des salles par les cours (format=csv pour un export CSV)
+func (c *ReportController) GetRoomOccupancy(ctx *gin.Context) {
+	startDate, endDate, ok := parseReportPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	var filter models.RoomOccupancyFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := c.reportService.GetRoomOccupancy(startDate, endDate, &filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if ctx.Query("format") == "csv" {
+		content, err := c.reportService.RoomOccupancyCSV(report)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la génération du fichier CSV"})
+			return
+		}
+		ctx.Header("Content-Disposition", `attachment; filename="occupation-salles.csv"`)
+		ctx.Data(http.StatusOK, "text/csv; charset=utf-8", content)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// parseReportPeriod lit les paramètres start_date et end_date (YYYY-MM-DD, fin incluse)
+func parseReportPeriod(ctx *gin.Context) (time.Time, time.Time, bool) {
+	startDateStr := ctx.Query("start_date")
+	endDateStr := ctx.Query("end_date")
+
+	if startDateStr == "" || endDateStr == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Les dates de début et de fin sont requises"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Format de date de début invalide (YYYY-MM-DD)"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Format de date de fin invalide (YYYY-MM-DD)"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	// Inclure toute la journée de fin
+	return startDate, endDate.Add(24*time.Hour - time.Second), true
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RoomBookingController struct {
+	bookingService *services.RoomBookingService
+}
+
+func NewRoomBookingController(bookingService *services.RoomBookingService) *RoomBookingController {
+	return &RoomBookingController{bookingService: bookingService}
+}
+
+// CreateBooking soumet une demande de réservation de salle
+func (c *RoomBookingController) CreateBooking(ctx *gin.Context) {
+	var req models.CreateRoomBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	booking, err := c.bookingService.CreateBooking(&req, ctx.GetUint("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    booking,
+		"message": "Demande de réservation envoyée, en attente de validation",
+	})
+}
+
+// GetBookings récupère les réservations (toutes pour un administrateur, les siennes sinon)
+func (c *RoomBookingController) GetBookings(ctx *gin.Context) {
+	var filter models.RoomBookingFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookings, err := c.bookingService.GetBookings(&filter, ctx.GetUint("user_id"), ctx.GetString("user_role"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des réservations"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    bookings,
+		"total":   len(bookings),
+	})
+}
+
+// GetBookingByID récupère une réservation
+func (c *RoomBookingController) GetBookingByID(ctx *gin.Context) {
+	id, ok := c.authorizeBooking(ctx)
+	if !ok {
+		return
+	}
+
+	booking, err := c.bookingService.GetBookingByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Réservation non trouvée"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    booking,
+	})
+}
+
+// CancelBooking annule une réservation (demandeur ou administrateur)
+func (c *RoomBookingController) CancelBooking(ctx *gin.Context) {
+	id, ok := c.authorizeBooking(ctx)
+	if !ok {
+		return
+	}
+
+	booking, err := c.bookingService.CancelBooking(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    booking,
+		"message": "Réservation annulée avec succès",
+	})
+}
+
+// ReviewBooking valide ou refuse une demande de réservation (administrateurs)
+func (c *RoomBookingController) ReviewBooking(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
+	}
+
+	var req models.ReviewRoomBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := c.bookingService.ReviewBooking(uint(id), &req, ctx.GetUint("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    booking,
+	})
+}
+
+// GetRoomTimeline retrace la configuration d'une salle (entière, séparée ou réunie) sur une période
+func (c *RoomBookingController) GetRoomTimeline(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
+	}
+
+	startDate, endDate, ok := parseReportPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	timeline, err := c.bookingService.GetRoomTimeline(uint(id), startDate, endDate)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Salle non trouvée"})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    timeline,
+	})
+}
+
+// authorizeBooking lit l'ID de la réservation et vérifie que l'utilisateur peut y accéder
+func (c *RoomBookingController) authorizeBooking(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return 0, false
+	}
+
+	canAccess, err := c.bookingService.CanAccessBooking(uint(id), ctx.GetUint("user_id"), ctx.GetString("user_role"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Réservation non trouvée"})
+			return 0, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la vérification des permissions"})
+		return 0, false
+	}
+	if !canAccess {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez accéder qu'à vos propres réservations"})
+		return 0, false
+	}
+
+	return uint(id), true
+}
@@ -133,3 +133,17 @@ func (c *RoomController) GetModularRooms(ctx *gin.Context) {
 		"total": len(rooms),
 	})
 }
+
+// GetAllEquipment récupère les étiquettes d'équipement connues
+func (c *RoomController) GetAllEquipment(ctx *gin.Context) {
+	equipment, err := c.roomService.GetAllEquipment()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":  equipment,
+		"total": len(equipment),
+	})
+}
@@ -3,18 +3,21 @@ package controllers
 import (
 	"eduqr-backend/internal/models"
 	"eduqr-backend/internal/services"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type SubjectController struct {
-	subjectService *services.SubjectService
+	subjectService    *services.SubjectService
+	permissionService *services.PermissionService
 }
 
-func NewSubjectController(subjectService *services.SubjectService) *SubjectController {
-	return &SubjectController{subjectService: subjectService}
+func NewSubjectController(subjectService *services.SubjectService, permissionService *services.PermissionService) *SubjectController {
+	return &SubjectController{subjectService: subjectService, permissionService: permissionService}
 }
 
 // GetAllSubjects récupère toutes les matières
@@ -107,3 +110,116 @@ func (c *SubjectController) DeleteSubject(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Matière supprimée avec succès"})
 }
+
+// GetSubjectTeachers liste les enseignants qualifiés pour une matière
+func (c *SubjectController) GetSubjectTeachers(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	teachers, err := c.subjectService.GetSubjectTeachers(id)
+	if err != nil {
+		respondSubjectError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":  teachers,
+		"total": len(teachers),
+	})
+}
+
+// AddSubjectTeacher qualifie un enseignant pour une matière
+func (c *SubjectController) AddSubjectTeacher(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req models.SubjectTeacherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.subjectService.AddTeacher(id, req.TeacherID); err != nil {
+		respondSubjectError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Enseignant qualifié pour la matière"})
+}
+
+// RemoveSubjectTeacher retire la qualification d'un enseignant pour une matière
+func (c *SubjectController) RemoveSubjectTeacher(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+	teacherID, ok := parseUintParam(ctx, "teacherId")
+	if !ok {
+		return
+	}
+
+	if err := c.subjectService.RemoveTeacher(id, teacherID); err != nil {
+		respondSubjectError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Qualification retirée avec succès"})
+}
+
+// SetHeadTeacher désigne ou retire le responsable d'une matière
+func (c *SubjectController) SetHeadTeacher(ctx *gin.Context) {
+	id, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req models.HeadTeacherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subject, err := c.subjectService.SetHeadTeacher(id, req.TeacherID)
+	if err != nil {
+		respondSubjectError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": subject})
+}
+
+// GetTeacherSubjects liste les matières d'un enseignant (lui-même ou un utilisateur qui gère les matières)
+func (c *SubjectController) GetTeacherSubjects(ctx *gin.Context) {
+	teacherID, ok := parseUintParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if !c.permissionService.HasPermission(ctx.GetString("user_role"), models.PermSubjectManageAny) && ctx.GetUint("user_id") != teacherID {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez consulter que vos propres matières"})
+		return
+	}
+
+	subjects, err := c.subjectService.GetTeacherSubjects(teacherID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":  subjects,
+		"total": len(subjects),
+	})
+}
+
+func respondSubjectError(ctx *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Matière non trouvée"})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeacherAvailabilityController struct {
+	availabilityService *services.TeacherAvailabilityService
+}
+
+func NewTeacherAvailabilityController(availabilityService *services.TeacherAvailabilityService) *TeacherAvailabilityController {
+	return &TeacherAvailabilityController{
+		availabilityService: availabilityService,
+	}
+}
+
+// GetTeacherAvailability récupère les disponibilités et indisponibilités d'un enseignant
+func (c *TeacherAvailabilityController) GetTeacherAvailability(ctx *gin.Context) {
+	teacherID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID enseignant invalide"})
+		return
+	}
+
+	availability, err := c.availabilityService.GetTeacherAvailability(uint(teacherID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    availability,
+	})
+}
+
+// AddAvailability ajoute une plage de disponibilité hebdomadaire
+func (c *TeacherAvailabilityController) AddAvailability(ctx *gin.Context) {
+	teacherID, ok := c.parseEditableTeacherID(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTeacherAvailabilityRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	availability, err := c.availabilityService.AddAvailability(teacherID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    availability,
+		"message": "Disponibilité ajoutée avec succès",
+	})
+}
+
+// DeleteAvailability supprime une plage de disponibilité
+func (c *TeacherAvailabilityController) DeleteAvailability(ctx *gin.Context) {
+	teacherID, ok := c.parseEditableTeacherID(ctx)
+	if !ok {
+		return
+	}
+
+	availabilityID, err := strconv.ParseUint(ctx.Param("availabilityId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
+	}
+
+	if err := c.availabilityService.DeleteAvailability(teacherID, uint(availabilityID)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Disponibilité supprimée avec succès",
+	})
+}
+
+// AddUnavailability ajoute une période d'indisponibilité
+func (c *TeacherAvailabilityController) AddUnavailability(ctx *gin.Context) {
+	teacherID, ok := c.parseEditableTeacherID(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTeacherUnavailabilityRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	unavailability, err := c.availabilityService.AddUnavailability(teacherID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    unavailability,
+		"message": "Indisponibilité ajoutée avec succès",
+	})
+}
+
+// DeleteUnavailability supprime une période d'indisponibilité
+func (c *TeacherAvailabilityController) DeleteUnavailability(ctx *gin.Context) {
+	teacherID, ok := c.parseEditableTeacherID(ctx)
+	if !ok {
+		return
+	}
+
+	unavailabilityID, err := strconv.ParseUint(ctx.Param("unavailabilityId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID invalide"})
+		return
+	}
+
+	if err := c.availabilityService.DeleteUnavailability(teacherID, uint(unavailabilityID)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Indisponibilité supprimée avec succès",
+	})
+}
+
+// parseEditableTeacherID lit l'ID de l'enseignant et vérifie que l'utilisateur peut modifier ses disponibilités
+func (c *TeacherAvailabilityController) parseEditableTeacherID(ctx *gin.Context) (uint, bool) {
+	teacherID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID enseignant invalide"})
+		return 0, false
+	}
+
+	if !c.availabilityService.CanEditAvailability(ctx.GetUint("user_id"), ctx.GetString("user_role"), uint(teacherID)) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez modifier que vos propres disponibilités"})
+		return 0, false
+	}
+
+	return uint(teacherID), true
+}
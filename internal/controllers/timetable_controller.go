@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimetableController struct {
+	timetableService *services.TimetableService
+}
+
+func NewTimetableController(timetableService *services.TimetableService) *TimetableController {
+	return &TimetableController{
+		timetableService: timetableService,
+	}
+}
+
+// GenerateTimetable propose un emploi du temps sans l'enregistrer
+func (c *TimetableController) GenerateTimetable(ctx *gin.Context) {
+	var req models.GenerateTimetableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	proposal, err := c.timetableService.GenerateTimetable(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    proposal,
+	})
+}
+
+// ApplyTimetable génère l'emploi du temps et crée les cours récurrents
+func (c *TimetableController) ApplyTimetable(ctx *gin.Context) {
+	var req models.GenerateTimetableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides: " + err.Error()})
+		return
+	}
+
+	proposal, err := c.timetableService.ApplyTimetable(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    proposal,
+		"message": "Emploi du temps généré avec succès",
+	})
+}
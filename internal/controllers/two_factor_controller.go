@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorController struct {
+	authService       *services.AuthService
+	twoFactorService  *services.TwoFactorService
+	userService       *services.UserService
+	permissionService *services.PermissionService
+}
+
+func NewTwoFactorController(authService *services.AuthService, twoFactorService *services.TwoFactorService, userService *services.UserService, permissionService *services.PermissionService) *TwoFactorController {
+	return &TwoFactorController{
+		authService:       authService,
+		twoFactorService:  twoFactorService,
+		userService:       userService,
+		permissionService: permissionService,
+	}
+}
+
+// @Summary Verify two-factor login
+// @Description Second login step: exchange the challenge token and a TOTP or recovery code for tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorChallengeRequest true "Challenge token and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /auth/2fa/verify [post]
+func (c *TwoFactorController) VerifyLogin(ctx *gin.Context) {
+	var req models.TwoFactorChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, user, err := c.authService.CompleteTwoFactorLogin(&req, sessionClient(ctx))
+	if err != nil {
+		respondLoginError(ctx, err)
+		return
+	}
+
+	setLoginUserData(ctx, user)
+	ctx.JSON(http.StatusOK, LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
+	})
+}
+
+// @Summary Begin two-factor setup during login
+// @Description Generate the TOTP secret of a user whose role requires two-factor authentication
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorChallengeRequest true "Challenge token"
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /auth/2fa/setup [post]
+func (c *TwoFactorController) BeginLoginSetup(ctx *gin.Context) {
+	var req models.TwoFactorChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setup, err := c.authService.BeginTwoFactorLoginSetup(req.ChallengeToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, setup)
+}
+
+// @Summary Confirm two-factor setup during login
+// @Description Enable two-factor authentication with a first code and finish the login. Recovery codes are returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorChallengeRequest true "Challenge token and code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /auth/2fa/setup/confirm [post]
+func (c *TwoFactorController) ConfirmLoginSetup(ctx *gin.Context) {
+	var req models.TwoFactorChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, user, codes, err := c.authService.ConfirmTwoFactorLoginSetup(&req, sessionClient(ctx))
+	if err != nil {
+		respondLoginError(ctx, err)
+		return
+	}
+
+	setLoginUserData(ctx, user)
+	ctx.JSON(http.StatusOK, gin.H{
+		"token":          tokens.AccessToken,
+		"refresh_token":  tokens.RefreshToken,
+		"expires_in":     tokens.ExpiresIn,
+		"user":           user,
+		"recovery_codes": codes,
+	})
+}
+
+// @Summary Get two-factor status
+// @Description Get the two-factor authentication status of the current user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TwoFactorStatusResponse
+// @Router /users/profile/2fa [get]
+func (c *TwoFactorController) GetStatus(ctx *gin.Context) {
+	status, err := c.twoFactorService.GetStatus(ctx.GetUint("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// @Summary Begin two-factor setup
+// @Description Generate a TOTP secret and its provisioning URI for the current user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/profile/2fa/setup [post]
+func (c *TwoFactorController) BeginSetup(ctx *gin.Context) {
+	setup, err := c.twoFactorService.BeginSetup(ctx.GetUint("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, setup)
+}
+
+// @Summary Enable two-factor authentication
+// @Description Confirm the setup with a first code. Recovery codes are returned once.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} models.TwoFactorRecoveryCodesResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/profile/2fa/enable [post]
+func (c *TwoFactorController) Enable(ctx *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := c.twoFactorService.Enable(ctx.GetUint("user_id"), req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication, unless the role of the user requires it
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.TwoFactorDisableRequest true "Password and code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/profile/2fa/disable [post]
+func (c *TwoFactorController) Disable(ctx *gin.Context) {
+	var req models.TwoFactorDisableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.twoFactorService.Disable(ctx.GetUint("user_id"), req.Password, req.Code); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Double authentification désactivée"})
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes of the current user. The new codes are returned once.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} models.TwoFactorRecoveryCodesResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/profile/2fa/recovery-codes [post]
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := c.twoFactorService.RegenerateRecoveryCodes(ctx.GetUint("user_id"), req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Reset user two-factor authentication
+// @Description Remove the two-factor authentication of a user who lost their device, based on role permissions
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/{id}/2fa [delete]
+func (c *TwoFactorController) ResetUserTwoFactor(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	targetUser, err := c.userService.GetUserByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	if !c.permissionService.CanManageRole(ctx.GetString("user_role"), targetUser.Role) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions to reset this user's two-factor authentication"})
+		return
+	}
+
+	if err := c.twoFactorService.Reset(uint(id)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "two-factor authentication reset successfully"})
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -8,21 +10,28 @@ import (
 	"eduqr-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type UserController struct {
-	userService *services.UserService
+	userService       *services.UserService
+	authService       *services.AuthService
+	permissionService *services.PermissionService
 }
 
-func NewUserController(userService *services.UserService) *UserController {
+func NewUserController(userService *services.UserService, authService *services.AuthService, permissionService *services.PermissionService) *UserController {
 	return &UserController{
-		userService: userService,
+		userService:       userService,
+		authService:       authService,
+		permissionService: permissionService,
 	}
 }
 
 type LoginResponse struct {
-	Token string               `json:"token"`
-	User  *models.UserResponse `json:"user"`
+	Token        string               `json:"token"`
+	RefreshToken string               `json:"refresh_token"`
+	ExpiresIn    int64                `json:"expires_in"`
+	User         *models.UserResponse `json:"user"`
 }
 
 // @Summary Register a new user
@@ -72,20 +81,313 @@ func (c *UserController) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, user, err := c.userService.Login(&req)
+	tokens, user, err := c.authService.Login(&req, sessionClient(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondLoginError(ctx, err)
 		return
 	}
 
+	setLoginUserData(ctx, user)
+
 	response := LoginResponse{
-		Token: token,
-		User:  user,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
 
+// sessionClient décrit le client de la requête pour l'ouverture d'une session
+func sessionClient(ctx *gin.Context) models.SessionClient {
+	return models.SessionClient{
+		UserAgent: ctx.GetHeader("User-Agent"),
+		IPAddress: ctx.ClientIP(),
+	}
+}
+
+// setLoginUserData expose l'utilisateur connecté à AuditLoginMiddleware
+func setLoginUserData(ctx *gin.Context, user *models.UserResponse) {
+	ctx.Set("login_user_data", map[string]interface{}{
+		"id":    float64(user.ID),
+		"email": user.Email,
+		"role":  user.Role,
+	})
+}
+
+// respondLoginError répond à un échec de connexion : 429 avec Retry-After si les tentatives
+// sont limitées, 200 avec un jeton de seconde étape si la double authentification est requise, sinon 401
+func respondLoginError(ctx *gin.Context, err error) {
+	var throttled *services.LoginThrottledError
+	if errors.As(err, &throttled) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+
+	var twoFactor *services.TwoFactorRequiredError
+	if errors.As(err, &twoFactor) {
+		ctx.JSON(http.StatusOK, gin.H{
+			"two_factor_required":       !twoFactor.Setup,
+			"two_factor_setup_required": twoFactor.Setup,
+			"challenge_token":           twoFactor.ChallengeToken,
+		})
+		return
+	}
+
+	if errors.Is(err, services.ErrAccountAlreadyLinked) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
+
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token and a new refresh token (rotation)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} models.AuthTokens
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /auth/refresh [post]
+func (c *UserController) RefreshToken(ctx *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := c.authService.Refresh(req.RefreshToken, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Logout
+// @Description Revoke the session of the given refresh token, or of the current access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.LogoutRequest false "Refresh token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /auth/logout [post]
+func (c *UserController) Logout(ctx *gin.Context) {
+	var req models.LogoutRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := c.authService.Logout(ctx.GetUint("user_id"), ctx.GetUint("session_id"), req.RefreshToken); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
+}
+
+// @Summary Get my sessions
+// @Description List the open sessions of the current user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SessionResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /users/profile/sessions [get]
+func (c *UserController) GetMySessions(ctx *gin.Context) {
+	sessions, err := c.authService.GetUserSessions(ctx.GetUint("user_id"), ctx.GetUint("session_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// @Summary Revoke one of my sessions
+// @Description Revoke an open session of the current user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param sessionId path int true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/profile/sessions/{sessionId} [delete]
+func (c *UserController) RevokeMySession(ctx *gin.Context) {
+	sessionID, err := strconv.ParseUint(ctx.Param("sessionId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		return
+	}
+
+	c.revokeSession(ctx, ctx.GetUint("user_id"), uint(sessionID))
+}
+
+// @Summary Get user sessions
+// @Description List the open sessions of a user based on role permissions
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} models.SessionResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/{id}/sessions [get]
+func (c *UserController) GetUserSessions(ctx *gin.Context) {
+	userID, ok := c.managedUserID(ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := c.authService.GetUserSessions(userID, ctx.GetUint("session_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// @Summary Revoke a user session
+// @Description Revoke an open session of a user based on role permissions
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param sessionId path int true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/{id}/sessions/{sessionId} [delete]
+func (c *UserController) RevokeUserSession(ctx *gin.Context) {
+	userID, ok := c.managedUserID(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(ctx.Param("sessionId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		return
+	}
+
+	c.revokeSession(ctx, userID, uint(sessionID))
+}
+
+func (c *UserController) revokeSession(ctx *gin.Context, userID, sessionID uint) {
+	if err := c.authService.RevokeUserSession(userID, sessionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "session revoked successfully"})
+}
+
+// managedUserID lit l'utilisateur ciblé par la route et vérifie que l'utilisateur connecté peut le gérer.
+// En cas d'échec, la réponse est déjà écrite.
+func (c *UserController) managedUserID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return 0, false
+	}
+
+	targetUser, err := c.userService.GetUserByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return 0, false
+	}
+
+	if !c.permissionService.CanManageRole(ctx.GetString("user_role"), targetUser.Role) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions to manage this user's sessions"})
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// @Summary Unlock user account
+// @Description Lift the temporary lockout of an account after repeated login failures, based on role permissions
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/{id}/unlock [post]
+func (c *UserController) UnlockUser(ctx *gin.Context) {
+	userID, ok := c.managedUserID(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.authService.UnlockAccount(
+		userID,
+		ctx.GetUint("user_id"),
+		ctx.GetString("user_email"),
+		ctx.GetString("user_role"),
+		ctx.ClientIP(),
+		ctx.GetHeader("User-Agent"),
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "account unlocked successfully"})
+}
+
+// @Summary Revoke all sessions of a user
+// @Description Revoke every open session of a user based on role permissions
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/{id}/sessions/revoke [post]
+func (c *UserController) RevokeUserSessions(ctx *gin.Context) {
+	userID, ok := c.managedUserID(ctx)
+	if !ok {
+		return
+	}
+
+	revoked, err := c.authService.RevokeAllSessions(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "sessions revoked successfully",
+		"revoked": revoked,
+	})
+}
+
 // @Summary Get user profile
 // @Description Get current user profile
 // @Tags users
@@ -178,13 +480,13 @@ func (c *UserController) GetUserByID(ctx *gin.Context) {
 	}
 
 	// Check if user can view this user
-	if !models.CanViewRole(userRole.(string), user.Role) {
+	if !c.permissionService.CanViewRole(userRole.(string), user.Role) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
 		return
 	}
 
 	// Get viewable fields for this user
-	viewableFields := models.GetViewableFields(userRole.(string), user.Role)
+	viewableFields := c.permissionService.GetViewableFields(userRole.(string), user.Role)
 	filteredUser := filterUserFields(*user, viewableFields)
 
 	ctx.JSON(http.StatusOK, filteredUser)
@@ -215,9 +517,9 @@ func (c *UserController) GetAllUsers(ctx *gin.Context) {
 	// Filter users based on role permissions
 	filteredUsers := []models.UserResponse{}
 	for _, user := range users {
-		if models.CanViewRole(userRole.(string), user.Role) {
+		if c.permissionService.CanViewRole(userRole.(string), user.Role) {
 			// Get viewable fields for this user
-			viewableFields := models.GetViewableFields(userRole.(string), user.Role)
+			viewableFields := c.permissionService.GetViewableFields(userRole.(string), user.Role)
 			filteredUser := filterUserFields(*user, viewableFields)
 			filteredUsers = append(filteredUsers, filteredUser)
 		}
@@ -292,7 +594,7 @@ func (c *UserController) CreateUser(ctx *gin.Context) {
 	}
 
 	// Check if user can manage the target role
-	if !models.CanManageRole(userRole.(string), req.Role) {
+	if !c.permissionService.CanManageRole(userRole.(string), req.Role) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions to create user with this role"})
 		return
 	}
@@ -346,7 +648,7 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 	}
 
 	// Check if user can manage the target user
-	if !models.CanManageRole(userRole.(string), targetUser.Role) {
+	if !c.permissionService.CanManageRole(userRole.(string), targetUser.Role) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions to update this user"})
 		return
 	}
@@ -401,7 +703,7 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 	}
 
 	// Check if user can manage the target user
-	if !models.CanManageRole(userRole.(string), targetUser.Role) {
+	if !c.permissionService.CanManageRole(userRole.(string), targetUser.Role) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions to delete this user"})
 		return
 	}
@@ -465,13 +767,13 @@ func (c *UserController) UpdateUserRole(ctx *gin.Context) {
 	}
 
 	// Check if user can manage the target user
-	if !models.CanManageRole(userRole.(string), targetUser.Role) {
+	if !c.permissionService.CanManageRole(userRole.(string), targetUser.Role) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions to update this user's role"})
 		return
 	}
 
 	// Check if user can manage the new role
-	if !models.CanManageRole(userRole.(string), req.Role) {
+	if !c.permissionService.CanManageRole(userRole.(string), req.Role) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions to assign this role"})
 		return
 	}
@@ -7,18 +7,23 @@ import (
 	"time"
 
 	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
 	"eduqr-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthMiddleware struct {
-	jwtSecret string
+	jwtSecret         string
+	authService       *services.AuthService
+	permissionService *services.PermissionService
 }
 
-func NewAuthMiddleware(jwtSecret string) *AuthMiddleware {
+func NewAuthMiddleware(jwtSecret string, authService *services.AuthService, permissionService *services.PermissionService) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtSecret: jwtSecret,
+		jwtSecret:         jwtSecret,
+		authService:       authService,
+		permissionService: permissionService,
 	}
 }
 
@@ -57,17 +62,25 @@ func (m *AuthMiddleware) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens whose session has been revoked (logout, admin revocation)
+		if claims.SessionID == 0 || m.authService.ValidateSession(claims.SessionID, c.ClientIP()) != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session revoked"})
+			c.Abort()
+			return
+		}
+
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
 }
 
-// RoleMiddleware checks if user has required role or higher
-func (m *AuthMiddleware) RoleMiddleware(requiredRole string) gin.HandlerFunc {
+// RequirePermission checks if the user's role has at least one of the given permissions
+func (m *AuthMiddleware) RequirePermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
@@ -76,8 +89,7 @@ func (m *AuthMiddleware) RoleMiddleware(requiredRole string) gin.HandlerFunc {
 			return
 		}
 
-		// Check if user has the required role or higher
-		if !hasRoleOrHigher(userRole.(string), requiredRole) {
+		if !m.permissionService.HasAnyPermission(userRole.(string), permissions...) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
 			c.Abort()
 			return
@@ -179,13 +191,6 @@ func (m *AuthMiddleware) CanViewUserMiddleware() gin.HandlerFunc {
 	}
 }
 
-// hasRoleOrHigher checks if userRole has the same or higher privileges than requiredRole
-func hasRoleOrHigher(userRole, requiredRole string) bool {
-	userLevel := models.RoleHierarchy[userRole]
-	requiredLevel := models.RoleHierarchy[requiredRole]
-	return userLevel >= requiredLevel
-}
-
 // OptionalAuthMiddleware is like AuthMiddleware but doesn't require authentication
 func (m *AuthMiddleware) OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -213,15 +218,22 @@ func (m *AuthMiddleware) OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if claims.SessionID == 0 || m.authService.ValidateSession(claims.SessionID, c.ClientIP()) != nil {
+			c.Next()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
 }
 
-// CanDeleteMiddleware checks if the current user can delete the target resource
+// CanDeleteMiddleware prepares the deletion of the target resource, once RequirePermission has checked
+// the deletion permission
 func (m *AuthMiddleware) CanDeleteMiddleware(resourceType string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
@@ -231,13 +243,6 @@ func (m *AuthMiddleware) CanDeleteMiddleware(resourceType string) gin.HandlerFun
 			return
 		}
 
-		// Seuls les Admins et Super Admins peuvent supprimer
-		if userRole != models.RoleAdmin && userRole != models.RoleSuperAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions for deletion"})
-			c.Abort()
-			return
-		}
-
 		// Pour les suppressions d'utilisateurs, vérifier les règles spéciales
 		if resourceType == "user" {
 			// Get target user ID from URL parameter
@@ -291,8 +296,8 @@ func (m *AuthMiddleware) CanDeleteAdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Seul le Super Admin peut supprimer un Admin
-		if userRole != models.RoleSuperAdmin {
+		// Par défaut, seul le Super Admin peut supprimer un Admin
+		if !m.permissionService.CanDeleteRole(userRole.(string), models.RoleAdmin) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "only super admin can delete admin accounts"})
 			c.Abort()
 			return
@@ -0,0 +1,173 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Portées de limitation, configurées chacune dans config.RateLimitConfig
+const (
+	RateLimitGlobal = "global" // Toutes les requêtes, par adresse IP
+	RateLimitAuth   = "auth"   // Routes /auth (connexion, inscription, mot de passe oublié)
+	RateLimitScan   = "scan"   // Scan des QR codes de présence
+	RateLimitImport = "import" // Imports de fichiers (utilisateurs, ICS)
+)
+
+// RateLimit autorise Requests requêtes par période Per, avec une rafale d'au plus Requests requêtes
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// IsZero indique une limite désactivée
+func (l RateLimit) IsZero() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// ParseRateLimit lit une limite au format "<requêtes>/<unité>" (unité s, m ou h), par exemple "20/m".
+// Une valeur vide ou "0" désactive la limite.
+func ParseRateLimit(value string) (RateLimit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return RateLimit{}, nil
+	}
+
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return RateLimit{}, fmt.Errorf("limite invalide %q : format attendu <requêtes>/<s|m|h>", value)
+	}
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests < 0 {
+		return RateLimit{}, fmt.Errorf("limite invalide %q : nombre de requêtes incorrect", value)
+	}
+
+	var per time.Duration
+	switch parts[1] {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return RateLimit{}, fmt.Errorf("limite invalide %q : unité inconnue %q", value, parts[1])
+	}
+	return RateLimit{Requests: requests, Per: per}, nil
+}
+
+// RateLimitStore conserve l'état des seaux à jetons. L'implémentation en mémoire convient à une seule
+// instance ; un stockage partagé peut la remplacer pour plusieurs instances.
+type RateLimitStore interface {
+	// Take consomme un jeton du seau key. Si le seau est vide, retourne false et le délai
+	// avant qu'un jeton soit disponible.
+	Take(key string, limit RateLimit, now time.Time) (bool, time.Duration)
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	refill  time.Duration // Durée pour remplir entièrement le seau
+}
+
+// MemoryRateLimitStore est un RateLimitStore en mémoire
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// rateLimitSweepInterval espace les purges des seaux inactifs
+const rateLimitSweepInterval = time.Minute
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, limit RateLimit, now time.Time) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= rateLimitSweepInterval {
+		s.sweep(now)
+	}
+
+	capacity := float64(limit.Requests)
+	refillPerSecond := capacity / limit.Per.Seconds()
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now, refill: limit.Per}
+		s.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updated).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(capacity, bucket.tokens+elapsed*refillPerSecond)
+		bucket.updated = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	missing := 1 - bucket.tokens
+	return false, time.Duration(missing / refillPerSecond * float64(time.Second))
+}
+
+// sweep supprime les seaux restés inactifs assez longtemps pour être pleins : les recréer est équivalent
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updated) >= bucket.refill {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+type RateLimitMiddleware struct {
+	store  RateLimitStore
+	limits map[string]RateLimit
+}
+
+func NewRateLimitMiddleware(store RateLimitStore, limits map[string]RateLimit) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Limit applique la limite de la portée scope. Les requêtes sont comptées par utilisateur
+// lorsque AuthMiddleware a déjà été exécuté, sinon par adresse IP. X-Forwarded-For n'est pris
+// en compte que pour les proxys déclarés dans TRUSTED_PROXIES (voir gin.Engine.SetTrustedProxies).
+func (m *RateLimitMiddleware) Limit(scope string) gin.HandlerFunc {
+	limit := m.limits[scope]
+	if limit.IsZero() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		key := scope + ":ip:" + c.ClientIP()
+		if userID := c.GetUint("user_id"); userID != 0 {
+			key = scope + ":user:" + strconv.FormatUint(uint64(userID), 10)
+		}
+
+		allowed, retryAfter := m.store.Take(key, limit, time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -14,6 +14,10 @@ const (
 	ActionDelete = "delete"
 	ActionLogin  = "login"
 	ActionLogout = "logout"
+
+	ActionPasswordReset   = "password_reset"
+	ActionAccountLocked   = "account_locked"
+	ActionAccountUnlocked = "account_unlocked"
 )
 
 // ResourceType constants for audit logging
@@ -32,7 +36,7 @@ type AuditLog struct {
 	UserID       uint           `json:"user_id" gorm:"not null;index"`
 	UserEmail    string         `json:"user_email" gorm:"not null"`
 	UserRole     string         `json:"user_role" gorm:"not null"`
-	Action       string         `json:"action" gorm:"not null;index"`        // create, update, delete, login, logout
+	Action       string         `json:"action" gorm:"not null;index"`        // create, update, delete, login, logout, password_reset, account_locked, account_unlocked
 	ResourceType string         `json:"resource_type" gorm:"not null;index"` // user, room, subject, course, event
 	ResourceID   *uint          `json:"resource_id" gorm:"index"`            // ID of the affected resource (nullable for login/logout)
 	Description  string         `json:"description" gorm:"not null"`         // Human readable description
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// Types de flux iCalendar
+const (
+	CalendarFeedUser  = "user"
+	CalendarFeedRoom  = "room"
+	CalendarFeedGroup = "group"
+)
+
+// CalendarFeedToken représente un jeton d'abonnement à un flux iCalendar en lecture seule.
+// Seule l'empreinte du jeton est stockée : l'URL complète n'est communiquée qu'à la création.
+type CalendarFeedToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	OwnerID    uint       `json:"owner_id" gorm:"not null;index"`
+	Owner      User       `json:"-" gorm:"foreignKey:OwnerID"`
+	FeedType   string     `json:"feed_type" gorm:"not null"`
+	TargetID   uint       `json:"target_id" gorm:"not null"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive indique si le jeton n'a pas été révoqué
+func (t *CalendarFeedToken) IsActive() bool {
+	return t.RevokedAt == nil
+}
+
+// CreateCalendarFeedRequest pour créer un flux iCalendar
+type CreateCalendarFeedRequest struct {
+	FeedType string `json:"feed_type" binding:"required,oneof=user room group"`
+	TargetID uint   `json:"target_id"` // Ignoré pour un flux utilisateur (toujours l'utilisateur connecté)
+}
+
+// CalendarFeedResponse pour l'API
+type CalendarFeedResponse struct {
+	ID         uint       `json:"id"`
+	FeedType   string     `json:"feed_type"`
+	TargetID   uint       `json:"target_id"`
+	URL        string     `json:"url,omitempty"` // Renseignée uniquement à la création
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToCalendarFeedResponse convertit un CalendarFeedToken en CalendarFeedResponse
+func (t *CalendarFeedToken) ToCalendarFeedResponse() CalendarFeedResponse {
+	return CalendarFeedResponse{
+		ID:         t.ID,
+		FeedType:   t.FeedType,
+		TargetID:   t.TargetID,
+		LastUsedAt: t.LastUsedAt,
+		RevokedAt:  t.RevokedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
@@ -1,33 +1,51 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Statuts d'un cours
+const (
+	CourseStatusScheduled   = "scheduled"   // Prévu
+	CourseStatusCancelled   = "cancelled"   // Annulé
+	CourseStatusRescheduled = "rescheduled" // Déplacé (remplacé par une autre occurrence)
+	CourseStatusCompleted   = "completed"   // Effectué
+)
+
 // Course représente un cours ou événement pédagogique
 type Course struct {
-	ID                uint           `json:"id" gorm:"primaryKey"`
-	Name              string         `json:"name" gorm:"not null"`
-	SubjectID         uint           `json:"subject_id" gorm:"not null"`
-	Subject           Subject        `json:"subject" gorm:"foreignKey:SubjectID"`
-	TeacherID         uint           `json:"teacher_id" gorm:"not null"`
-	Teacher           User           `json:"teacher" gorm:"foreignKey:TeacherID"`
-	RoomID            uint           `json:"room_id" gorm:"not null"`
-	Room              Room           `json:"room" gorm:"foreignKey:RoomID"`
-	StartTime         time.Time      `json:"start_time" gorm:"not null"`
-	EndTime           time.Time      `json:"end_time" gorm:"not null"`
-	Duration          int            `json:"duration" gorm:"not null"` // en minutes
-	Description       string         `json:"description"`
-	IsRecurring       bool           `json:"is_recurring" gorm:"default:false"`
-	RecurrenceID      *uint          `json:"recurrence_id"`      // ID du cours parent pour les récurrences
-	RecurrencePattern *string        `json:"recurrence_pattern"` // JSON string pour les jours de répétition
-	RecurrenceEndDate *time.Time     `json:"recurrence_end_date"`
-	ExcludeHolidays   bool           `json:"exclude_holidays" gorm:"default:true"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
-	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	Name                string         `json:"name" gorm:"not null"`
+	SubjectID           uint           `json:"subject_id" gorm:"not null"`
+	Subject             Subject        `json:"subject" gorm:"foreignKey:SubjectID"`
+	TeacherID           uint           `json:"teacher_id" gorm:"not null"`
+	Teacher             User           `json:"teacher" gorm:"foreignKey:TeacherID"`
+	RoomID              uint           `json:"room_id" gorm:"not null"`
+	Room                Room           `json:"room" gorm:"foreignKey:RoomID"`
+	MergedRooms         []Room         `json:"merged_rooms" gorm:"many2many:course_merged_rooms"` // Sous-salles voisines réunies avec Room
+	StartTime           time.Time      `json:"start_time" gorm:"not null"`
+	EndTime             time.Time      `json:"end_time" gorm:"not null"`
+	Duration            int            `json:"duration" gorm:"not null"` // en minutes
+	Description         string         `json:"description"`
+	IsRecurring         bool           `json:"is_recurring" gorm:"default:false"`
+	RecurrenceID        *uint          `json:"recurrence_id"`      // ID du cours parent pour les récurrences
+	RecurrencePattern   *string        `json:"recurrence_pattern"` // JSON string pour les jours de répétition
+	RecurrenceEndDate   *time.Time     `json:"recurrence_end_date"`
+	ExcludeHolidays     bool           `json:"exclude_holidays" gorm:"default:true"`
+	Groups              []Group        `json:"groups" gorm:"many2many:course_groups"`
+	Status              string         `json:"status" gorm:"default:'scheduled';index"` // scheduled, cancelled, rescheduled, completed
+	StatusReason        string         `json:"status_reason"`
+	RescheduledFromID   *uint          `json:"rescheduled_from_id" gorm:"index"`   // Occurrence d'origine si ce cours est un report
+	RescheduledToID     *uint          `json:"rescheduled_to_id"`                  // Occurrence de remplacement si ce cours a été déplacé
+	SubstituteTeacherID *uint          `json:"substitute_teacher_id" gorm:"index"` // Remplaçant pour cette occurrence uniquement
+	SubstituteTeacher   *User          `json:"substitute_teacher,omitempty" gorm:"foreignKey:SubstituteTeacherID"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 // CourseResponse pour l'API
@@ -37,6 +55,7 @@ type CourseResponse struct {
 	Subject           SubjectResponse `json:"subject"`
 	Teacher           UserResponse    `json:"teacher"`
 	Room              RoomResponse    `json:"room"`
+	MergedRooms       []RoomResponse  `json:"merged_rooms,omitempty"`
 	StartTime         time.Time       `json:"start_time"`
 	EndTime           time.Time       `json:"end_time"`
 	Duration          int             `json:"duration"`
@@ -46,6 +65,13 @@ type CourseResponse struct {
 	RecurrencePattern *string         `json:"recurrence_pattern"`
 	RecurrenceEndDate *time.Time      `json:"recurrence_end_date"`
 	ExcludeHolidays   bool            `json:"exclude_holidays"`
+	Groups            []GroupResponse `json:"groups"`
+	Status            string          `json:"status"`
+	StatusReason      string          `json:"status_reason"`
+	RescheduledFromID *uint           `json:"rescheduled_from_id"`
+	RescheduledToID   *uint           `json:"rescheduled_to_id"`
+	SubstituteTeacher *UserResponse   `json:"substitute_teacher,omitempty"`
+	Warnings          []string        `json:"warnings,omitempty"` // Avertissements non bloquants (disponibilités de l'enseignant)
 	CreatedAt         time.Time       `json:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at"`
 }
@@ -56,6 +82,7 @@ type CreateCourseRequest struct {
 	SubjectID         uint       `json:"subject_id" binding:"required"`
 	TeacherID         uint       `json:"teacher_id" binding:"required"`
 	RoomID            uint       `json:"room_id" binding:"required"`
+	MergedRoomIDs     []uint     `json:"merged_room_ids"` // Sous-salles voisines de room_id à réunir en un seul espace
 	StartTime         time.Time  `json:"start_time" binding:"required"`
 	Duration          int        `json:"duration" binding:"required,min=15,max=480"` // 15min à 8h
 	Description       string     `json:"description"`
@@ -63,6 +90,7 @@ type CreateCourseRequest struct {
 	RecurrencePattern *string    `json:"recurrence_pattern"` // ["monday", "wednesday", "friday"]
 	RecurrenceEndDate *time.Time `json:"recurrence_end_date"`
 	ExcludeHolidays   bool       `json:"exclude_holidays"`
+	GroupIDs          []uint     `json:"group_ids"`
 }
 
 // UpdateCourseRequest pour la modification d'un cours
@@ -71,6 +99,7 @@ type UpdateCourseRequest struct {
 	SubjectID         uint       `json:"subject_id"`
 	TeacherID         uint       `json:"teacher_id"`
 	RoomID            uint       `json:"room_id"`
+	MergedRoomIDs     []uint     `json:"merged_room_ids"` // nil = sous-salles réunies inchangées
 	StartTime         time.Time  `json:"start_time"`
 	Duration          int        `json:"duration" binding:"min=15,max=480"`
 	Description       string     `json:"description"`
@@ -78,6 +107,43 @@ type UpdateCourseRequest struct {
 	RecurrencePattern *string    `json:"recurrence_pattern"`
 	RecurrenceEndDate *time.Time `json:"recurrence_end_date"`
 	ExcludeHolidays   bool       `json:"exclude_holidays"`
+	GroupIDs          []uint     `json:"group_ids"` // nil = groupes inchangés
+}
+
+// CancelCourseRequest pour l'annulation d'un cours
+type CancelCourseRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RescheduleCourseRequest pour le report d'un cours sur un autre créneau
+type RescheduleCourseRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	Duration  int       `json:"duration" binding:"omitempty,min=15,max=480"` // Durée d'origine si vide
+	RoomID    uint      `json:"room_id"`                                     // Salle d'origine si vide
+	Reason    string    `json:"reason" binding:"required"`
+}
+
+// CourseStatusHistory conserve l'historique des changements de statut d'un cours
+type CourseStatusHistory struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CourseID    uint      `json:"course_id" gorm:"not null;index"`
+	FromStatus  string    `json:"from_status"`
+	ToStatus    string    `json:"to_status" gorm:"not null"`
+	Reason      string    `json:"reason"`
+	ChangedByID uint      `json:"changed_by_id"`
+	ChangedBy   User      `json:"changed_by" gorm:"foreignKey:ChangedByID"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CourseStatusHistoryResponse pour l'API
+type CourseStatusHistoryResponse struct {
+	ID         uint         `json:"id"`
+	CourseID   uint         `json:"course_id"`
+	FromStatus string       `json:"from_status"`
+	ToStatus   string       `json:"to_status"`
+	Reason     string       `json:"reason"`
+	ChangedBy  UserResponse `json:"changed_by"`
+	CreatedAt  time.Time    `json:"created_at"`
 }
 
 // RecurrencePattern représente les jours de répétition
@@ -85,23 +151,109 @@ type RecurrencePattern struct {
 	Days []string `json:"days"` // ["monday", "tuesday", etc.]
 }
 
+// Types de conflits détectés lors de la planification
+const (
+	ConflictTypeRoom               = "room"                // Salle déjà occupée
+	ConflictTypeTeacher            = "teacher"             // Enseignant déjà en cours
+	ConflictTypeGroup              = "group"               // Groupe d'étudiants déjà en cours
+	ConflictTypeTeacherUnavailable = "teacher_unavailable" // Enseignant en période d'indisponibilité
+	ConflictTypeRoomBooking        = "room_booking"        // Salle réservée hors cours
+)
+
 // ConflictInfo pour les conflits de réservation
 type ConflictInfo struct {
-	Date       time.Time `json:"date"`
-	StartTime  time.Time `json:"start_time"`
-	EndTime    time.Time `json:"end_time"`
-	RoomName   string    `json:"room_name"`
-	CourseName string    `json:"course_name"`
+	Type        string    `json:"type"` // room, teacher, group, teacher_unavailable, room_booking
+	CourseID    uint      `json:"course_id"`
+	BookingID   uint      `json:"booking_id,omitempty"`
+	Date        time.Time `json:"date"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	RoomName    string    `json:"room_name"`
+	CourseName  string    `json:"course_name"`
+	TeacherName string    `json:"teacher_name,omitempty"`
+	GroupName   string    `json:"group_name,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// AssignSubstituteRequest pour l'affectation d'un enseignant remplaçant à une occurrence
+type AssignSubstituteRequest struct {
+	TeacherID uint `json:"teacher_id" binding:"required"`
+}
+
+// HasTeachingRights indique si l'utilisateur enseigne ce cours, en titulaire ou en remplaçant
+func (c *Course) HasTeachingRights(userID uint) bool {
+	return c.TeacherID == userID || (c.SubstituteTeacherID != nil && *c.SubstituteTeacherID == userID)
+}
+
+// IsActive indique si le cours a toujours lieu (ni annulé ni déplacé)
+func (c *Course) IsActive() bool {
+	return c.Status != CourseStatusCancelled && c.Status != CourseStatusRescheduled
+}
+
+// RoomIDs retourne les IDs de toutes les salles occupées par le cours (salle principale et sous-salles réunies)
+func (c *Course) RoomIDs() []uint {
+	return OccupiedRoomIDs(c.RoomID, c.MergedRooms)
+}
+
+// GroupIDs retourne les IDs des groupes associés au cours
+func (c *Course) GroupIDs() []uint {
+	ids := make([]uint, len(c.Groups))
+	for i, group := range c.Groups {
+		ids[i] = group.ID
+	}
+	return ids
+}
+
+// RecurrenceOccurrences calcule les heures de début des occurrences d'une série récurrente
+// (le cours parent lui-même n'est pas inclus)
+func (c *Course) RecurrenceOccurrences() ([]time.Time, error) {
+	if !c.IsRecurring || c.RecurrencePattern == nil || c.RecurrenceEndDate == nil {
+		return nil, fmt.Errorf("cours non récurrent ou paramètres manquants")
+	}
+
+	var pattern RecurrencePattern
+	if err := json.Unmarshal([]byte(*c.RecurrencePattern), &pattern); err != nil {
+		return nil, err
+	}
+
+	var occurrences []time.Time
+	for currentDate := c.StartTime.AddDate(0, 0, 1); currentDate.Before(*c.RecurrenceEndDate); currentDate = currentDate.AddDate(0, 0, 1) {
+		weekday := currentDate.Weekday().String()
+		for _, day := range pattern.Days {
+			if day == weekday {
+				occurrences = append(occurrences, time.Date(
+					currentDate.Year(), currentDate.Month(), currentDate.Day(),
+					c.StartTime.Hour(), c.StartTime.Minute(), 0, 0,
+					c.StartTime.Location(),
+				))
+				break
+			}
+		}
+	}
+
+	return occurrences, nil
 }
 
 // ToCourseResponse convertit un Course en CourseResponse
 func (c *Course) ToCourseResponse() CourseResponse {
+	groups := make([]GroupResponse, len(c.Groups))
+	for i, group := range c.Groups {
+		groups[i] = group.ToGroupResponse()
+	}
+
+	var substitute *UserResponse
+	if c.SubstituteTeacher != nil {
+		response := UserToUserResponse(*c.SubstituteTeacher)
+		substitute = &response
+	}
+
 	return CourseResponse{
 		ID:                c.ID,
 		Name:              c.Name,
 		Subject:           c.Subject.ToSubjectResponse(),
 		Teacher:           UserToUserResponse(c.Teacher),
 		Room:              c.Room.ToRoomResponse(),
+		MergedRooms:       roomResponses(c.MergedRooms),
 		StartTime:         c.StartTime,
 		EndTime:           c.EndTime,
 		Duration:          c.Duration,
@@ -111,24 +263,45 @@ func (c *Course) ToCourseResponse() CourseResponse {
 		RecurrencePattern: c.RecurrencePattern,
 		RecurrenceEndDate: c.RecurrenceEndDate,
 		ExcludeHolidays:   c.ExcludeHolidays,
+		Groups:            groups,
+		Status:            c.Status,
+		StatusReason:      c.StatusReason,
+		RescheduledFromID: c.RescheduledFromID,
+		RescheduledToID:   c.RescheduledToID,
+		SubstituteTeacher: substitute,
 		CreatedAt:         c.CreatedAt,
 		UpdatedAt:         c.UpdatedAt,
 	}
 }
 
+// ToResponse convertit un CourseStatusHistory en CourseStatusHistoryResponse
+func (h *CourseStatusHistory) ToResponse() CourseStatusHistoryResponse {
+	return CourseStatusHistoryResponse{
+		ID:         h.ID,
+		CourseID:   h.CourseID,
+		FromStatus: h.FromStatus,
+		ToStatus:   h.ToStatus,
+		Reason:     h.Reason,
+		ChangedBy:  UserToUserResponse(h.ChangedBy),
+		CreatedAt:  h.CreatedAt,
+	}
+}
+
 // UserToUserResponse convertit un User en UserResponse
 func UserToUserResponse(user User) UserResponse {
 	return UserResponse{
-		ID:           user.ID,
-		Email:        user.Email,
-		ContactEmail: user.ContactEmail,
-		FirstName:    user.FirstName,
-		LastName:     user.LastName,
-		Phone:        user.Phone,
-		Address:      user.Address,
-		Avatar:       user.Avatar,
-		Role:         user.Role,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
+		ID:            user.ID,
+		Email:         user.Email,
+		ContactEmail:  user.ContactEmail,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Phone:         user.Phone,
+		Address:       user.Address,
+		Avatar:        user.Avatar,
+		Role:          user.Role,
+		AuthProvider:  user.AuthProvider,
+		DeactivatedAt: user.DeactivatedAt,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
 	}
 }
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Équipements courants ; d'autres étiquettes libres peuvent être utilisées
+const (
+	EquipmentProjector     = "projector"
+	EquipmentComputers     = "computers"
+	EquipmentLabBenches    = "lab_benches"
+	EquipmentAccessibility = "accessibility"
+)
+
+// Equipment représente une étiquette d'équipement, partagée entre les salles et les matières
+type Equipment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"uniqueIndex;not null;size:50"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName évite le pluriel « equipments »
+func (Equipment) TableName() string {
+	return "equipment"
+}
+
+// NormalizeEquipmentTags met les étiquettes en minuscules, retire les doublons et les valeurs vides
+func NormalizeEquipmentTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		tag = strings.Join(strings.Fields(tag), "_")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// EquipmentNames retourne les étiquettes triées d'une liste d'équipements
+func EquipmentNames(equipment []Equipment) []string {
+	names := make([]string, len(equipment))
+	for i, item := range equipment {
+		names[i] = item.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MissingEquipment retourne les étiquettes requises absentes des équipements disponibles
+func MissingEquipment(required, available []Equipment) []string {
+	have := make(map[string]bool, len(available))
+	for _, item := range available {
+		have[item.Name] = true
+	}
+
+	var missing []string
+	for _, name := range EquipmentNames(required) {
+		if !have[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Group représente un groupe d'étudiants suivant les mêmes cours (promotion, groupe de TD/TP...).
+// Un groupe peut être subdivisé en sous-groupes : les cours d'un groupe concernent aussi ses sous-groupes.
+type Group struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"uniqueIndex;not null"`
+	Description string         `json:"description"`
+	ParentID    *uint          `json:"parent_id" gorm:"index"`
+	Parent      *Group         `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
+	Children    []Group        `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// GroupMembership représente l'appartenance d'un étudiant à un groupe sur une période.
+// La date de fin est exclusive ; une appartenance sans date de fin est toujours en cours.
+type GroupMembership struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	GroupID   uint       `json:"group_id" gorm:"not null;index"`
+	Group     Group      `json:"group" gorm:"foreignKey:GroupID"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"user" gorm:"foreignKey:UserID"`
+	StartDate time.Time  `json:"start_date" gorm:"not null"`
+	EndDate   *time.Time `json:"end_date"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsActiveAt indique si l'appartenance est effective à une date donnée
+func (m *GroupMembership) IsActiveAt(t time.Time) bool {
+	return !m.StartDate.After(t) && (m.EndDate == nil || m.EndDate.After(t))
+}
+
+// GroupResponse représente la réponse pour un groupe
+type GroupResponse struct {
+	ID          uint            `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	ParentID    *uint           `json:"parent_id"`
+	Children    []GroupResponse `json:"children,omitempty"`
+	MemberCount *int64          `json:"member_count,omitempty"` // Membres actuels, sous-groupes compris
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ToGroupResponse convertit un Group en GroupResponse
+func (g *Group) ToGroupResponse() GroupResponse {
+	var children []GroupResponse
+	for _, child := range g.Children {
+		children = append(children, child.ToGroupResponse())
+	}
+
+	return GroupResponse{
+		ID:          g.ID,
+		Name:        g.Name,
+		Description: g.Description,
+		ParentID:    g.ParentID,
+		Children:    children,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+}
+
+// CreateGroupRequest pour la création d'un groupe
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	ParentID    *uint  `json:"parent_id"`
+}
+
+// UpdateGroupRequest pour la modification d'un groupe
+type UpdateGroupRequest struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	ParentID     *uint  `json:"parent_id"`     // nil = parent inchangé
+	RemoveParent bool   `json:"remove_parent"` // Rattache le groupe à la racine
+}
+
+// AddGroupMembersRequest pour l'ajout d'étudiants à un groupe
+type AddGroupMembersRequest struct {
+	UserIDs   []uint     `json:"user_ids" binding:"required,min=1"`
+	StartDate *time.Time `json:"start_date"` // Aujourd'hui si vide
+}
+
+// MoveGroupMemberRequest pour le changement de groupe d'un étudiant à une date d'effet
+type MoveGroupMemberRequest struct {
+	UserID        uint       `json:"user_id" binding:"required"`
+	ToGroupID     uint       `json:"to_group_id" binding:"required"`
+	EffectiveDate *time.Time `json:"effective_date"` // Aujourd'hui si vide
+}
+
+// GroupMembershipResponse pour l'API
+type GroupMembershipResponse struct {
+	ID        uint         `json:"id"`
+	GroupID   uint         `json:"group_id"`
+	GroupName string       `json:"group_name"`
+	User      UserResponse `json:"user"`
+	StartDate time.Time    `json:"start_date"`
+	EndDate   *time.Time   `json:"end_date"`
+}
+
+// ToGroupMembershipResponse convertit un GroupMembership en GroupMembershipResponse
+func (m *GroupMembership) ToGroupMembershipResponse() GroupMembershipResponse {
+	return GroupMembershipResponse{
+		ID:        m.ID,
+		GroupID:   m.GroupID,
+		GroupName: m.Group.Name,
+		User:      UserToUserResponse(m.User),
+		StartDate: m.StartDate,
+		EndDate:   m.EndDate,
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Cibles d'un import iCalendar
+const (
+	ICSImportTargetCourse = "course"
+	ICSImportTargetEvent  = "event"
+)
+
+// ICSImportOptions paramètres d'un import iCalendar (formulaire multipart, fichier dans le champ "file").
+// Les valeurs par défaut s'appliquent aux VEVENT dont la salle (LOCATION) ou la matière (CATEGORIES)
+// ne correspondent à aucun enregistrement existant.
+type ICSImportOptions struct {
+	DryRun    bool   `form:"dry_run"`
+	SubjectID uint   `form:"subject_id"`
+	TeacherID uint   `form:"teacher_id"`
+	RoomID    uint   `form:"room_id"`
+	GroupIDs  []uint `form:"group_ids"`
+}
+
+// ICSImportItem résultat de l'import d'un VEVENT
+type ICSImportItem struct {
+	Index             int            `json:"index"`
+	UID               string         `json:"uid"`
+	Summary           string         `json:"summary"`
+	Target            string         `json:"target"` // course, event
+	StartTime         time.Time      `json:"start_time"`
+	EndTime           time.Time      `json:"end_time"`
+	RoomID            uint           `json:"room_id,omitempty"`
+	SubjectID         uint           `json:"subject_id,omitempty"`
+	TeacherID         uint           `json:"teacher_id,omitempty"`
+	IsRecurring       bool           `json:"is_recurring"`
+	RecurrencePattern *string        `json:"recurrence_pattern,omitempty"`
+	RecurrenceEndDate *time.Time     `json:"recurrence_end_date,omitempty"`
+	Occurrences       int            `json:"occurrences"` // Nombre d'occurrences à créer
+	Conflicts         []ConflictInfo `json:"conflicts,omitempty"`
+	Warnings          []string       `json:"warnings,omitempty"`
+	Error             string         `json:"error,omitempty"`
+	CreatedIDs        []uint         `json:"created_ids,omitempty"`
+}
+
+// Importable indique si le VEVENT peut être créé (ni erreur ni conflit)
+func (i *ICSImportItem) Importable() bool {
+	return i.Error == "" && len(i.Conflicts) == 0
+}
+
+// ICSImportReport rapport d'un import (ou d'un aperçu) iCalendar
+type ICSImportReport struct {
+	DryRun     bool            `json:"dry_run"`
+	Total      int             `json:"total"`
+	Importable int             `json:"importable"`
+	Created    int             `json:"created"`
+	Skipped    int             `json:"skipped"`
+	Items      []ICSImportItem `json:"items"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Portées du suivi des échecs de connexion
+const (
+	ThrottleScopeAccount = "account" // Identifiant : e-mail de connexion
+	ThrottleScopeIP      = "ip"      // Identifiant : adresse IP du client
+)
+
+// LoginThrottle compte les échecs de connexion récents d'un compte ou d'une adresse IP.
+// Au-delà d'un certain nombre d'échecs, chaque nouvelle tentative est retardée, puis bloquée jusqu'à LockedUntil.
+type LoginThrottle struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Scope         string     `json:"scope" gorm:"size:20;not null;uniqueIndex:idx_login_throttle_key"`
+	Identifier    string     `json:"identifier" gorm:"size:255;not null;uniqueIndex:idx_login_throttle_key"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsLocked indique si le verrouillage est en cours
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(now)
+}
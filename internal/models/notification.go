@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Types de notifications
+const (
+	NotificationCourseCancelled   = "course_cancelled"
+	NotificationCourseRescheduled = "course_rescheduled"
+	NotificationCourseSubstitute  = "course_substitute"
+	NotificationRoomBooking       = "room_booking"
+)
+
+// Notification représente un message adressé à un utilisateur
+type Notification struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	Type      string         `json:"type" gorm:"not null;index"`
+	Title     string         `json:"title" gorm:"not null"`
+	Message   string         `json:"message"`
+	CourseID  *uint          `json:"course_id" gorm:"index"`
+	ReadAt    *time.Time     `json:"read_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// NotificationResponse pour l'API
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	CourseID  *uint      `json:"course_id"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ToNotificationResponse convertit une Notification en NotificationResponse
+func (n *Notification) ToNotificationResponse() NotificationResponse {
+	return NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Message:   n.Message,
+		CourseID:  n.CourseID,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
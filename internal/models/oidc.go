@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// OIDCLoginState conserve, le temps de l'aller-retour chez le fournisseur d'identité, les secrets
+// d'une connexion OpenID Connect. Seule l'empreinte du paramètre state est stockée ; il ne sert qu'une fois.
+type OIDCLoginState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	StateHash    string    `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"` // Vérificateur PKCE
+	BrowserHash  string    `json:"-" gorm:"size:64"`  // Empreinte du secret déposé en cookie dans le navigateur qui a démarré la connexion
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OIDCAuthorizationResponse contient l'URL du fournisseur d'identité vers laquelle rediriger l'utilisateur
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OIDCCallbackRequest transmet les paramètres reçus par la page de retour du frontend
+type OIDCCallbackRequest struct {
+	Code   string `json:"code" binding:"required"`
+	State  string `json:"state" binding:"required"`
+	Device string `json:"device" binding:"omitempty,max=100"` // Nom de l'appareil, déduit du user agent s'il est absent
+}
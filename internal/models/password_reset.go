@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// PasswordResetToken est un jeton de réinitialisation de mot de passe envoyé par e-mail.
+// Seule son empreinte est stockée ; il n'est utilisable qu'une fois et jusqu'à son expiration.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ForgotPasswordRequest représente une demande de réinitialisation de mot de passe
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest représente la réinitialisation du mot de passe avec le jeton reçu par e-mail
+type ResetPasswordRequest struct {
+	Token           string `json:"token" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword"`
+}
@@ -0,0 +1,195 @@
+package models
+
+import "time"
+
+// Les permissions sont nommées ressource:action:portée. La portée "any" couvre toutes les ressources et
+// "own" celles dont l'utilisateur a la charge ; pour les comptes utilisateurs, la portée est le rôle du compte visé.
+const (
+	PermPermissionManageAny   = "permission:manage:any"
+	PermRoomManageAny         = "room:manage:any"
+	PermRoomSearchAny         = "room:search:any"
+	PermRoomDeleteAny         = "room:delete:any"
+	PermRoomBookingReviewAny  = "room_booking:review:any"
+	PermSubjectManageAny      = "subject:manage:any"
+	PermSubjectDeleteAny      = "subject:delete:any"
+	PermCurriculumManageAny   = "curriculum:manage:any"
+	PermCourseManageAny       = "course:manage:any"
+	PermCourseCreateAny       = "course:create:any"
+	PermCourseUpdateAny       = "course:update:any"
+	PermCourseUpdateOwn       = "course:update:own"
+	PermCourseDeleteAny       = "course:delete:any"
+	PermCourseDeleteOwn       = "course:delete:own"
+	PermGroupManageAny        = "group:manage:any"
+	PermTimetableManageAny    = "timetable:manage:any"
+	PermReportReadAny         = "report:read:any"
+	PermAbsenceReadAny        = "absence:read:any"
+	PermAbsenceReadOwn        = "absence:read:own"
+	PermAbsenceDeleteAny      = "absence:delete:any"
+	PermAbsenceReviewAny      = "absence:review:any"
+	PermAbsenceReviewOwn      = "absence:review:own"
+	PermPresenceReadAny       = "presence:read:any"
+	PermQRCodeReadAny         = "qr_code:read:any"
+	PermQRCodeReadOwn         = "qr_code:read:own"
+	PermQRCodeRegenerateAny   = "qr_code:regenerate:any"
+	PermQRCodeRegenerateOwn   = "qr_code:regenerate:own"
+	PermAuditLogReadAny       = "audit_log:read:any"
+	PermAvailabilityUpdateAny = "availability:update:any"
+	PermAvailabilityUpdateOwn = "availability:update:own"
+)
+
+// Actions sur les comptes utilisateurs, dont la portée est le rôle du compte visé
+const (
+	UserActionView        = "view"         // Nom et prénom
+	UserActionViewDetails = "view_details" // Nom, prénom, rôle et date de création
+	UserActionManage      = "manage"       // Profil complet, modification, rôle et sessions
+	UserActionDelete      = "delete"
+)
+
+// UserPermission retourne la permission d'effectuer une action sur les comptes ayant le rôle donné,
+// par exemple user:manage:etudiant
+func UserPermission(action, targetRole string) string {
+	return "user:" + action + ":" + targetRole
+}
+
+// UserPermissions retourne la permission d'effectuer une action sur chacun des rôles
+func UserPermissions(action string) []string {
+	permissions := make([]string, len(ValidRoles))
+	for i, role := range ValidRoles {
+		permissions[i] = UserPermission(action, role)
+	}
+	return permissions
+}
+
+// PermissionDefinition décrit une permission du registre
+type PermissionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var permissionDescriptions = []PermissionDefinition{
+	{PermPermissionManageAny, "Modifier les permissions des rôles"},
+	{PermRoomManageAny, "Gérer les salles et leurs équipements"},
+	{PermRoomSearchAny, "Rechercher les salles disponibles"},
+	{PermRoomDeleteAny, "Supprimer une salle"},
+	{PermRoomBookingReviewAny, "Accepter ou refuser les demandes de réservation de salle"},
+	{PermSubjectManageAny, "Gérer les matières et leurs enseignants"},
+	{PermSubjectDeleteAny, "Supprimer une matière"},
+	{PermCurriculumManageAny, "Gérer les quotas horaires du programme"},
+	{PermCourseManageAny, "Gérer tous les cours depuis l'administration"},
+	{PermCourseCreateAny, "Créer des cours"},
+	{PermCourseUpdateAny, "Modifier, annuler, reporter ou terminer n'importe quel cours"},
+	{PermCourseUpdateOwn, "Modifier, annuler, reporter ou terminer ses propres cours"},
+	{PermCourseDeleteAny, "Supprimer n'importe quel cours"},
+	{PermCourseDeleteOwn, "Supprimer ses propres cours"},
+	{PermGroupManageAny, "Gérer les groupes d'étudiants"},
+	{PermTimetableManageAny, "Générer et appliquer les emplois du temps"},
+	{PermReportReadAny, "Consulter les rapports et la charge d'enseignement de tous les professeurs"},
+	{PermAbsenceReadAny, "Consulter toutes les absences et leurs statistiques"},
+	{PermAbsenceReadOwn, "Consulter les absences de ses cours et filtrer les absences"},
+	{PermAbsenceDeleteAny, "Supprimer n'importe quelle absence"},
+	{PermAbsenceReviewAny, "Traiter toutes les absences"},
+	{PermAbsenceReviewOwn, "Traiter les absences de ses cours"},
+	{PermPresenceReadAny, "Consulter toutes les présences"},
+	{PermQRCodeReadAny, "Afficher le QR code de n'importe quel cours"},
+	{PermQRCodeReadOwn, "Afficher le QR code de ses cours"},
+	{PermQRCodeRegenerateAny, "Régénérer le QR code de n'importe quel cours"},
+	{PermQRCodeRegenerateOwn, "Régénérer le QR code de ses cours"},
+	{PermAuditLogReadAny, "Consulter le journal d'audit"},
+	{PermAvailabilityUpdateAny, "Modifier les disponibilités de tous les professeurs"},
+	{PermAvailabilityUpdateOwn, "Modifier ses propres disponibilités"},
+}
+
+var userActionDescriptions = map[string]string{
+	UserActionView:        "Voir le nom des comptes",
+	UserActionViewDetails: "Voir le nom, le rôle et la date de création des comptes",
+	UserActionManage:      "Voir et gérer les comptes",
+	UserActionDelete:      "Supprimer les comptes",
+}
+
+// PermissionRegistry retourne toutes les permissions connues
+func PermissionRegistry() []PermissionDefinition {
+	registry := append([]PermissionDefinition{}, permissionDescriptions...)
+	for _, action := range []string{UserActionView, UserActionViewDetails, UserActionManage, UserActionDelete} {
+		for _, role := range ValidRoles {
+			registry = append(registry, PermissionDefinition{
+				Name:        UserPermission(action, role),
+				Description: userActionDescriptions[action] + " " + role,
+			})
+		}
+	}
+	return registry
+}
+
+// IsValidPermission checks if a permission is part of the registry
+func IsValidPermission(permission string) bool {
+	for _, definition := range PermissionRegistry() {
+		if definition.Name == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRolePermissions reproduit les droits historiques de chaque rôle ; les modifications du super admin
+// sont enregistrées par rapport à ces valeurs
+var DefaultRolePermissions = map[string][]string{
+	RoleSuperAdmin: superAdminPermissions(),
+	RoleAdmin: {
+		PermRoomManageAny, PermRoomSearchAny, PermRoomDeleteAny, PermRoomBookingReviewAny,
+		PermSubjectManageAny, PermSubjectDeleteAny, PermCurriculumManageAny,
+		PermCourseManageAny, PermCourseCreateAny, PermCourseUpdateAny, PermCourseDeleteAny,
+		PermGroupManageAny, PermTimetableManageAny, PermReportReadAny,
+		PermAbsenceReadAny, PermAbsenceReviewAny, PermAbsenceDeleteAny, PermPresenceReadAny,
+		PermQRCodeReadAny, PermQRCodeRegenerateAny, PermAuditLogReadAny, PermAvailabilityUpdateAny,
+		UserPermission(UserActionView, RoleProfesseur), UserPermission(UserActionView, RoleEtudiant),
+		UserPermission(UserActionManage, RoleProfesseur), UserPermission(UserActionManage, RoleEtudiant),
+		UserPermission(UserActionDelete, RoleProfesseur), UserPermission(UserActionDelete, RoleEtudiant),
+	},
+	RoleProfesseur: {
+		PermRoomSearchAny, PermCourseCreateAny, PermCourseUpdateOwn, PermCourseDeleteOwn, PermAbsenceReadOwn, PermAbsenceReviewOwn,
+		PermQRCodeReadOwn, PermQRCodeRegenerateOwn, PermAvailabilityUpdateOwn,
+		UserPermission(UserActionView, RoleProfesseur), UserPermission(UserActionView, RoleEtudiant),
+		UserPermission(UserActionViewDetails, RoleProfesseur), UserPermission(UserActionViewDetails, RoleEtudiant),
+	},
+	RoleEtudiant: {
+		UserPermission(UserActionView, RoleEtudiant),
+	},
+}
+
+// superAdminPermissions accorde tout le registre, sauf la suppression d'un autre super admin
+func superAdminPermissions() []string {
+	var permissions []string
+	for _, definition := range PermissionRegistry() {
+		if definition.Name != UserPermission(UserActionDelete, RoleSuperAdmin) {
+			permissions = append(permissions, definition.Name)
+		}
+	}
+	return permissions
+}
+
+// RolePermissionOverride enregistre une permission accordée (Granted) ou retirée à un rôle par rapport
+// à DefaultRolePermissions
+type RolePermissionOverride struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Role       string    `json:"role" gorm:"not null;uniqueIndex:idx_role_permission"`
+	Permission string    `json:"permission" gorm:"not null;uniqueIndex:idx_role_permission"`
+	Granted    bool      `json:"granted"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RolePermissionsResponse liste les permissions effectives d'un rôle
+type RolePermissionsResponse struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionRegistryResponse contient le registre des permissions et leur attribution à chaque rôle
+type PermissionRegistryResponse struct {
+	Permissions []PermissionDefinition    `json:"permissions"`
+	Roles       []RolePermissionsResponse `json:"roles"`
+}
+
+// UpdateRolePermissionsRequest remplace les permissions d'un rôle
+type UpdateRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
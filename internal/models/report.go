@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// TeacherWorkloadResponse résume la charge d'enseignement d'un professeur sur une période.
+// Les cours annulés ou déplacés ne sont pas comptés ; une occurrence assurée par un remplaçant
+// est comptée pour le remplaçant et non pour le titulaire.
+type TeacherWorkloadResponse struct {
+	TeacherID         uint      `json:"teacher_id"`
+	TeacherName       string    `json:"teacher_name"`
+	StartDate         time.Time `json:"start_date"`
+	EndDate           time.Time `json:"end_date"`
+	CourseCount       int       `json:"course_count"`       // Cours assurés en titulaire
+	CourseMinutes     int       `json:"course_minutes"`     // Minutes assurées en titulaire
+	SubstituteCount   int       `json:"substitute_count"`   // Occurrences assurées en remplacement
+	SubstituteMinutes int       `json:"substitute_minutes"` // Minutes assurées en remplacement
+	ReplacedCount     int       `json:"replaced_count"`     // Cours du titulaire assurés par un remplaçant
+	ReplacedMinutes   int       `json:"replaced_minutes"`   // Minutes du titulaire assurées par un remplaçant
+	TotalMinutes      int       `json:"total_minutes"`      // CourseMinutes + SubstituteMinutes
+}
+
+// RoomOccupancyFilter représente les options du rapport d'occupation des salles
+type RoomOccupancyFilter struct {
+	Building        string `form:"building"`
+	Floor           string `form:"floor"`
+	OpeningHour     *int   `form:"opening_hour"` // Heure d'ouverture des salles (8 par défaut)
+	ClosingHour     *int   `form:"closing_hour"` // Heure de fermeture des salles (20 par défaut)
+	IncludeWeekends bool   `form:"include_weekends"`
+}
+
+// RoomOccupancy résume l'occupation d'une salle par les cours sur une période.
+// Une salle modulable découpée en sous-salles cumule les chiffres de ses sous-salles :
+// un cours dans la salle entière occupe chacune d'elles.
+type RoomOccupancy struct {
+	RoomID            uint     `json:"room_id"`
+	RoomName          string   `json:"room_name"`
+	Building          string   `json:"building"`
+	Floor             string   `json:"floor"`
+	ParentID          *uint    `json:"parent_id"`
+	SubRoomCount      int      `json:"sub_room_count"`
+	Capacity          int      `json:"capacity"`
+	AvailableHours    float64  `json:"available_hours"`
+	BookedHours       float64  `json:"booked_hours"`
+	UtilisationRate   float64  `json:"utilisation_rate"`   // Pourcentage des heures disponibles occupées
+	CourseCount       int      `json:"course_count"`       // Cours actifs ayant eu lieu dans la salle
+	AttendedCourses   int      `json:"attended_courses"`   // Cours dont l'appel a été enregistré
+	AverageAttendance *float64 `json:"average_attendance"` // Étudiants présents ou en retard par cours
+	AverageFillRate   *float64 `json:"average_fill_rate"`  // Pourcentage de la capacité occupée, si elle est renseignée
+}
+
+// OccupancySummary cumule l'occupation des salles d'un bâtiment ou d'un étage
+type OccupancySummary struct {
+	Building        string  `json:"building"`
+	Floor           string  `json:"floor,omitempty"`
+	RoomCount       int     `json:"room_count"` // Salles simples et sous-salles
+	AvailableHours  float64 `json:"available_hours"`
+	BookedHours     float64 `json:"booked_hours"`
+	UtilisationRate float64 `json:"utilisation_rate"`
+}
+
+// RoomOccupancyReport est le rapport d'occupation des salles sur une période.
+// Les cumuls par étage, par bâtiment et global ne comptent que les salles simples et les sous-salles,
+// afin de ne pas compter deux fois une salle modulable et ses sous-salles.
+type RoomOccupancyReport struct {
+	StartDate   time.Time          `json:"start_date"`
+	EndDate     time.Time          `json:"end_date"`
+	OpeningHour int                `json:"opening_hour"`
+	ClosingHour int                `json:"closing_hour"`
+	Rooms       []RoomOccupancy    `json:"rooms"`
+	Floors      []OccupancySummary `json:"floors"`
+	Buildings   []OccupancySummary `json:"buildings"`
+	Total       OccupancySummary   `json:"total"`
+}
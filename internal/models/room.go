@@ -1,22 +1,25 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
 // Room représente une salle dans le système
 type Room struct {
-	ID        uint       `json:"id" gorm:"primaryKey"`
-	Name      string     `json:"name" gorm:"uniqueIndex;not null"`
-	Building  string     `json:"building"`
-	Floor     string     `json:"floor"`
-	IsModular bool       `json:"is_modular" gorm:"default:false"`
-	ParentID  *uint      `json:"parent_id" gorm:"index"`
-	Parent    *Room      `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
-	Children  []Room     `json:"children,omitempty" gorm:"foreignKey:ParentID"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	Name      string      `json:"name" gorm:"uniqueIndex;not null"`
+	Building  string      `json:"building"`
+	Floor     string      `json:"floor"`
+	IsModular bool        `json:"is_modular" gorm:"default:false"`
+	Capacity  int         `json:"capacity" gorm:"default:0"` // 0 = capacité non renseignée
+	Equipment []Equipment `json:"equipment" gorm:"many2many:room_equipment"`
+	ParentID  *uint       `json:"parent_id" gorm:"index"`
+	Parent    *Room       `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
+	Children  []Room      `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	DeletedAt *time.Time  `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // RoomResponse représente la réponse pour une salle
@@ -26,6 +29,8 @@ type RoomResponse struct {
 	Building  string         `json:"building"`
 	Floor     string         `json:"floor"`
 	IsModular bool           `json:"is_modular"`
+	Capacity  int            `json:"capacity"`
+	Equipment []string       `json:"equipment"`
 	ParentID  *uint          `json:"parent_id"`
 	Parent    *RoomResponse  `json:"parent,omitempty"`
 	Children  []RoomResponse `json:"children,omitempty"`
@@ -35,29 +40,54 @@ type RoomResponse struct {
 
 // CreateRoomRequest représente la requête de création d'une salle
 type CreateRoomRequest struct {
-	Name      string `json:"name" binding:"required"`
-	Building  string `json:"building"`
-	Floor     string `json:"floor"`
-	IsModular bool   `json:"is_modular"`
+	Name      string   `json:"name" binding:"required"`
+	Building  string   `json:"building"`
+	Floor     string   `json:"floor"`
+	IsModular bool     `json:"is_modular"`
+	Capacity  int      `json:"capacity" binding:"min=0"`
+	Equipment []string `json:"equipment"`
 	// Si modulable, nombre de sous-salles à créer
 	SubRoomsCount int `json:"sub_rooms_count,omitempty"`
 }
 
 // UpdateRoomRequest représente la requête de modification d'une salle
 type UpdateRoomRequest struct {
-	Name      string `json:"name" binding:"required"`
-	Building  string `json:"building"`
-	Floor     string `json:"floor"`
-	IsModular bool   `json:"is_modular"`
+	Name      string   `json:"name" binding:"required"`
+	Building  string   `json:"building"`
+	Floor     string   `json:"floor"`
+	IsModular bool     `json:"is_modular"`
+	Capacity  int      `json:"capacity" binding:"min=0"`
+	Equipment []string `json:"equipment"` // nil = équipements inchangés
 }
 
 // RoomFilter représente les filtres pour la recherche de salles
 type RoomFilter struct {
-	Name     string `form:"name"`
-	Building string `form:"building"`
-	Floor    string `form:"floor"`
-	Modular  *bool  `form:"modular"`
-	ParentID *uint  `form:"parent_id"`
+	Name            string `form:"name"`
+	Building        string `form:"building"`
+	Floor           string `form:"floor"`
+	Modular         *bool  `form:"modular"`
+	ParentID        *uint  `form:"parent_id"`
+	MinCapacity     *int   `form:"min_capacity"`      // Inclut aussi les sous-salles
+	Equipment       string `form:"equipment"`         // Étiquettes séparées par des virgules, toutes requises ; inclut aussi les sous-salles
+	IncludeSubRooms bool   `form:"include_sub_rooms"` // Inclure les sous-salles des salles modulables
+}
+
+// AvailableRoomFilter représente la recherche de salles libres sur un créneau
+type AvailableRoomFilter struct {
+	Start       time.Time `form:"start" binding:"required"` // RFC 3339
+	End         time.Time `form:"end" binding:"required"`   // RFC 3339
+	Building    string    `form:"building"`
+	MinCapacity *int      `form:"min_capacity"`
+	Equipment   string    `form:"equipment"` // Étiquettes séparées par des virgules, toutes requises
+	Modular     *bool     `form:"modular"`
+}
+
+// EquipmentTags retourne les étiquettes d'équipement demandées par le filtre
+func (f *RoomFilter) EquipmentTags() []string {
+	if f.Equipment == "" {
+		return nil
+	}
+	return NormalizeEquipmentTags(strings.Split(f.Equipment, ","))
 }
 
 // ToRoomResponse convertit un Room en RoomResponse
@@ -68,6 +98,8 @@ func (r *Room) ToRoomResponse() RoomResponse {
 		Building:  r.Building,
 		Floor:     r.Floor,
 		IsModular: r.IsModular,
+		Capacity:  r.Capacity,
+		Equipment: EquipmentNames(r.Equipment),
 		ParentID:  r.ParentID,
 		CreatedAt: r.CreatedAt,
 		UpdatedAt: r.UpdatedAt,
@@ -88,3 +120,24 @@ func (r *Room) ToRoomResponse() RoomResponse {
 
 	return response
 }
+
+// roomResponses convertit une liste de salles, nil si elle est vide
+func roomResponses(rooms []Room) []RoomResponse {
+	if len(rooms) == 0 {
+		return nil
+	}
+	responses := make([]RoomResponse, len(rooms))
+	for i, room := range rooms {
+		responses[i] = room.ToRoomResponse()
+	}
+	return responses
+}
+
+// occupiedRoomIDs retourne la salle principale suivie des sous-salles qui lui sont réunies
+func OccupiedRoomIDs(roomID uint, merged []Room) []uint {
+	ids := []uint{roomID}
+	for _, room := range merged {
+		ids = append(ids, room.ID)
+	}
+	return ids
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuts d'une demande de réservation de salle
+const (
+	RoomBookingStatusPending   = "pending"   // En attente de validation
+	RoomBookingStatusApproved  = "approved"  // Validée, la salle est réservée
+	RoomBookingStatusDeclined  = "declined"  // Refusée par un administrateur
+	RoomBookingStatusCancelled = "cancelled" // Annulée par le demandeur
+)
+
+// Motifs de réservation
+const (
+	RoomBookingPurposeMeeting = "meeting"
+	RoomBookingPurposeExam    = "exam"
+	RoomBookingPurposeClub    = "club"
+	RoomBookingPurposeOther   = "other"
+)
+
+// RoomBooking représente une réservation de salle en dehors des cours (réunion, examen, association...)
+type RoomBooking struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	RoomID        uint           `json:"room_id" gorm:"not null;index"`
+	Room          Room           `json:"room" gorm:"foreignKey:RoomID"`
+	MergedRooms   []Room         `json:"merged_rooms" gorm:"many2many:room_booking_merged_rooms"` // Sous-salles voisines réunies avec Room
+	RequesterID   uint           `json:"requester_id" gorm:"not null;index"`
+	Requester     User           `json:"requester" gorm:"foreignKey:RequesterID"`
+	Title         string         `json:"title" gorm:"not null"`
+	Purpose       string         `json:"purpose" gorm:"not null;default:'other'"`
+	Description   string         `json:"description"`
+	StartTime     time.Time      `json:"start_time" gorm:"not null;index"`
+	EndTime       time.Time      `json:"end_time" gorm:"not null;index"`
+	Status        string         `json:"status" gorm:"not null;default:'pending';index"`
+	ReviewerID    *uint          `json:"reviewer_id"`
+	Reviewer      *User          `json:"reviewer" gorm:"foreignKey:ReviewerID"`
+	ReviewComment string         `json:"review_comment"`
+	ReviewedAt    *time.Time     `json:"reviewed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// RoomBookingResponse pour l'API
+type RoomBookingResponse struct {
+	ID            uint           `json:"id"`
+	Room          RoomResponse   `json:"room"`
+	MergedRooms   []RoomResponse `json:"merged_rooms,omitempty"`
+	Requester     UserResponse   `json:"requester"`
+	Title         string         `json:"title"`
+	Purpose       string         `json:"purpose"`
+	Description   string         `json:"description"`
+	StartTime     time.Time      `json:"start_time"`
+	EndTime       time.Time      `json:"end_time"`
+	Status        string         `json:"status"`
+	Reviewer      *UserResponse  `json:"reviewer,omitempty"`
+	ReviewComment string         `json:"review_comment"`
+	ReviewedAt    *time.Time     `json:"reviewed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// CreateRoomBookingRequest pour une demande de réservation
+type CreateRoomBookingRequest struct {
+	RoomID        uint      `json:"room_id" binding:"required"`
+	MergedRoomIDs []uint    `json:"merged_room_ids"` // Sous-salles voisines de room_id à réunir en un seul espace
+	Title         string    `json:"title" binding:"required"`
+	Purpose       string    `json:"purpose" binding:"omitempty,oneof=meeting exam club other"`
+	Description   string    `json:"description"`
+	StartTime     time.Time `json:"start_time" binding:"required"`
+	EndTime       time.Time `json:"end_time" binding:"required"`
+}
+
+// ReviewRoomBookingRequest pour la validation ou le refus d'une réservation
+type ReviewRoomBookingRequest struct {
+	Status        string `json:"status" binding:"required,oneof=approved declined"`
+	ReviewComment string `json:"review_comment"`
+}
+
+// RoomBookingFilter pour le filtrage des réservations
+type RoomBookingFilter struct {
+	Status      string `form:"status"`
+	RoomID      *uint  `form:"room_id"`
+	RequesterID *uint  `form:"-"`
+}
+
+// IsActive indique si la réservation bloque encore la salle ou est susceptible de la bloquer
+func (b *RoomBooking) IsActive() bool {
+	return b.Status == RoomBookingStatusPending || b.Status == RoomBookingStatusApproved
+}
+
+// RoomIDs retourne les IDs de toutes les salles réservées (salle principale et sous-salles réunies)
+func (b *RoomBooking) RoomIDs() []uint {
+	return OccupiedRoomIDs(b.RoomID, b.MergedRooms)
+}
+
+// ToRoomBookingResponse convertit une RoomBooking en RoomBookingResponse
+func (b *RoomBooking) ToRoomBookingResponse() RoomBookingResponse {
+	response := RoomBookingResponse{
+		ID:            b.ID,
+		Room:          b.Room.ToRoomResponse(),
+		MergedRooms:   roomResponses(b.MergedRooms),
+		Requester:     UserToUserResponse(b.Requester),
+		Title:         b.Title,
+		Purpose:       b.Purpose,
+		Description:   b.Description,
+		StartTime:     b.StartTime,
+		EndTime:       b.EndTime,
+		Status:        b.Status,
+		ReviewComment: b.ReviewComment,
+		ReviewedAt:    b.ReviewedAt,
+		CreatedAt:     b.CreatedAt,
+		UpdatedAt:     b.UpdatedAt,
+	}
+
+	if b.Reviewer != nil {
+		reviewer := UserToUserResponse(*b.Reviewer)
+		response.Reviewer = &reviewer
+	}
+
+	return response
+}
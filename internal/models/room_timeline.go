@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Types d'occupation affichés dans la chronologie d'une salle
+const (
+	RoomActivityCourse  = "course"
+	RoomActivityBooking = "booking"
+)
+
+// RoomActivity est une occupation de salle (cours actif ou réservation validée)
+type RoomActivity struct {
+	Type      string    `json:"type"` // course, booking
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	RoomIDs   []uint    `json:"-"`
+}
+
+// RoomTimelineSpace est un espace de la configuration : une salle seule ou des sous-salles réunies
+type RoomTimelineSpace struct {
+	RoomIDs   []uint        `json:"room_ids"`
+	RoomNames []string      `json:"room_names"`
+	Merged    bool          `json:"merged"`
+	Activity  *RoomActivity `json:"activity"` // nil si l'espace est libre
+}
+
+// RoomTimelineSegment est un intervalle pendant lequel la configuration de la salle ne change pas
+type RoomTimelineSegment struct {
+	Start         time.Time           `json:"start"`
+	End           time.Time           `json:"end"`
+	Configuration string              `json:"configuration"` // Ex. « Amphi A+Amphi B | Amphi C »
+	Spaces        []RoomTimelineSpace `json:"spaces"`
+}
+
+// RoomTimelineResponse est la chronologie d'une salle (modulable : la salle parente et toutes ses sous-salles)
+type RoomTimelineResponse struct {
+	Room     RoomResponse          `json:"room"`
+	Start    time.Time             `json:"start"`
+	End      time.Time             `json:"end"`
+	Segments []RoomTimelineSegment `json:"segments"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Session représente une connexion d'un utilisateur. Elle reste ouverte tant que son jeton de
+// rafraîchissement n'a pas expiré et qu'elle n'a pas été révoquée (déconnexion ou révocation par un admin).
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	User       User       `json:"-" gorm:"foreignKey:UserID"`
+	Device     string     `json:"device" gorm:"size:100"` // Nom fourni à la connexion ou déduit du user agent
+	UserAgent  string     `json:"user_agent" gorm:"size:500"`
+	IPAddress  string     `json:"ip_address" gorm:"size:45"` // Dernière adresse IP connue
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"` // Expiration du jeton de rafraîchissement courant
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IsActive indique si la session peut encore être rafraîchie
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
+
+// SessionClient décrit le client à l'origine d'une requête d'authentification
+type SessionClient struct {
+	Device    string
+	UserAgent string
+	IPAddress string
+}
+
+// SessionResponse représente une session ouverte dans la liste des sessions d'un utilisateur
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Session du jeton d'accès utilisé pour la requête
+}
+
+// SessionToSessionResponse convertit une session en réponse
+func SessionToSessionResponse(session Session, currentSessionID uint) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID,
+		Device:     session.Device,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+		Current:    session.ID == currentSessionID,
+	}
+}
+
+// RefreshToken est un jeton de rafraîchissement d'une session. Seule son empreinte est stockée ;
+// chaque utilisation le remplace par un nouveau jeton (rotation).
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	SessionID uint       `json:"session_id" gorm:"not null;index"`
+	Session   Session    `json:"-" gorm:"foreignKey:SessionID"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"` // Rempli lors de la rotation : une réutilisation révoque la session
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RefreshTokenRequest représente la requête de rafraîchissement du jeton d'accès
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest représente la requête de déconnexion ; sans jeton, la session du jeton d'accès est fermée
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthTokens contient les jetons remis à la connexion et à chaque rafraîchissement
+type AuthTokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Durée de validité du jeton d'accès, en secondes
+	SessionID    uint   `json:"session_id"`
+}
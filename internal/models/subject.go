@@ -8,47 +8,85 @@ import (
 
 // Subject représente une matière dans le système
 type Subject struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Name        string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
-	Code        string         `json:"code" gorm:"size:20"`
-	Description string         `json:"description"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Name              string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Code              string         `json:"code" gorm:"size:20"`
+	Description       string         `json:"description"`
+	RequiredEquipment []Equipment    `json:"required_equipment" gorm:"many2many:subject_equipment"` // Équipements requis dans la salle
+	Teachers          []User         `json:"teachers,omitempty" gorm:"many2many:subject_teachers"`  // Enseignants qualifiés
+	HeadTeacherID     *uint          `json:"head_teacher_id" gorm:"index"`                          // Enseignant responsable de la matière
+	HeadTeacher       *User          `json:"head_teacher,omitempty" gorm:"foreignKey:HeadTeacherID"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // SubjectResponse représente la réponse pour une matière
 type SubjectResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Code        string    `json:"code"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                uint          `json:"id"`
+	Name              string        `json:"name"`
+	Code              string        `json:"code"`
+	Description       string        `json:"description"`
+	RequiredEquipment []string      `json:"required_equipment"`
+	HeadTeacherID     *uint         `json:"head_teacher_id"`
+	HeadTeacher       *UserResponse `json:"head_teacher,omitempty"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
 }
 
 // CreateSubjectRequest représente la requête de création d'une matière
 type CreateSubjectRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Code        string `json:"code"`
-	Description string `json:"description"`
+	Name              string   `json:"name" binding:"required"`
+	Code              string   `json:"code"`
+	Description       string   `json:"description"`
+	RequiredEquipment []string `json:"required_equipment"`
 }
 
 // UpdateSubjectRequest représente la requête de modification d'une matière
 type UpdateSubjectRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Code        string `json:"code"`
-	Description string `json:"description"`
+	Name              string   `json:"name" binding:"required"`
+	Code              string   `json:"code"`
+	Description       string   `json:"description"`
+	RequiredEquipment []string `json:"required_equipment"` // nil = équipements inchangés
+}
+
+// SubjectTeacherRequest représente l'ajout d'un enseignant qualifié à une matière
+type SubjectTeacherRequest struct {
+	TeacherID uint `json:"teacher_id" binding:"required"`
+}
+
+// HeadTeacherRequest représente la désignation du responsable d'une matière (null pour le retirer)
+type HeadTeacherRequest struct {
+	TeacherID *uint `json:"teacher_id"`
+}
+
+// SubjectTeacherResponse représente un enseignant qualifié pour une matière
+type SubjectTeacherResponse struct {
+	Teacher UserResponse `json:"teacher"`
+	IsHead  bool         `json:"is_head"`
+}
+
+// TeacherSubjectResponse représente une matière qu'un enseignant est qualifié pour enseigner
+type TeacherSubjectResponse struct {
+	Subject SubjectResponse `json:"subject"`
+	IsHead  bool            `json:"is_head"`
 }
 
 // ToSubjectResponse convertit un Subject en SubjectResponse
 func (s *Subject) ToSubjectResponse() SubjectResponse {
-	return SubjectResponse{
-		ID:          s.ID,
-		Name:        s.Name,
-		Code:        s.Code,
-		Description: s.Description,
-		CreatedAt:   s.CreatedAt,
-		UpdatedAt:   s.UpdatedAt,
+	response := SubjectResponse{
+		ID:                s.ID,
+		Name:              s.Name,
+		Code:              s.Code,
+		Description:       s.Description,
+		RequiredEquipment: EquipmentNames(s.RequiredEquipment),
+		HeadTeacherID:     s.HeadTeacherID,
+		CreatedAt:         s.CreatedAt,
+		UpdatedAt:         s.UpdatedAt,
+	}
+	if s.HeadTeacher != nil {
+		headTeacher := UserToUserResponse(*s.HeadTeacher)
+		response.HeadTeacher = &headTeacher
 	}
+	return response
 }
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Statuts d'avancement d'un quota horaire
+const (
+	QuotaStatusOnTrack        = "on_track"        // Dans les temps
+	QuotaStatusBehind         = "behind"          // Heures effectuées en retard sur le prorata de la période
+	QuotaStatusUnderScheduled = "under_scheduled" // Heures planifiées insuffisantes pour atteindre le quota
+	QuotaStatusCompleted      = "completed"       // Quota atteint
+)
+
+// SubjectQuota fixe le volume horaire d'une matière pour un groupe sur une période du programme (trimestre, semestre...)
+type SubjectQuota struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	SubjectID     uint      `json:"subject_id" gorm:"not null;uniqueIndex:idx_subject_quota"`
+	Subject       Subject   `json:"subject" gorm:"foreignKey:SubjectID"`
+	GroupID       uint      `json:"group_id" gorm:"not null;uniqueIndex:idx_subject_quota"`
+	Group         Group     `json:"group" gorm:"foreignKey:GroupID"`
+	Term          string    `json:"term" gorm:"not null;size:50;uniqueIndex:idx_subject_quota"` // Ex. « 2024-2025 S1 »
+	StartDate     time.Time `json:"start_date" gorm:"not null"`
+	EndDate       time.Time `json:"end_date" gorm:"not null"` // Incluse
+	RequiredHours float64   `json:"required_hours" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SubjectQuotaResponse représente la réponse pour un quota horaire
+type SubjectQuotaResponse struct {
+	ID            uint            `json:"id"`
+	Subject       SubjectResponse `json:"subject"`
+	Group         GroupResponse   `json:"group"`
+	Term          string          `json:"term"`
+	StartDate     time.Time       `json:"start_date"`
+	EndDate       time.Time       `json:"end_date"`
+	RequiredHours float64         `json:"required_hours"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// CreateSubjectQuotaRequest représente la requête de création d'un quota horaire
+type CreateSubjectQuotaRequest struct {
+	SubjectID     uint    `json:"subject_id" binding:"required"`
+	GroupID       uint    `json:"group_id" binding:"required"`
+	Term          string  `json:"term" binding:"required,max=50"`
+	StartDate     string  `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate       string  `json:"end_date" binding:"required"`   // YYYY-MM-DD, incluse
+	RequiredHours float64 `json:"required_hours" binding:"required,gt=0"`
+}
+
+// UpdateSubjectQuotaRequest représente la requête de modification d'un quota horaire
+type UpdateSubjectQuotaRequest struct {
+	Term          string  `json:"term" binding:"required,max=50"`
+	StartDate     string  `json:"start_date" binding:"required"`
+	EndDate       string  `json:"end_date" binding:"required"`
+	RequiredHours float64 `json:"required_hours" binding:"required,gt=0"`
+}
+
+// SubjectQuotaFilter représente les filtres de recherche des quotas et de leur avancement
+type SubjectQuotaFilter struct {
+	SubjectID  *uint  `form:"subject_id"`
+	GroupID    *uint  `form:"group_id"`
+	Term       string `form:"term"`
+	BehindOnly bool   `form:"behind_only"` // Avancement : uniquement les matières en retard
+}
+
+// SubjectProgressResponse compare les heures planifiées et effectuées d'une matière à son quota.
+// Les cours du groupe et de ses groupes parents sont comptés ; les cours annulés ou déplacés ne le sont pas.
+type SubjectProgressResponse struct {
+	Quota          SubjectQuotaResponse `json:"quota"`
+	ScheduledHours float64              `json:"scheduled_hours"` // Cours prévus ou effectués sur la période
+	DeliveredHours float64              `json:"delivered_hours"` // Cours effectués
+	ExpectedHours  float64              `json:"expected_hours"`  // Heures qui devraient être effectuées à la date du jour, au prorata de la période
+	RemainingHours float64              `json:"remaining_hours"` // Heures restant à effectuer
+	ProgressRate   float64              `json:"progress_rate"`   // Pourcentage du quota effectué
+	Status         string               `json:"status"`          // on_track, behind, under_scheduled, completed
+	Behind         bool                 `json:"behind"`          // Matière à surveiller (behind ou under_scheduled)
+}
+
+// ToSubjectQuotaResponse convertit un SubjectQuota en SubjectQuotaResponse
+func (q *SubjectQuota) ToSubjectQuotaResponse() SubjectQuotaResponse {
+	return SubjectQuotaResponse{
+		ID:            q.ID,
+		Subject:       q.Subject.ToSubjectResponse(),
+		Group:         q.Group.ToGroupResponse(),
+		Term:          q.Term,
+		StartDate:     q.StartDate,
+		EndDate:       q.EndDate,
+		RequiredHours: q.RequiredHours,
+		CreatedAt:     q.CreatedAt,
+		UpdatedAt:     q.UpdatedAt,
+	}
+}
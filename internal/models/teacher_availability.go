@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SchoolLocation est le fuseau horaire de l'établissement, dans lequel sont exprimées les plages "HH:MM".
+// Il est fixé au démarrage du serveur à partir de la configuration.
+var SchoolLocation = time.UTC
+
+// TeacherAvailability est une plage de disponibilité hebdomadaire récurrente d'un enseignant.
+// Un enseignant sans aucune plage déclarée est considéré disponible en permanence.
+type TeacherAvailability struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	TeacherID uint           `json:"teacher_id" gorm:"not null;index"`
+	DayOfWeek string         `json:"day_of_week" gorm:"not null"` // Monday, Tuesday, ...
+	StartTime string         `json:"start_time" gorm:"not null"`  // HH:MM
+	EndTime   string         `json:"end_time" gorm:"not null"`    // HH:MM
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TeacherUnavailability est une période ponctuelle d'indisponibilité (congé, formation, ...)
+type TeacherUnavailability struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	TeacherID uint           `json:"teacher_id" gorm:"not null;index"`
+	StartDate time.Time      `json:"start_date" gorm:"not null"`
+	EndDate   time.Time      `json:"end_date" gorm:"not null"`
+	Reason    string         `json:"reason"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreateTeacherAvailabilityRequest pour l'ajout d'une plage de disponibilité
+type CreateTeacherAvailabilityRequest struct {
+	DayOfWeek string `json:"day_of_week" binding:"required,oneof=Monday Tuesday Wednesday Thursday Friday Saturday Sunday"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+}
+
+// CreateTeacherUnavailabilityRequest pour l'ajout d'une période d'indisponibilité
+type CreateTeacherUnavailabilityRequest struct {
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"`
+	Reason    string    `json:"reason"`
+}
+
+// TeacherAvailabilityResponse regroupe les disponibilités et indisponibilités d'un enseignant
+type TeacherAvailabilityResponse struct {
+	TeacherID        uint                    `json:"teacher_id"`
+	Availabilities   []TeacherAvailability   `json:"availabilities"`
+	Unavailabilities []TeacherUnavailability `json:"unavailabilities"`
+}
+
+// Minutes retourne les bornes de la plage en minutes depuis minuit
+func (a *TeacherAvailability) Minutes() (int, int, error) {
+	start, err := ParseClock(a.StartTime)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := ParseClock(a.EndTime)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// Covers indique si le créneau [start, end) est entièrement inclus dans la plage,
+// les heures étant comparées dans le fuseau de l'établissement
+func (a *TeacherAvailability) Covers(start, end time.Time) bool {
+	start, end = start.In(SchoolLocation), end.In(SchoolLocation)
+	if start.Weekday().String() != a.DayOfWeek || start.YearDay() != end.YearDay() {
+		return false
+	}
+	from, to, err := a.Minutes()
+	if err != nil {
+		return false
+	}
+	return from <= start.Hour()*60+start.Minute() && end.Hour()*60+end.Minute() <= to
+}
+
+// ParseClock convertit une heure "HH:MM" en minutes depuis minuit
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("heure invalide: %s (format attendu HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
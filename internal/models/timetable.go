@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// TimetableRequirementRequest décrit un volume horaire hebdomadaire à planifier
+type TimetableRequirementRequest struct {
+	SubjectID       uint    `json:"subject_id" binding:"required"`
+	GroupID         uint    `json:"group_id" binding:"required"`
+	TeacherID       uint    `json:"teacher_id" binding:"required"`
+	WeeklyHours     float64 `json:"weekly_hours" binding:"required,gt=0"`
+	SessionDuration int     `json:"session_duration" binding:"omitempty,min=15,max=480"` // 120 minutes par défaut
+}
+
+// GenerateTimetableRequest pour la génération automatique d'un emploi du temps hebdomadaire
+type GenerateTimetableRequest struct {
+	StartDate    time.Time                     `json:"start_date" binding:"required"`          // Début de la première semaine
+	EndDate      time.Time                     `json:"end_date" binding:"required"`            // Fin de la récurrence
+	Days         []string                      `json:"days"`                                   // ["Monday", ...], du lundi au vendredi par défaut
+	DayStart     string                        `json:"day_start"`                              // "08:00" par défaut
+	DayEnd       string                        `json:"day_end"`                                // "18:00" par défaut
+	Step         int                           `json:"step" binding:"omitempty,min=5,max=240"` // Pas entre deux horaires de début, 30 minutes par défaut
+	RoomIDs      []uint                        `json:"room_ids"`                               // Toutes les salles principales par défaut
+	Requirements []TimetableRequirementRequest `json:"requirements" binding:"required,min=1,dive"`
+}
+
+// TimetableSession est une séance hebdomadaire proposée par le générateur
+type TimetableSession struct {
+	SubjectID   uint      `json:"subject_id"`
+	SubjectName string    `json:"subject_name"`
+	TeacherID   uint      `json:"teacher_id"`
+	TeacherName string    `json:"teacher_name"`
+	GroupID     uint      `json:"group_id"`
+	GroupName   string    `json:"group_name"`
+	RoomID      uint      `json:"room_id"`
+	RoomName    string    `json:"room_name"`
+	Day         string    `json:"day"`
+	StartTime   time.Time `json:"start_time"` // Première occurrence
+	EndTime     time.Time `json:"end_time"`
+	Duration    int       `json:"duration"`
+}
+
+// TimetableUnsatisfied décrit un volume horaire que le générateur n'a pas pu placer
+type TimetableUnsatisfied struct {
+	SubjectID      uint   `json:"subject_id"`
+	SubjectName    string `json:"subject_name"`
+	TeacherID      uint   `json:"teacher_id"`
+	GroupID        uint   `json:"group_id"`
+	GroupName      string `json:"group_name"`
+	MissingMinutes int    `json:"missing_minutes"`
+	Reason         string `json:"reason"`
+}
+
+// TimetableProposalResponse est le résultat d'une génération d'emploi du temps
+type TimetableProposalResponse struct {
+	Sessions    []TimetableSession     `json:"sessions"`
+	Unsatisfied []TimetableUnsatisfied `json:"unsatisfied"`
+	Courses     []CourseResponse       `json:"courses,omitempty"` // Cours créés lors de l'application
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// TwoFactor contient le secret TOTP d'un utilisateur. Le secret est créé à l'enrôlement
+// et n'est actif qu'une fois confirmé par un premier code (EnabledAt).
+type TwoFactor struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	User         User       `json:"-" gorm:"foreignKey:UserID"`
+	Secret       string     `json:"-" gorm:"not null;size:64"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"-"` // Période du dernier code accepté : un code ne sert qu'une fois
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IsEnabled indique si la double authentification est active
+func (t *TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TwoFactorRecoveryCode est un code de secours à usage unique, utilisable à la place d'un code TOTP.
+// Seule son empreinte est stockée.
+type TwoFactorRecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	CodeHash  string     `json:"-" gorm:"not null;size:64"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorCodeRequest représente une requête confirmée par un code TOTP
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest représente la désactivation de la double authentification
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // Code TOTP ou code de secours
+}
+
+// TwoFactorChallengeRequest représente la seconde étape de connexion.
+// Code accepte un code TOTP ou, pour la vérification, un code de secours.
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+}
+
+// TwoFactorSetupResponse contient le secret à enregistrer dans l'application d'authentification
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // URI otpauth:// à afficher en QR code
+}
+
+// TwoFactorStatusResponse décrit l'état de la double authentification d'un utilisateur
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"` // Imposée par le rôle de l'utilisateur
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// TwoFactorRecoveryCodesResponse contient des codes de secours, affichés une seule fois
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -15,6 +15,13 @@ const (
 	RoleEtudiant   = "etudiant"
 )
 
+// Origine de l'authentification d'un utilisateur
+const (
+	AuthProviderLocal = "local" // Mot de passe EduQR
+	AuthProviderOIDC  = "oidc"  // Fournisseur d'identité OpenID Connect
+	AuthProviderLDAP  = "ldap"  // Annuaire LDAP / Active Directory
+)
+
 // Role hierarchy - higher index means higher privileges
 var RoleHierarchy = map[string]int{
 	RoleSuperAdmin: 4,
@@ -36,105 +43,50 @@ func IsValidRole(role string) bool {
 	return false
 }
 
-// CanManageRole checks if a user with role1 can manage a user with role2
-func CanManageRole(managerRole, targetRole string) bool {
-	// Super Admin can manage everyone
-	if managerRole == RoleSuperAdmin {
-		return true
-	}
-
-	// Admin can manage Professeur and Etudiant, but not other Admins or Super Admin
-	if managerRole == RoleAdmin {
-		return targetRole == RoleProfesseur || targetRole == RoleEtudiant
-	}
-
-	// Professeur and Etudiant cannot manage anyone
-	return false
-}
-
-// CanViewRole checks if a user with role1 can view users with role2
-func CanViewRole(viewerRole, targetRole string) bool {
-	// Super Admin can view everyone
-	if viewerRole == RoleSuperAdmin {
-		return true
-	}
-
-	// Admin can view Professeur and Etudiant, but not other Admins (except self)
-	if viewerRole == RoleAdmin {
-		return targetRole == RoleProfesseur || targetRole == RoleEtudiant
-	}
-
-	// Professeur can view other Professeurs and Etudiants
-	if viewerRole == RoleProfesseur {
-		return targetRole == RoleProfesseur || targetRole == RoleEtudiant
-	}
-
-	// Etudiant can only view other Etudiants
-	if viewerRole == RoleEtudiant {
-		return targetRole == RoleEtudiant
-	}
-
-	return false
-}
-
-// GetViewableFields returns the fields that a user with the given role can view
-func GetViewableFields(viewerRole, targetRole string) []string {
-	// Super Admin can see all fields
-	if viewerRole == RoleSuperAdmin {
-		return []string{"id", "email", "contact_email", "first_name", "last_name", "phone", "address", "avatar", "role", "created_at", "updated_at"}
-	}
-
-	// Admin can see all fields for Professeur and Etudiant
-	if viewerRole == RoleAdmin && (targetRole == RoleProfesseur || targetRole == RoleEtudiant) {
-		return []string{"id", "email", "contact_email", "first_name", "last_name", "phone", "address", "avatar", "role", "created_at", "updated_at"}
-	}
-
-	// Professeur can see limited fields for other Professeurs and Etudiants
-	if viewerRole == RoleProfesseur && (targetRole == RoleProfesseur || targetRole == RoleEtudiant) {
-		return []string{"id", "first_name", "last_name", "role", "created_at"}
-	}
-
-	// Etudiant can only see name fields for other Etudiants
-	if viewerRole == RoleEtudiant && targetRole == RoleEtudiant {
-		return []string{"id", "first_name", "last_name"}
-	}
-
-	return []string{}
-}
-
 type User struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	Email        string         `json:"email" gorm:"uniqueIndex;not null"`
-	ContactEmail string         `json:"contact_email"`
-	Password     string         `json:"-" gorm:"not null"`
-	FirstName    string         `json:"first_name" gorm:"not null"`
-	LastName     string         `json:"last_name" gorm:"not null"`
-	Phone        string         `json:"phone"`
-	Address      string         `json:"address"`
-	Avatar       string         `json:"avatar" gorm:"default:'/assets/images/avatars/default-avatar.png'"`
-	Role         string         `json:"role" gorm:"default:'etudiant'"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Email         string         `json:"email" gorm:"uniqueIndex;not null"`
+	ContactEmail  string         `json:"contact_email"`
+	Password      string         `json:"-" gorm:"not null"`
+	FirstName     string         `json:"first_name" gorm:"not null"`
+	LastName      string         `json:"last_name" gorm:"not null"`
+	Phone         string         `json:"phone"`
+	Address       string         `json:"address"`
+	Avatar        string         `json:"avatar" gorm:"default:'/assets/images/avatars/default-avatar.png'"`
+	Role          string         `json:"role" gorm:"default:'etudiant'"`
+	AuthProvider  string         `json:"auth_provider" gorm:"default:'local';not null"`
+	ExternalID    string         `json:"-" gorm:"index"` // Identifiant de l'utilisateur chez le fournisseur d'identité
+	DeactivatedAt *time.Time     `json:"deactivated_at"` // Compte désactivé, par exemple retiré de l'annuaire
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// IsActive indique si l'utilisateur peut se connecter
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
 }
 
 type UserResponse struct {
-	ID           uint      `json:"id"`
-	Email        string    `json:"email"`
-	ContactEmail string    `json:"contact_email"`
-	FirstName    string    `json:"first_name"`
-	LastName     string    `json:"last_name"`
-	Phone        string    `json:"phone"`
-	Address      string    `json:"address"`
-	Avatar       string    `json:"avatar"`
-	Role         string    `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uint       `json:"id"`
+	Email         string     `json:"email"`
+	ContactEmail  string     `json:"contact_email"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	Phone         string     `json:"phone"`
+	Address       string     `json:"address"`
+	Avatar        string     `json:"avatar"`
+	Role          string     `json:"role"`
+	AuthProvider  string     `json:"auth_provider"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	Device   string `json:"device" binding:"omitempty,max=100"` // Nom de l'appareil, déduit du user agent s'il est absent
 }
 
 type RegisterRequest struct {
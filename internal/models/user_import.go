@@ -0,0 +1,52 @@
+package models
+
+// Colonnes reconnues lors d'un import d'utilisateurs
+const (
+	UserImportColumnEmail     = "email"
+	UserImportColumnFirstName = "first_name"
+	UserImportColumnLastName  = "last_name"
+	UserImportColumnRole      = "role"
+	UserImportColumnGroup     = "group"
+	UserImportColumnPhone     = "phone"
+)
+
+// Statuts d'une ligne d'import
+const (
+	UserImportRowValid   = "valid"
+	UserImportRowInvalid = "invalid"
+	UserImportRowCreated = "created"
+)
+
+// UserImportOptions paramètres d'un import d'utilisateurs (formulaire multipart, fichier dans le champ "file").
+// Mapping associe une colonne reconnue (email, first_name, last_name, role, group, phone)
+// à l'en-tête utilisé dans le fichier, lorsqu'il diffère des en-têtes reconnus automatiquement.
+type UserImportOptions struct {
+	DryRun      bool              `form:"dry_run"`
+	DefaultRole string            `form:"default_role"`
+	Mapping     map[string]string `form:"-"`
+}
+
+// UserImportRow résultat de la validation d'une ligne
+type UserImportRow struct {
+	Row               int      `json:"row"` // Numéro de ligne dans le fichier (en-tête = 1)
+	Email             string   `json:"email"`
+	FirstName         string   `json:"first_name"`
+	LastName          string   `json:"last_name"`
+	Role              string   `json:"role"`
+	Groups            []string `json:"groups,omitempty"`
+	Phone             string   `json:"phone,omitempty"`
+	Status            string   `json:"status"` // valid, invalid, created
+	Errors            []string `json:"errors,omitempty"`
+	UserID            uint     `json:"user_id,omitempty"`
+	TemporaryPassword string   `json:"temporary_password,omitempty"` // Communiqué uniquement dans ce rapport
+}
+
+// UserImportReport rapport d'un import d'utilisateurs
+type UserImportReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Valid   int             `json:"valid"`
+	Invalid int             `json:"invalid"`
+	Created int             `json:"created"`
+	Rows    []UserImportRow `json:"rows"`
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{db: db}
+}
+
+// CreateFeedToken crée un jeton de flux
+func (r *CalendarFeedRepository) CreateFeedToken(token *models.CalendarFeedToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetFeedTokenByHash récupère un jeton actif par son empreinte
+func (r *CalendarFeedRepository) GetFeedTokenByHash(tokenHash string) (*models.CalendarFeedToken, error) {
+	var token models.CalendarFeedToken
+	err := r.db.Where("token_hash = ? AND revoked_at IS NULL", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetFeedTokensByOwner récupère les jetons d'un utilisateur
+func (r *CalendarFeedRepository) GetFeedTokensByOwner(ownerID uint) ([]models.CalendarFeedToken, error) {
+	var tokens []models.CalendarFeedToken
+	err := r.db.Where("owner_id = ?", ownerID).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeFeedToken révoque un jeton appartenant à l'utilisateur
+func (r *CalendarFeedRepository) RevokeFeedToken(id, ownerID uint) error {
+	result := r.db.Model(&models.CalendarFeedToken{}).
+		Where("id = ? AND owner_id = ? AND revoked_at IS NULL", id, ownerID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchFeedToken enregistre la dernière utilisation d'un jeton
+func (r *CalendarFeedRepository) TouchFeedToken(id uint) error {
+	return r.db.Model(&models.CalendarFeedToken{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
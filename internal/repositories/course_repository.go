@@ -1,7 +1,7 @@
 package repositories
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -21,14 +21,14 @@ func NewCourseRepository(db *gorm.DB) *CourseRepository {
 // GetAllCourses récupère tous les cours avec leurs relations
 func (r *CourseRepository) GetAllCourses() ([]models.Course, error) {
 	var courses []models.Course
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Find(&courses).Error
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").Find(&courses).Error
 	return courses, err
 }
 
 // GetCourseByID récupère un cours par son ID
 func (r *CourseRepository) GetCourseByID(id uint) (*models.Course, error) {
 	var course models.Course
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").First(&course, id).Error
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").First(&course, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +66,15 @@ func (r *CourseRepository) UpdateCourse(course *models.Course) error {
 		return fmt.Errorf("conflits détectés: %v", conflicts)
 	}
 
-	return r.db.Save(course).Error
+	if err := r.db.Save(course).Error; err != nil {
+		return err
+	}
+
+	// Synchroniser les groupes et les sous-salles réunies (Save n'enlève pas les associations supprimées)
+	if err := r.db.Model(course).Association("Groups").Replace(course.Groups); err != nil {
+		return err
+	}
+	return r.db.Model(course).Association("MergedRooms").Replace(course.MergedRooms)
 }
 
 // DeleteCourse supprime un cours
@@ -85,10 +93,130 @@ func (r *CourseRepository) DeleteRecurringCourses(recurrenceID uint) error {
 	return r.db.Where("recurrence_id = ?", recurrenceID).Delete(&models.Course{}).Error
 }
 
+// UpdateCourseStatus change le statut d'un cours et enregistre le changement dans l'historique
+func (r *CourseRepository) UpdateCourseStatus(course *models.Course, status, reason string, changedByID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		history := models.CourseStatusHistory{
+			CourseID:    course.ID,
+			FromStatus:  course.Status,
+			ToStatus:    status,
+			Reason:      reason,
+			ChangedByID: changedByID,
+		}
+
+		if err := tx.Model(&models.Course{}).Where("id = ?", course.ID).Updates(map[string]interface{}{
+			"status":        status,
+			"status_reason": reason,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+
+		course.Status = status
+		course.StatusReason = reason
+		return nil
+	})
+}
+
+// RescheduleCourse crée l'occurrence de remplacement et marque le cours d'origine comme déplacé
+func (r *CourseRepository) RescheduleCourse(original, replacement *models.Course, reason string, changedByID uint) error {
+	replacement.EndTime = replacement.StartTime.Add(time.Duration(replacement.Duration) * time.Minute)
+
+	// Le nouveau créneau ne doit pas entrer en conflit avec d'autres cours (le cours d'origine est ignoré,
+	// mais pas les autres occurrences de sa série)
+	conflicts, err := r.CheckConflicts(replacement)
+	if err != nil {
+		return err
+	}
+	var blocking []models.ConflictInfo
+	for _, conflict := range conflicts {
+		if conflict.CourseID != original.ID {
+			blocking = append(blocking, conflict)
+		}
+	}
+	if len(blocking) > 0 {
+		return fmt.Errorf("conflits détectés: %v", blocking)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		replacement.Status = models.CourseStatusScheduled
+		replacement.RescheduledFromID = &original.ID
+		if err := tx.Create(replacement).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Course{}).Where("id = ?", original.ID).Updates(map[string]interface{}{
+			"status":            models.CourseStatusRescheduled,
+			"status_reason":     reason,
+			"rescheduled_to_id": replacement.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		histories := []models.CourseStatusHistory{
+			{
+				CourseID:    original.ID,
+				FromStatus:  original.Status,
+				ToStatus:    models.CourseStatusRescheduled,
+				Reason:      reason,
+				ChangedByID: changedByID,
+			},
+			{
+				CourseID:    replacement.ID,
+				ToStatus:    models.CourseStatusScheduled,
+				Reason:      reason,
+				ChangedByID: changedByID,
+			},
+		}
+		if err := tx.Create(&histories).Error; err != nil {
+			return err
+		}
+
+		original.Status = models.CourseStatusRescheduled
+		original.StatusReason = reason
+		original.RescheduledToID = &replacement.ID
+		return nil
+	})
+}
+
+// CheckTeacherConflicts vérifie si un enseignant est déjà occupé ou indisponible sur un créneau
+func (r *CourseRepository) CheckTeacherConflicts(teacherID uint, startTime, endTime time.Time) ([]models.ConflictInfo, error) {
+	conflicts, err := r.checkTeacherConflicts(0, teacherID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	unavailabilityConflicts, err := r.checkTeacherUnavailability(teacherID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(conflicts, unavailabilityConflicts...), nil
+}
+
+// SetSubstituteTeacher affecte (ou retire, si substituteID est nil) un remplaçant à une occurrence
+func (r *CourseRepository) SetSubstituteTeacher(courseID uint, substituteID *uint) error {
+	return r.db.Model(&models.Course{}).Where("id = ?", courseID).
+		Update("substitute_teacher_id", substituteID).Error
+}
+
+// GetCourseStatusHistory récupère l'historique des statuts d'un cours
+func (r *CourseRepository) GetCourseStatusHistory(courseID uint) ([]models.CourseStatusHistory, error) {
+	var history []models.CourseStatusHistory
+	err := r.db.Preload("ChangedBy").
+		Where("course_id = ?", courseID).
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
+}
+
 // GetCoursesByDateRange récupère les cours dans une plage de dates
 func (r *CourseRepository) GetCoursesByDateRange(startDate, endDate time.Time) ([]models.Course, error) {
 	var courses []models.Course
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
 		Where("start_time >= ? AND start_time <= ?", startDate, endDate).
 		Find(&courses).Error
 	return courses, err
@@ -97,8 +225,8 @@ func (r *CourseRepository) GetCoursesByDateRange(startDate, endDate time.Time) (
 // GetCoursesByRoom récupère les cours d'une salle
 func (r *CourseRepository) GetCoursesByRoom(roomID uint) ([]models.Course, error) {
 	var courses []models.Course
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
-		Where("room_id = ?", roomID).
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Scopes(occupyingRoom(roomID)).
 		Find(&courses).Error
 	return courses, err
 }
@@ -111,8 +239,9 @@ func (r *CourseRepository) GetCoursesByRoomAndDate(roomID uint, targetDate time.
 	startOfDay := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 0, 0, 0, 0, targetDate.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
-		Where("room_id = ? AND start_time >= ? AND start_time < ?", roomID, startOfDay, endOfDay).
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Scopes(occupyingRoom(roomID)).
+		Where("start_time >= ? AND start_time < ?", startOfDay, endOfDay).
 		Order("start_time ASC").
 		Find(&courses).Error
 	return courses, err
@@ -121,7 +250,7 @@ func (r *CourseRepository) GetCoursesByRoomAndDate(roomID uint, targetDate time.
 // GetCoursesByTeacher récupère les cours d'un enseignant
 func (r *CourseRepository) GetCoursesByTeacher(teacherID uint) ([]models.Course, error) {
 	var courses []models.Course
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
 		Where("teacher_id = ?", teacherID).
 		Find(&courses).Error
 	return courses, err
@@ -164,9 +293,81 @@ func (r *CourseRepository) CheckConflicts(course *models.Course) ([]models.Confl
 		conflicts = append(conflicts, parentConflicts...)
 	}
 
+	// Sous-salles voisines réunies avec la salle principale (leur salle parente est déjà vérifiée)
+	for _, merged := range course.MergedRooms {
+		mergedConflicts, err := r.checkRoomConflicts(merged.ID, course.StartTime, course.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, mergedConflicts...)
+	}
+	conflicts = uniqueConflicts(conflicts)
+
+	// Vérifier les conflits de l'enseignant et des groupes
+	teacherConflicts, err := r.checkTeacherConflicts(0, course.TeacherID, course.StartTime, course.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, teacherConflicts...)
+
+	unavailabilityConflicts, err := r.checkTeacherUnavailability(course.TeacherID, course.StartTime, course.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, unavailabilityConflicts...)
+
+	groupConflicts, err := r.checkGroupConflicts(0, course.GroupIDs(), course.StartTime, course.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, groupConflicts...)
+
 	return conflicts, nil
 }
 
+// CheckRoomConflicts vérifie l'occupation d'un ensemble de salles (cours actifs et réservations validées)
+// en appliquant les règles des salles modulables à chacune
+func (r *CourseRepository) CheckRoomConflicts(roomIDs []uint, startTime, endTime time.Time) ([]models.ConflictInfo, error) {
+	var rooms []models.Room
+	if err := r.db.Preload("Children").Where("id IN ?", roomIDs).Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	if len(rooms) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	checked := make(map[uint]bool)
+	var related []uint
+	add := func(id uint) {
+		if !checked[id] {
+			checked[id] = true
+			related = append(related, id)
+		}
+	}
+	for _, room := range rooms {
+		add(room.ID)
+		if room.IsModular {
+			for _, child := range room.Children {
+				add(child.ID)
+			}
+		}
+		if room.ParentID != nil {
+			add(*room.ParentID)
+		}
+	}
+
+	var conflicts []models.ConflictInfo
+	for _, id := range related {
+		roomConflicts, err := r.checkRoomConflicts(id, startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, roomConflicts...)
+	}
+
+	return uniqueConflicts(conflicts), nil
+}
+
 // CheckConflictsExcluding vérifie les conflits en excluant un cours spécifique
 func (r *CourseRepository) CheckConflictsExcluding(excludeID uint, course *models.Course) ([]models.ConflictInfo, error) {
 	var conflicts []models.ConflictInfo
@@ -204,6 +405,43 @@ func (r *CourseRepository) CheckConflictsExcluding(excludeID uint, course *model
 		conflicts = append(conflicts, parentConflicts...)
 	}
 
+	// Sous-salles voisines réunies avec la salle principale (leur salle parente est déjà vérifiée)
+	for _, merged := range course.MergedRooms {
+		mergedConflicts, err := r.checkRoomConflictsExcluding(excludeID, merged.ID, course.StartTime, course.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, mergedConflicts...)
+	}
+	conflicts = uniqueConflicts(conflicts)
+
+	// Vérifier les conflits de l'enseignant et des groupes (en excluant la série)
+	teacherConflicts, err := r.checkTeacherConflicts(excludeID, course.TeacherID, course.StartTime, course.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, teacherConflicts...)
+
+	// Une indisponibilité déclarée après coup ne bloque pas la modification d'un cours déjà planifié :
+	// elle n'est vérifiée que si le créneau ou l'enseignant change
+	scheduleChanged, err := r.scheduleChanged(course)
+	if err != nil {
+		return nil, err
+	}
+	if scheduleChanged {
+		unavailabilityConflicts, err := r.checkTeacherUnavailability(course.TeacherID, course.StartTime, course.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, unavailabilityConflicts...)
+	}
+
+	groupConflicts, err := r.checkGroupConflicts(excludeID, course.GroupIDs(), course.StartTime, course.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, groupConflicts...)
+
 	return conflicts, nil
 }
 
@@ -212,9 +450,9 @@ func (r *CourseRepository) checkRoomConflicts(roomID uint, startTime, endTime ti
 	var conflicts []models.ConflictInfo
 
 	var existingCourses []models.Course
-	err := r.db.Preload("Room").
-		Where("room_id = ? AND ((start_time <= ? AND end_time > ?) OR (start_time < ? AND end_time >= ?) OR (start_time >= ? AND end_time <= ?))",
-			roomID, startTime, startTime, endTime, endTime, startTime, endTime).
+	err := r.db.Preload("Room").Scopes(activeCourses, occupyingRoom(roomID)).
+		Where("((start_time <= ? AND end_time > ?) OR (start_time < ? AND end_time >= ?) OR (start_time >= ? AND end_time <= ?))",
+			startTime, startTime, endTime, endTime, startTime, endTime).
 		Find(&existingCourses).Error
 
 	if err != nil {
@@ -223,6 +461,8 @@ func (r *CourseRepository) checkRoomConflicts(roomID uint, startTime, endTime ti
 
 	for _, course := range existingCourses {
 		conflicts = append(conflicts, models.ConflictInfo{
+			Type:       models.ConflictTypeRoom,
+			CourseID:   course.ID,
 			Date:       course.StartTime,
 			StartTime:  course.StartTime,
 			EndTime:    course.EndTime,
@@ -231,9 +471,69 @@ func (r *CourseRepository) checkRoomConflicts(roomID uint, startTime, endTime ti
 		})
 	}
 
+	bookingConflicts, err := r.checkRoomBookingConflicts(roomID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, bookingConflicts...)
+
 	return conflicts, nil
 }
 
+// GetActiveCoursesInRooms récupère les cours actifs occupant l'une des salles sur la période
+func (r *CourseRepository) GetActiveCoursesInRooms(roomIDs []uint, startTime, endTime time.Time) ([]models.Course, error) {
+	var courses []models.Course
+	err := r.db.Preload("Room").Preload("MergedRooms").Scopes(activeCourses).
+		Where("(room_id IN ? OR id IN (SELECT course_id FROM course_merged_rooms WHERE room_id IN ?))", roomIDs, roomIDs).
+		Where("start_time < ? AND end_time > ?", endTime, startTime).
+		Order("start_time ASC").
+		Find(&courses).Error
+	return courses, err
+}
+
+// GetOccupiedRoomIDs récupère les salles occupées par un cours actif ou une réservation validée sur le créneau
+func (r *CourseRepository) GetOccupiedRoomIDs(startTime, endTime time.Time) ([]uint, error) {
+	var roomIDs []uint
+	err := r.db.Model(&models.Course{}).Scopes(activeCourses).
+		Where("start_time < ? AND end_time > ?", endTime, startTime).
+		Distinct().
+		Pluck("room_id", &roomIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var mergedIDs []uint
+	err = r.db.Table("course_merged_rooms").
+		Joins("JOIN courses ON courses.id = course_merged_rooms.course_id").
+		Where("courses.deleted_at IS NULL AND courses.status NOT IN ?", []string{models.CourseStatusCancelled, models.CourseStatusRescheduled}).
+		Where("courses.start_time < ? AND courses.end_time > ?", endTime, startTime).
+		Distinct().
+		Pluck("course_merged_rooms.room_id", &mergedIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	roomIDs = append(roomIDs, mergedIDs...)
+
+	var bookedIDs []uint
+	err = r.db.Model(&models.RoomBooking{}).
+		Where("status = ? AND start_time < ? AND end_time > ?", models.RoomBookingStatusApproved, endTime, startTime).
+		Distinct().
+		Pluck("room_id", &bookedIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	roomIDs = append(roomIDs, bookedIDs...)
+
+	var mergedBookedIDs []uint
+	err = r.db.Table("room_booking_merged_rooms").
+		Joins("JOIN room_bookings ON room_bookings.id = room_booking_merged_rooms.room_booking_id").
+		Where("room_bookings.deleted_at IS NULL AND room_bookings.status = ?", models.RoomBookingStatusApproved).
+		Where("room_bookings.start_time < ? AND room_bookings.end_time > ?", endTime, startTime).
+		Distinct().
+		Pluck("room_booking_merged_rooms.room_id", &mergedBookedIDs).Error
+	return append(roomIDs, mergedBookedIDs...), err
+}
+
 // checkRoomConflictsExcluding vérifie les conflits en excluant un cours spécifique et ses cours récurrents associés
 func (r *CourseRepository) checkRoomConflictsExcluding(excludeID uint, roomID uint, startTime, endTime time.Time) ([]models.ConflictInfo, error) {
 	var conflicts []models.ConflictInfo
@@ -245,8 +545,8 @@ func (r *CourseRepository) checkRoomConflictsExcluding(excludeID uint, roomID ui
 	}
 
 	// Construire la condition WHERE pour exclure le cours et ses cours récurrents associés
-	whereCondition := "room_id = ? AND ((start_time <= ? AND end_time > ?) OR (start_time < ? AND end_time >= ?) OR (start_time >= ? AND end_time <= ?))"
-	args := []interface{}{roomID, startTime, startTime, endTime, endTime, startTime, endTime}
+	whereCondition := "((start_time <= ? AND end_time > ?) OR (start_time < ? AND end_time >= ?) OR (start_time >= ? AND end_time <= ?))"
+	args := []interface{}{startTime, startTime, endTime, endTime, startTime, endTime}
 
 	// Exclure le cours lui-même
 	whereCondition += " AND id != ?"
@@ -266,7 +566,7 @@ func (r *CourseRepository) checkRoomConflictsExcluding(excludeID uint, roomID ui
 	}
 
 	var existingCourses []models.Course
-	err := r.db.Preload("Room").
+	err := r.db.Preload("Room").Scopes(activeCourses, occupyingRoom(roomID)).
 		Where(whereCondition, args...).
 		Find(&existingCourses).Error
 
@@ -276,6 +576,8 @@ func (r *CourseRepository) checkRoomConflictsExcluding(excludeID uint, roomID ui
 
 	for _, course := range existingCourses {
 		conflicts = append(conflicts, models.ConflictInfo{
+			Type:       models.ConflictTypeRoom,
+			CourseID:   course.ID,
 			Date:       course.StartTime,
 			StartTime:  course.StartTime,
 			EndTime:    course.EndTime,
@@ -284,66 +586,277 @@ func (r *CourseRepository) checkRoomConflictsExcluding(excludeID uint, roomID ui
 		})
 	}
 
+	bookingConflicts, err := r.checkRoomBookingConflicts(roomID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, bookingConflicts...)
+
 	return conflicts, nil
 }
 
-// GenerateRecurringCourses génère les cours récurrents
-func (r *CourseRepository) GenerateRecurringCourses(parentCourse *models.Course) error {
-	if !parentCourse.IsRecurring || parentCourse.RecurrencePattern == nil || parentCourse.RecurrenceEndDate == nil {
-		return fmt.Errorf("cours non récurrent ou paramètres manquants")
+// checkRoomBookingConflicts vérifie si la salle fait l'objet d'une réservation validée sur le créneau
+func (r *CourseRepository) checkRoomBookingConflicts(roomID uint, startTime, endTime time.Time) ([]models.ConflictInfo, error) {
+	var conflicts []models.ConflictInfo
+
+	var bookings []models.RoomBooking
+	err := r.db.Preload("Room").
+		Where("(room_id = ? OR id IN (SELECT room_booking_id FROM room_booking_merged_rooms WHERE room_id = ?))", roomID, roomID).
+		Where("status = ? AND start_time < ? AND end_time > ?", models.RoomBookingStatusApproved, endTime, startTime).
+		Find(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, booking := range bookings {
+		conflicts = append(conflicts, models.ConflictInfo{
+			Type:       models.ConflictTypeRoomBooking,
+			BookingID:  booking.ID,
+			Date:       booking.StartTime,
+			StartTime:  booking.StartTime,
+			EndTime:    booking.EndTime,
+			RoomName:   booking.Room.Name,
+			CourseName: booking.Title,
+		})
+	}
+
+	return conflicts, nil
+}
+
+// checkTeacherConflicts vérifie si l'enseignant a déjà un cours sur le créneau
+func (r *CourseRepository) checkTeacherConflicts(excludeID uint, teacherID uint, startTime, endTime time.Time) ([]models.ConflictInfo, error) {
+	var conflicts []models.ConflictInfo
+	if teacherID == 0 {
+		return conflicts, nil
+	}
+
+	query, err := r.excludeCourseSeries(r.db.Preload("Room").Preload("Teacher").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Scopes(activeCourses, taughtBy(teacherID)).
+		Where("start_time < ? AND end_time > ?", endTime, startTime), excludeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingCourses []models.Course
+	if err := query.Find(&existingCourses).Error; err != nil {
+		return nil, err
+	}
+
+	for _, course := range existingCourses {
+		teacher := course.Teacher
+		if course.SubstituteTeacher != nil {
+			teacher = *course.SubstituteTeacher
+		}
+
+		conflicts = append(conflicts, models.ConflictInfo{
+			Type:        models.ConflictTypeTeacher,
+			CourseID:    course.ID,
+			Date:        course.StartTime,
+			StartTime:   course.StartTime,
+			EndTime:     course.EndTime,
+			RoomName:    course.Room.Name,
+			CourseName:  course.Name,
+			TeacherName: teacher.FirstName + " " + teacher.LastName,
+		})
+	}
+
+	return conflicts, nil
+}
+
+// scheduleChanged indique si le cours est nouveau, ou si son créneau ou son enseignant diffère de celui enregistré
+func (r *CourseRepository) scheduleChanged(course *models.Course) (bool, error) {
+	if course.ID == 0 {
+		return true, nil
+	}
+
+	var stored models.Course
+	err := r.db.Select("teacher_id", "start_time", "duration").First(&stored, course.ID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return stored.TeacherID != course.TeacherID || !stored.StartTime.Equal(course.StartTime) || stored.Duration != course.Duration, nil
+}
+
+// checkTeacherUnavailability vérifie si le créneau tombe pendant une indisponibilité de l'enseignant
+func (r *CourseRepository) checkTeacherUnavailability(teacherID uint, startTime, endTime time.Time) ([]models.ConflictInfo, error) {
+	var conflicts []models.ConflictInfo
+	if teacherID == 0 {
+		return conflicts, nil
+	}
+
+	var unavailabilities []models.TeacherUnavailability
+	if err := r.db.Where("teacher_id = ? AND start_date < ? AND end_date > ?", teacherID, endTime, startTime).
+		Find(&unavailabilities).Error; err != nil {
+		return nil, err
+	}
+
+	for _, unavailability := range unavailabilities {
+		conflicts = append(conflicts, models.ConflictInfo{
+			Type:      models.ConflictTypeTeacherUnavailable,
+			Date:      startTime,
+			StartTime: unavailability.StartDate,
+			EndTime:   unavailability.EndDate,
+			Reason:    unavailability.Reason,
+		})
+	}
+
+	return conflicts, nil
+}
+
+// checkGroupConflicts vérifie si l'un des groupes a déjà un cours sur le créneau
+func (r *CourseRepository) checkGroupConflicts(excludeID uint, groupIDs []uint, startTime, endTime time.Time) ([]models.ConflictInfo, error) {
+	var conflicts []models.ConflictInfo
+	if len(groupIDs) == 0 {
+		return conflicts, nil
+	}
+
+	// Un cours d'un groupe concerne aussi ses sous-groupes : les groupes parents et enfants sont en conflit
+	ancestors, err := groupAncestorIDs(r.db, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := groupDescendantIDs(r.db, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	relatedIDs := append(append(append([]uint{}, groupIDs...), ancestors...), descendants...)
+
+	groupCourses := r.db.Table("course_groups").Select("course_id").Where("group_id IN ?", relatedIDs)
+	query, err := r.excludeCourseSeries(r.db.Preload("Room").Preload("Groups", "id IN ?", relatedIDs).Scopes(activeCourses).
+		Where("id IN (?) AND start_time < ? AND end_time > ?", groupCourses, endTime, startTime), excludeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingCourses []models.Course
+	if err := query.Find(&existingCourses).Error; err != nil {
+		return nil, err
+	}
+
+	for _, course := range existingCourses {
+		for _, group := range course.Groups {
+			conflicts = append(conflicts, models.ConflictInfo{
+				Type:       models.ConflictTypeGroup,
+				CourseID:   course.ID,
+				Date:       course.StartTime,
+				StartTime:  course.StartTime,
+				EndTime:    course.EndTime,
+				RoomName:   course.Room.Name,
+				CourseName: course.Name,
+				GroupName:  group.Name,
+			})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// taughtBy restreint une requête aux cours effectivement assurés par l'enseignant :
+// ses propres cours sans remplaçant et les occurrences où il est remplaçant
+func taughtBy(teacherID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("((teacher_id = ? AND substitute_teacher_id IS NULL) OR substitute_teacher_id = ?)", teacherID, teacherID)
+	}
+}
+
+// uniqueConflicts retire les doublons : un cours réunissant plusieurs sous-salles est trouvé une fois par salle
+func uniqueConflicts(conflicts []models.ConflictInfo) []models.ConflictInfo {
+	type key struct {
+		conflictType string
+		courseID     uint
+		bookingID    uint
+		startTime    time.Time
 	}
 
-	var pattern models.RecurrencePattern
-	if err := json.Unmarshal([]byte(*parentCourse.RecurrencePattern), &pattern); err != nil {
+	seen := make(map[key]bool, len(conflicts))
+	var unique []models.ConflictInfo
+	for _, conflict := range conflicts {
+		k := key{conflict.Type, conflict.CourseID, conflict.BookingID, conflict.StartTime}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, conflict)
+	}
+	return unique
+}
+
+// occupyingRoom limite la requête aux cours occupant la salle, comme salle principale ou comme sous-salle réunie
+func occupyingRoom(roomID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(room_id = ? OR id IN (SELECT course_id FROM course_merged_rooms WHERE room_id = ?))", roomID, roomID)
+	}
+}
+
+// activeCourses restreint une requête aux cours qui occupent réellement leur créneau
+// (les cours annulés ou déplacés ne génèrent plus de conflit)
+func activeCourses(db *gorm.DB) *gorm.DB {
+	return db.Where("status NOT IN ?", []string{models.CourseStatusCancelled, models.CourseStatusRescheduled})
+}
+
+// excludeCourseSeries exclut un cours de la requête et, s'il est récurrent, toute sa série
+func (r *CourseRepository) excludeCourseSeries(query *gorm.DB, excludeID uint) (*gorm.DB, error) {
+	if excludeID == 0 {
+		return query, nil
+	}
+
+	var excludeCourse models.Course
+	if err := r.db.First(&excludeCourse, excludeID).Error; err != nil {
+		return nil, err
+	}
+
+	query = query.Where("id != ?", excludeID)
+	if excludeCourse.IsRecurring {
+		seriesID := excludeID
+		if excludeCourse.RecurrenceID != nil {
+			seriesID = *excludeCourse.RecurrenceID
+		}
+		query = query.Where("id != ? AND (recurrence_id IS NULL OR recurrence_id != ?)", seriesID, seriesID)
+	}
+
+	return query, nil
+}
+
+// GenerateRecurringCourses génère les cours récurrents
+func (r *CourseRepository) GenerateRecurringCourses(parentCourse *models.Course) error {
+	occurrences, err := parentCourse.RecurrenceOccurrences()
+	if err != nil {
 		return err
 	}
 
-	// Générer les cours pour chaque jour de la semaine spécifié
-	currentDate := parentCourse.StartTime
-	for currentDate.Before(*parentCourse.RecurrenceEndDate) {
-		weekday := currentDate.Weekday().String()
-
-		// Vérifier si ce jour est dans le pattern
-		for _, day := range pattern.Days {
-			if day == weekday {
-				// Éviter de créer un doublon pour la date du cours parent
-				if currentDate.Equal(parentCourse.StartTime) {
-					// Passer au jour suivant
-					currentDate = currentDate.AddDate(0, 0, 1)
-					continue
-				}
-
-				// Créer un cours pour ce jour
-				course := *parentCourse
-				course.ID = 0 // Nouveau cours
-				course.RecurrenceID = &parentCourse.ID
-				course.StartTime = time.Date(
-					currentDate.Year(), currentDate.Month(), currentDate.Day(),
-					parentCourse.StartTime.Hour(), parentCourse.StartTime.Minute(), 0, 0,
-					parentCourse.StartTime.Location(),
-				)
-				course.EndTime = course.StartTime.Add(time.Duration(course.Duration) * time.Minute)
-
-				// Vérifier les conflits en excluant les cours de la même série récurrente
-				conflicts, err := r.CheckConflictsExcluding(parentCourse.ID, &course)
-				if err != nil {
-					return err
-				}
-				if len(conflicts) > 0 {
-					// Skip ce jour s'il y a un conflit
-					continue
-				}
-
-				// Créer le cours
-				if err := r.db.Create(&course).Error; err != nil {
-					return err
-				}
-				break
-			}
+	for _, startTime := range occurrences {
+		// Créer un cours pour ce jour
+		course := *parentCourse
+		course.ID = 0 // Nouveau cours
+		course.RecurrenceID = &parentCourse.ID
+		// Le statut et le remplaçant sont propres à chaque occurrence
+		course.Status = models.CourseStatusScheduled
+		course.StatusReason = ""
+		course.RescheduledFromID = nil
+		course.RescheduledToID = nil
+		course.SubstituteTeacherID = nil
+		course.SubstituteTeacher = nil
+		course.StartTime = startTime
+		course.EndTime = course.StartTime.Add(time.Duration(course.Duration) * time.Minute)
+
+		// Vérifier les conflits (salle, enseignant, groupes) en excluant les cours de la même série
+		conflicts, err := r.CheckConflictsExcluding(parentCourse.ID, &course)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			// Skip ce jour s'il y a un conflit
+			continue
 		}
 
-		// Passer au jour suivant
-		currentDate = currentDate.AddDate(0, 0, 1)
+		// Créer le cours
+		if err := r.db.Create(&course).Error; err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -354,7 +867,7 @@ func (r *CourseRepository) GetFutureCoursesByUser(userID uint) ([]models.Course,
 	var courses []models.Course
 	now := time.Now()
 
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
 		Where("teacher_id = ? AND start_time > ?", userID, now).
 		Find(&courses).Error
 
@@ -366,7 +879,7 @@ func (r *CourseRepository) GetPastCoursesByUser(userID uint) ([]models.Course, e
 	var courses []models.Course
 	now := time.Now()
 
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
 		Where("teacher_id = ? AND end_time < ?", userID, now).
 		Find(&courses).Error
 
@@ -377,7 +890,7 @@ func (r *CourseRepository) GetPastCoursesByUser(userID uint) ([]models.Course, e
 func (r *CourseRepository) GetAllCoursesByUser(userID uint) ([]models.Course, error) {
 	var courses []models.Course
 
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
 		Where("teacher_id = ?", userID).
 		Find(&courses).Error
 
@@ -389,8 +902,9 @@ func (r *CourseRepository) GetFutureCoursesByRoom(roomID uint) ([]models.Course,
 	var courses []models.Course
 	now := time.Now()
 
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
-		Where("room_id = ? AND start_time > ?", roomID, now).
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Scopes(occupyingRoom(roomID)).
+		Where("start_time > ?", now).
 		Find(&courses).Error
 
 	return courses, err
@@ -400,13 +914,71 @@ func (r *CourseRepository) GetFutureCoursesByRoom(roomID uint) ([]models.Course,
 func (r *CourseRepository) GetCoursesBySubject(subjectID uint) ([]models.Course, error) {
 	var courses []models.Course
 
-	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
 		Where("subject_id = ?", subjectID).
 		Find(&courses).Error
 
 	return courses, err
 }
 
+// GetCalendarCoursesByTeacher récupère les cours assurés par un enseignant depuis une date, annulations comprises
+func (r *CourseRepository) GetCalendarCoursesByTeacher(teacherID uint, since time.Time) ([]models.Course, error) {
+	var courses []models.Course
+
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Scopes(taughtBy(teacherID)).
+		Where("end_time >= ?", since).
+		Order("start_time").
+		Find(&courses).Error
+
+	return courses, err
+}
+
+// GetCalendarCoursesByGroups récupère les cours de plusieurs groupes depuis une date, annulations comprises
+func (r *CourseRepository) GetCalendarCoursesByGroups(groupIDs []uint, since time.Time) ([]models.Course, error) {
+	var courses []models.Course
+	if len(groupIDs) == 0 {
+		return courses, nil
+	}
+
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Where("id IN (?)", r.db.Table("course_groups").Select("course_id").Where("group_id IN ?", groupIDs)).
+		Where("end_time >= ?", since).
+		Order("start_time").
+		Find(&courses).Error
+
+	return courses, err
+}
+
+// GetCoursesByGroupsAndDateRange récupère les cours de plusieurs groupes entre deux dates
+func (r *CourseRepository) GetCoursesByGroupsAndDateRange(groupIDs []uint, startDate, endDate time.Time) ([]models.Course, error) {
+	var courses []models.Course
+	if len(groupIDs) == 0 {
+		return courses, nil
+	}
+
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Where("id IN (?)", r.db.Table("course_groups").Select("course_id").Where("group_id IN ?", groupIDs)).
+		Where("start_time >= ? AND start_time <= ?", startDate, endDate).
+		Order("start_time").
+		Find(&courses).Error
+
+	return courses, err
+}
+
+// GetCalendarCoursesByRoom récupère les cours d'une salle depuis une date, annulations comprises
+func (r *CourseRepository) GetCalendarCoursesByRoom(roomID uint, since time.Time) ([]models.Course, error) {
+	var courses []models.Course
+
+	err := r.db.Preload("Subject").Preload("Teacher").Preload("Room").Preload("Groups").Preload("SubstituteTeacher").Preload("MergedRooms").
+		Scopes(occupyingRoom(roomID)).
+		Where("end_time >= ?", since).
+		Order("start_time").
+		Find(&courses).Error
+
+	return courses, err
+}
+
 // HasAttendance vérifie si un cours a des présences enregistrées
 func (r *CourseRepository) HasAttendance(courseID uint) (bool, error) {
 	// Pour l'instant, on retourne false car la table des présences n'existe pas encore
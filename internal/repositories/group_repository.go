@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type GroupRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupRepository(db *gorm.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// GetAllGroups récupère tous les groupes
+func (r *GroupRepository) GetAllGroups() ([]models.Group, error) {
+	var groups []models.Group
+	err := r.db.Order("name").Find(&groups).Error
+	return groups, err
+}
+
+// GetGroupByID récupère un groupe par son ID avec ses sous-groupes directs
+func (r *GroupRepository) GetGroupByID(id uint) (*models.Group, error) {
+	var group models.Group
+	err := r.db.Preload("Children").First(&group, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetGroupByName récupère un groupe par son nom
+func (r *GroupRepository) GetGroupByName(name string) (*models.Group, error) {
+	var group models.Group
+	err := r.db.Where("name = ?", name).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetGroupsByIDs récupère plusieurs groupes par leurs IDs
+func (r *GroupRepository) GetGroupsByIDs(ids []uint) ([]models.Group, error) {
+	var groups []models.Group
+	if len(ids) == 0 {
+		return groups, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&groups).Error
+	return groups, err
+}
+
+// CreateGroup crée un groupe
+func (r *GroupRepository) CreateGroup(group *models.Group) error {
+	return r.db.Create(group).Error
+}
+
+// UpdateGroup met à jour un groupe
+func (r *GroupRepository) UpdateGroup(group *models.Group) error {
+	return r.db.Model(&models.Group{}).Where("id = ?", group.ID).
+		Select("name", "description", "parent_id").
+		Updates(map[string]interface{}{
+			"name":        group.Name,
+			"description": group.Description,
+			"parent_id":   group.ParentID,
+		}).Error
+}
+
+// DeleteGroup supprime un groupe (soft delete), clôt les appartenances en cours et supprime ses quotas horaires
+func (r *GroupRepository) DeleteGroup(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.GroupMembership{}).
+			Where("group_id = ? AND (end_date IS NULL OR end_date > ?)", id, now).
+			Update("end_date", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.SubjectQuota{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Group{}, id).Error
+	})
+}
+
+// IsGroupUsedByCourses vérifie si des cours sont associés au groupe
+func (r *GroupRepository) IsGroupUsedByCourses(id uint) (bool, error) {
+	var count int64
+	err := r.db.Table("course_groups").
+		Joins("JOIN courses ON courses.id = course_groups.course_id AND courses.deleted_at IS NULL").
+		Where("course_groups.group_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// GetDescendantIDs récupère les IDs de tous les sous-groupes (tous niveaux) des groupes donnés
+func (r *GroupRepository) GetDescendantIDs(ids []uint) ([]uint, error) {
+	return groupDescendantIDs(r.db, ids)
+}
+
+// GetAncestorIDs récupère les IDs de tous les groupes parents (tous niveaux) des groupes donnés
+func (r *GroupRepository) GetAncestorIDs(ids []uint) ([]uint, error) {
+	return groupAncestorIDs(r.db, ids)
+}
+
+// GetStudentIDsByGroupIDs récupère les IDs des étudiants appartenant actuellement aux groupes ou à leurs sous-groupes
+func (r *GroupRepository) GetStudentIDsByGroupIDs(groupIDs []uint) ([]uint, error) {
+	var studentIDs []uint
+	if len(groupIDs) == 0 {
+		return studentIDs, nil
+	}
+
+	descendants, err := groupDescendantIDs(r.db, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&models.GroupMembership{}).Scopes(activeMembership(time.Now())).Distinct("user_id").
+		Where("group_id IN ?", append(append([]uint{}, groupIDs...), descendants...)).Pluck("user_id", &studentIDs).Error
+	return studentIDs, err
+}
+
+// CountStudents compte les étudiants appartenant actuellement à un groupe ou à ses sous-groupes
+func (r *GroupRepository) CountStudents(groupID uint) (int64, error) {
+	descendants, err := groupDescendantIDs(r.db, []uint{groupID})
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.db.Model(&models.GroupMembership{}).Scopes(activeMembership(time.Now())).
+		Where("group_id IN ?", append([]uint{groupID}, descendants...)).
+		Distinct("user_id").Count(&count).Error
+	return count, err
+}
+
+// GetGroupIDsByStudent récupère les IDs des groupes auxquels un étudiant appartient actuellement
+func (r *GroupRepository) GetGroupIDsByStudent(studentID uint) ([]uint, error) {
+	var groupIDs []uint
+	err := r.db.Model(&models.GroupMembership{}).Scopes(activeMembership(time.Now())).
+		Where("user_id = ?", studentID).Pluck("group_id", &groupIDs).Error
+	return groupIDs, err
+}
+
+// GetMemberships récupère les appartenances d'un groupe ; si at est renseigné, seules celles effectives à cette date
+func (r *GroupRepository) GetMemberships(groupID uint, at *time.Time) ([]models.GroupMembership, error) {
+	var memberships []models.GroupMembership
+	query := r.db.Preload("Group").Preload("User").Where("group_id = ?", groupID)
+	if at != nil {
+		query = query.Scopes(activeMembership(*at))
+	}
+	err := query.Order("start_date").Find(&memberships).Error
+	return memberships, err
+}
+
+// GetActiveMembership récupère l'appartenance d'un étudiant à un groupe effective à une date
+func (r *GroupRepository) GetActiveMembership(groupID, userID uint, at time.Time) (*models.GroupMembership, error) {
+	var membership models.GroupMembership
+	err := r.db.Scopes(activeMembership(at)).Where("group_id = ? AND user_id = ?", groupID, userID).First(&membership).Error
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// CreateMemberships crée plusieurs appartenances
+func (r *GroupRepository) CreateMemberships(memberships []models.GroupMembership) error {
+	if len(memberships) == 0 {
+		return nil
+	}
+	return r.db.Create(&memberships).Error
+}
+
+// EndMembership clôt une appartenance à une date
+func (r *GroupRepository) EndMembership(id uint, endDate time.Time) error {
+	return r.db.Model(&models.GroupMembership{}).Where("id = ?", id).Update("end_date", endDate).Error
+}
+
+// MoveMembership clôt une appartenance et crée la suivante en une seule transaction
+func (r *GroupRepository) MoveMembership(current *models.GroupMembership, next *models.GroupMembership) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GroupMembership{}).Where("id = ?", current.ID).Update("end_date", next.StartDate).Error; err != nil {
+			return err
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// SyncMemberships aligne les appartenances en cours d'un étudiant parmi les groupes managed sur wanted :
+// il rejoint les groupes de wanted dont il n'est pas membre et quitte ceux de managed absents de wanted.
+// Les appartenances aux autres groupes ne sont pas modifiées.
+func (r *GroupRepository) SyncMemberships(userID uint, managed, wanted []uint) error {
+	if len(managed) == 0 {
+		return nil
+	}
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current []models.GroupMembership
+		if err := tx.Scopes(activeMembership(now)).Where("user_id = ? AND group_id IN ?", userID, managed).Find(&current).Error; err != nil {
+			return err
+		}
+
+		keep := make(map[uint]bool, len(wanted))
+		for _, groupID := range wanted {
+			keep[groupID] = true
+		}
+		member := make(map[uint]bool, len(current))
+		for _, membership := range current {
+			if !keep[membership.GroupID] {
+				if err := tx.Model(&models.GroupMembership{}).Where("id = ?", membership.ID).Update("end_date", now).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			member[membership.GroupID] = true
+		}
+
+		for _, groupID := range wanted {
+			if member[groupID] {
+				continue
+			}
+			member[groupID] = true
+			if err := tx.Create(&models.GroupMembership{GroupID: groupID, UserID: userID, StartDate: now}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// activeMembership restreint une requête aux appartenances effectives à une date
+func activeMembership(at time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("start_date <= ? AND (end_date IS NULL OR end_date > ?)", at, at)
+	}
+}
+
+// groupDescendantIDs parcourt la hiérarchie vers le bas à partir des groupes donnés
+func groupDescendantIDs(db *gorm.DB, ids []uint) ([]uint, error) {
+	var descendants []uint
+	seen := map[uint]bool{}
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	current := ids
+	for len(current) > 0 {
+		var children []uint
+		if err := db.Model(&models.Group{}).Where("parent_id IN ?", current).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+
+		current = nil
+		for _, child := range children {
+			if !seen[child] {
+				seen[child] = true
+				descendants = append(descendants, child)
+				current = append(current, child)
+			}
+		}
+	}
+
+	return descendants, nil
+}
+
+// groupAncestorIDs parcourt la hiérarchie vers le haut à partir des groupes donnés
+func groupAncestorIDs(db *gorm.DB, ids []uint) ([]uint, error) {
+	var ancestors []uint
+	seen := map[uint]bool{}
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	current := ids
+	for len(current) > 0 {
+		var parents []uint
+		if err := db.Model(&models.Group{}).Where("id IN ? AND parent_id IS NOT NULL", current).Pluck("parent_id", &parents).Error; err != nil {
+			return nil, err
+		}
+
+		current = nil
+		for _, parent := range parents {
+			if !seen[parent] {
+				seen[parent] = true
+				ancestors = append(ancestors, parent)
+				current = append(current, parent)
+			}
+		}
+	}
+
+	return ancestors, nil
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+// GetThrottle récupère le suivi des échecs d'un compte ou d'une adresse IP ; retourne nil s'il n'y en a pas
+func (r *LoginThrottleRepository) GetThrottle(scope, identifier string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.Where("scope = ? AND identifier = ?", scope, identifier).First(&throttle).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RecordFailure ajoute un échec et retourne le suivi mis à jour. Le compteur repart de zéro
+// lorsque le dernier échec date de plus de window. lockFor est appelé avec le nombre d'échecs
+// et retourne la date de fin de verrouillage à appliquer, ou nil.
+func (r *LoginThrottleRepository) RecordFailure(scope, identifier string, now time.Time, window time.Duration, lockFor func(failures int) *time.Time) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Crée le suivi s'il n'existe pas ; une création concurrente est ignorée
+		initial := &models.LoginThrottle{Scope: scope, Identifier: identifier, LastFailureAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(initial).Error; err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND identifier = ?", scope, identifier).
+			First(&throttle).Error
+		if err != nil {
+			return err
+		}
+
+		if now.Sub(throttle.LastFailureAt) > window {
+			throttle.Failures = 0
+		}
+		throttle.Failures++
+		throttle.LastFailureAt = now
+		if lockedUntil := lockFor(throttle.Failures); lockedUntil != nil {
+			throttle.LockedUntil = lockedUntil
+		}
+		return tx.Save(&throttle).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// ResetThrottle efface les échecs et le verrouillage d'un compte ou d'une adresse IP
+func (r *LoginThrottleRepository) ResetThrottle(scope, identifier string) error {
+	return r.db.Where("scope = ? AND identifier = ?", scope, identifier).Delete(&models.LoginThrottle{}).Error
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateNotifications crée plusieurs notifications en une seule requête
+func (r *NotificationRepository) CreateNotifications(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// GetNotificationsByUser récupère les notifications d'un utilisateur
+func (r *NotificationRepository) GetNotificationsByUser(userID uint, unreadOnly bool) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := r.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	err := query.Order("created_at DESC").Find(&notifications).Error
+	return notifications, err
+}
+
+// MarkAsRead marque une notification de l'utilisateur comme lue
+func (r *NotificationRepository) MarkAsRead(id, userID uint) error {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OIDCRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// CreateState enregistre l'état d'une connexion et purge les états expirés
+func (r *OIDCRepository) CreateState(state *models.OIDCLoginState) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(state).Error
+}
+
+// ConsumeState récupère et supprime l'état correspondant à l'empreinte. Retourne gorm.ErrRecordNotFound
+// s'il est inconnu, expiré ou déjà consommé, y compris lors d'une requête concurrente.
+func (r *OIDCRepository) ConsumeState(stateHash string) (*models.OIDCLoginState, error) {
+	var state models.OIDCLoginState
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ? AND expires_at > ?", stateHash, time.Now()).First(&state).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.OIDCLoginState{}, state.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
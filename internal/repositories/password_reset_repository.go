@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrResetTokenUsed indique qu'un jeton de réinitialisation a déjà servi
+var ErrResetTokenUsed = errors.New("jeton de réinitialisation déjà utilisé")
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// ReplaceToken enregistre un nouveau jeton pour l'utilisateur et supprime ses jetons encore inutilisés :
+// seul le dernier e-mail envoyé reste valable.
+func (r *PasswordResetRepository) ReplaceToken(token *models.PasswordResetToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", token.UserID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// GetTokenByHash récupère un jeton de réinitialisation à partir de son empreinte
+func (r *PasswordResetRepository) GetTokenByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumeToken marque le jeton comme utilisé et change le mot de passe de son utilisateur.
+// Retourne ErrResetTokenUsed si le jeton a déjà servi, y compris lors d'une requête concurrente.
+func (r *PasswordResetRepository) ConsumeToken(token *models.PasswordResetToken, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrResetTokenUsed
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", token.UserID).Update("password", hashedPassword).Error
+	})
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+// GetOverrides récupère toutes les modifications des permissions par défaut
+func (r *PermissionRepository) GetOverrides() ([]models.RolePermissionOverride, error) {
+	var overrides []models.RolePermissionOverride
+	err := r.db.Order("role, permission").Find(&overrides).Error
+	return overrides, err
+}
+
+// ReplaceRoleOverrides remplace les modifications des permissions d'un rôle
+func (r *PermissionRepository) ReplaceRoleOverrides(role string, overrides []models.RolePermissionOverride) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", role).Delete(&models.RolePermissionOverride{}).Error; err != nil {
+			return err
+		}
+		if len(overrides) == 0 {
+			return nil
+		}
+		return tx.Create(&overrides).Error
+	})
+}
@@ -112,6 +112,32 @@ func (r *PresenceRepository) GetPresenceStats(courseID uint) (*models.PresenceSt
 	return &stats, nil
 }
 
+// GetAttendedCountsByCourses compte, pour chaque cours ayant un appel enregistré, les étudiants présents ou en retard
+func (r *PresenceRepository) GetAttendedCountsByCourses(courseIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int)
+	if len(courseIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		CourseID uint
+		Attended int
+	}
+	err := r.db.Model(&models.Presence{}).
+		Select("course_id, SUM(CASE WHEN status IN (?, ?) THEN 1 ELSE 0 END) AS attended", models.StatusPresent, models.StatusLate).
+		Where("course_id IN ?", courseIDs).
+		Group("course_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.CourseID] = row.Attended
+	}
+	return counts, nil
+}
+
 // GetPresencesWithFilters récupère les présences avec filtres
 func (r *PresenceRepository) GetPresencesWithFilters(filters map[string]interface{}, page, limit int) ([]models.Presence, int64, error) {
 	var presences []models.Presence
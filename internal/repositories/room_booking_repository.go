@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RoomBookingRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomBookingRepository(db *gorm.DB) *RoomBookingRepository {
+	return &RoomBookingRepository{db: db}
+}
+
+// CreateBooking crée une demande de réservation
+func (r *RoomBookingRepository) CreateBooking(booking *models.RoomBooking) error {
+	return r.db.Create(booking).Error
+}
+
+// GetBookingByID récupère une réservation par son ID
+func (r *RoomBookingRepository) GetBookingByID(id uint) (*models.RoomBooking, error) {
+	var booking models.RoomBooking
+	err := r.db.Preload("Room").Preload("MergedRooms").Preload("Requester").Preload("Reviewer").First(&booking, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetBookings récupère les réservations selon les filtres, les plus proches en premier
+func (r *RoomBookingRepository) GetBookings(filter *models.RoomBookingFilter) ([]models.RoomBooking, error) {
+	var bookings []models.RoomBooking
+	query := r.db.Preload("Room").Preload("MergedRooms").Preload("Requester").Preload("Reviewer")
+
+	if filter != nil {
+		if filter.Status != "" {
+			query = query.Where("status = ?", filter.Status)
+		}
+		if filter.RoomID != nil {
+			query = query.Where("room_id = ?", *filter.RoomID)
+		}
+		if filter.RequesterID != nil {
+			query = query.Where("requester_id = ?", *filter.RequesterID)
+		}
+	}
+
+	err := query.Order("start_time ASC").Find(&bookings).Error
+	return bookings, err
+}
+
+// GetApprovedBookingsInRooms récupère les réservations validées portant sur l'une des salles sur la période
+func (r *RoomBookingRepository) GetApprovedBookingsInRooms(roomIDs []uint, startTime, endTime time.Time) ([]models.RoomBooking, error) {
+	var bookings []models.RoomBooking
+	err := r.db.Preload("Room").Preload("MergedRooms").
+		Where("(room_id IN ? OR id IN (SELECT room_booking_id FROM room_booking_merged_rooms WHERE room_id IN ?))", roomIDs, roomIDs).
+		Where("status = ? AND start_time < ? AND end_time > ?", models.RoomBookingStatusApproved, endTime, startTime).
+		Order("start_time ASC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// ReviewBooking enregistre la décision d'un administrateur
+func (r *RoomBookingRepository) ReviewBooking(id uint, status string, reviewerID uint, reviewComment string) error {
+	now := time.Now()
+	return r.db.Model(&models.RoomBooking{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"reviewer_id":    reviewerID,
+			"review_comment": reviewComment,
+			"reviewed_at":    &now,
+		}).Error
+}
+
+// UpdateBookingStatus change le statut d'une réservation
+func (r *RoomBookingRepository) UpdateBookingStatus(id uint, status string) error {
+	return r.db.Model(&models.RoomBooking{}).Where("id = ?", id).Update("status", status).Error
+}
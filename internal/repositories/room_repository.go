@@ -17,9 +17,17 @@ func NewRoomRepository(db *gorm.DB) *RoomRepository {
 // GetAllRooms récupère toutes les salles avec filtres
 func (r *RoomRepository) GetAllRooms(filter *models.RoomFilter) ([]models.Room, error) {
 	var rooms []models.Room
-	query := r.db.Preload("Parent").Preload("Children")
+	query := r.db.Preload("Parent").Preload("Children").Preload("Equipment")
 
 	if filter != nil {
+		tags := filter.EquipmentTags()
+		if filter.MinCapacity != nil {
+			query = query.Where("capacity >= ?", *filter.MinCapacity)
+		}
+		if len(tags) > 0 {
+			query = query.Where("id IN (?)", roomsWithEquipment(r.db, tags))
+		}
+
 		if filter.Name != "" {
 			query = query.Where("name ILIKE ?", "%"+filter.Name+"%")
 		}
@@ -34,7 +42,7 @@ func (r *RoomRepository) GetAllRooms(filter *models.RoomFilter) ([]models.Room,
 		}
 		if filter.ParentID != nil {
 			query = query.Where("parent_id = ?", *filter.ParentID)
-		} else {
+		} else if !filter.IncludeSubRooms && filter.MinCapacity == nil && len(tags) == 0 {
 			// Par défaut, ne montrer que les salles principales (pas les sous-salles)
 			query = query.Where("parent_id IS NULL")
 		}
@@ -47,7 +55,7 @@ func (r *RoomRepository) GetAllRooms(filter *models.RoomFilter) ([]models.Room,
 // GetRoomByID récupère une salle par son ID
 func (r *RoomRepository) GetRoomByID(id uint) (*models.Room, error) {
 	var room models.Room
-	err := r.db.Preload("Parent").Preload("Children").First(&room, id).Error
+	err := r.db.Preload("Parent").Preload("Children").Preload("Equipment").First(&room, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +82,22 @@ func (r *RoomRepository) UpdateRoom(room *models.Room) error {
 	return r.db.Save(room).Error
 }
 
+// ReplaceEquipment remplace les équipements d'une salle par les étiquettes données
+func (r *RoomRepository) ReplaceEquipment(room *models.Room, tags []string) error {
+	equipment, err := resolveEquipment(r.db, tags)
+	if err != nil {
+		return err
+	}
+	return r.db.Model(room).Association("Equipment").Replace(equipment)
+}
+
+// GetAllEquipment récupère toutes les étiquettes d'équipement connues
+func (r *RoomRepository) GetAllEquipment() ([]models.Equipment, error) {
+	var equipment []models.Equipment
+	err := r.db.Order("name ASC").Find(&equipment).Error
+	return equipment, err
+}
+
 // DeleteRoom supprime une salle (soft delete)
 func (r *RoomRepository) DeleteRoom(id uint) error {
 	return r.db.Delete(&models.Room{}, id).Error
@@ -112,3 +136,26 @@ func (r *RoomRepository) GetChildRooms(parentID uint) ([]models.Room, error) {
 	err := r.db.Where("parent_id = ?", parentID).Find(&rooms).Error
 	return rooms, err
 }
+
+// roomsWithEquipment construit la sous-requête des salles disposant de tous les équipements donnés
+func roomsWithEquipment(db *gorm.DB, tags []string) *gorm.DB {
+	return db.Table("room_equipment").
+		Select("room_equipment.room_id").
+		Joins("JOIN equipment ON equipment.id = room_equipment.equipment_id").
+		Where("equipment.name IN ?", tags).
+		Group("room_equipment.room_id").
+		Having("COUNT(DISTINCT equipment.name) = ?", len(tags))
+}
+
+// resolveEquipment retrouve les équipements correspondant aux étiquettes, en créant celles qui n'existent pas
+func resolveEquipment(db *gorm.DB, tags []string) ([]models.Equipment, error) {
+	equipment := make([]models.Equipment, 0, len(tags))
+	for _, tag := range models.NormalizeEquipmentTags(tags) {
+		item := models.Equipment{Name: tag}
+		if err := db.Where("name = ?", tag).FirstOrCreate(&item).Error; err != nil {
+			return nil, err
+		}
+		equipment = append(equipment, item)
+	}
+	return equipment, nil
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenUsed indique qu'un jeton de rafraîchissement a déjà servi
+var ErrRefreshTokenUsed = errors.New("jeton de rafraîchissement déjà utilisé")
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// CreateSession crée une session et son premier jeton de rafraîchissement
+func (r *SessionRepository) CreateSession(session *models.Session, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+// GetSessionByID récupère une session par son ID
+func (r *SessionRepository) GetSessionByID(id uint) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveUserSessions récupère les sessions ouvertes d'un utilisateur, de la plus récemment active à la plus ancienne
+func (r *SessionRepository) GetActiveUserSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession met à jour la date de dernière activité et l'adresse IP d'une session
+func (r *SessionRepository) TouchSession(id uint, ipAddress string, seenAt time.Time) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_seen_at": seenAt,
+		"ip_address":   ipAddress,
+	}).Error
+}
+
+// GetRefreshTokenByHash récupère un jeton de rafraîchissement et sa session à partir de son empreinte
+func (r *SessionRepository) GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Preload("Session").Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken marque le jeton comme utilisé et le remplace par le suivant.
+// Retourne ErrRefreshTokenUsed si le jeton a déjà servi, y compris lors d'une requête concurrente.
+func (r *SessionRepository) RotateRefreshToken(current *models.RefreshToken, next *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", current.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenUsed
+		}
+
+		next.SessionID = current.SessionID
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).Where("id = ?", current.SessionID).Update("expires_at", next.ExpiresAt).Error
+	})
+}
+
+// RevokeSession révoque une session
+func (r *SessionRepository) RevokeSession(id uint) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserSessions révoque toutes les sessions ouvertes d'un utilisateur et retourne leur nombre
+func (r *SessionRepository) RevokeUserSessions(userID uint) (int64, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SubjectQuotaRepository struct {
+	db *gorm.DB
+}
+
+func NewSubjectQuotaRepository(db *gorm.DB) *SubjectQuotaRepository {
+	return &SubjectQuotaRepository{db: db}
+}
+
+// CreateQuota crée un quota horaire
+func (r *SubjectQuotaRepository) CreateQuota(quota *models.SubjectQuota) error {
+	return r.db.Create(quota).Error
+}
+
+// GetQuotaByID récupère un quota horaire par son ID
+func (r *SubjectQuotaRepository) GetQuotaByID(id uint) (*models.SubjectQuota, error) {
+	var quota models.SubjectQuota
+	err := r.db.Preload("Subject").Preload("Group").First(&quota, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// GetQuotas récupère les quotas horaires selon les filtres
+func (r *SubjectQuotaRepository) GetQuotas(filter *models.SubjectQuotaFilter) ([]models.SubjectQuota, error) {
+	var quotas []models.SubjectQuota
+	query := r.db.Preload("Subject").Preload("Group")
+
+	if filter != nil {
+		if filter.SubjectID != nil {
+			query = query.Where("subject_id = ?", *filter.SubjectID)
+		}
+		if filter.GroupID != nil {
+			query = query.Where("group_id = ?", *filter.GroupID)
+		}
+		if filter.Term != "" {
+			query = query.Where("term = ?", filter.Term)
+		}
+	}
+
+	err := query.Order("start_date ASC, group_id ASC, subject_id ASC").Find(&quotas).Error
+	return quotas, err
+}
+
+// CheckQuotaExists vérifie si un quota existe déjà pour la matière, le groupe et la période
+func (r *SubjectQuotaRepository) CheckQuotaExists(subjectID, groupID uint, term string, excludeID *uint) (bool, error) {
+	var count int64
+	query := r.db.Model(&models.SubjectQuota{}).Where("subject_id = ? AND group_id = ? AND term = ?", subjectID, groupID, term)
+	if excludeID != nil {
+		query = query.Where("id != ?", *excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateQuota met à jour un quota horaire
+func (r *SubjectQuotaRepository) UpdateQuota(quota *models.SubjectQuota) error {
+	return r.db.Save(quota).Error
+}
+
+// DeleteQuota supprime un quota horaire
+func (r *SubjectQuotaRepository) DeleteQuota(id uint) error {
+	return r.db.Delete(&models.SubjectQuota{}, id).Error
+}
@@ -20,14 +20,14 @@ func NewSubjectRepository() *SubjectRepository {
 // GetAllSubjects récupère toutes les matières
 func (r *SubjectRepository) GetAllSubjects() ([]models.Subject, error) {
 	var subjects []models.Subject
-	err := r.db.Find(&subjects).Error
+	err := r.db.Preload("RequiredEquipment").Preload("HeadTeacher").Find(&subjects).Error
 	return subjects, err
 }
 
 // GetSubjectByID récupère une matière par son ID
 func (r *SubjectRepository) GetSubjectByID(id uint) (*models.Subject, error) {
 	var subject models.Subject
-	err := r.db.First(&subject, id).Error
+	err := r.db.Preload("RequiredEquipment").Preload("HeadTeacher").First(&subject, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -64,9 +64,92 @@ func (r *SubjectRepository) UpdateSubject(subject *models.Subject) error {
 	return r.db.Save(subject).Error
 }
 
-// DeleteSubject supprime une matière (soft delete)
+// ReplaceRequiredEquipment remplace les équipements requis d'une matière par les étiquettes données
+func (r *SubjectRepository) ReplaceRequiredEquipment(subject *models.Subject, tags []string) error {
+	equipment, err := resolveEquipment(r.db, tags)
+	if err != nil {
+		return err
+	}
+	return r.db.Model(subject).Association("RequiredEquipment").Replace(equipment)
+}
+
+// DeleteSubject supprime une matière (soft delete), ses quotas horaires et ses enseignants qualifiés
 func (r *SubjectRepository) DeleteSubject(id uint) error {
-	return r.db.Delete(&models.Subject{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subject_id = ?", id).Delete(&models.SubjectQuota{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM subject_teachers WHERE subject_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Subject{}, id).Error
+	})
+}
+
+// GetSubjectTeachers récupère les enseignants qualifiés pour une matière
+func (r *SubjectRepository) GetSubjectTeachers(subjectID uint) ([]models.User, error) {
+	var teachers []models.User
+	err := r.db.Joins("JOIN subject_teachers ON subject_teachers.user_id = users.id").
+		Where("subject_teachers.subject_id = ?", subjectID).
+		Order("users.last_name, users.first_name").
+		Find(&teachers).Error
+	return teachers, err
+}
+
+// GetTeacherSubjects récupère les matières qu'un enseignant est qualifié pour enseigner
+func (r *SubjectRepository) GetTeacherSubjects(teacherID uint) ([]models.Subject, error) {
+	var subjects []models.Subject
+	err := r.db.Preload("RequiredEquipment").Preload("HeadTeacher").
+		Joins("JOIN subject_teachers ON subject_teachers.subject_id = subjects.id").
+		Where("subject_teachers.user_id = ?", teacherID).
+		Order("subjects.name").
+		Find(&subjects).Error
+	return subjects, err
+}
+
+// CountSubjectTeachers compte les enseignants qualifiés pour une matière
+func (r *SubjectRepository) CountSubjectTeachers(subjectID uint) (int64, error) {
+	var count int64
+	err := r.db.Table("subject_teachers").Where("subject_id = ?", subjectID).Count(&count).Error
+	return count, err
+}
+
+// IsTeacherLinked vérifie si un enseignant est qualifié pour une matière
+func (r *SubjectRepository) IsTeacherLinked(subjectID, teacherID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("subject_teachers").Where("subject_id = ? AND user_id = ?", subjectID, teacherID).Count(&count).Error
+	return count > 0, err
+}
+
+// AddTeacher qualifie un enseignant pour une matière
+func (r *SubjectRepository) AddTeacher(subject *models.Subject, teacher *models.User) error {
+	return r.db.Model(subject).Association("Teachers").Append(teacher)
+}
+
+// RemoveTeacher retire la qualification d'un enseignant, ainsi que la responsabilité de la matière s'il l'avait
+func (r *SubjectRepository) RemoveTeacher(subject *models.Subject, teacher *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(subject).Association("Teachers").Delete(teacher); err != nil {
+			return err
+		}
+		return tx.Model(&models.Subject{}).Where("id = ? AND head_teacher_id = ?", subject.ID, teacher.ID).
+			Update("head_teacher_id", nil).Error
+	})
+}
+
+// SetHeadTeacher désigne (ou retire, si nil) le responsable d'une matière
+func (r *SubjectRepository) SetHeadTeacher(subjectID uint, teacherID *uint) error {
+	return r.db.Model(&models.Subject{}).Where("id = ?", subjectID).Update("head_teacher_id", teacherID).Error
+}
+
+// RemoveTeacherFromAllSubjects retire toutes les qualifications et responsabilités d'un enseignant
+func (r *SubjectRepository) RemoveTeacherFromAllSubjects(teacherID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM subject_teachers WHERE user_id = ?", teacherID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Subject{}).Where("head_teacher_id = ?", teacherID).Update("head_teacher_id", nil).Error
+	})
 }
 
 // CheckSubjectExists vérifie si une matière existe déjà avec le même nom
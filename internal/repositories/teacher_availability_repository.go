@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TeacherAvailabilityRepository struct {
+	db *gorm.DB
+}
+
+func NewTeacherAvailabilityRepository(db *gorm.DB) *TeacherAvailabilityRepository {
+	return &TeacherAvailabilityRepository{db: db}
+}
+
+// GetAvailabilities récupère les plages de disponibilité d'un enseignant
+func (r *TeacherAvailabilityRepository) GetAvailabilities(teacherID uint) ([]models.TeacherAvailability, error) {
+	var availabilities []models.TeacherAvailability
+	err := r.db.Where("teacher_id = ?", teacherID).
+		Order("id").
+		Find(&availabilities).Error
+	return availabilities, err
+}
+
+// GetAvailabilityByID récupère une plage de disponibilité par son ID
+func (r *TeacherAvailabilityRepository) GetAvailabilityByID(id uint) (*models.TeacherAvailability, error) {
+	var availability models.TeacherAvailability
+	if err := r.db.First(&availability, id).Error; err != nil {
+		return nil, err
+	}
+	return &availability, nil
+}
+
+// CreateAvailability ajoute une plage de disponibilité
+func (r *TeacherAvailabilityRepository) CreateAvailability(availability *models.TeacherAvailability) error {
+	return r.db.Create(availability).Error
+}
+
+// DeleteAvailability supprime une plage de disponibilité
+func (r *TeacherAvailabilityRepository) DeleteAvailability(id uint) error {
+	return r.db.Delete(&models.TeacherAvailability{}, id).Error
+}
+
+// GetUnavailabilities récupère les périodes d'indisponibilité d'un enseignant
+func (r *TeacherAvailabilityRepository) GetUnavailabilities(teacherID uint) ([]models.TeacherUnavailability, error) {
+	var unavailabilities []models.TeacherUnavailability
+	err := r.db.Where("teacher_id = ?", teacherID).
+		Order("start_date").
+		Find(&unavailabilities).Error
+	return unavailabilities, err
+}
+
+// GetUnavailabilityByID récupère une période d'indisponibilité par son ID
+func (r *TeacherAvailabilityRepository) GetUnavailabilityByID(id uint) (*models.TeacherUnavailability, error) {
+	var unavailability models.TeacherUnavailability
+	if err := r.db.First(&unavailability, id).Error; err != nil {
+		return nil, err
+	}
+	return &unavailability, nil
+}
+
+// CreateUnavailability ajoute une période d'indisponibilité
+func (r *TeacherAvailabilityRepository) CreateUnavailability(unavailability *models.TeacherUnavailability) error {
+	return r.db.Create(unavailability).Error
+}
+
+// DeleteUnavailability supprime une période d'indisponibilité
+func (r *TeacherAvailabilityRepository) DeleteUnavailability(id uint) error {
+	return r.db.Delete(&models.TeacherUnavailability{}, id).Error
+}
+
+// GetUnavailabilitiesInRange récupère les indisponibilités d'un enseignant qui chevauchent une période
+func (r *TeacherAvailabilityRepository) GetUnavailabilitiesInRange(teacherID uint, start, end time.Time) ([]models.TeacherUnavailability, error) {
+	var unavailabilities []models.TeacherUnavailability
+	err := r.db.Where("teacher_id = ? AND start_date < ? AND end_date > ?", teacherID, end, start).
+		Order("start_date").
+		Find(&unavailabilities).Error
+	return unavailabilities, err
+}
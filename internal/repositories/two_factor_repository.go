@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"time"
+
+	"eduqr-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetByUserID récupère la configuration de double authentification d'un utilisateur
+func (r *TwoFactorRepository) GetByUserID(userID uint) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	if err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// SavePending remplace le secret d'un enrôlement non confirmé
+func (r *TwoFactorRepository) SavePending(userID uint, secret string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TwoFactor{UserID: userID, Secret: secret}).Error
+	})
+}
+
+// Enable active la double authentification et remplace les codes de secours
+func (r *TwoFactorRepository) Enable(twoFactor *models.TwoFactor, step int64, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.TwoFactor{}).Where("id = ?", twoFactor.ID).Updates(map[string]interface{}{
+			"enabled_at":     now,
+			"last_used_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, twoFactor.UserID, codeHashes)
+	})
+}
+
+// UseStep enregistre la période d'un code accepté. Retourne false si un code de cette période
+// ou d'une période ultérieure a déjà servi.
+func (r *TwoFactorRepository) UseStep(twoFactorID uint, step int64) (bool, error) {
+	result := r.db.Model(&models.TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", twoFactorID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceRecoveryCodes remplace les codes de secours d'un utilisateur
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// UseRecoveryCode consomme un code de secours. Retourne false s'il n'existe pas ou a déjà servi.
+func (r *TwoFactorRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountRecoveryCodes compte les codes de secours encore utilisables
+func (r *TwoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteByUserID supprime la double authentification et les codes de secours d'un utilisateur
+func (r *TwoFactorRepository) DeleteByUserID(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.TwoFactorRecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = models.TwoFactorRecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"eduqr-backend/internal/database"
 	"eduqr-backend/internal/models"
 
@@ -30,6 +32,28 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByExternalID récupère un utilisateur à partir de son identifiant chez un fournisseur d'identité
+func (r *UserRepository) FindByExternalID(provider, externalID string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("auth_provider = ? AND external_id = ?", provider, externalID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByAuthProvider récupère les utilisateurs provenant d'un fournisseur d'identité
+func (r *UserRepository) FindByAuthProvider(provider string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("auth_provider = ?", provider).Find(&users).Error
+	return users, err
+}
+
+// SetDeactivatedAt désactive un utilisateur à une date, ou le réactive si deactivatedAt est nil
+func (r *UserRepository) SetDeactivatedAt(id uint, deactivatedAt *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("deactivated_at", deactivatedAt).Error
+}
+
 func (r *UserRepository) FindByID(id uint) (*models.User, error) {
 	var user models.User
 	err := r.db.First(&user, id).Error
@@ -72,3 +96,24 @@ func (r *UserRepository) GetUserByID(id uint) (*models.User, error) {
 func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	return r.FindByEmail(email)
 }
+
+// CreateUsersWithGroups crée plusieurs utilisateurs et leurs appartenances aux groupes (effectives immédiatement)
+// en une seule transaction.
+// groupIDs[i] contient les groupes de users[i].
+func (r *UserRepository) CreateUsersWithGroups(users []*models.User, groupIDs [][]uint) error {
+	startDate := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, user := range users {
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+			for _, groupID := range groupIDs[i] {
+				membership := models.GroupMembership{GroupID: groupID, UserID: user.ID, StartDate: startDate}
+				if err := tx.Create(&membership).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -5,25 +5,38 @@ import (
 	"eduqr-backend/internal/middlewares"
 	"time"
 
-	"eduqr-backend/internal/database"
-	"eduqr-backend/internal/repositories"
-	"eduqr-backend/internal/services"
+	"eduqr-backend/internal/models"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 type Router struct {
-	userController     *controllers.UserController
-	eventController    *controllers.EventController
-	roomController     *controllers.RoomController
-	subjectController  *controllers.SubjectController
-	courseController   *controllers.CourseController
-	auditLogController *controllers.AuditLogController
-	absenceController  *controllers.AbsenceController
-	presenceController *controllers.PresenceController
-	authMiddleware     *middlewares.AuthMiddleware
-	auditMiddleware    *middlewares.AuditMiddleware
+	userController          *controllers.UserController
+	eventController         *controllers.EventController
+	roomController          *controllers.RoomController
+	subjectController       *controllers.SubjectController
+	courseController        *controllers.CourseController
+	auditLogController      *controllers.AuditLogController
+	absenceController       *controllers.AbsenceController
+	presenceController      *controllers.PresenceController
+	notificationController  *controllers.NotificationController
+	timetableController     *controllers.TimetableController
+	availabilityController  *controllers.TeacherAvailabilityController
+	reportController        *controllers.ReportController
+	calendarFeedController  *controllers.CalendarFeedController
+	importController        *controllers.ImportController
+	groupController         *controllers.GroupController
+	roomBookingController   *controllers.RoomBookingController
+	curriculumController    *controllers.CurriculumController
+	passwordResetController *controllers.PasswordResetController
+	twoFactorController     *controllers.TwoFactorController
+	oidcController          *controllers.OIDCController
+	deletionController      *controllers.DeletionController
+	permissionController    *controllers.PermissionController
+	authMiddleware          *middlewares.AuthMiddleware
+	auditMiddleware         *middlewares.AuditMiddleware
+	rateLimitMiddleware     *middlewares.RateLimitMiddleware
 }
 
 func NewRouter(
@@ -35,20 +48,50 @@ func NewRouter(
 	auditLogController *controllers.AuditLogController,
 	absenceController *controllers.AbsenceController,
 	presenceController *controllers.PresenceController,
+	notificationController *controllers.NotificationController,
+	timetableController *controllers.TimetableController,
+	availabilityController *controllers.TeacherAvailabilityController,
+	reportController *controllers.ReportController,
+	calendarFeedController *controllers.CalendarFeedController,
+	importController *controllers.ImportController,
+	groupController *controllers.GroupController,
+	roomBookingController *controllers.RoomBookingController,
+	curriculumController *controllers.CurriculumController,
+	passwordResetController *controllers.PasswordResetController,
+	twoFactorController *controllers.TwoFactorController,
+	oidcController *controllers.OIDCController,
+	deletionController *controllers.DeletionController,
+	permissionController *controllers.PermissionController,
 	authMiddleware *middlewares.AuthMiddleware,
 	auditMiddleware *middlewares.AuditMiddleware,
+	rateLimitMiddleware *middlewares.RateLimitMiddleware,
 ) *Router {
 	return &Router{
-		userController:     userController,
-		eventController:    eventController,
-		roomController:     roomController,
-		subjectController:  subjectController,
-		courseController:   courseController,
-		auditLogController: auditLogController,
-		absenceController:  absenceController,
-		presenceController: presenceController,
-		authMiddleware:     authMiddleware,
-		auditMiddleware:    auditMiddleware,
+		userController:          userController,
+		eventController:         eventController,
+		roomController:          roomController,
+		subjectController:       subjectController,
+		courseController:        courseController,
+		auditLogController:      auditLogController,
+		absenceController:       absenceController,
+		presenceController:      presenceController,
+		notificationController:  notificationController,
+		timetableController:     timetableController,
+		availabilityController:  availabilityController,
+		reportController:        reportController,
+		calendarFeedController:  calendarFeedController,
+		importController:        importController,
+		groupController:         groupController,
+		roomBookingController:   roomBookingController,
+		curriculumController:    curriculumController,
+		passwordResetController: passwordResetController,
+		twoFactorController:     twoFactorController,
+		oidcController:          oidcController,
+		deletionController:      deletionController,
+		permissionController:    permissionController,
+		authMiddleware:          authMiddleware,
+		auditMiddleware:         auditMiddleware,
+		rateLimitMiddleware:     rateLimitMiddleware,
 	}
 }
 
@@ -65,6 +108,9 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// Global rate limit, per client IP
+	router.Use(r.rateLimitMiddleware.Limit(middlewares.RateLimitGlobal))
+
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -78,9 +124,19 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	{
 		// Auth routes (no authentication required)
 		auth := v1.Group("/auth")
+		auth.Use(r.rateLimitMiddleware.Limit(middlewares.RateLimitAuth))
 		{
 			auth.POST("/register", r.userController.Register)
 			auth.POST("/login", r.auditMiddleware.AuditLoginMiddleware(), r.userController.Login)
+			auth.POST("/2fa/verify", r.auditMiddleware.AuditLoginMiddleware(), r.twoFactorController.VerifyLogin)
+			auth.POST("/2fa/setup", r.twoFactorController.BeginLoginSetup)
+			auth.POST("/2fa/setup/confirm", r.auditMiddleware.AuditLoginMiddleware(), r.twoFactorController.ConfirmLoginSetup)
+			auth.POST("/refresh", r.userController.RefreshToken)
+			auth.POST("/logout", r.authMiddleware.AuthMiddleware(), r.auditMiddleware.AuditLogoutMiddleware(), r.userController.Logout)
+			auth.POST("/forgot-password", r.passwordResetController.ForgotPassword)
+			auth.POST("/reset-password", r.passwordResetController.ResetPassword)
+			auth.GET("/oidc/login", r.oidcController.Authorize)
+			auth.POST("/oidc/callback", r.auditMiddleware.AuditLoginMiddleware(), r.oidcController.Callback)
 		}
 
 		// User routes (authentication required)
@@ -92,15 +148,28 @@ func (r *Router) SetupRoutes() *gin.Engine {
 			users.PUT("/profile", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.UpdateProfile)
 			users.PUT("/profile/password", r.userController.ChangePassword)
 			users.POST("/profile/validate-password", r.userController.ValidatePassword)
+			users.GET("/profile/2fa", r.twoFactorController.GetStatus)
+			users.POST("/profile/2fa/setup", r.twoFactorController.BeginSetup)
+			users.POST("/profile/2fa/enable", r.auditMiddleware.AuditMiddleware("update", "user"), r.twoFactorController.Enable)
+			users.POST("/profile/2fa/disable", r.auditMiddleware.AuditMiddleware("update", "user"), r.twoFactorController.Disable)
+			users.POST("/profile/2fa/recovery-codes", r.twoFactorController.RegenerateRecoveryCodes)
+			users.GET("/profile/sessions", r.userController.GetMySessions)
+			users.DELETE("/profile/sessions/:sessionId", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.RevokeMySession)
 
 			// User management routes with role-based permissions
-			users.GET("/all", r.userController.GetAllUsers)                                                         // All authenticated users can view based on their role
-			users.POST("/create", r.auditMiddleware.AuditMiddleware("create", "user"), r.userController.CreateUser) // Only users who can manage roles
+			users.GET("/all", r.userController.GetAllUsers)                                                                                                                      // All authenticated users can view based on their role
+			users.POST("/create", r.auditMiddleware.AuditMiddleware("create", "user"), r.userController.CreateUser)                                                              // Only users who can manage roles
+			users.POST("/import", r.rateLimitMiddleware.Limit(middlewares.RateLimitImport), r.auditMiddleware.AuditMiddleware("create", "user"), r.importController.ImportUsers) // Only users who can manage roles
 
 			// Parameterized routes with role-based permissions
-			users.GET("/:id", r.userController.GetUserByID)                                                                // View permissions based on role
-			users.PUT("/:id", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.UpdateUser)            // Manage permissions based on role
-			users.PATCH("/:id/role", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.UpdateUserRole) // Manage permissions based on role
+			users.GET("/:id", r.userController.GetUserByID)                                                                                   // View permissions based on role
+			users.PUT("/:id", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.UpdateUser)                               // Manage permissions based on role
+			users.PATCH("/:id/role", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.UpdateUserRole)                    // Manage permissions based on role
+			users.GET("/:id/sessions", r.userController.GetUserSessions)                                                                      // Manage permissions based on role
+			users.DELETE("/:id/sessions/:sessionId", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.RevokeUserSession) // Manage permissions based on role
+			users.DELETE("/:id/2fa", r.auditMiddleware.AuditMiddleware("update", "user"), r.twoFactorController.ResetUserTwoFactor)           // Manage permissions based on role
+			users.POST("/:id/unlock", r.userController.UnlockUser)                                                                            // Manage permissions based on role
+			users.POST("/:id/sessions/revoke", r.auditMiddleware.AuditMiddleware("update", "user"), r.userController.RevokeUserSessions)      // Manage permissions based on role
 		}
 
 		// Event routes (authentication required)
@@ -110,6 +179,7 @@ func (r *Router) SetupRoutes() *gin.Engine {
 			events.GET("", r.eventController.GetUserEvents)
 			events.POST("", r.auditMiddleware.AuditMiddleware("create", "event"), r.eventController.CreateEvent)
 			events.GET("/range", r.eventController.GetEventsByDateRange)
+			events.POST("/import/ics", r.rateLimitMiddleware.Limit(middlewares.RateLimitImport), r.auditMiddleware.AuditMiddleware("create", "event"), r.importController.ImportEventsICS)
 			events.GET("/:id", r.eventController.GetEventByID)
 			events.PUT("/:id", r.auditMiddleware.AuditMiddleware("update", "event"), r.eventController.UpdateEvent)
 			events.DELETE("/:id", r.auditMiddleware.AuditMiddleware("delete", "event"), r.eventController.DeleteEvent)
@@ -135,8 +205,8 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		presences.Use(r.authMiddleware.AuthMiddleware())
 		{
 			// Routes pour les étudiants
-			presences.POST("/scan", r.auditMiddleware.AuditMiddleware("create", "presence"), r.presenceController.ScanQRCode) // Étudiants seulement
-			presences.GET("/my", r.presenceController.GetMyPresences)                                                         // Étudiants seulement
+			presences.POST("/scan", r.rateLimitMiddleware.Limit(middlewares.RateLimitScan), r.auditMiddleware.AuditMiddleware("create", "presence"), r.presenceController.ScanQRCode) // Étudiants seulement
+			presences.GET("/my", r.presenceController.GetMyPresences)                                                                                                                 // Étudiants seulement
 
 			// Routes pour les professeurs et admins
 			presences.GET("/course/:courseId", r.presenceController.GetPresencesByCourse)                                                                              // Professeurs et admins
@@ -156,30 +226,60 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		// Room routes (admin authentication required)
 		rooms := v1.Group("/admin/rooms")
 		rooms.Use(r.authMiddleware.AuthMiddleware())
-		rooms.Use(r.authMiddleware.RoleMiddleware("admin"))
+		rooms.Use(r.authMiddleware.RequirePermission(models.PermRoomManageAny))
 		{
 			rooms.GET("", r.roomController.GetAllRooms)
 			rooms.GET("/modular", r.roomController.GetModularRooms)
+			rooms.GET("/equipment", r.roomController.GetAllEquipment)
 			rooms.POST("", r.auditMiddleware.AuditMiddleware("create", "room"), r.roomController.CreateRoom)
 			rooms.GET("/:id", r.roomController.GetRoomByID)
 			rooms.PUT("/:id", r.auditMiddleware.AuditMiddleware("update", "room"), r.roomController.UpdateRoom)
 		}
 
+		// Room booking routes (authentication required, review by admins)
+		roomBookings := v1.Group("/room-bookings")
+		roomBookings.Use(r.authMiddleware.AuthMiddleware())
+		{
+			roomBookings.GET("", r.roomBookingController.GetBookings) // Ses propres demandes (toutes pour les admins)
+			roomBookings.POST("", r.auditMiddleware.AuditMiddleware("create", "room_booking"), r.roomBookingController.CreateBooking)
+			roomBookings.GET("/rooms/:id/timeline", r.roomBookingController.GetRoomTimeline) // Configuration de la salle à chaque instant
+			roomBookings.GET("/:id", r.roomBookingController.GetBookingByID)
+			roomBookings.POST("/:id/cancel", r.auditMiddleware.AuditMiddleware("update", "room_booking"), r.roomBookingController.CancelBooking)
+			roomBookings.POST("/:id/review", r.authMiddleware.RequirePermission(models.PermRoomBookingReviewAny), r.auditMiddleware.AuditMiddleware("update", "room_booking"), r.roomBookingController.ReviewBooking)
+		}
+
 		// Subject routes (admin authentication required)
 		subjects := v1.Group("/admin/subjects")
 		subjects.Use(r.authMiddleware.AuthMiddleware())
-		subjects.Use(r.authMiddleware.RoleMiddleware("admin"))
+		subjects.Use(r.authMiddleware.RequirePermission(models.PermSubjectManageAny))
 		{
 			subjects.GET("", r.subjectController.GetAllSubjects)
 			subjects.POST("", r.auditMiddleware.AuditMiddleware("create", "subject"), r.subjectController.CreateSubject)
 			subjects.GET("/:id", r.subjectController.GetSubjectByID)
 			subjects.PUT("/:id", r.auditMiddleware.AuditMiddleware("update", "subject"), r.subjectController.UpdateSubject)
+			subjects.GET("/:id/teachers", r.subjectController.GetSubjectTeachers)
+			subjects.POST("/:id/teachers", r.auditMiddleware.AuditMiddleware("update", "subject"), r.subjectController.AddSubjectTeacher)
+			subjects.DELETE("/:id/teachers/:teacherId", r.auditMiddleware.AuditMiddleware("update", "subject"), r.subjectController.RemoveSubjectTeacher)
+			subjects.PUT("/:id/head-teacher", r.auditMiddleware.AuditMiddleware("update", "subject"), r.subjectController.SetHeadTeacher)
+		}
+
+		// Curriculum routes: quotas horaires par matière, groupe et période (admin authentication required)
+		curriculum := v1.Group("/admin/curriculum")
+		curriculum.Use(r.authMiddleware.AuthMiddleware())
+		curriculum.Use(r.authMiddleware.RequirePermission(models.PermCurriculumManageAny))
+		{
+			curriculum.GET("/quotas", r.curriculumController.GetQuotas)
+			curriculum.POST("/quotas", r.auditMiddleware.AuditMiddleware("create", "subject_quota"), r.curriculumController.CreateQuota)
+			curriculum.GET("/quotas/:id", r.curriculumController.GetQuotaByID)
+			curriculum.PUT("/quotas/:id", r.auditMiddleware.AuditMiddleware("update", "subject_quota"), r.curriculumController.UpdateQuota)
+			curriculum.DELETE("/quotas/:id", r.auditMiddleware.AuditMiddleware("delete", "subject_quota"), r.curriculumController.DeleteQuota)
+			curriculum.GET("/progress", r.curriculumController.GetProgress) // Heures planifiées et effectuées, matières en retard en tête
 		}
 
 		// Course routes (admin authentication required)
 		courses := v1.Group("/admin/courses")
 		courses.Use(r.authMiddleware.AuthMiddleware())
-		courses.Use(r.authMiddleware.RoleMiddleware("admin"))
+		courses.Use(r.authMiddleware.RequirePermission(models.PermCourseManageAny))
 		{
 			courses.GET("", r.courseController.GetAllCourses)
 			courses.POST("", r.auditMiddleware.AuditMiddleware("create", "course"), r.courseController.CreateCourse)
@@ -189,7 +289,50 @@ func (r *Router) SetupRoutes() *gin.Engine {
 			courses.GET("/by-room/:roomId", r.courseController.GetCoursesByRoom)
 			courses.GET("/by-teacher/:teacherId", r.courseController.GetCoursesByTeacher)
 			courses.POST("/check-conflicts", r.courseController.CheckConflicts)
+			courses.GET("/available-rooms", r.courseController.FindAvailableRooms)
+			courses.POST("/import/ics", r.rateLimitMiddleware.Limit(middlewares.RateLimitImport), r.auditMiddleware.AuditMiddleware("create", "course"), r.importController.ImportCoursesICS)
 			courses.POST("/:id/check-conflicts", r.courseController.CheckConflictsForUpdate)
+			courses.POST("/:id/cancel", r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.CancelCourse)
+			courses.POST("/:id/reschedule", r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.RescheduleCourse)
+			courses.POST("/:id/complete", r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.CompleteCourse)
+			courses.GET("/:id/history", r.courseController.GetCourseHistory)
+			courses.PUT("/:id/substitute", r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.AssignSubstitute)
+			courses.DELETE("/:id/substitute", r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.RemoveSubstitute)
+		}
+
+		// Group routes (admin authentication required)
+		groups := v1.Group("/admin/groups")
+		groups.Use(r.authMiddleware.AuthMiddleware())
+		groups.Use(r.authMiddleware.RequirePermission(models.PermGroupManageAny))
+		{
+			groups.GET("", r.groupController.GetAllGroups)
+			groups.POST("", r.auditMiddleware.AuditMiddleware("create", "group"), r.groupController.CreateGroup)
+			groups.GET("/:id", r.groupController.GetGroupByID)
+			groups.PUT("/:id", r.auditMiddleware.AuditMiddleware("update", "group"), r.groupController.UpdateGroup)
+			groups.DELETE("/:id", r.auditMiddleware.AuditMiddleware("delete", "group"), r.groupController.DeleteGroup)
+			groups.GET("/:id/timetable", r.groupController.GetGroupTimetable)
+			groups.GET("/:id/members", r.groupController.GetMembers)
+			groups.POST("/:id/members", r.auditMiddleware.AuditMiddleware("update", "group"), r.groupController.AddMembers)
+			groups.POST("/:id/members/move", r.auditMiddleware.AuditMiddleware("update", "group"), r.groupController.MoveMember)
+			groups.DELETE("/:id/members/:userId", r.auditMiddleware.AuditMiddleware("update", "group"), r.groupController.RemoveMember)
+		}
+
+		// Timetable generation routes (admin authentication required)
+		timetable := v1.Group("/admin/timetable")
+		timetable.Use(r.authMiddleware.AuthMiddleware())
+		timetable.Use(r.authMiddleware.RequirePermission(models.PermTimetableManageAny))
+		{
+			timetable.POST("/generate", r.timetableController.GenerateTimetable)
+			timetable.POST("/apply", r.auditMiddleware.AuditMiddleware("create", "course"), r.timetableController.ApplyTimetable)
+		}
+
+		// Report routes (admin authentication required)
+		reports := v1.Group("/admin/reports")
+		reports.Use(r.authMiddleware.AuthMiddleware())
+		reports.Use(r.authMiddleware.RequirePermission(models.PermReportReadAny))
+		{
+			reports.GET("/teacher-workload", r.reportController.GetTeacherWorkload)
+			reports.GET("/room-occupancy", r.reportController.GetRoomOccupancy) // ?format=csv pour un export CSV
 		}
 
 		// Public course routes (authentication required, no admin role required)
@@ -200,17 +343,54 @@ func (r *Router) SetupRoutes() *gin.Engine {
 			publicCourses.GET("/:id", r.courseController.GetCourseByID)
 			publicCourses.GET("/by-teacher/:teacherId", r.courseController.GetCoursesByTeacher)
 			publicCourses.GET("/by-room/:roomId", r.courseController.GetCoursesByRoom)
+			publicCourses.GET("/available-rooms", r.authMiddleware.RequirePermission(models.PermRoomSearchAny), r.courseController.FindAvailableRooms) // Professeurs et admins
 
 			// Routes pour les professeurs (création et modification de leurs propres cours)
-			publicCourses.POST("", r.auditMiddleware.AuditMiddleware("create", "course"), r.courseController.CreateCourse)
+			publicCourses.POST("", r.authMiddleware.RequirePermission(models.PermCourseCreateAny), r.auditMiddleware.AuditMiddleware("create", "course"), r.courseController.CreateCourse)
 			publicCourses.PUT("/:id", r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.UpdateCourse)
 			publicCourses.DELETE("/:id", r.auditMiddleware.AuditMiddleware("delete", "course"), r.courseController.DeleteCourse)
+
+			// Changements de statut (professeurs sur leurs propres cours, admins sur tous)
+			publicCourses.GET("/:id/history", r.courseController.GetCourseHistory)
+			publicCourses.POST("/:id/cancel", r.authMiddleware.RequirePermission(models.PermCourseUpdateOwn, models.PermCourseUpdateAny), r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.CancelCourse)
+			publicCourses.POST("/:id/reschedule", r.authMiddleware.RequirePermission(models.PermCourseUpdateOwn, models.PermCourseUpdateAny), r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.RescheduleCourse)
+			publicCourses.POST("/:id/complete", r.authMiddleware.RequirePermission(models.PermCourseUpdateOwn, models.PermCourseUpdateAny), r.auditMiddleware.AuditMiddleware("update", "course"), r.courseController.CompleteCourse)
+		}
+
+		// Teacher availability routes (authentication required, edition by the teacher or an admin)
+		teachers := v1.Group("/teachers")
+		teachers.Use(r.authMiddleware.AuthMiddleware())
+		{
+			teachers.GET("/:id/availability", r.availabilityController.GetTeacherAvailability)
+			teachers.GET("/:id/workload", r.reportController.GetMyTeacherWorkload)
+			teachers.GET("/:id/subjects", r.subjectController.GetTeacherSubjects)
+			teachers.POST("/:id/availabilities", r.auditMiddleware.AuditMiddleware("update", "user"), r.availabilityController.AddAvailability)
+			teachers.DELETE("/:id/availabilities/:availabilityId", r.auditMiddleware.AuditMiddleware("update", "user"), r.availabilityController.DeleteAvailability)
+			teachers.POST("/:id/unavailabilities", r.auditMiddleware.AuditMiddleware("update", "user"), r.availabilityController.AddUnavailability)
+			teachers.DELETE("/:id/unavailabilities/:unavailabilityId", r.auditMiddleware.AuditMiddleware("update", "user"), r.availabilityController.DeleteUnavailability)
+		}
+
+		// Notification routes (authentication required)
+		notifications := v1.Group("/notifications")
+		notifications.Use(r.authMiddleware.AuthMiddleware())
+		{
+			notifications.GET("", r.notificationController.GetMyNotifications)
+			notifications.PATCH("/:id/read", r.notificationController.MarkAsRead)
+		}
+
+		// Calendar feed routes : le téléchargement du flux est authentifié par le jeton de l'URL
+		calendarFeeds := v1.Group("/calendar/feeds")
+		{
+			calendarFeeds.GET("/:token", r.calendarFeedController.GetFeed)
+			calendarFeeds.GET("", r.authMiddleware.AuthMiddleware(), r.calendarFeedController.GetMyFeeds)
+			calendarFeeds.POST("", r.authMiddleware.AuthMiddleware(), r.calendarFeedController.CreateFeed)
+			calendarFeeds.DELETE("/:id", r.authMiddleware.AuthMiddleware(), r.calendarFeedController.RevokeFeed)
 		}
 
 		// Admin absence routes (admin authentication required)
 		adminAbsences := v1.Group("/admin/absences")
 		adminAbsences.Use(r.authMiddleware.AuthMiddleware())
-		adminAbsences.Use(r.authMiddleware.RoleMiddleware("admin"))
+		adminAbsences.Use(r.authMiddleware.RequirePermission(models.PermAbsenceReadAny))
 		{
 			adminAbsences.GET("", r.absenceController.GetAllAbsences)
 		}
@@ -218,35 +398,38 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		// Admin presence routes (admin authentication required)
 		adminPresences := v1.Group("/admin/presences")
 		adminPresences.Use(r.authMiddleware.AuthMiddleware())
-		adminPresences.Use(r.authMiddleware.RoleMiddleware("admin"))
+		adminPresences.Use(r.authMiddleware.RequirePermission(models.PermPresenceReadAny))
 		{
 			adminPresences.GET("", r.presenceController.GetPresencesWithFilters)
 		}
 
 		// Routes de suppression sécurisées
-		deletionController := controllers.NewDeletionController(services.NewDeletionService(
-			repositories.NewUserRepository(),
-			repositories.NewCourseRepository(database.GetDB()),
-			repositories.NewRoomRepository(database.GetDB()),
-			repositories.NewSubjectRepository(),
-		))
 
-		// Suppression d'utilisateurs (Admin/Super Admin seulement)
-		v1.DELETE("/admin/users/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.CanDeleteMiddleware("user"), r.auditMiddleware.AuditMiddleware("delete", "user"), deletionController.DeleteUser)
+		// Suppression d'utilisateurs (Admin/Super Admin par défaut, selon le rôle du compte supprimé)
+		v1.DELETE("/admin/users/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.RequirePermission(models.UserPermissions(models.UserActionDelete)...), r.authMiddleware.CanDeleteMiddleware("user"), r.auditMiddleware.AuditMiddleware("delete", "user"), r.deletionController.DeleteUser)
 
-		// Suppression de salles (Admin/Super Admin seulement)
-		v1.DELETE("/admin/rooms/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.CanDeleteMiddleware("room"), r.auditMiddleware.AuditMiddleware("delete", "room"), deletionController.DeleteRoom)
+		// Suppression de salles (Admin/Super Admin par défaut)
+		v1.DELETE("/admin/rooms/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.RequirePermission(models.PermRoomDeleteAny), r.authMiddleware.CanDeleteMiddleware("room"), r.auditMiddleware.AuditMiddleware("delete", "room"), r.deletionController.DeleteRoom)
 
-		// Suppression de matières (Admin/Super Admin seulement)
-		v1.DELETE("/admin/subjects/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.CanDeleteMiddleware("subject"), r.auditMiddleware.AuditMiddleware("delete", "subject"), deletionController.DeleteSubject)
+		// Suppression de matières (Admin/Super Admin par défaut)
+		v1.DELETE("/admin/subjects/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.RequirePermission(models.PermSubjectDeleteAny), r.authMiddleware.CanDeleteMiddleware("subject"), r.auditMiddleware.AuditMiddleware("delete", "subject"), r.deletionController.DeleteSubject)
 
-		// Suppression de cours (Admin/Super Admin seulement)
-		v1.DELETE("/admin/courses/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.CanDeleteMiddleware("course"), r.auditMiddleware.AuditMiddleware("delete", "course"), deletionController.DeleteCourse)
+		// Suppression de cours (Admin/Super Admin par défaut)
+		v1.DELETE("/admin/courses/:id", r.authMiddleware.AuthMiddleware(), r.authMiddleware.RequirePermission(models.PermCourseDeleteAny), r.authMiddleware.CanDeleteMiddleware("course"), r.auditMiddleware.AuditMiddleware("delete", "course"), r.deletionController.DeleteCourse)
+
+		// Permission routes (Super Admin only by default)
+		permissions := v1.Group("/admin/permissions")
+		permissions.Use(r.authMiddleware.AuthMiddleware())
+		permissions.Use(r.authMiddleware.RequirePermission(models.PermPermissionManageAny))
+		{
+			permissions.GET("", r.permissionController.GetRegistry)
+			permissions.PUT("/roles/:role", r.auditMiddleware.AuditMiddleware("update", "permission"), r.permissionController.UpdateRolePermissions)
+		}
 
 		// Audit Log routes (Admin/Super Admin only)
 		auditLogs := v1.Group("/admin/audit-logs")
 		auditLogs.Use(r.authMiddleware.AuthMiddleware())
-		auditLogs.Use(r.authMiddleware.RoleMiddleware("admin"))
+		auditLogs.Use(r.authMiddleware.RequirePermission(models.PermAuditLogReadAny))
 		{
 			auditLogs.GET("", r.auditLogController.GetAuditLogs)
 			auditLogs.GET("/stats", r.auditLogController.GetAuditLogStats)
@@ -0,0 +1,466 @@
+// Package scheduler construit un emploi du temps hebdomadaire sans conflit à partir
+// de contraintes (volumes horaires, disponibilités des enseignants, groupes, salles).
+//
+// Le solveur ne dépend ni de la base de données ni de l'heure courante : pour un même
+// problème il produit toujours la même solution, ce qui permet de le tester hors ligne.
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Window est un intervalle horaire dans une journée de la semaine.
+// Start et End sont exprimés en minutes depuis minuit, End exclu.
+type Window struct {
+	Day   time.Weekday
+	Start int
+	End   int
+}
+
+// Contains indique si l'intervalle [start, end) du jour day est entièrement inclus dans la fenêtre
+func (w Window) Contains(day time.Weekday, start, end int) bool {
+	return w.Day == day && w.Start <= start && end <= w.End
+}
+
+// Overlaps indique si l'intervalle [start, end) du jour day chevauche la fenêtre
+func (w Window) Overlaps(day time.Weekday, start, end int) bool {
+	return w.Day == day && w.Start < end && start < w.End
+}
+
+// Teacher décrit un enseignant et ses disponibilités hebdomadaires.
+// Sans disponibilité déclarée, l'enseignant est considéré disponible sur toute la grille.
+type Teacher struct {
+	ID           uint
+	Name         string
+	Availability []Window
+}
+
+// Room décrit une salle. Une capacité nulle signifie « capacité inconnue » et n'est pas contrôlée.
+type Room struct {
+	ID       uint
+	Name     string
+	Capacity int
+}
+
+// Group décrit un groupe d'étudiants
+type Group struct {
+	ID   uint
+	Name string
+	Size int
+}
+
+// Requirement est un volume horaire hebdomadaire à placer pour une matière, un groupe et un enseignant
+type Requirement struct {
+	SubjectID       uint
+	SubjectName     string
+	GroupID         uint
+	TeacherID       uint
+	WeeklyMinutes   int
+	SessionDuration int // Durée d'une séance en minutes (la dernière séance peut être plus courte)
+}
+
+// Busy est un créneau déjà occupé (cours existant) pour un enseignant, un groupe ou une salle
+type Busy struct {
+	TeacherID uint
+	GroupID   uint
+	RoomID    uint
+	Window    Window
+}
+
+// Problem regroupe toutes les données d'entrée du solveur
+type Problem struct {
+	Days         []time.Weekday
+	DayStart     int // Début de journée, en minutes depuis minuit
+	DayEnd       int // Fin de journée, en minutes depuis minuit
+	Step         int // Pas entre deux horaires de début candidats, en minutes
+	Teachers     []Teacher
+	Rooms        []Room
+	Groups       []Group
+	Requirements []Requirement
+	Busy         []Busy
+}
+
+// Assignment est une séance placée dans la grille hebdomadaire
+type Assignment struct {
+	Requirement int // Index de l'exigence dans Problem.Requirements
+	SubjectID   uint
+	TeacherID   uint
+	GroupID     uint
+	RoomID      uint
+	Window      Window
+}
+
+// Unsatisfied décrit un volume horaire qui n'a pas pu être placé, et pourquoi
+type Unsatisfied struct {
+	Requirement    int
+	SubjectID      uint
+	TeacherID      uint
+	GroupID        uint
+	MissingMinutes int
+	Reason         string
+}
+
+// Solution est le résultat du solveur
+type Solution struct {
+	Assignments []Assignment
+	Unsatisfied []Unsatisfied
+}
+
+// Validate vérifie la cohérence du problème avant résolution
+func (p *Problem) Validate() error {
+	if len(p.Days) == 0 {
+		return fmt.Errorf("aucun jour ouvré défini")
+	}
+	if p.DayStart < 0 || p.DayEnd > 24*60 || p.DayStart >= p.DayEnd {
+		return fmt.Errorf("plage horaire de la journée invalide")
+	}
+	if p.Step <= 0 {
+		return fmt.Errorf("le pas de la grille doit être positif")
+	}
+
+	teachers := p.teacherIndex()
+	groups := p.groupIndex()
+	for i, req := range p.Requirements {
+		if req.WeeklyMinutes <= 0 {
+			return fmt.Errorf("exigence %d : le volume horaire doit être positif", i+1)
+		}
+		if req.SessionDuration <= 0 {
+			return fmt.Errorf("exigence %d : la durée de séance doit être positive", i+1)
+		}
+		if _, ok := teachers[req.TeacherID]; !ok {
+			return fmt.Errorf("exigence %d : enseignant %d inconnu", i+1, req.TeacherID)
+		}
+		if _, ok := groups[req.GroupID]; !ok {
+			return fmt.Errorf("exigence %d : groupe %d inconnu", i+1, req.GroupID)
+		}
+	}
+
+	return nil
+}
+
+// Solve place les séances de chaque exigence dans la grille hebdomadaire.
+//
+// L'algorithme est glouton et déterministe : les exigences les plus contraintes
+// (enseignant le moins disponible, puis volume le plus important) sont placées en premier,
+// chaque séance prend le premier créneau libre de la grille (jours dans l'ordre de Days,
+// puis horaires croissants) en évitant si possible deux séances de la même exigence
+// le même jour, et la plus petite salle suffisante (puis l'ID le plus petit) est retenue.
+func Solve(p Problem) (*Solution, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := newState(&p)
+	solution := &Solution{}
+
+	for _, index := range s.orderRequirements() {
+		req := p.Requirements[index]
+		usedDays := map[time.Weekday]bool{}
+
+		for remaining := req.WeeklyMinutes; remaining > 0; {
+			duration := req.SessionDuration
+			if remaining < duration {
+				duration = remaining
+			}
+
+			assignment, ok := s.place(index, duration, usedDays, true)
+			if !ok {
+				assignment, ok = s.place(index, duration, usedDays, false)
+			}
+			if !ok {
+				solution.Unsatisfied = append(solution.Unsatisfied, Unsatisfied{
+					Requirement:    index,
+					SubjectID:      req.SubjectID,
+					TeacherID:      req.TeacherID,
+					GroupID:        req.GroupID,
+					MissingMinutes: remaining,
+					Reason:         s.explain(index, duration),
+				})
+				break
+			}
+
+			s.reserve(assignment)
+			usedDays[assignment.Window.Day] = true
+			solution.Assignments = append(solution.Assignments, assignment)
+			remaining -= duration
+		}
+	}
+
+	sort.SliceStable(solution.Assignments, func(i, j int) bool {
+		a, b := solution.Assignments[i].Window, solution.Assignments[j].Window
+		if s.dayRank[a.Day] != s.dayRank[b.Day] {
+			return s.dayRank[a.Day] < s.dayRank[b.Day]
+		}
+		return a.Start < b.Start
+	})
+	sort.SliceStable(solution.Unsatisfied, func(i, j int) bool {
+		return solution.Unsatisfied[i].Requirement < solution.Unsatisfied[j].Requirement
+	})
+
+	return solution, nil
+}
+
+// state conserve l'occupation de la grille pendant la résolution
+type state struct {
+	problem  *Problem
+	teachers map[uint]Teacher
+	groups   map[uint]Group
+	rooms    []Room
+	dayRank  map[time.Weekday]int
+
+	teacherBusy map[uint][]Window
+	groupBusy   map[uint][]Window
+	roomBusy    map[uint][]Window
+}
+
+func newState(p *Problem) *state {
+	s := &state{
+		problem:     p,
+		teachers:    p.teacherIndex(),
+		groups:      p.groupIndex(),
+		dayRank:     map[time.Weekday]int{},
+		teacherBusy: map[uint][]Window{},
+		groupBusy:   map[uint][]Window{},
+		roomBusy:    map[uint][]Window{},
+	}
+
+	for i, day := range p.Days {
+		if _, ok := s.dayRank[day]; !ok {
+			s.dayRank[day] = i
+		}
+	}
+
+	// Les plus petites salles d'abord pour garder les grandes salles disponibles
+	s.rooms = append([]Room(nil), p.Rooms...)
+	sort.SliceStable(s.rooms, func(i, j int) bool {
+		if s.rooms[i].Capacity != s.rooms[j].Capacity {
+			return s.rooms[i].Capacity < s.rooms[j].Capacity
+		}
+		return s.rooms[i].ID < s.rooms[j].ID
+	})
+
+	for _, busy := range p.Busy {
+		if busy.TeacherID != 0 {
+			s.teacherBusy[busy.TeacherID] = append(s.teacherBusy[busy.TeacherID], busy.Window)
+		}
+		if busy.GroupID != 0 {
+			s.groupBusy[busy.GroupID] = append(s.groupBusy[busy.GroupID], busy.Window)
+		}
+		if busy.RoomID != 0 {
+			s.roomBusy[busy.RoomID] = append(s.roomBusy[busy.RoomID], busy.Window)
+		}
+	}
+
+	return s
+}
+
+// orderRequirements trie les exigences de la plus contrainte à la moins contrainte
+func (s *state) orderRequirements() []int {
+	order := make([]int, len(s.problem.Requirements))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := s.problem.Requirements[order[i]], s.problem.Requirements[order[j]]
+		availA, availB := s.availableMinutes(a.TeacherID), s.availableMinutes(b.TeacherID)
+		if availA != availB {
+			return availA < availB
+		}
+		return a.WeeklyMinutes > b.WeeklyMinutes
+	})
+
+	return order
+}
+
+// availableMinutes calcule le nombre de minutes de disponibilité d'un enseignant dans la grille
+func (s *state) availableMinutes(teacherID uint) int {
+	teacher := s.teachers[teacherID]
+	if len(teacher.Availability) == 0 {
+		return len(s.problem.Days) * (s.problem.DayEnd - s.problem.DayStart)
+	}
+
+	total := 0
+	for _, window := range teacher.Availability {
+		if _, ok := s.dayRank[window.Day]; !ok {
+			continue
+		}
+		start, end := max(window.Start, s.problem.DayStart), min(window.End, s.problem.DayEnd)
+		if end > start {
+			total += end - start
+		}
+	}
+	return total
+}
+
+// candidates énumère les créneaux de la grille dans l'ordre de préférence
+func (s *state) candidates(duration int, visit func(window Window) bool) {
+	for _, day := range s.problem.Days {
+		for start := s.problem.DayStart; start+duration <= s.problem.DayEnd; start += s.problem.Step {
+			if !visit(Window{Day: day, Start: start, End: start + duration}) {
+				return
+			}
+		}
+	}
+}
+
+// place cherche le premier créneau et la première salle compatibles pour une séance
+func (s *state) place(index, duration int, usedDays map[time.Weekday]bool, spread bool) (Assignment, bool) {
+	req := s.problem.Requirements[index]
+	var result Assignment
+	found := false
+
+	s.candidates(duration, func(window Window) bool {
+		if spread && usedDays[window.Day] {
+			return true
+		}
+		if !s.teacherAvailable(req.TeacherID, window) ||
+			overlapsAny(s.teacherBusy[req.TeacherID], window) ||
+			overlapsAny(s.groupBusy[req.GroupID], window) {
+			return true
+		}
+
+		room, ok := s.freeRoom(req.GroupID, window)
+		if !ok {
+			return true
+		}
+
+		result = Assignment{
+			Requirement: index,
+			SubjectID:   req.SubjectID,
+			TeacherID:   req.TeacherID,
+			GroupID:     req.GroupID,
+			RoomID:      room.ID,
+			Window:      window,
+		}
+		found = true
+		return false
+	})
+
+	return result, found
+}
+
+// reserve marque le créneau comme occupé pour l'enseignant, le groupe et la salle
+func (s *state) reserve(a Assignment) {
+	s.teacherBusy[a.TeacherID] = append(s.teacherBusy[a.TeacherID], a.Window)
+	s.groupBusy[a.GroupID] = append(s.groupBusy[a.GroupID], a.Window)
+	s.roomBusy[a.RoomID] = append(s.roomBusy[a.RoomID], a.Window)
+}
+
+// teacherAvailable vérifie que le créneau est inclus dans une disponibilité de l'enseignant
+func (s *state) teacherAvailable(teacherID uint, window Window) bool {
+	teacher := s.teachers[teacherID]
+	if len(teacher.Availability) == 0 {
+		return true
+	}
+	for _, available := range teacher.Availability {
+		if available.Contains(window.Day, window.Start, window.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// roomFits indique si la salle peut accueillir le groupe
+func (s *state) roomFits(room Room, groupID uint) bool {
+	return room.Capacity == 0 || room.Capacity >= s.groups[groupID].Size
+}
+
+// freeRoom retourne la première salle suffisante et libre sur le créneau
+func (s *state) freeRoom(groupID uint, window Window) (Room, bool) {
+	for _, room := range s.rooms {
+		if s.roomFits(room, groupID) && !overlapsAny(s.roomBusy[room.ID], window) {
+			return room, true
+		}
+	}
+	return Room{}, false
+}
+
+// explain détermine pourquoi une séance n'a pas pu être placée
+func (s *state) explain(index, duration int) string {
+	req := s.problem.Requirements[index]
+	teacher := s.teachers[req.TeacherID]
+	group := s.groups[req.GroupID]
+
+	if len(s.rooms) == 0 {
+		return "aucune salle disponible pour la génération"
+	}
+
+	fitting := false
+	for _, room := range s.rooms {
+		if s.roomFits(room, req.GroupID) {
+			fitting = true
+			break
+		}
+	}
+	if !fitting {
+		return fmt.Sprintf("aucune salle ne peut accueillir les %d étudiants du groupe %s", group.Size, group.Name)
+	}
+
+	var unavailable, teacherBusy, groupBusy, roomsBusy, total int
+	s.candidates(duration, func(window Window) bool {
+		total++
+		switch {
+		case !s.teacherAvailable(req.TeacherID, window):
+			unavailable++
+		case overlapsAny(s.teacherBusy[req.TeacherID], window):
+			teacherBusy++
+		case overlapsAny(s.groupBusy[req.GroupID], window):
+			groupBusy++
+		default:
+			roomsBusy++
+		}
+		return true
+	})
+
+	if total == 0 {
+		return fmt.Sprintf("la séance de %d minutes ne tient pas dans la journée", duration)
+	}
+	if unavailable == total {
+		return fmt.Sprintf("l'enseignant %s n'a aucune disponibilité compatible avec une séance de %d minutes", teacher.Name, duration)
+	}
+
+	var details []string
+	if unavailable > 0 {
+		details = append(details, fmt.Sprintf("enseignant indisponible sur %d créneau(x)", unavailable))
+	}
+	if teacherBusy > 0 {
+		details = append(details, fmt.Sprintf("enseignant %s déjà occupé sur %d créneau(x)", teacher.Name, teacherBusy))
+	}
+	if groupBusy > 0 {
+		details = append(details, fmt.Sprintf("groupe %s déjà occupé sur %d créneau(x)", group.Name, groupBusy))
+	}
+	if roomsBusy > 0 {
+		details = append(details, fmt.Sprintf("aucune salle libre sur %d créneau(x)", roomsBusy))
+	}
+
+	return "aucun créneau libre : " + strings.Join(details, ", ")
+}
+
+func (p *Problem) teacherIndex() map[uint]Teacher {
+	index := make(map[uint]Teacher, len(p.Teachers))
+	for _, teacher := range p.Teachers {
+		index[teacher.ID] = teacher
+	}
+	return index
+}
+
+func (p *Problem) groupIndex() map[uint]Group {
+	index := make(map[uint]Group, len(p.Groups))
+	for _, group := range p.Groups {
+		index[group.ID] = group
+	}
+	return index
+}
+
+func overlapsAny(windows []Window, window Window) bool {
+	for _, w := range windows {
+		if w.Overlaps(window.Day, window.Start, window.End) {
+			return true
+		}
+	}
+	return false
+}
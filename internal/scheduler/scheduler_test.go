@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func baseProblem() Problem {
+	return Problem{
+		Days:     []time.Weekday{time.Monday, time.Tuesday},
+		DayStart: 8 * 60,
+		DayEnd:   12 * 60,
+		Step:     60,
+		Teachers: []Teacher{{ID: 1, Name: "Alice Martin"}, {ID: 2, Name: "Bob Durand"}},
+		Rooms:    []Room{{ID: 10, Name: "A101", Capacity: 30}, {ID: 11, Name: "Amphi", Capacity: 120}},
+		Groups:   []Group{{ID: 100, Name: "L1", Size: 25}, {ID: 101, Name: "L2", Size: 80}},
+	}
+}
+
+func assertNoConflict(t *testing.T, assignments []Assignment) {
+	for i := range assignments {
+		for j := i + 1; j < len(assignments); j++ {
+			a, b := assignments[i], assignments[j]
+			if !a.Window.Overlaps(b.Window.Day, b.Window.Start, b.Window.End) {
+				continue
+			}
+			assert.NotEqual(t, a.TeacherID, b.TeacherID, "enseignant en double")
+			assert.NotEqual(t, a.GroupID, b.GroupID, "groupe en double")
+			assert.NotEqual(t, a.RoomID, b.RoomID, "salle en double")
+		}
+	}
+}
+
+func TestSolve_PlacesAllSessionsWithoutConflict(t *testing.T) {
+	p := baseProblem()
+	p.Requirements = []Requirement{
+		{SubjectID: 1, GroupID: 100, TeacherID: 1, WeeklyMinutes: 240, SessionDuration: 120},
+		{SubjectID: 2, GroupID: 100, TeacherID: 2, WeeklyMinutes: 120, SessionDuration: 60},
+		{SubjectID: 3, GroupID: 101, TeacherID: 1, WeeklyMinutes: 120, SessionDuration: 120},
+	}
+
+	solution, err := Solve(p)
+	assert.NoError(t, err)
+	assert.Empty(t, solution.Unsatisfied)
+
+	total := 0
+	for _, a := range solution.Assignments {
+		total += a.Window.End - a.Window.Start
+	}
+	assert.Equal(t, 480, total)
+	assertNoConflict(t, solution.Assignments)
+}
+
+func TestSolve_SpreadsSessionsAcrossDays(t *testing.T) {
+	p := baseProblem()
+	p.Requirements = []Requirement{
+		{SubjectID: 1, GroupID: 100, TeacherID: 1, WeeklyMinutes: 120, SessionDuration: 60},
+	}
+
+	solution, err := Solve(p)
+	assert.NoError(t, err)
+	assert.Len(t, solution.Assignments, 2)
+	assert.Equal(t, time.Monday, solution.Assignments[0].Window.Day)
+	assert.Equal(t, time.Tuesday, solution.Assignments[1].Window.Day)
+}
+
+func TestSolve_RespectsAvailabilityAndCapacity(t *testing.T) {
+	p := baseProblem()
+	p.Teachers[0].Availability = []Window{{Day: time.Tuesday, Start: 10 * 60, End: 12 * 60}}
+	p.Requirements = []Requirement{
+		{SubjectID: 1, GroupID: 101, TeacherID: 1, WeeklyMinutes: 120, SessionDuration: 120},
+	}
+
+	solution, err := Solve(p)
+	assert.NoError(t, err)
+	assert.Empty(t, solution.Unsatisfied)
+	assert.Len(t, solution.Assignments, 1)
+
+	a := solution.Assignments[0]
+	assert.Equal(t, Window{Day: time.Tuesday, Start: 10 * 60, End: 12 * 60}, a.Window)
+	assert.Equal(t, uint(11), a.RoomID) // Seul l'amphi accueille 80 étudiants
+}
+
+func TestSolve_AvoidsExistingCourses(t *testing.T) {
+	p := baseProblem()
+	p.Days = []time.Weekday{time.Monday}
+	p.Busy = []Busy{{GroupID: 100, Window: Window{Day: time.Monday, Start: 8 * 60, End: 10 * 60}}}
+	p.Requirements = []Requirement{
+		{SubjectID: 1, GroupID: 100, TeacherID: 1, WeeklyMinutes: 60, SessionDuration: 60},
+	}
+
+	solution, err := Solve(p)
+	assert.NoError(t, err)
+	assert.Len(t, solution.Assignments, 1)
+	assert.Equal(t, 10*60, solution.Assignments[0].Window.Start)
+}
+
+func TestSolve_ExplainsUnsatisfiedConstraints(t *testing.T) {
+	t.Run("RoomTooSmall", func(t *testing.T) {
+		p := baseProblem()
+		p.Groups[1].Size = 200
+		p.Requirements = []Requirement{
+			{SubjectID: 1, GroupID: 101, TeacherID: 1, WeeklyMinutes: 60, SessionDuration: 60},
+		}
+
+		solution, err := Solve(p)
+		assert.NoError(t, err)
+		assert.Len(t, solution.Unsatisfied, 1)
+		assert.Equal(t, 60, solution.Unsatisfied[0].MissingMinutes)
+		assert.Contains(t, solution.Unsatisfied[0].Reason, "200 étudiants")
+	})
+
+	t.Run("TeacherUnavailable", func(t *testing.T) {
+		p := baseProblem()
+		p.Teachers[1].Availability = []Window{{Day: time.Friday, Start: 8 * 60, End: 12 * 60}}
+		p.Requirements = []Requirement{
+			{SubjectID: 1, GroupID: 100, TeacherID: 2, WeeklyMinutes: 60, SessionDuration: 60},
+		}
+
+		solution, err := Solve(p)
+		assert.NoError(t, err)
+		assert.Len(t, solution.Unsatisfied, 1)
+		assert.Contains(t, solution.Unsatisfied[0].Reason, "Bob Durand n'a aucune disponibilité")
+	})
+
+	t.Run("GridFull", func(t *testing.T) {
+		p := baseProblem()
+		p.Days = []time.Weekday{time.Monday}
+		p.Requirements = []Requirement{
+			{SubjectID: 1, GroupID: 100, TeacherID: 1, WeeklyMinutes: 300, SessionDuration: 60},
+		}
+
+		solution, err := Solve(p)
+		assert.NoError(t, err)
+		assert.Len(t, solution.Assignments, 4)
+		assert.Len(t, solution.Unsatisfied, 1)
+		assert.Equal(t, 60, solution.Unsatisfied[0].MissingMinutes)
+		assert.True(t, strings.HasPrefix(solution.Unsatisfied[0].Reason, "aucun créneau libre"))
+	})
+}
+
+func TestSolve_IsDeterministic(t *testing.T) {
+	p := baseProblem()
+	p.Requirements = []Requirement{
+		{SubjectID: 1, GroupID: 100, TeacherID: 1, WeeklyMinutes: 180, SessionDuration: 90},
+		{SubjectID: 2, GroupID: 101, TeacherID: 2, WeeklyMinutes: 120, SessionDuration: 60},
+		{SubjectID: 3, GroupID: 100, TeacherID: 2, WeeklyMinutes: 60, SessionDuration: 60},
+	}
+	p.Step = 30
+
+	first, err := Solve(p)
+	assert.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		again, err := Solve(p)
+		assert.NoError(t, err)
+		assert.True(t, reflect.DeepEqual(first, again))
+	}
+}
+
+func TestProblem_Validate(t *testing.T) {
+	p := baseProblem()
+	p.Requirements = []Requirement{{SubjectID: 1, GroupID: 999, TeacherID: 1, WeeklyMinutes: 60, SessionDuration: 60}}
+
+	_, err := Solve(p)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "groupe 999 inconnu")
+}
@@ -9,20 +9,23 @@ import (
 )
 
 type AbsenceService struct {
-	absenceRepo *repositories.AbsenceRepository
-	courseRepo  *repositories.CourseRepository
-	userRepo    *repositories.UserRepository
+	absenceRepo       *repositories.AbsenceRepository
+	courseRepo        *repositories.CourseRepository
+	userRepo          *repositories.UserRepository
+	permissionService *PermissionService
 }
 
 func NewAbsenceService(
 	absenceRepo *repositories.AbsenceRepository,
 	courseRepo *repositories.CourseRepository,
 	userRepo *repositories.UserRepository,
+	permissionService *PermissionService,
 ) *AbsenceService {
 	return &AbsenceService{
-		absenceRepo: absenceRepo,
-		courseRepo:  courseRepo,
-		userRepo:    userRepo,
+		absenceRepo:       absenceRepo,
+		courseRepo:        courseRepo,
+		userRepo:          userRepo,
+		permissionService: permissionService,
 	}
 }
 
@@ -43,6 +46,11 @@ func (s *AbsenceService) CreateAbsence(req *models.CreateAbsenceRequest, student
 		return nil, fmt.Errorf("cours non trouvé")
 	}
 
+	// Un cours annulé ou déplacé n'a pas eu lieu, il n'y a pas d'absence à justifier
+	if !course.IsActive() {
+		return nil, fmt.Errorf("ce cours a été annulé ou déplacé")
+	}
+
 	// Vérifier que le cours est passé
 	if course.StartTime.After(time.Now()) {
 		return nil, fmt.Errorf("vous ne pouvez justifier qu'un cours déjà passé")
@@ -140,7 +148,7 @@ func (s *AbsenceService) GetAbsencesByTeacher(teacherID uint, page, limit int, u
 // GetAllAbsences récupère toutes les absences (pour les admins)
 func (s *AbsenceService) GetAllAbsences(page, limit int, userRole string) ([]models.AbsenceResponse, int64, error) {
 	// Vérifier les permissions
-	if !s.isAdmin(userRole) {
+	if !s.permissionService.HasPermission(userRole, models.PermAbsenceReadAny) {
 		return nil, 0, fmt.Errorf("permissions insuffisantes")
 	}
 
@@ -227,104 +235,87 @@ func (s *AbsenceService) DeleteAbsence(id uint, userID uint, userRole string) er
 	return s.absenceRepo.DeleteAbsence(id)
 }
 
-// GetAbsenceStats récupère les statistiques des absences
+// GetAbsenceStats récupère les statistiques des absences : toutes les absences, celles des cours
+// de l'utilisateur, ou à défaut ses propres absences
 func (s *AbsenceService) GetAbsenceStats(userID uint, userRole string) (*models.AbsenceStatsResponse, error) {
-	switch userRole {
-	case models.RoleSuperAdmin, models.RoleAdmin:
+	switch {
+	case s.permissionService.HasPermission(userRole, models.PermAbsenceReadAny):
 		return s.absenceRepo.GetAbsenceStats()
-	case models.RoleProfesseur:
+	case s.permissionService.HasPermission(userRole, models.PermAbsenceReadOwn):
 		return s.absenceRepo.GetAbsenceStatsByTeacher(userID)
-	case models.RoleEtudiant:
-		return s.absenceRepo.GetAbsenceStatsByStudent(userID)
 	default:
-		return nil, fmt.Errorf("rôle non reconnu")
+		return s.absenceRepo.GetAbsenceStatsByStudent(userID)
 	}
 }
 
-// Méthodes de vérification des permissions
+// Méthodes de vérification des permissions. Un étudiant accède toujours à ses propres absences.
 
 func (s *AbsenceService) canViewAbsence(userID uint, userRole string, absence *models.Absence) bool {
-	// Super Admin et Admin peuvent voir toutes les absences
-	if s.isAdmin(userRole) {
+	// Par défaut, Super Admin et Admin peuvent voir toutes les absences
+	if s.permissionService.HasPermission(userRole, models.PermAbsenceReadAny) {
 		return true
 	}
 
-	// Professeur peut voir les absences de ses cours
-	if userRole == models.RoleProfesseur {
-		return absence.Course.TeacherID == userID
+	// Professeur peut voir les absences de ses cours (titulaire ou remplaçant)
+	if s.permissionService.HasPermission(userRole, models.PermAbsenceReadOwn) && absence.Course.HasTeachingRights(userID) {
+		return true
 	}
 
 	// Étudiant peut voir ses propres absences
-	if userRole == models.RoleEtudiant {
-		return absence.StudentID == userID
-	}
-
-	return false
+	return absence.StudentID == userID
 }
 
 func (s *AbsenceService) canViewStudentAbsences(userID uint, userRole string, studentID uint) bool {
-	// Super Admin et Admin peuvent voir toutes les absences
-	if s.isAdmin(userRole) {
+	// Par défaut, Super Admin et Admin peuvent voir toutes les absences
+	if s.permissionService.HasPermission(userRole, models.PermAbsenceReadAny) {
 		return true
 	}
 
-	// Étudiant peut voir ses propres absences
-	if userRole == models.RoleEtudiant {
-		return userID == studentID
-	}
-
 	// Professeur peut voir les absences de ses étudiants (via les cours)
 	// Cette logique est plus complexe et nécessiterait une vérification supplémentaire
-	return userRole == models.RoleProfesseur
+	if s.permissionService.HasPermission(userRole, models.PermAbsenceReadOwn) {
+		return true
+	}
+
+	// Étudiant peut voir ses propres absences
+	return userID == studentID
 }
 
 func (s *AbsenceService) canViewTeacherAbsences(userID uint, userRole string, teacherID uint) bool {
-	// Super Admin et Admin peuvent voir toutes les absences
-	if s.isAdmin(userRole) {
+	// Par défaut, Super Admin et Admin peuvent voir toutes les absences
+	if s.permissionService.HasPermission(userRole, models.PermAbsenceReadAny) {
 		return true
 	}
 
 	// Professeur peut voir les absences de ses propres cours
-	if userRole == models.RoleProfesseur {
-		return userID == teacherID
-	}
-
-	return false
+	return s.permissionService.HasPermission(userRole, models.PermAbsenceReadOwn) && userID == teacherID
 }
 
 func (s *AbsenceService) canReviewAbsence(reviewerID uint, reviewerRole string, absence *models.Absence) bool {
-	// Super Admin et Admin peuvent traiter toutes les absences
-	if s.isAdmin(reviewerRole) {
+	// Par défaut, Super Admin et Admin peuvent traiter toutes les absences
+	if s.permissionService.HasPermission(reviewerRole, models.PermAbsenceReviewAny) {
 		return true
 	}
 
-	// Professeur peut traiter les absences de ses cours
-	if reviewerRole == models.RoleProfesseur {
-		return absence.Course.TeacherID == reviewerID
+	// Professeur peut traiter les absences de ses cours (titulaire ou remplaçant)
+	if s.permissionService.HasPermission(reviewerRole, models.PermAbsenceReviewOwn) {
+		return absence.Course.HasTeachingRights(reviewerID)
 	}
 
 	return false
 }
 
 func (s *AbsenceService) canDeleteAbsence(userID uint, userRole string, absence *models.Absence) bool {
-	// Super Admin et Admin peuvent supprimer toutes les absences
-	if s.isAdmin(userRole) {
+	// Par défaut, Super Admin et Admin peuvent supprimer toutes les absences
+	if s.permissionService.HasPermission(userRole, models.PermAbsenceDeleteAny) {
 		return true
 	}
 
 	// Étudiant peut supprimer ses propres absences si elles sont encore en attente
-	if userRole == models.RoleEtudiant {
-		return absence.StudentID == userID && absence.Status == models.StatusPending
-	}
-
-	return false
+	return absence.StudentID == userID && absence.Status == models.StatusPending
 }
 
 func (s *AbsenceService) canUseFilters(userRole string) bool {
-	// Seuls les admins et professeurs peuvent utiliser les filtres avancés
-	return s.isAdmin(userRole) || userRole == models.RoleProfesseur
-}
-
-func (s *AbsenceService) isAdmin(userRole string) bool {
-	return userRole == models.RoleSuperAdmin || userRole == models.RoleAdmin
+	// Par défaut, seuls les admins et professeurs peuvent utiliser les filtres avancés
+	return s.permissionService.HasAnyPermission(userRole, models.PermAbsenceReadAny, models.PermAbsenceReadOwn)
 }
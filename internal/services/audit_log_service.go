@@ -4,6 +4,7 @@ import (
 	"eduqr-backend/internal/models"
 	"eduqr-backend/internal/repositories"
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -210,6 +211,57 @@ func (s *AuditLogService) LogLogout(userID uint, userEmail, userRole, ipAddress,
 	)
 }
 
+// LogPasswordReset logs a password reset through an e-mailed token
+func (s *AuditLogService) LogPasswordReset(userID uint, userEmail, userRole, ipAddress, userAgent string) error {
+	return s.LogUserAction(
+		userID,
+		userEmail,
+		userRole,
+		models.ActionPasswordReset,
+		models.ResourceUser,
+		&userID,
+		"Réinitialisation du mot de passe par e-mail",
+		ipAddress,
+		userAgent,
+		nil,
+		nil,
+	)
+}
+
+// LogAccountLocked logs the temporary lockout of an account after repeated login failures
+func (s *AuditLogService) LogAccountLocked(user *models.User, lockedUntil time.Time, ipAddress, userAgent string) error {
+	return s.LogUserAction(
+		user.ID,
+		user.Email,
+		user.Role,
+		models.ActionAccountLocked,
+		models.ResourceUser,
+		&user.ID,
+		fmt.Sprintf("Compte verrouillé jusqu'au %s après des échecs de connexion répétés", lockedUntil.Format("02/01/2006 15:04")),
+		ipAddress,
+		userAgent,
+		nil,
+		nil,
+	)
+}
+
+// LogAccountUnlocked logs the unlock of an account by an administrator
+func (s *AuditLogService) LogAccountUnlocked(adminID uint, adminEmail, adminRole string, user *models.User, ipAddress, userAgent string) error {
+	return s.LogUserAction(
+		adminID,
+		adminEmail,
+		adminRole,
+		models.ActionAccountUnlocked,
+		models.ResourceUser,
+		&user.ID,
+		fmt.Sprintf("Déverrouillage du compte %s", user.Email),
+		ipAddress,
+		userAgent,
+		nil,
+		nil,
+	)
+}
+
 // toAuditLogResponse converts AuditLog to AuditLogResponse
 func (s *AuditLogService) toAuditLogResponse(log *models.AuditLog) *models.AuditLogResponse {
 	return &models.AuditLogResponse{
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// refreshTokenSize est la taille en octets des jetons de rafraîchissement
+	refreshTokenSize = 32
+	// sessionTouchInterval limite l'écriture de la dernière activité d'une session à une fois par intervalle
+	sessionTouchInterval = time.Minute
+	// maxUserAgentLength correspond à la taille de la colonne user_agent des sessions
+	maxUserAgentLength = 500
+	// twoFactorChallengeTTL est la durée laissée pour saisir le code de double authentification
+	twoFactorChallengeTTL = 5 * time.Minute
+	// challengeSecretSuffix dérive la clé des jetons de seconde étape : ils ne sont pas des jetons d'accès
+	challengeSecretSuffix = ":2fa-challenge"
+)
+
+// ErrInvalidCredentials indique un identifiant inconnu ou un mot de passe incorrect
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrAccountDeactivated indique un compte désactivé, par exemple retiré de l'annuaire
+var ErrAccountDeactivated = errors.New("account deactivated")
+
+// TwoFactorRequiredError indique que le mot de passe est correct mais que la connexion
+// doit être confirmée par un code de double authentification, ou par un enrôlement si Setup est vrai
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	Setup          bool
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	if e.Setup {
+		return "two-factor authentication setup required"
+	}
+	return "two-factor authentication required"
+}
+
+type AuthService struct {
+	userRepo          *repositories.UserRepository
+	sessionRepo       *repositories.SessionRepository
+	loginThrottle     *LoginThrottleService
+	twoFactor         *TwoFactorService
+	directory         *LDAPService
+	jwtSecret         string
+	accessExpiration  time.Duration
+	refreshExpiration time.Duration
+}
+
+func NewAuthService(
+	userRepo *repositories.UserRepository,
+	sessionRepo *repositories.SessionRepository,
+	loginThrottle *LoginThrottleService,
+	twoFactor *TwoFactorService,
+	directory *LDAPService,
+	jwtSecret string,
+	accessExpiration time.Duration,
+	refreshExpiration time.Duration,
+) *AuthService {
+	return &AuthService{
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		loginThrottle:     loginThrottle,
+		twoFactor:         twoFactor,
+		directory:         directory,
+		jwtSecret:         jwtSecret,
+		accessExpiration:  accessExpiration,
+		refreshExpiration: refreshExpiration,
+	}
+}
+
+// Login vérifie les identifiants et ouvre une session pour le client.
+// Retourne une LoginThrottledError si le compte ou l'adresse IP a accumulé trop d'échecs,
+// et une TwoFactorRequiredError si la connexion doit être confirmée par la double authentification.
+func (s *AuthService) Login(req *models.LoginRequest, client models.SessionClient) (*models.AuthTokens, *models.UserResponse, error) {
+	if err := s.loginThrottle.CheckAllowed(req.Email, client.IPAddress); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.authenticate(req.Email, req.Password)
+	if err != nil {
+		// Un annuaire injoignable n'est pas un échec imputable au client
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return nil, nil, err
+		}
+		if err := s.loginThrottle.RecordFailure(req.Email, client.IPAddress, client.UserAgent); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidCredentials
+	}
+	if !user.IsActive() {
+		return nil, nil, ErrAccountDeactivated
+	}
+
+	// Les échecs ne sont effacés qu'une fois la seconde étape franchie : sinon chaque mot de passe
+	// correct permettrait de nouveaux essais de codes
+	if err := s.requireSecondFactor(user); err != nil {
+		return nil, nil, err
+	}
+
+	if req.Device != "" {
+		client.Device = req.Device
+	}
+	return s.completeLogin(user, client)
+}
+
+// LoginWithIdentity ouvre une session pour un utilisateur déjà authentifié par un fournisseur d'identité externe.
+// La double authentification EduQR s'applique comme pour une connexion par mot de passe.
+func (s *AuthService) LoginWithIdentity(user *models.User, client models.SessionClient) (*models.AuthTokens, *models.UserResponse, error) {
+	if !user.IsActive() {
+		return nil, nil, ErrAccountDeactivated
+	}
+	if err := s.requireSecondFactor(user); err != nil {
+		return nil, nil, err
+	}
+	return s.completeLogin(user, client)
+}
+
+// authenticate vérifie les identifiants d'une connexion par mot de passe. Lorsqu'un annuaire est configuré,
+// les comptes qui en proviennent et les adresses inconnues d'EduQR sont vérifiés auprès de l'annuaire ;
+// les autres comptes gardent leur mot de passe EduQR.
+func (s *AuthService) authenticate(email, password string) (*models.User, error) {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if s.directory.Enabled() && (user == nil || user.AuthProvider == models.AuthProviderLDAP) {
+		return s.directory.Authenticate(email, password)
+	}
+	if user == nil || !utils.CheckPassword(password, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// requireSecondFactor retourne une TwoFactorRequiredError si l'utilisateur a activé la double authentification
+// ou si son rôle l'impose
+func (s *AuthService) requireSecondFactor(user *models.User) error {
+	enabled, err := s.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled && !s.twoFactor.IsRequired(user.Role) {
+		return nil
+	}
+	challenge, err := utils.GenerateToken(user.ID, user.Email, user.Role, 0, s.jwtSecret+challengeSecretSuffix, twoFactorChallengeTTL)
+	if err != nil {
+		return err
+	}
+	return &TwoFactorRequiredError{ChallengeToken: challenge, Setup: !enabled}
+}
+
+// CompleteTwoFactorLogin termine une connexion avec un code TOTP ou un code de secours
+func (s *AuthService) CompleteTwoFactorLogin(req *models.TwoFactorChallengeRequest, client models.SessionClient) (*models.AuthTokens, *models.UserResponse, error) {
+	user, err := s.challengeUser(req.ChallengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.loginThrottle.CheckAllowed(user.Email, client.IPAddress); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.twoFactor.Verify(user.ID, req.Code); err != nil {
+		if err := s.loginThrottle.RecordFailure(user.Email, client.IPAddress, client.UserAgent); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, err
+	}
+
+	return s.completeLogin(user, client)
+}
+
+// BeginTwoFactorLoginSetup démarre l'enrôlement imposé par le rôle, pendant la connexion
+func (s *AuthService) BeginTwoFactorLoginSetup(challengeToken string) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.challengeUser(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.twoFactor.BeginSetup(user.ID)
+}
+
+// ConfirmTwoFactorLoginSetup active la double authentification avec un premier code et termine la connexion.
+// Retourne aussi les codes de secours, qui ne seront plus affichés.
+func (s *AuthService) ConfirmTwoFactorLoginSetup(req *models.TwoFactorChallengeRequest, client models.SessionClient) (*models.AuthTokens, *models.UserResponse, []string, error) {
+	user, err := s.challengeUser(req.ChallengeToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := s.loginThrottle.CheckAllowed(user.Email, client.IPAddress); err != nil {
+		return nil, nil, nil, err
+	}
+
+	codes, err := s.twoFactor.Enable(user.ID, req.Code)
+	if err != nil {
+		if err := s.loginThrottle.RecordFailure(user.Email, client.IPAddress, client.UserAgent); err != nil {
+			return nil, nil, nil, err
+		}
+		return nil, nil, nil, err
+	}
+
+	tokens, response, err := s.completeLogin(user, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tokens, response, codes, nil
+}
+
+// completeLogin efface les échecs de connexion du compte et ouvre la session
+func (s *AuthService) completeLogin(user *models.User, client models.SessionClient) (*models.AuthTokens, *models.UserResponse, error) {
+	if err := s.loginThrottle.RecordSuccess(user.Email); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.openSession(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response := models.UserToUserResponse(*user)
+	return tokens, &response, nil
+}
+
+// challengeUser retrouve l'utilisateur d'un jeton de seconde étape
+func (s *AuthService) challengeUser(challengeToken string) (*models.User, error) {
+	claims, err := utils.ValidateToken(challengeToken, s.jwtSecret+challengeSecretSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("jeton de double authentification invalide ou expiré")
+	}
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("utilisateur non trouvé")
+	}
+	if !user.IsActive() {
+		return nil, ErrAccountDeactivated
+	}
+	return user, nil
+}
+
+// Refresh échange un jeton de rafraîchissement contre un nouveau jeton d'accès et un nouveau jeton de rafraîchissement.
+// Présenter un jeton déjà utilisé révoque toute la session : il a pu être dérobé.
+func (s *AuthService) Refresh(refreshToken string, ipAddress string) (*models.AuthTokens, error) {
+	current, err := s.sessionRepo.GetRefreshTokenByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("jeton de rafraîchissement invalide")
+	}
+	if !current.Session.IsActive() || current.ExpiresAt.Before(time.Now()) {
+		return nil, fmt.Errorf("session expirée ou révoquée")
+	}
+	if current.UsedAt != nil {
+		if err := s.sessionRepo.RevokeSession(current.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("jeton de rafraîchissement déjà utilisé, la session a été révoquée")
+	}
+
+	// Le rôle peut avoir changé depuis la connexion : le jeton d'accès est émis d'après l'utilisateur actuel
+	user, err := s.userRepo.FindByID(current.Session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("utilisateur non trouvé")
+	}
+	if !user.IsActive() {
+		return nil, ErrAccountDeactivated
+	}
+
+	plain, next, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.RotateRefreshToken(current, next); err != nil {
+		if errors.Is(err, repositories.ErrRefreshTokenUsed) {
+			_ = s.sessionRepo.RevokeSession(current.SessionID)
+			return nil, fmt.Errorf("jeton de rafraîchissement déjà utilisé, la session a été révoquée")
+		}
+		return nil, err
+	}
+	if err := s.sessionRepo.TouchSession(current.SessionID, ipAddress, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, current.SessionID, plain)
+}
+
+// Logout ferme la session du jeton de rafraîchissement fourni, ou à défaut celle du jeton d'accès
+func (s *AuthService) Logout(userID, sessionID uint, refreshToken string) error {
+	if refreshToken != "" {
+		token, err := s.sessionRepo.GetRefreshTokenByHash(utils.HashToken(refreshToken))
+		if err != nil {
+			return fmt.Errorf("jeton de rafraîchissement invalide")
+		}
+		if token.Session.UserID != userID {
+			return fmt.Errorf("ce jeton n'appartient pas à l'utilisateur connecté")
+		}
+		sessionID = token.SessionID
+	}
+	if sessionID == 0 {
+		return fmt.Errorf("aucune session à fermer")
+	}
+
+	session, err := s.sessionRepo.GetSessionByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("session non trouvée")
+		}
+		return err
+	}
+	if session.UserID != userID {
+		return fmt.Errorf("cette session n'appartient pas à l'utilisateur connecté")
+	}
+
+	return s.sessionRepo.RevokeSession(sessionID)
+}
+
+// ValidateSession vérifie que la session d'un jeton d'accès est toujours ouverte et enregistre son activité
+func (s *AuthService) ValidateSession(sessionID uint, ipAddress string) error {
+	session, err := s.sessionRepo.GetSessionByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("session non trouvée")
+		}
+		return err
+	}
+	if !session.IsActive() {
+		return fmt.Errorf("session révoquée ou expirée")
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval || session.IPAddress != ipAddress {
+		if err := s.sessionRepo.TouchSession(session.ID, ipAddress, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUserSessions liste les sessions ouvertes d'un utilisateur en signalant la session courante
+func (s *AuthService) GetUserSessions(userID, currentSessionID uint) ([]models.SessionResponse, error) {
+	sessions, err := s.sessionRepo.GetActiveUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = models.SessionToSessionResponse(session, currentSessionID)
+	}
+	return responses, nil
+}
+
+// RevokeUserSession révoque une session d'un utilisateur.
+// Retourne gorm.ErrRecordNotFound si la session n'existe pas ou appartient à un autre utilisateur.
+func (s *AuthService) RevokeUserSession(userID, sessionID uint) error {
+	session, err := s.sessionRepo.GetSessionByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	return s.sessionRepo.RevokeSession(sessionID)
+}
+
+// UnlockAccount lève le verrouillage d'un compte après des échecs de connexion répétés
+func (s *AuthService) UnlockAccount(userID, adminID uint, adminEmail, adminRole, ipAddress, userAgent string) error {
+	return s.loginThrottle.UnlockAccount(userID, adminID, adminEmail, adminRole, ipAddress, userAgent)
+}
+
+// RevokeAllSessions révoque toutes les sessions d'un utilisateur et retourne leur nombre
+func (s *AuthService) RevokeAllSessions(userID uint) (int64, error) {
+	return s.sessionRepo.RevokeUserSessions(userID)
+}
+
+// openSession crée une session pour l'utilisateur et émet ses premiers jetons
+func (s *AuthService) openSession(user *models.User, client models.SessionClient) (*models.AuthTokens, error) {
+	plain, token, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	device := client.Device
+	if device == "" {
+		device = utils.DescribeUserAgent(client.UserAgent)
+	}
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session := &models.Session{
+		UserID:     user.ID,
+		Device:     device,
+		UserAgent:  userAgent,
+		IPAddress:  client.IPAddress,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  token.ExpiresAt,
+	}
+	if err := s.sessionRepo.CreateSession(session, token); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session.ID, plain)
+}
+
+// newRefreshToken génère un jeton de rafraîchissement ; seule son empreinte est conservée
+func (s *AuthService) newRefreshToken() (string, *models.RefreshToken, error) {
+	plain, err := utils.GenerateRandomToken(refreshTokenSize)
+	if err != nil {
+		return "", nil, err
+	}
+	return plain, &models.RefreshToken{
+		TokenHash: utils.HashToken(plain),
+		ExpiresAt: time.Now().Add(s.refreshExpiration),
+	}, nil
+}
+
+func (s *AuthService) issueTokens(user *models.User, sessionID uint, refreshToken string) (*models.AuthTokens, error) {
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role, sessionID, s.jwtSecret, s.accessExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessExpiration.Seconds()),
+		SessionID:    sessionID,
+	}, nil
+}
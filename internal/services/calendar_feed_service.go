@@ -0,0 +1,283 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/ical"
+	"eduqr-backend/pkg/utils"
+)
+
+const (
+	// feedHistory limite l'historique exporté dans les flux
+	feedHistory = 90 * 24 * time.Hour
+	// feedRefresh est l'intervalle de rafraîchissement suggéré aux applications d'agenda
+	feedRefresh = time.Hour
+)
+
+type CalendarFeedService struct {
+	feedRepo   *repositories.CalendarFeedRepository
+	courseRepo *repositories.CourseRepository
+	groupRepo  *repositories.GroupRepository
+	eventRepo  *repositories.EventRepository
+	userRepo   *repositories.UserRepository
+	roomRepo   *repositories.RoomRepository
+}
+
+func NewCalendarFeedService(
+	feedRepo *repositories.CalendarFeedRepository,
+	courseRepo *repositories.CourseRepository,
+	groupRepo *repositories.GroupRepository,
+	eventRepo *repositories.EventRepository,
+	userRepo *repositories.UserRepository,
+	roomRepo *repositories.RoomRepository,
+) *CalendarFeedService {
+	return &CalendarFeedService{
+		feedRepo:   feedRepo,
+		courseRepo: courseRepo,
+		groupRepo:  groupRepo,
+		eventRepo:  eventRepo,
+		userRepo:   userRepo,
+		roomRepo:   roomRepo,
+	}
+}
+
+// CreateFeed crée un jeton de flux et retourne le jeton en clair (il n'est plus récupérable ensuite)
+func (s *CalendarFeedService) CreateFeed(userID uint, req *models.CreateCalendarFeedRequest) (*models.CalendarFeedToken, string, error) {
+	targetID := req.TargetID
+
+	switch req.FeedType {
+	case models.CalendarFeedUser:
+		// Un utilisateur ne peut s'abonner qu'à son propre agenda
+		targetID = userID
+	case models.CalendarFeedRoom:
+		if _, err := s.roomRepo.GetRoomByID(targetID); err != nil {
+			return nil, "", fmt.Errorf("salle non trouvée")
+		}
+	case models.CalendarFeedGroup:
+		if _, err := s.groupRepo.GetGroupByID(targetID); err != nil {
+			return nil, "", fmt.Errorf("groupe non trouvé")
+		}
+	default:
+		return nil, "", fmt.Errorf("type de flux invalide")
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("erreur lors de la génération du jeton")
+	}
+
+	feed := &models.CalendarFeedToken{
+		OwnerID:   userID,
+		FeedType:  req.FeedType,
+		TargetID:  targetID,
+		TokenHash: utils.HashToken(token),
+	}
+	if err := s.feedRepo.CreateFeedToken(feed); err != nil {
+		return nil, "", fmt.Errorf("erreur lors de la création du flux: %v", err)
+	}
+
+	return feed, token, nil
+}
+
+// GetUserFeeds récupère les flux d'un utilisateur
+func (s *CalendarFeedService) GetUserFeeds(userID uint) ([]models.CalendarFeedResponse, error) {
+	feeds, err := s.feedRepo.GetFeedTokensByOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.CalendarFeedResponse, len(feeds))
+	for i, feed := range feeds {
+		responses[i] = feed.ToCalendarFeedResponse()
+	}
+	return responses, nil
+}
+
+// RevokeFeed révoque un flux de l'utilisateur
+func (s *CalendarFeedService) RevokeFeed(id, userID uint) error {
+	return s.feedRepo.RevokeFeedToken(id, userID)
+}
+
+// RenderFeed génère le calendrier iCalendar associé à un jeton
+func (s *CalendarFeedService) RenderFeed(token string) ([]byte, error) {
+	feed, err := s.feedRepo.GetFeedTokenByHash(utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-feedHistory)
+	calendar := &ical.Calendar{Refresh: feedRefresh}
+
+	switch feed.FeedType {
+	case models.CalendarFeedUser:
+		err = s.buildUserFeed(calendar, feed.TargetID, since)
+	case models.CalendarFeedRoom:
+		err = s.buildRoomFeed(calendar, feed.TargetID, since)
+	case models.CalendarFeedGroup:
+		err = s.buildGroupFeed(calendar, feed.TargetID, since)
+	default:
+		err = fmt.Errorf("type de flux invalide")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.feedRepo.TouchFeedToken(feed.ID); err != nil {
+		log.Printf("Erreur lors de la mise à jour du flux %d: %v", feed.ID, err)
+	}
+
+	return calendar.Bytes(), nil
+}
+
+// buildUserFeed ajoute au calendrier les cours d'un utilisateur selon son rôle et ses événements personnels
+func (s *CalendarFeedService) buildUserFeed(calendar *ical.Calendar, userID uint, since time.Time) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("utilisateur non trouvé")
+	}
+	calendar.Name = "EduQR - " + user.FirstName + " " + user.LastName
+
+	var courses []models.Course
+	switch user.Role {
+	case models.RoleProfesseur:
+		courses, err = s.courseRepo.GetCalendarCoursesByTeacher(userID, since)
+	case models.RoleEtudiant:
+		var groupIDs []uint
+		groupIDs, err = s.groupRepo.GetGroupIDsByStudent(userID)
+		if err == nil {
+			groupIDs, err = s.withAncestors(groupIDs)
+		}
+		if err == nil {
+			courses, err = s.courseRepo.GetCalendarCoursesByGroups(groupIDs, since)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	addCourseEvents(calendar, courses)
+
+	events, err := s.eventRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if event.EndTime.Before(since) {
+			continue
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:          fmt.Sprintf("event-%d@eduqr", event.ID),
+			Summary:      event.Title,
+			Description:  event.Description,
+			Start:        event.StartTime,
+			End:          event.EndTime,
+			LastModified: event.UpdatedAt,
+		})
+	}
+
+	return nil
+}
+
+// buildRoomFeed ajoute au calendrier les cours d'une salle
+func (s *CalendarFeedService) buildRoomFeed(calendar *ical.Calendar, roomID uint, since time.Time) error {
+	room, err := s.roomRepo.GetRoomByID(roomID)
+	if err != nil {
+		return fmt.Errorf("salle non trouvée")
+	}
+	calendar.Name = "EduQR - " + room.Name
+
+	courses, err := s.courseRepo.GetCalendarCoursesByRoom(roomID, since)
+	if err != nil {
+		return err
+	}
+	addCourseEvents(calendar, courses)
+	return nil
+}
+
+// buildGroupFeed ajoute au calendrier les cours d'un groupe
+func (s *CalendarFeedService) buildGroupFeed(calendar *ical.Calendar, groupID uint, since time.Time) error {
+	group, err := s.groupRepo.GetGroupByID(groupID)
+	if err != nil {
+		return fmt.Errorf("groupe non trouvé")
+	}
+	calendar.Name = "EduQR - " + group.Name
+
+	// Les cours des groupes parents (promotion) concernent aussi ce groupe
+	groupIDs, err := s.withAncestors([]uint{groupID})
+	if err != nil {
+		return err
+	}
+
+	courses, err := s.courseRepo.GetCalendarCoursesByGroups(groupIDs, since)
+	if err != nil {
+		return err
+	}
+	addCourseEvents(calendar, courses)
+	return nil
+}
+
+// withAncestors complète une liste de groupes avec leurs groupes parents
+func (s *CalendarFeedService) withAncestors(groupIDs []uint) ([]uint, error) {
+	ancestors, err := s.groupRepo.GetAncestorIDs(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	return append(groupIDs, ancestors...), nil
+}
+
+// addCourseEvents convertit des cours en VEVENT ; les cours annulés ou déplacés sont publiés en STATUS:CANCELLED
+func addCourseEvents(calendar *ical.Calendar, courses []models.Course) {
+	for _, course := range courses {
+		event := ical.Event{
+			UID:          fmt.Sprintf("course-%d@eduqr", course.ID),
+			Summary:      course.Name,
+			Start:        course.StartTime,
+			End:          course.EndTime,
+			Status:       ical.StatusConfirmed,
+			LastModified: course.UpdatedAt,
+		}
+		if course.Subject.Name != "" {
+			event.Categories = []string{course.Subject.Name}
+		}
+
+		location := course.Room.Name
+		if course.Room.Building != "" {
+			location += ", " + course.Room.Building
+		}
+		event.Location = location
+
+		var description []string
+		if course.Subject.Name != "" {
+			description = append(description, "Matière : "+course.Subject.Name)
+		}
+		teacher := course.Teacher
+		if course.SubstituteTeacher != nil {
+			teacher = *course.SubstituteTeacher
+		}
+		description = append(description, "Enseignant : "+teacher.FirstName+" "+teacher.LastName)
+		if len(course.Groups) > 0 {
+			names := make([]string, len(course.Groups))
+			for i, group := range course.Groups {
+				names[i] = group.Name
+			}
+			description = append(description, "Groupes : "+strings.Join(names, ", "))
+		}
+		if course.Description != "" {
+			description = append(description, course.Description)
+		}
+
+		if !course.IsActive() {
+			event.Status = ical.StatusCancelled
+			if course.StatusReason != "" {
+				description = append(description, "Motif : "+course.StatusReason)
+			}
+		}
+		event.Description = strings.Join(description, "\n")
+
+		calendar.Events = append(calendar.Events, event)
+	}
+}
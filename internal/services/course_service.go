@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"eduqr-backend/internal/models"
@@ -9,10 +11,13 @@ import (
 )
 
 type CourseService struct {
-	courseRepo  *repositories.CourseRepository
-	subjectRepo *repositories.SubjectRepository
-	userRepo    *repositories.UserRepository
-	roomRepo    *repositories.RoomRepository
+	courseRepo          *repositories.CourseRepository
+	subjectRepo         *repositories.SubjectRepository
+	userRepo            *repositories.UserRepository
+	roomRepo            *repositories.RoomRepository
+	groupRepo           *repositories.GroupRepository
+	availabilityRepo    *repositories.TeacherAvailabilityRepository
+	notificationService *NotificationService
 }
 
 func NewCourseService(
@@ -20,12 +25,18 @@ func NewCourseService(
 	subjectRepo *repositories.SubjectRepository,
 	userRepo *repositories.UserRepository,
 	roomRepo *repositories.RoomRepository,
+	groupRepo *repositories.GroupRepository,
+	availabilityRepo *repositories.TeacherAvailabilityRepository,
+	notificationService *NotificationService,
 ) *CourseService {
 	return &CourseService{
-		courseRepo:  courseRepo,
-		subjectRepo: subjectRepo,
-		userRepo:    userRepo,
-		roomRepo:    roomRepo,
+		courseRepo:          courseRepo,
+		subjectRepo:         subjectRepo,
+		userRepo:            userRepo,
+		roomRepo:            roomRepo,
+		groupRepo:           groupRepo,
+		availabilityRepo:    availabilityRepo,
+		notificationService: notificationService,
 	}
 }
 
@@ -72,12 +83,29 @@ func (s *CourseService) CreateCourse(req *models.CreateCourseRequest) (*models.C
 		return nil, fmt.Errorf("l'utilisateur sélectionné n'est pas un enseignant")
 	}
 
+	// Vérifier que l'enseignant est qualifié pour la matière
+	if err := checkTeacherQualified(s.subjectRepo, req.SubjectID, req.TeacherID); err != nil {
+		return nil, err
+	}
+
 	// Vérifier que la salle existe
 	_, err = s.roomRepo.GetRoomByID(req.RoomID)
 	if err != nil {
 		return nil, fmt.Errorf("salle non trouvée")
 	}
 
+	// Vérifier que les sous-salles à réunir sont voisines de la salle
+	mergedRooms, err := resolveMergedRooms(s.roomRepo, req.RoomID, req.MergedRoomIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Vérifier que les groupes existent
+	groups, err := s.resolveGroups(req.GroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Vérifier que la date de fin de récurrence est après la date de début
 	if req.IsRecurring && req.RecurrenceEndDate != nil {
 		if req.RecurrenceEndDate.Before(req.StartTime) || req.RecurrenceEndDate.Equal(req.StartTime) {
@@ -91,6 +119,7 @@ func (s *CourseService) CreateCourse(req *models.CreateCourseRequest) (*models.C
 		SubjectID:         req.SubjectID,
 		TeacherID:         req.TeacherID,
 		RoomID:            req.RoomID,
+		MergedRooms:       mergedRooms,
 		StartTime:         req.StartTime,
 		Duration:          req.Duration,
 		Description:       req.Description,
@@ -98,8 +127,22 @@ func (s *CourseService) CreateCourse(req *models.CreateCourseRequest) (*models.C
 		RecurrencePattern: req.RecurrencePattern,
 		RecurrenceEndDate: req.RecurrenceEndDate,
 		ExcludeHolidays:   req.ExcludeHolidays,
+		Groups:            groups,
+	}
+
+	// Vérifier les disponibilités de l'enseignant
+	warnings, err := s.checkTeacherSchedule(course)
+	if err != nil {
+		return nil, err
 	}
 
+	// Vérifier que la salle convient (capacité, équipements)
+	roomWarnings, err := s.roomSuitabilityWarnings(course)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, roomWarnings...)
+
 	// Si c'est un cours récurrent, générer les cours
 	if req.IsRecurring {
 		if err := s.courseRepo.CreateCourse(course); err != nil {
@@ -126,6 +169,7 @@ func (s *CourseService) CreateCourse(req *models.CreateCourseRequest) (*models.C
 	}
 
 	response := createdCourse.ToCourseResponse()
+	response.Warnings = warnings
 	return &response, nil
 }
 
@@ -159,6 +203,13 @@ func (s *CourseService) UpdateCourse(id uint, req *models.UpdateCourseRequest) (
 		course.TeacherID = req.TeacherID
 	}
 
+	// Revérifier la qualification de l'enseignant si la matière ou l'enseignant change
+	if req.SubjectID != 0 || req.TeacherID != 0 {
+		if err := checkTeacherQualified(s.subjectRepo, course.SubjectID, course.TeacherID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Vérifier que la salle existe si elle est modifiée
 	if req.RoomID != 0 {
 		_, err := s.roomRepo.GetRoomByID(req.RoomID)
@@ -168,6 +219,28 @@ func (s *CourseService) UpdateCourse(id uint, req *models.UpdateCourseRequest) (
 		course.RoomID = req.RoomID
 	}
 
+	// Revalider les sous-salles réunies si elles ou la salle principale changent
+	if req.MergedRoomIDs != nil || req.RoomID != 0 {
+		mergedRoomIDs := req.MergedRoomIDs
+		if mergedRoomIDs == nil {
+			mergedRoomIDs = course.RoomIDs()[1:]
+		}
+		mergedRooms, err := resolveMergedRooms(s.roomRepo, course.RoomID, mergedRoomIDs)
+		if err != nil {
+			return nil, err
+		}
+		course.MergedRooms = mergedRooms
+	}
+
+	// Vérifier que les groupes existent s'ils sont modifiés
+	if req.GroupIDs != nil {
+		groups, err := s.resolveGroups(req.GroupIDs)
+		if err != nil {
+			return nil, err
+		}
+		course.Groups = groups
+	}
+
 	// Mettre à jour les autres champs
 	if req.Name != "" {
 		course.Name = req.Name
@@ -192,6 +265,19 @@ func (s *CourseService) UpdateCourse(id uint, req *models.UpdateCourseRequest) (
 	}
 	course.ExcludeHolidays = req.ExcludeHolidays
 
+	// Vérifier les disponibilités de l'enseignant
+	warnings, err := s.checkTeacherSchedule(course)
+	if err != nil {
+		return nil, err
+	}
+
+	// Vérifier que la salle convient (capacité, équipements)
+	roomWarnings, err := s.roomSuitabilityWarnings(course)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, roomWarnings...)
+
 	// Si c'est un cours récurrent, supprimer tous les cours récurrents existants et les régénérer
 	if course.IsRecurring {
 		fmt.Printf("DEBUG: Cours récurrent détecté - ID: %d, RecurrenceID: %v, IsRecurring: %v\n", course.ID, course.RecurrenceID, course.IsRecurring)
@@ -223,6 +309,7 @@ func (s *CourseService) UpdateCourse(id uint, req *models.UpdateCourseRequest) (
 
 			// Le cours a été recréé avec un nouvel ID, retourner directement la réponse
 			response := course.ToCourseResponse()
+			response.Warnings = warnings
 			return &response, nil
 		} else {
 			fmt.Printf("DEBUG: Cours enfant récurrent - Modification interdite\n")
@@ -244,6 +331,7 @@ func (s *CourseService) UpdateCourse(id uint, req *models.UpdateCourseRequest) (
 	}
 
 	response := updatedCourse.ToCourseResponse()
+	response.Warnings = warnings
 	return &response, nil
 }
 
@@ -264,6 +352,215 @@ func (s *CourseService) DeleteCourse(id uint) error {
 	return s.courseRepo.DeleteCourse(id)
 }
 
+// CancelCourse annule un cours en conservant son enregistrement et notifie les participants
+func (s *CourseService) CancelCourse(id uint, req *models.CancelCourseRequest, userID uint) (*models.CourseResponse, error) {
+	course, err := s.courseRepo.GetCourseByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("cours avec l'ID %d non trouvé", id)
+	}
+
+	switch course.Status {
+	case models.CourseStatusCancelled:
+		return nil, fmt.Errorf("le cours est déjà annulé")
+	case models.CourseStatusRescheduled:
+		return nil, fmt.Errorf("le cours a été déplacé, annulez plutôt l'occurrence de remplacement")
+	case models.CourseStatusCompleted:
+		return nil, fmt.Errorf("impossible d'annuler un cours déjà effectué")
+	}
+
+	if err := s.courseRepo.UpdateCourseStatus(course, models.CourseStatusCancelled, req.Reason, userID); err != nil {
+		return nil, err
+	}
+
+	// Une notification manquée ne doit pas empêcher l'annulation
+	if err := s.notificationService.NotifyCourseCancelled(course, req.Reason); err != nil {
+		log.Printf("Erreur lors de l'envoi des notifications d'annulation du cours %d: %v", course.ID, err)
+	}
+
+	response := course.ToCourseResponse()
+	return &response, nil
+}
+
+// RescheduleCourse déplace un cours : l'occurrence de remplacement est créée et liée au cours d'origine
+func (s *CourseService) RescheduleCourse(id uint, req *models.RescheduleCourseRequest, userID uint) (*models.CourseResponse, error) {
+	original, err := s.courseRepo.GetCourseByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("cours avec l'ID %d non trouvé", id)
+	}
+
+	if !original.IsActive() {
+		return nil, fmt.Errorf("impossible de déplacer un cours annulé ou déjà déplacé")
+	}
+	if original.Status == models.CourseStatusCompleted {
+		return nil, fmt.Errorf("impossible de déplacer un cours déjà effectué")
+	}
+
+	replacement := &models.Course{
+		Name:        original.Name,
+		SubjectID:   original.SubjectID,
+		TeacherID:   original.TeacherID,
+		RoomID:      original.RoomID,
+		StartTime:   req.StartTime,
+		Duration:    original.Duration,
+		Description: original.Description,
+		Groups:      original.Groups,
+		MergedRooms: original.MergedRooms,
+	}
+	if req.Duration != 0 {
+		replacement.Duration = req.Duration
+	}
+	if req.RoomID != 0 {
+		if _, err := s.roomRepo.GetRoomByID(req.RoomID); err != nil {
+			return nil, fmt.Errorf("salle non trouvée")
+		}
+		replacement.RoomID = req.RoomID
+		// Les sous-salles réunies ne suivent pas un changement de salle
+		replacement.MergedRooms = nil
+	}
+
+	if err := s.courseRepo.RescheduleCourse(original, replacement, req.Reason, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.notificationService.NotifyCourseRescheduled(original, replacement, req.Reason); err != nil {
+		log.Printf("Erreur lors de l'envoi des notifications de report du cours %d: %v", original.ID, err)
+	}
+
+	createdCourse, err := s.courseRepo.GetCourseByID(replacement.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := createdCourse.ToCourseResponse()
+	return &response, nil
+}
+
+// CompleteCourse marque un cours terminé comme effectué
+func (s *CourseService) CompleteCourse(id uint, userID uint) (*models.CourseResponse, error) {
+	course, err := s.courseRepo.GetCourseByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("cours avec l'ID %d non trouvé", id)
+	}
+
+	if course.Status != models.CourseStatusScheduled {
+		return nil, fmt.Errorf("seul un cours prévu peut être marqué comme effectué")
+	}
+	if course.EndTime.After(time.Now()) {
+		return nil, fmt.Errorf("le cours n'est pas encore terminé")
+	}
+
+	if err := s.courseRepo.UpdateCourseStatus(course, models.CourseStatusCompleted, "", userID); err != nil {
+		return nil, err
+	}
+
+	response := course.ToCourseResponse()
+	return &response, nil
+}
+
+// AssignSubstitute affecte un enseignant remplaçant à une occurrence sans modifier le titulaire de la série
+func (s *CourseService) AssignSubstitute(id uint, req *models.AssignSubstituteRequest) (*models.CourseResponse, error) {
+	course, err := s.courseRepo.GetCourseByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("cours avec l'ID %d non trouvé", id)
+	}
+	if !course.IsActive() || course.Status == models.CourseStatusCompleted {
+		return nil, fmt.Errorf("seul un cours prévu peut recevoir un remplaçant")
+	}
+
+	substitute, err := s.userRepo.FindByID(req.TeacherID)
+	if err != nil {
+		return nil, fmt.Errorf("enseignant remplaçant non trouvé")
+	}
+	if substitute.Role != models.RoleProfesseur {
+		return nil, fmt.Errorf("l'utilisateur sélectionné n'est pas un enseignant")
+	}
+	if substitute.ID == course.TeacherID {
+		return nil, fmt.Errorf("le remplaçant doit être différent de l'enseignant titulaire")
+	}
+	if err := checkTeacherQualified(s.subjectRepo, course.SubjectID, substitute.ID); err != nil {
+		return nil, err
+	}
+
+	// Le remplaçant doit être libre sur le créneau (ce cours excepté)
+	conflicts, err := s.courseRepo.CheckTeacherConflicts(substitute.ID, course.StartTime, course.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	var blocking []models.ConflictInfo
+	for _, conflict := range conflicts {
+		if conflict.CourseID != course.ID {
+			blocking = append(blocking, conflict)
+		}
+	}
+	if len(blocking) > 0 {
+		return nil, fmt.Errorf("conflits détectés: %v", blocking)
+	}
+
+	warnings, err := s.availabilityWarnings(substitute.ID, []time.Time{course.StartTime}, course.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.courseRepo.SetSubstituteTeacher(course.ID, &substitute.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.notificationService.NotifySubstituteAssigned(course, substitute.ID); err != nil {
+		log.Printf("Erreur lors de l'envoi de la notification de remplacement du cours %d: %v", course.ID, err)
+	}
+
+	updatedCourse, err := s.courseRepo.GetCourseByID(course.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := updatedCourse.ToCourseResponse()
+	response.Warnings = warnings
+	return &response, nil
+}
+
+// RemoveSubstitute retire le remplaçant d'une occurrence
+func (s *CourseService) RemoveSubstitute(id uint) (*models.CourseResponse, error) {
+	course, err := s.courseRepo.GetCourseByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("cours avec l'ID %d non trouvé", id)
+	}
+	if course.SubstituteTeacherID == nil {
+		return nil, fmt.Errorf("ce cours n'a pas de remplaçant")
+	}
+
+	if err := s.courseRepo.SetSubstituteTeacher(course.ID, nil); err != nil {
+		return nil, err
+	}
+
+	updatedCourse, err := s.courseRepo.GetCourseByID(course.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := updatedCourse.ToCourseResponse()
+	return &response, nil
+}
+
+// GetCourseHistory récupère l'historique des changements de statut d'un cours
+func (s *CourseService) GetCourseHistory(id uint) ([]models.CourseStatusHistoryResponse, error) {
+	if _, err := s.courseRepo.GetCourseByID(id); err != nil {
+		return nil, fmt.Errorf("cours avec l'ID %d non trouvé", id)
+	}
+
+	history, err := s.courseRepo.GetCourseStatusHistory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.CourseStatusHistoryResponse, len(history))
+	for i, entry := range history {
+		responses[i] = entry.ToResponse()
+	}
+
+	return responses, nil
+}
+
 // GetCoursesByDateRange récupère les cours dans une plage de dates
 func (s *CourseService) GetCoursesByDateRange(startDate, endDate time.Time) ([]models.CourseResponse, error) {
 	courses, err := s.courseRepo.GetCoursesByDateRange(startDate, endDate)
@@ -324,15 +621,125 @@ func (s *CourseService) GetCoursesByTeacher(teacherID uint) ([]models.CourseResp
 	return responses, nil
 }
 
-// CheckConflicts vérifie les conflits pour un cours
+// FindAvailableRooms recherche les salles sans cours sur le créneau. Comme pour CheckConflicts,
+// une sous-salle est occupée si sa salle parente l'est, et une salle modulable l'est si l'une de ses sous-salles l'est.
+func (s *CourseService) FindAvailableRooms(filter *models.AvailableRoomFilter) ([]models.RoomResponse, error) {
+	if !filter.End.After(filter.Start) {
+		return nil, fmt.Errorf("la fin du créneau doit être après le début")
+	}
+
+	rooms, err := s.roomRepo.GetAllRooms(&models.RoomFilter{
+		Building:        filter.Building,
+		Modular:         filter.Modular,
+		MinCapacity:     filter.MinCapacity,
+		Equipment:       filter.Equipment,
+		IncludeSubRooms: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	occupiedIDs, err := s.courseRepo.GetOccupiedRoomIDs(filter.Start, filter.End)
+	if err != nil {
+		return nil, err
+	}
+	occupied := make(map[uint]bool, len(occupiedIDs))
+	for _, id := range occupiedIDs {
+		occupied[id] = true
+	}
+
+	responses := make([]models.RoomResponse, 0, len(rooms))
+	for _, room := range rooms {
+		if !isRoomFree(&room, occupied) {
+			continue
+		}
+		responses = append(responses, room.ToRoomResponse())
+	}
+
+	return responses, nil
+}
+
+// isRoomFree applique les règles des salles modulables à l'ensemble des salles occupées
+func isRoomFree(room *models.Room, occupied map[uint]bool) bool {
+	if occupied[room.ID] {
+		return false
+	}
+	if room.ParentID != nil && occupied[*room.ParentID] {
+		return false
+	}
+	for _, child := range room.Children {
+		if occupied[child.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckConflicts vérifie les conflits (salle, enseignant, groupes) pour un cours
+// et, s'il est récurrent, pour chacune de ses occurrences
 func (s *CourseService) CheckConflicts(req *models.CreateCourseRequest) ([]models.ConflictInfo, error) {
 	course := &models.Course{
-		RoomID:    req.RoomID,
-		StartTime: req.StartTime,
-		Duration:  req.Duration,
+		TeacherID:         req.TeacherID,
+		RoomID:            req.RoomID,
+		StartTime:         req.StartTime,
+		Duration:          req.Duration,
+		IsRecurring:       req.IsRecurring,
+		RecurrencePattern: req.RecurrencePattern,
+		RecurrenceEndDate: req.RecurrenceEndDate,
+	}
+	for _, groupID := range req.GroupIDs {
+		course.Groups = append(course.Groups, models.Group{ID: groupID})
+	}
+	for _, roomID := range req.MergedRoomIDs {
+		course.MergedRooms = append(course.MergedRooms, models.Room{ID: roomID})
+	}
+	course.EndTime = course.StartTime.Add(time.Duration(course.Duration) * time.Minute)
+
+	conflicts, err := s.courseRepo.CheckConflicts(course)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IsRecurring && req.RecurrencePattern != nil && req.RecurrenceEndDate != nil {
+		occurrences, err := course.RecurrenceOccurrences()
+		if err != nil {
+			return nil, fmt.Errorf("motif de récurrence invalide: %v", err)
+		}
+
+		for _, startTime := range occurrences {
+			occurrence := *course
+			occurrence.StartTime = startTime
+			occurrence.EndTime = startTime.Add(time.Duration(course.Duration) * time.Minute)
+
+			occurrenceConflicts, err := s.courseRepo.CheckConflicts(&occurrence)
+			if err != nil {
+				return nil, err
+			}
+			conflicts = append(conflicts, occurrenceConflicts...)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// CheckAvailability retourne les avertissements liés aux disponibilités de l'enseignant pour un cours
+// (créneaux hors des plages de disponibilité déclarées)
+func (s *CourseService) CheckAvailability(req *models.CreateCourseRequest) ([]string, error) {
+	course := &models.Course{
+		TeacherID:         req.TeacherID,
+		StartTime:         req.StartTime,
+		Duration:          req.Duration,
+		IsRecurring:       req.IsRecurring,
+		RecurrencePattern: req.RecurrencePattern,
+		RecurrenceEndDate: req.RecurrenceEndDate,
+	}
+
+	startTimes, err := scheduledStartTimes(course)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.courseRepo.CheckConflicts(course)
+	return s.availabilityWarnings(course.TeacherID, startTimes, course.Duration)
 }
 
 // CheckConflictsForUpdate vérifie les conflits pour la modification d'un cours
@@ -348,12 +755,32 @@ func (s *CourseService) CheckConflictsForUpdate(courseID uint, req *models.Updat
 	// Créer un cours temporaire avec les nouvelles valeurs
 	course := &models.Course{
 		ID:        courseID,
+		TeacherID: req.TeacherID,
 		RoomID:    req.RoomID,
 		StartTime: req.StartTime,
 		Duration:  req.Duration,
+		Groups:    existingCourse.Groups,
+	}
+	if req.RoomID == 0 || req.RoomID == existingCourse.RoomID {
+		course.MergedRooms = existingCourse.MergedRooms
+	}
+	if req.MergedRoomIDs != nil {
+		course.MergedRooms = nil
+		for _, roomID := range req.MergedRoomIDs {
+			course.MergedRooms = append(course.MergedRooms, models.Room{ID: roomID})
+		}
 	}
 
 	// Utiliser les valeurs existantes si non modifiées
+	if req.TeacherID == 0 {
+		course.TeacherID = existingCourse.TeacherID
+	}
+	if req.GroupIDs != nil {
+		course.Groups = nil
+		for _, groupID := range req.GroupIDs {
+			course.Groups = append(course.Groups, models.Group{ID: groupID})
+		}
+	}
 	if req.RoomID == 0 {
 		course.RoomID = existingCourse.RoomID
 	}
@@ -379,3 +806,167 @@ func (s *CourseService) CheckConflictsForUpdate(courseID uint, req *models.Updat
 		return s.courseRepo.CheckConflictsExcluding(courseID, course)
 	}
 }
+
+// resolveGroups vérifie que tous les groupes demandés existent
+func (s *CourseService) resolveGroups(groupIDs []uint) ([]models.Group, error) {
+	groups, err := s.groupRepo.GetGroupsByIDs(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, groupID := range groupIDs {
+		found := false
+		for _, group := range groups {
+			if group.ID == groupID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("groupe %d non trouvé", groupID)
+		}
+	}
+
+	return groups, nil
+}
+
+// checkTeacherSchedule refuse un cours dont le premier créneau tombe pendant une indisponibilité
+// de l'enseignant et retourne les avertissements pour les autres occurrences :
+// occurrences hors disponibilités (créées) et occurrences pendant une indisponibilité (non créées)
+func (s *CourseService) checkTeacherSchedule(course *models.Course) ([]string, error) {
+	startTimes, err := scheduledStartTimes(course)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(course.Duration) * time.Minute
+	var warnings []string
+	var available []time.Time
+	for i, startTime := range startTimes {
+		unavailabilities, err := s.availabilityRepo.GetUnavailabilitiesInRange(course.TeacherID, startTime, startTime.Add(duration))
+		if err != nil {
+			return nil, err
+		}
+		if len(unavailabilities) == 0 {
+			available = append(available, startTime)
+			continue
+		}
+
+		unavailability := unavailabilities[0]
+		if i == 0 {
+			return nil, fmt.Errorf("l'enseignant est indisponible du %s au %s%s",
+				unavailability.StartDate.Format("02/01/2006 15:04"), unavailability.EndDate.Format("02/01/2006 15:04"), formatReason(unavailability.Reason))
+		}
+		warnings = append(warnings, fmt.Sprintf("l'occurrence du %s ne sera pas créée : enseignant indisponible%s",
+			startTime.Format("02/01/2006 15:04"), formatReason(unavailability.Reason)))
+	}
+
+	availabilityWarnings, err := s.availabilityWarnings(course.TeacherID, available, course.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(availabilityWarnings, warnings...), nil
+}
+
+// roomSuitabilityWarnings signale une salle trop petite pour l'effectif des groupes
+// ou dépourvue d'équipements requis par la matière. Une capacité nulle n'est pas vérifiée.
+// Des sous-salles réunies cumulent leurs places et leurs équipements.
+func (s *CourseService) roomSuitabilityWarnings(course *models.Course) ([]string, error) {
+	var names []string
+	var equipment []models.Equipment
+	capacity := 0 // -1 dès qu'une salle n'a pas de capacité renseignée
+	for _, roomID := range course.RoomIDs() {
+		room, err := s.roomRepo.GetRoomByID(roomID)
+		if err != nil {
+			return nil, fmt.Errorf("salle non trouvée")
+		}
+		names = append(names, room.Name)
+		equipment = append(equipment, room.Equipment...)
+		if room.Capacity == 0 {
+			capacity = -1
+		} else if capacity >= 0 {
+			capacity += room.Capacity
+		}
+	}
+	roomName := strings.Join(names, " + ")
+
+	subject, err := s.subjectRepo.GetSubjectByID(course.SubjectID)
+	if err != nil {
+		return nil, fmt.Errorf("matière non trouvée")
+	}
+
+	var warnings []string
+	if groupIDs := course.GroupIDs(); capacity > 0 && len(groupIDs) > 0 {
+		studentIDs, err := s.groupRepo.GetStudentIDsByGroupIDs(groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(studentIDs) > capacity {
+			warnings = append(warnings, fmt.Sprintf("la salle %s (%d places) est trop petite pour les %d étudiants inscrits",
+				roomName, capacity, len(studentIDs)))
+		}
+	}
+
+	if missing := models.MissingEquipment(subject.RequiredEquipment, equipment); len(missing) > 0 {
+		warnings = append(warnings, fmt.Sprintf("la salle %s ne dispose pas des équipements requis par la matière : %s",
+			roomName, strings.Join(missing, ", ")))
+	}
+
+	return warnings, nil
+}
+
+// availabilityWarnings signale les créneaux situés hors des plages de disponibilité de l'enseignant.
+// Un enseignant sans plage déclarée est considéré disponible en permanence.
+func (s *CourseService) availabilityWarnings(teacherID uint, startTimes []time.Time, durationMinutes int) ([]string, error) {
+	availabilities, err := s.availabilityRepo.GetAvailabilities(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if len(availabilities) == 0 {
+		return nil, nil
+	}
+
+	var warnings []string
+	for _, startTime := range startTimes {
+		startTime = startTime.In(models.SchoolLocation)
+		endTime := startTime.Add(time.Duration(durationMinutes) * time.Minute)
+
+		covered := false
+		for _, availability := range availabilities {
+			if availability.Covers(startTime, endTime) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			warnings = append(warnings, fmt.Sprintf("le créneau du %s de %s à %s est en dehors des disponibilités de l'enseignant",
+				startTime.Format("02/01/2006"), startTime.Format("15:04"), endTime.Format("15:04")))
+		}
+	}
+
+	return warnings, nil
+}
+
+// scheduledStartTimes retourne l'heure de début du cours puis celles de ses occurrences s'il est récurrent
+func scheduledStartTimes(course *models.Course) ([]time.Time, error) {
+	startTimes := []time.Time{course.StartTime}
+	if !course.IsRecurring || course.RecurrenceID != nil || course.RecurrencePattern == nil || course.RecurrenceEndDate == nil {
+		return startTimes, nil
+	}
+
+	occurrences, err := course.RecurrenceOccurrences()
+	if err != nil {
+		return nil, fmt.Errorf("motif de récurrence invalide: %v", err)
+	}
+
+	return append(startTimes, occurrences...), nil
+}
+
+// formatReason formate un motif optionnel pour l'ajouter à un message
+func formatReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", reason)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+)
+
+// quotaBehindTolerance est le retard toléré, en part du quota, avant de signaler une matière en retard
+const quotaBehindTolerance = 0.1
+
+type CurriculumService struct {
+	quotaRepo   *repositories.SubjectQuotaRepository
+	subjectRepo *repositories.SubjectRepository
+	groupRepo   *repositories.GroupRepository
+	courseRepo  *repositories.CourseRepository
+}
+
+func NewCurriculumService(
+	quotaRepo *repositories.SubjectQuotaRepository,
+	subjectRepo *repositories.SubjectRepository,
+	groupRepo *repositories.GroupRepository,
+	courseRepo *repositories.CourseRepository,
+) *CurriculumService {
+	return &CurriculumService{
+		quotaRepo:   quotaRepo,
+		subjectRepo: subjectRepo,
+		groupRepo:   groupRepo,
+		courseRepo:  courseRepo,
+	}
+}
+
+// GetQuotas récupère les quotas horaires selon les filtres
+func (s *CurriculumService) GetQuotas(filter *models.SubjectQuotaFilter) ([]models.SubjectQuotaResponse, error) {
+	quotas, err := s.quotaRepo.GetQuotas(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.SubjectQuotaResponse, len(quotas))
+	for i, quota := range quotas {
+		responses[i] = quota.ToSubjectQuotaResponse()
+	}
+	return responses, nil
+}
+
+// GetQuotaByID récupère un quota horaire
+func (s *CurriculumService) GetQuotaByID(id uint) (*models.SubjectQuotaResponse, error) {
+	quota, err := s.quotaRepo.GetQuotaByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := quota.ToSubjectQuotaResponse()
+	return &response, nil
+}
+
+// CreateQuota fixe le volume horaire d'une matière pour un groupe sur une période
+func (s *CurriculumService) CreateQuota(req *models.CreateSubjectQuotaRequest) (*models.SubjectQuotaResponse, error) {
+	if _, err := s.subjectRepo.GetSubjectByID(req.SubjectID); err != nil {
+		return nil, fmt.Errorf("matière non trouvée")
+	}
+	if _, err := s.groupRepo.GetGroupByID(req.GroupID); err != nil {
+		return nil, fmt.Errorf("groupe non trouvé")
+	}
+
+	startDate, endDate, err := parseQuotaPeriod(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.quotaRepo.CheckQuotaExists(req.SubjectID, req.GroupID, req.Term, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("un quota existe déjà pour cette matière, ce groupe et cette période")
+	}
+
+	quota := &models.SubjectQuota{
+		SubjectID:     req.SubjectID,
+		GroupID:       req.GroupID,
+		Term:          req.Term,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		RequiredHours: req.RequiredHours,
+	}
+	if err := s.quotaRepo.CreateQuota(quota); err != nil {
+		return nil, err
+	}
+
+	return s.GetQuotaByID(quota.ID)
+}
+
+// UpdateQuota modifie la période ou le volume horaire d'un quota
+func (s *CurriculumService) UpdateQuota(id uint, req *models.UpdateSubjectQuotaRequest) (*models.SubjectQuotaResponse, error) {
+	quota, err := s.quotaRepo.GetQuotaByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseQuotaPeriod(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.quotaRepo.CheckQuotaExists(quota.SubjectID, quota.GroupID, req.Term, &id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("un quota existe déjà pour cette matière, ce groupe et cette période")
+	}
+
+	quota.Term = req.Term
+	quota.StartDate = startDate
+	quota.EndDate = endDate
+	quota.RequiredHours = req.RequiredHours
+	if err := s.quotaRepo.UpdateQuota(quota); err != nil {
+		return nil, err
+	}
+
+	return s.GetQuotaByID(id)
+}
+
+// DeleteQuota supprime un quota horaire
+func (s *CurriculumService) DeleteQuota(id uint) error {
+	if _, err := s.quotaRepo.GetQuotaByID(id); err != nil {
+		return err
+	}
+	return s.quotaRepo.DeleteQuota(id)
+}
+
+// GetProgress compare, à une date donnée, les heures planifiées et effectuées de chaque quota.
+// Les matières en retard sont placées en tête.
+func (s *CurriculumService) GetProgress(filter *models.SubjectQuotaFilter, at time.Time) ([]models.SubjectProgressResponse, error) {
+	quotas, err := s.quotaRepo.GetQuotas(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]models.SubjectProgressResponse, 0, len(quotas))
+	for i := range quotas {
+		item, err := s.quotaProgress(&quotas[i], at)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil && filter.BehindOnly && !item.Behind {
+			continue
+		}
+		progress = append(progress, *item)
+	}
+
+	sort.SliceStable(progress, func(i, j int) bool {
+		return progress[i].Behind && !progress[j].Behind
+	})
+	return progress, nil
+}
+
+// quotaProgress calcule l'avancement d'un quota : les cours du groupe et de ses groupes parents sont comptés
+func (s *CurriculumService) quotaProgress(quota *models.SubjectQuota, at time.Time) (*models.SubjectProgressResponse, error) {
+	ancestors, err := s.groupRepo.GetAncestorIDs([]uint{quota.GroupID})
+	if err != nil {
+		return nil, err
+	}
+
+	periodEnd := quota.EndDate.Add(24 * time.Hour)
+	courses, err := s.courseRepo.GetCoursesByGroupsAndDateRange(append([]uint{quota.GroupID}, ancestors...), quota.StartDate, periodEnd.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &models.SubjectProgressResponse{Quota: quota.ToSubjectQuotaResponse()}
+	for _, course := range courses {
+		if course.SubjectID != quota.SubjectID || !course.IsActive() {
+			continue
+		}
+		hours := float64(course.Duration) / 60
+		progress.ScheduledHours += hours
+		if course.Status == models.CourseStatusCompleted {
+			progress.DeliveredHours += hours
+		}
+	}
+
+	// Heures attendues au prorata du temps écoulé sur la période
+	switch {
+	case !at.After(quota.StartDate):
+		progress.ExpectedHours = 0
+	case !at.Before(periodEnd):
+		progress.ExpectedHours = quota.RequiredHours
+	default:
+		elapsed := at.Sub(quota.StartDate).Hours() / periodEnd.Sub(quota.StartDate).Hours()
+		progress.ExpectedHours = quota.RequiredHours * elapsed
+	}
+
+	progress.RemainingHours = quota.RequiredHours - progress.DeliveredHours
+	if progress.RemainingHours < 0 {
+		progress.RemainingHours = 0
+	}
+	progress.ProgressRate = roundTwoDecimals(progress.DeliveredHours / quota.RequiredHours * 100)
+
+	switch {
+	case progress.DeliveredHours >= quota.RequiredHours:
+		progress.Status = models.QuotaStatusCompleted
+	case progress.ExpectedHours-progress.DeliveredHours > quota.RequiredHours*quotaBehindTolerance:
+		progress.Status = models.QuotaStatusBehind
+	case progress.ScheduledHours < quota.RequiredHours:
+		progress.Status = models.QuotaStatusUnderScheduled
+	default:
+		progress.Status = models.QuotaStatusOnTrack
+	}
+	progress.Behind = progress.Status == models.QuotaStatusBehind || progress.Status == models.QuotaStatusUnderScheduled
+
+	progress.ScheduledHours = roundTwoDecimals(progress.ScheduledHours)
+	progress.DeliveredHours = roundTwoDecimals(progress.DeliveredHours)
+	progress.ExpectedHours = roundTwoDecimals(progress.ExpectedHours)
+	progress.RemainingHours = roundTwoDecimals(progress.RemainingHours)
+
+	return progress, nil
+}
+
+// parseQuotaPeriod lit les dates de début et de fin (incluse) d'un quota
+func parseQuotaPeriod(start, end string) (time.Time, time.Time, error) {
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("format de date de début invalide (YYYY-MM-DD)")
+	}
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("format de date de fin invalide (YYYY-MM-DD)")
+	}
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("la date de fin doit être après la date de début")
+	}
+	return startDate, endDate, nil
+}
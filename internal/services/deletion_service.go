@@ -9,10 +9,11 @@ import (
 )
 
 type DeletionService struct {
-	userRepo    *repositories.UserRepository
-	courseRepo  *repositories.CourseRepository
-	roomRepo    *repositories.RoomRepository
-	subjectRepo *repositories.SubjectRepository
+	userRepo          *repositories.UserRepository
+	courseRepo        *repositories.CourseRepository
+	roomRepo          *repositories.RoomRepository
+	subjectRepo       *repositories.SubjectRepository
+	permissionService *PermissionService
 }
 
 func NewDeletionService(
@@ -20,12 +21,14 @@ func NewDeletionService(
 	courseRepo *repositories.CourseRepository,
 	roomRepo *repositories.RoomRepository,
 	subjectRepo *repositories.SubjectRepository,
+	permissionService *PermissionService,
 ) *DeletionService {
 	return &DeletionService{
-		userRepo:    userRepo,
-		courseRepo:  courseRepo,
-		roomRepo:    roomRepo,
-		subjectRepo: subjectRepo,
+		userRepo:          userRepo,
+		courseRepo:        courseRepo,
+		roomRepo:          roomRepo,
+		subjectRepo:       subjectRepo,
+		permissionService: permissionService,
 	}
 }
 
@@ -104,6 +107,13 @@ func (s *DeletionService) DeleteUser(userID uint, currentUserRole string, confir
 		}
 	}
 
+	// Retirer ses qualifications et responsabilités de matières
+	if user.Role == models.RoleProfesseur {
+		if err := s.subjectRepo.RemoveTeacherFromAllSubjects(userID); err != nil {
+			return nil, fmt.Errorf("erreur lors du retrait des qualifications de l'enseignant")
+		}
+	}
+
 	// Effectuer la suppression de l'utilisateur (soft delete)
 	if err := s.userRepo.DeleteUser(userID); err != nil {
 		return nil, fmt.Errorf("erreur lors de la suppression de l'utilisateur")
@@ -229,15 +239,35 @@ func (s *DeletionService) DeleteSubject(subjectID uint) (*DeleteSubjectResponse,
 		return response, nil
 	}
 
+	// Les enseignants qualifiés et le responsable sont détachés avec la matière
+	subject, err := s.subjectRepo.GetSubjectByID(subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("matière non trouvée")
+	}
+	linkedTeachers, err := s.subjectRepo.GetSubjectTeachers(subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la vérification des enseignants liés")
+	}
+
 	// Effectuer la suppression (soft delete)
 	if err := s.subjectRepo.DeleteSubject(subjectID); err != nil {
 		return nil, fmt.Errorf("erreur lors de la suppression de la matière")
 	}
 
-	return &DeleteSubjectResponse{
+	response := &DeleteSubjectResponse{
 		Success: true,
 		Message: "matière supprimée avec succès",
-	}, nil
+	}
+	if len(linkedTeachers) > 0 {
+		response.Warnings = append(response.Warnings,
+			fmt.Sprintf("%d enseignants qualifiés ont été détachés de la matière", len(linkedTeachers)))
+	}
+	if subject.HeadTeacher != nil {
+		response.Warnings = append(response.Warnings,
+			fmt.Sprintf("%s %s n'est plus responsable de la matière", subject.HeadTeacher.FirstName, subject.HeadTeacher.LastName))
+	}
+
+	return response, nil
 }
 
 // DeleteCourseResponse contient les informations sur la suppression d'un cours
@@ -300,17 +330,8 @@ func (s *DeletionService) DeleteCourse(courseID uint, deleteRecurring bool) (*De
 	return response, nil
 }
 
-// canDeleteUser vérifie si l'utilisateur actuel peut supprimer l'utilisateur cible
+// canDeleteUser vérifie si l'utilisateur actuel peut supprimer l'utilisateur cible. Par défaut, seul le
+// Super Admin peut supprimer un Admin et les Admins et Super Admins peuvent supprimer les Professeurs et Étudiants.
 func (s *DeletionService) canDeleteUser(currentUserRole, targetUserRole string) bool {
-	// Seul le Super Admin peut supprimer un Admin
-	if targetUserRole == models.RoleAdmin {
-		return currentUserRole == models.RoleSuperAdmin
-	}
-
-	// Les Admins et Super Admins peuvent supprimer les Professeurs et Étudiants
-	if targetUserRole == models.RoleProfesseur || targetUserRole == models.RoleEtudiant {
-		return currentUserRole == models.RoleAdmin || currentUserRole == models.RoleSuperAdmin
-	}
-
-	return false
+	return s.permissionService.CanDeleteRole(currentUserRole, targetUserRole)
 }
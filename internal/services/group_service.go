@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+type GroupService struct {
+	groupRepo  *repositories.GroupRepository
+	userRepo   *repositories.UserRepository
+	courseRepo *repositories.CourseRepository
+}
+
+func NewGroupService(groupRepo *repositories.GroupRepository, userRepo *repositories.UserRepository, courseRepo *repositories.CourseRepository) *GroupService {
+	return &GroupService{
+		groupRepo:  groupRepo,
+		userRepo:   userRepo,
+		courseRepo: courseRepo,
+	}
+}
+
+// GetAllGroups récupère tous les groupes (liste à plat, la hiérarchie est donnée par parent_id)
+func (s *GroupService) GetAllGroups() ([]models.GroupResponse, error) {
+	groups, err := s.groupRepo.GetAllGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.GroupResponse, len(groups))
+	for i, group := range groups {
+		responses[i] = group.ToGroupResponse()
+	}
+	return responses, nil
+}
+
+// GetGroupByID récupère un groupe avec ses sous-groupes directs et son effectif actuel
+func (s *GroupService) GetGroupByID(id uint) (*models.GroupResponse, error) {
+	group, err := s.groupRepo.GetGroupByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.groupRepo.CountStudents(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := group.ToGroupResponse()
+	response.MemberCount = &count
+	return &response, nil
+}
+
+// CreateGroup crée un groupe, éventuellement rattaché à un groupe parent
+func (s *GroupService) CreateGroup(req *models.CreateGroupRequest) (*models.GroupResponse, error) {
+	if existing, _ := s.groupRepo.GetGroupByName(req.Name); existing != nil {
+		return nil, errors.New("un groupe avec ce nom existe déjà")
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.groupRepo.GetGroupByID(*req.ParentID); err != nil {
+			return nil, errors.New("groupe parent non trouvé")
+		}
+	}
+
+	group := &models.Group{
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	if err := s.groupRepo.CreateGroup(group); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroupByID(group.ID)
+}
+
+// UpdateGroup met à jour un groupe ; un groupe ne peut pas être rattaché à lui-même ni à l'un de ses sous-groupes
+func (s *GroupService) UpdateGroup(id uint, req *models.UpdateGroupRequest) (*models.GroupResponse, error) {
+	group, err := s.groupRepo.GetGroupByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" && req.Name != group.Name {
+		if existing, _ := s.groupRepo.GetGroupByName(req.Name); existing != nil {
+			return nil, errors.New("un groupe avec ce nom existe déjà")
+		}
+		group.Name = req.Name
+	}
+	if req.Description != "" {
+		group.Description = req.Description
+	}
+
+	if req.RemoveParent {
+		group.ParentID = nil
+	} else if req.ParentID != nil {
+		if *req.ParentID == group.ID {
+			return nil, errors.New("un groupe ne peut pas être son propre parent")
+		}
+		if _, err := s.groupRepo.GetGroupByID(*req.ParentID); err != nil {
+			return nil, errors.New("groupe parent non trouvé")
+		}
+		descendants, err := s.groupRepo.GetDescendantIDs([]uint{group.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, descendantID := range descendants {
+			if descendantID == *req.ParentID {
+				return nil, errors.New("un groupe ne peut pas être rattaché à l'un de ses sous-groupes")
+			}
+		}
+		group.ParentID = req.ParentID
+	}
+
+	if err := s.groupRepo.UpdateGroup(group); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroupByID(group.ID)
+}
+
+// DeleteGroup supprime un groupe sans sous-groupe ni cours associé
+func (s *GroupService) DeleteGroup(id uint) error {
+	group, err := s.groupRepo.GetGroupByID(id)
+	if err != nil {
+		return err
+	}
+
+	if len(group.Children) > 0 {
+		return errors.New("impossible de supprimer un groupe qui contient des sous-groupes")
+	}
+
+	used, err := s.groupRepo.IsGroupUsedByCourses(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New("impossible de supprimer un groupe associé à des cours")
+	}
+
+	return s.groupRepo.DeleteGroup(id)
+}
+
+// GetMembers récupère les membres d'un groupe effectifs à une date, ou tout l'historique si at est nil
+func (s *GroupService) GetMembers(groupID uint, at *time.Time) ([]models.GroupMembershipResponse, error) {
+	if _, err := s.groupRepo.GetGroupByID(groupID); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.groupRepo.GetMemberships(groupID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.GroupMembershipResponse, len(memberships))
+	for i, membership := range memberships {
+		responses[i] = membership.ToGroupMembershipResponse()
+	}
+	return responses, nil
+}
+
+// AddMembers ajoute des étudiants à un groupe à partir d'une date d'effet
+func (s *GroupService) AddMembers(groupID uint, req *models.AddGroupMembersRequest) ([]models.GroupMembershipResponse, error) {
+	if _, err := s.groupRepo.GetGroupByID(groupID); err != nil {
+		return nil, err
+	}
+
+	startDate := effectiveDate(req.StartDate)
+
+	var memberships []models.GroupMembership
+	for _, userID := range req.UserIDs {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("utilisateur %d non trouvé", userID)
+		}
+		if user.Role != models.RoleEtudiant {
+			return nil, fmt.Errorf("%s %s n'est pas un étudiant", user.FirstName, user.LastName)
+		}
+		if existing, _ := s.groupRepo.GetActiveMembership(groupID, userID, startDate); existing != nil {
+			return nil, fmt.Errorf("%s %s appartient déjà à ce groupe à cette date", user.FirstName, user.LastName)
+		}
+
+		memberships = append(memberships, models.GroupMembership{
+			GroupID:   groupID,
+			UserID:    userID,
+			StartDate: startDate,
+		})
+	}
+
+	if err := s.groupRepo.CreateMemberships(memberships); err != nil {
+		return nil, err
+	}
+
+	return s.GetMembers(groupID, &startDate)
+}
+
+// RemoveMember retire un étudiant d'un groupe à une date (l'historique d'appartenance est conservé)
+func (s *GroupService) RemoveMember(groupID, userID uint, endDate *time.Time) error {
+	date := effectiveDate(endDate)
+
+	membership, err := s.groupRepo.GetActiveMembership(groupID, userID, date)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("l'étudiant n'appartient pas à ce groupe à cette date")
+		}
+		return err
+	}
+
+	return s.groupRepo.EndMembership(membership.ID, date)
+}
+
+// MoveMember déplace un étudiant d'un groupe vers un autre à une date d'effet
+func (s *GroupService) MoveMember(fromGroupID uint, req *models.MoveGroupMemberRequest) (*models.GroupMembershipResponse, error) {
+	if req.ToGroupID == fromGroupID {
+		return nil, errors.New("le groupe de destination doit être différent du groupe d'origine")
+	}
+	if _, err := s.groupRepo.GetGroupByID(req.ToGroupID); err != nil {
+		return nil, errors.New("groupe de destination non trouvé")
+	}
+
+	date := effectiveDate(req.EffectiveDate)
+
+	current, err := s.groupRepo.GetActiveMembership(fromGroupID, req.UserID, date)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("l'étudiant n'appartient pas au groupe d'origine à cette date")
+		}
+		return nil, err
+	}
+	if existing, _ := s.groupRepo.GetActiveMembership(req.ToGroupID, req.UserID, date); existing != nil {
+		return nil, errors.New("l'étudiant appartient déjà au groupe de destination à cette date")
+	}
+
+	next := &models.GroupMembership{
+		GroupID:   req.ToGroupID,
+		UserID:    req.UserID,
+		StartDate: date,
+	}
+	if err := s.groupRepo.MoveMembership(current, next); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.groupRepo.GetMemberships(req.ToGroupID, &date)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		if membership.ID == next.ID {
+			response := membership.ToGroupMembershipResponse()
+			return &response, nil
+		}
+	}
+	return nil, errors.New("appartenance créée introuvable")
+}
+
+// GetGroupTimetable récupère l'emploi du temps d'un groupe : ses cours et ceux de ses groupes parents,
+// ainsi que ceux de ses sous-groupes si includeSubgroups est vrai
+func (s *GroupService) GetGroupTimetable(groupID uint, startDate, endDate time.Time, includeSubgroups bool) ([]models.CourseResponse, error) {
+	if _, err := s.groupRepo.GetGroupByID(groupID); err != nil {
+		return nil, err
+	}
+	if endDate.Before(startDate) {
+		return nil, errors.New("la date de fin doit être après la date de début")
+	}
+
+	groupIDs := []uint{groupID}
+	ancestors, err := s.groupRepo.GetAncestorIDs(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs = append(groupIDs, ancestors...)
+
+	if includeSubgroups {
+		descendants, err := s.groupRepo.GetDescendantIDs([]uint{groupID})
+		if err != nil {
+			return nil, err
+		}
+		groupIDs = append(groupIDs, descendants...)
+	}
+
+	courses, err := s.courseRepo.GetCoursesByGroupsAndDateRange(groupIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.CourseResponse, len(courses))
+	for i, course := range courses {
+		responses[i] = course.ToCourseResponse()
+	}
+	return responses, nil
+}
+
+// effectiveDate retourne la date d'effet demandée, ou l'instant présent
+func effectiveDate(date *time.Time) time.Time {
+	if date != nil && !date.IsZero() {
+		return *date
+	}
+	return time.Now()
+}
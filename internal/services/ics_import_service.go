@@ -0,0 +1,423 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/ical"
+)
+
+// openEndedRecurrenceWeeks limite les récurrences sans UNTIL ni COUNT
+const openEndedRecurrenceWeeks = 52
+
+type ICSImportService struct {
+	courseService *CourseService
+	eventService  *EventService
+	subjectRepo   *repositories.SubjectRepository
+	userRepo      *repositories.UserRepository
+	roomRepo      *repositories.RoomRepository
+	groupRepo     *repositories.GroupRepository
+}
+
+func NewICSImportService(
+	courseService *CourseService,
+	eventService *EventService,
+	subjectRepo *repositories.SubjectRepository,
+	userRepo *repositories.UserRepository,
+	roomRepo *repositories.RoomRepository,
+	groupRepo *repositories.GroupRepository,
+) *ICSImportService {
+	return &ICSImportService{
+		courseService: courseService,
+		eventService:  eventService,
+		subjectRepo:   subjectRepo,
+		userRepo:      userRepo,
+		roomRepo:      roomRepo,
+		groupRepo:     groupRepo,
+	}
+}
+
+// ImportCourses importe les VEVENT d'un fichier iCalendar comme cours.
+// En mode dry_run, rien n'est créé : le rapport indique ce qui serait créé et les conflits détectés.
+// Les VEVENT en conflit ou en erreur sont ignorés, les autres sont créés via CourseService.CreateCourse.
+func (s *ICSImportService) ImportCourses(r io.Reader, opts *models.ICSImportOptions) (*models.ICSImportReport, error) {
+	calendar, err := ical.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("fichier iCalendar invalide: %v", err)
+	}
+
+	// L'enseignant s'applique à tous les cours importés
+	if opts.TeacherID == 0 {
+		return nil, fmt.Errorf("l'enseignant est requis pour importer des cours")
+	}
+	teacher, err := s.userRepo.FindByID(opts.TeacherID)
+	if err != nil {
+		return nil, fmt.Errorf("enseignant non trouvé")
+	}
+	if teacher.Role != models.RoleProfesseur {
+		return nil, fmt.Errorf("l'utilisateur sélectionné n'est pas un enseignant")
+	}
+
+	if len(opts.GroupIDs) > 0 {
+		groups, err := s.groupRepo.GetGroupsByIDs(opts.GroupIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(groups) != len(opts.GroupIDs) {
+			return nil, fmt.Errorf("un ou plusieurs groupes sont introuvables")
+		}
+	}
+
+	report := &models.ICSImportReport{DryRun: opts.DryRun, Total: len(calendar.Events)}
+
+	for i, event := range calendar.Events {
+		item := models.ICSImportItem{
+			Index:     i + 1,
+			UID:       event.UID,
+			Summary:   event.Summary,
+			Target:    models.ICSImportTargetCourse,
+			StartTime: event.Start,
+			EndTime:   event.End,
+			TeacherID: opts.TeacherID,
+		}
+
+		req, err := s.buildCourseRequest(&item, event, opts)
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			conflicts, err := s.courseService.CheckConflicts(req)
+			if err != nil {
+				item.Error = err.Error()
+			}
+			item.Conflicts = conflicts
+
+			availabilityWarnings, err := s.courseService.CheckAvailability(req)
+			if err == nil {
+				item.Warnings = append(item.Warnings, availabilityWarnings...)
+			}
+		}
+
+		if item.Importable() {
+			report.Importable++
+			if !opts.DryRun {
+				course, err := s.courseService.CreateCourse(req)
+				if err != nil {
+					item.Error = err.Error()
+				} else {
+					item.CreatedIDs = []uint{course.ID}
+					item.Warnings = course.Warnings
+					report.Created++
+				}
+			}
+		}
+		if !item.Importable() {
+			report.Skipped++
+		}
+
+		report.Items = append(report.Items, item)
+	}
+
+	return report, nil
+}
+
+// ImportEvents importe les VEVENT d'un fichier iCalendar comme événements personnels de l'utilisateur.
+// Les événements récurrents sont développés en une occurrence par date, le modèle Event n'ayant pas de récurrence.
+func (s *ICSImportService) ImportEvents(r io.Reader, userID uint, dryRun bool) (*models.ICSImportReport, error) {
+	calendar, err := ical.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("fichier iCalendar invalide: %v", err)
+	}
+
+	report := &models.ICSImportReport{DryRun: dryRun, Total: len(calendar.Events)}
+
+	for i, event := range calendar.Events {
+		item := models.ICSImportItem{
+			Index:     i + 1,
+			UID:       event.UID,
+			Summary:   event.Summary,
+			Target:    models.ICSImportTargetEvent,
+			StartTime: event.Start,
+			EndTime:   event.End,
+		}
+
+		startTimes, err := s.eventOccurrences(&item, event)
+		if err != nil {
+			item.Error = err.Error()
+		}
+		item.Occurrences = len(startTimes)
+
+		if item.Importable() {
+			report.Importable++
+			if !dryRun {
+				description := event.Description
+				if event.Location != "" {
+					description = strings.TrimSpace(description + "\nLieu : " + event.Location)
+				}
+				duration := event.End.Sub(event.Start)
+
+				for _, startTime := range startTimes {
+					created, err := s.eventService.CreateEvent(userID, &models.CreateEventRequest{
+						Title:       event.Summary,
+						Description: description,
+						StartTime:   startTime,
+						EndTime:     startTime.Add(duration),
+					})
+					if err != nil {
+						item.Error = err.Error()
+						break
+					}
+					item.CreatedIDs = append(item.CreatedIDs, created.ID)
+				}
+				if item.Error == "" {
+					report.Created++
+				}
+			}
+		}
+		if !item.Importable() {
+			report.Skipped++
+		}
+
+		report.Items = append(report.Items, item)
+	}
+
+	return report, nil
+}
+
+// buildCourseRequest convertit un VEVENT en demande de création de cours
+func (s *ICSImportService) buildCourseRequest(item *models.ICSImportItem, event ical.Event, opts *models.ICSImportOptions) (*models.CreateCourseRequest, error) {
+	if event.Status == ical.StatusCancelled {
+		return nil, fmt.Errorf("événement annulé dans le calendrier source")
+	}
+	if event.AllDay {
+		return nil, fmt.Errorf("un événement sur une journée entière ne peut pas être importé comme cours")
+	}
+	if strings.TrimSpace(event.Summary) == "" {
+		return nil, fmt.Errorf("l'événement n'a pas de titre (SUMMARY)")
+	}
+
+	duration := int(event.End.Sub(event.Start).Minutes())
+	if duration < 15 || duration > 480 {
+		return nil, fmt.Errorf("durée de %d minutes hors limites (15 min à 8h)", duration)
+	}
+
+	roomID, err := s.resolveRoom(item, event.Location, opts.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	item.RoomID = roomID
+
+	subjectID, err := s.resolveSubject(item, event.Categories, opts.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	item.SubjectID = subjectID
+
+	req := &models.CreateCourseRequest{
+		Name:        event.Summary,
+		SubjectID:   subjectID,
+		TeacherID:   opts.TeacherID,
+		RoomID:      roomID,
+		StartTime:   event.Start,
+		Duration:    duration,
+		Description: event.Description,
+		GroupIDs:    opts.GroupIDs,
+	}
+
+	item.Occurrences = 1
+	if event.RRule != "" {
+		pattern, endDate, warnings, err := mapRecurrence(event)
+		if err != nil {
+			return nil, err
+		}
+		item.Warnings = append(item.Warnings, warnings...)
+
+		if pattern != nil {
+			req.IsRecurring = true
+			req.RecurrencePattern = pattern
+			req.RecurrenceEndDate = endDate
+			item.IsRecurring = true
+			item.RecurrencePattern = pattern
+			item.RecurrenceEndDate = endDate
+
+			course := models.Course{IsRecurring: true, StartTime: event.Start, RecurrencePattern: pattern, RecurrenceEndDate: endDate}
+			occurrences, err := course.RecurrenceOccurrences()
+			if err != nil {
+				return nil, err
+			}
+			item.Occurrences += len(occurrences)
+		}
+	}
+
+	return req, nil
+}
+
+// resolveRoom associe le LOCATION du VEVENT à une salle, ou à la salle par défaut
+func (s *ICSImportService) resolveRoom(item *models.ICSImportItem, location string, defaultRoomID uint) (uint, error) {
+	location = strings.TrimSpace(location)
+	if location != "" {
+		if room, err := s.roomRepo.GetRoomByName(location); err == nil {
+			return room.ID, nil
+		}
+	}
+
+	if defaultRoomID == 0 {
+		if location != "" {
+			return 0, fmt.Errorf("salle \"%s\" introuvable et aucune salle par défaut", location)
+		}
+		return 0, fmt.Errorf("aucune salle indiquée et aucune salle par défaut")
+	}
+	if _, err := s.roomRepo.GetRoomByID(defaultRoomID); err != nil {
+		return 0, fmt.Errorf("salle par défaut non trouvée")
+	}
+	if location != "" {
+		item.Warnings = append(item.Warnings, fmt.Sprintf("salle \"%s\" introuvable, salle par défaut utilisée", location))
+	}
+	return defaultRoomID, nil
+}
+
+// resolveSubject associe les CATEGORIES du VEVENT à une matière (nom ou code), ou à la matière par défaut
+func (s *ICSImportService) resolveSubject(item *models.ICSImportItem, categories []string, defaultSubjectID uint) (uint, error) {
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category == "" {
+			continue
+		}
+		if subject, err := s.subjectRepo.GetSubjectByName(category); err == nil {
+			return subject.ID, nil
+		}
+		if subject, err := s.subjectRepo.GetSubjectByCode(category); err == nil {
+			return subject.ID, nil
+		}
+	}
+
+	if defaultSubjectID == 0 {
+		return 0, fmt.Errorf("aucune matière correspondante et aucune matière par défaut")
+	}
+	if _, err := s.subjectRepo.GetSubjectByID(defaultSubjectID); err != nil {
+		return 0, fmt.Errorf("matière par défaut non trouvée")
+	}
+	if len(categories) > 0 {
+		item.Warnings = append(item.Warnings, "aucune matière ne correspond aux catégories, matière par défaut utilisée")
+	}
+	return defaultSubjectID, nil
+}
+
+// eventOccurrences retourne les débuts d'occurrence d'un VEVENT importé comme événement personnel
+func (s *ICSImportService) eventOccurrences(item *models.ICSImportItem, event ical.Event) ([]time.Time, error) {
+	if event.Status == ical.StatusCancelled {
+		return nil, fmt.Errorf("événement annulé dans le calendrier source")
+	}
+	if strings.TrimSpace(event.Summary) == "" {
+		return nil, fmt.Errorf("l'événement n'a pas de titre (SUMMARY)")
+	}
+
+	startTimes := []time.Time{event.Start}
+	if event.RRule == "" {
+		return startTimes, nil
+	}
+
+	pattern, endDate, warnings, err := mapRecurrence(event)
+	if err != nil {
+		return nil, err
+	}
+	item.Warnings = append(item.Warnings, warnings...)
+	if pattern == nil {
+		return startTimes, nil
+	}
+
+	item.IsRecurring = true
+	item.RecurrencePattern = pattern
+	item.RecurrenceEndDate = endDate
+
+	course := models.Course{IsRecurring: true, StartTime: event.Start, RecurrencePattern: pattern, RecurrenceEndDate: endDate}
+	occurrences, err := course.RecurrenceOccurrences()
+	if err != nil {
+		return nil, err
+	}
+	return append(startTimes, occurrences...), nil
+}
+
+// mapRecurrence convertit une RRULE vers le modèle de récurrence des cours (jours de la semaine + date de fin).
+// Seules les règles quotidiennes et hebdomadaires d'intervalle 1 sont représentables.
+// Un motif nil signifie que la règle ne produit qu'une seule occurrence.
+func mapRecurrence(event ical.Event) (*string, *time.Time, []string, error) {
+	rule, err := ical.ParseRecurrence(event.RRule)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("règle de récurrence invalide: %v", err)
+	}
+	if len(rule.Unsupported) > 0 {
+		return nil, nil, nil, fmt.Errorf("règle de récurrence non prise en charge: %s", strings.Join(rule.Unsupported, ";"))
+	}
+	if rule.Interval != 1 {
+		return nil, nil, nil, fmt.Errorf("récurrence toutes les %d périodes non prise en charge", rule.Interval)
+	}
+
+	days := rule.ByDay
+	switch rule.Freq {
+	case ical.FreqWeekly:
+		if len(days) == 0 {
+			days = []time.Weekday{event.Start.Weekday()}
+		}
+	case ical.FreqDaily:
+		if len(days) == 0 {
+			days = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+		}
+	default:
+		return nil, nil, nil, fmt.Errorf("fréquence de récurrence %s non prise en charge", rule.Freq)
+	}
+
+	var warnings []string
+	if len(event.ExDates) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d exception(s) EXDATE ignorée(s)", len(event.ExDates)))
+	}
+
+	matches := func(t time.Time) bool {
+		for _, day := range days {
+			if t.Weekday() == day {
+				return true
+			}
+		}
+		return false
+	}
+
+	// RecurrenceOccurrences génère les occurrences strictement avant la date de fin,
+	// on ajoute une seconde pour que la dernière occurrence (UNTIL inclusif) soit conservée
+	var endDate time.Time
+	switch {
+	case rule.Until != nil:
+		endDate = rule.Until.Add(time.Second)
+	case rule.Count > 0:
+		if rule.Count == 1 {
+			return nil, nil, warnings, nil
+		}
+		remaining := rule.Count - 1
+		last := event.Start
+		for remaining > 0 {
+			last = last.AddDate(0, 0, 1)
+			if matches(last) {
+				remaining--
+			}
+		}
+		endDate = last.Add(time.Second)
+	default:
+		endDate = event.Start.AddDate(0, 0, 7*openEndedRecurrenceWeeks)
+		warnings = append(warnings, fmt.Sprintf("récurrence sans fin limitée à %d semaines", openEndedRecurrenceWeeks))
+	}
+
+	names := make([]string, len(days))
+	for i, day := range days {
+		names[i] = day.String()
+	}
+	data, err := json.Marshal(models.RecurrencePattern{Days: names})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pattern := string(data)
+
+	return &pattern, &endDate, warnings, nil
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	// ldapTimeout borne la connexion et chaque requête adressée à l'annuaire
+	ldapTimeout = 10 * time.Second
+	// ldapPageSize est la taille des pages de résultats lors de la synchronisation
+	ldapPageSize = 500
+)
+
+// LDAPSettings décrit l'annuaire LDAP / Active Directory et la correspondance de ses groupes
+type LDAPSettings struct {
+	URL                string // ldap://hôte:389 ou ldaps://hôte:636
+	StartTLS           bool
+	BindDN             string // Compte de service utilisé pour les recherches
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string // Filtre des comptes EduQR, par exemple (objectClass=person)
+	IDAttribute        string // Attribut stable identifiant un compte (objectGUID, entryUUID)
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+	GroupAttribute     string            // Attribut listant les DN des groupes du compte (memberOf)
+	RoleMapping        map[string]string // CN d'un groupe de l'annuaire -> rôle EduQR
+	GroupMapping       map[string]string // CN d'un groupe de l'annuaire -> nom d'un groupe d'étudiants EduQR
+	DefaultRole        string            // Rôle des utilisateurs créés sans groupe associé à un rôle
+}
+
+// LDAPSyncReport résume une synchronisation avec l'annuaire
+type LDAPSyncReport struct {
+	Created     int
+	Updated     int
+	Reactivated int
+	Deactivated int
+	Skipped     int // Entrées incomplètes ou en erreur
+}
+
+type LDAPService struct {
+	settings    LDAPSettings
+	provisioner *userProvisioner
+	userRepo    *repositories.UserRepository
+	sessionRepo *repositories.SessionRepository
+}
+
+func NewLDAPService(
+	settings LDAPSettings,
+	userRepo *repositories.UserRepository,
+	groupRepo *repositories.GroupRepository,
+	sessionRepo *repositories.SessionRepository,
+) *LDAPService {
+	return &LDAPService{
+		settings: settings,
+		provisioner: &userProvisioner{
+			userRepo:     userRepo,
+			groupRepo:    groupRepo,
+			sessionRepo:  sessionRepo,
+			roleMapping:  settings.RoleMapping,
+			groupMapping: settings.GroupMapping,
+			defaultRole:  settings.DefaultRole,
+		},
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Enabled indique si un annuaire est configuré
+func (s *LDAPService) Enabled() bool {
+	return s != nil && s.settings.URL != ""
+}
+
+// Authenticate vérifie le mot de passe d'un compte de l'annuaire et retourne l'utilisateur EduQR correspondant,
+// créé ou mis à jour à partir de l'annuaire. Retourne ErrInvalidCredentials si le compte est inconnu ou le mot de passe faux.
+func (s *LDAPService) Authenticate(email, password string) (*models.User, error) {
+	// Un bind avec un mot de passe vide est accepté comme anonyme par de nombreux annuaires
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf("(&%s(%s=%s))", s.settings.UserFilter, s.settings.EmailAttribute, ldap.EscapeFilter(email))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		s.settings.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		filter, s.attributes(), nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("recherche dans l'annuaire LDAP impossible : %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("authentification auprès de l'annuaire LDAP impossible : %w", err)
+	}
+
+	identity := s.entryIdentity(entry)
+	if identity == nil {
+		return nil, fmt.Errorf("compte de l'annuaire incomplet : %s", entry.DN)
+	}
+	user, _, err := s.provisioner.provision(identity)
+	if err != nil {
+		return nil, err
+	}
+	// Présent dans l'annuaire : le compte est de nouveau actif
+	if !user.IsActive() {
+		if err := s.userRepo.SetDeactivatedAt(user.ID, nil); err != nil {
+			return nil, err
+		}
+		user.DeactivatedAt = nil
+	}
+	return user, nil
+}
+
+// Sync importe et met à jour les utilisateurs de l'annuaire et leurs groupes. Les utilisateurs importés
+// qui n'y figurent plus sont désactivés, pas supprimés, et leurs sessions révoquées ; ils sont réactivés
+// s'ils y reviennent.
+func (s *LDAPService) Sync() (*LDAPSyncReport, error) {
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := conn.SearchWithPaging(ldap.NewSearchRequest(
+		s.settings.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		s.settings.UserFilter, s.attributes(), nil,
+	), ldapPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("recherche dans l'annuaire LDAP impossible : %w", err)
+	}
+	// Un annuaire vide signale plus probablement une erreur de configuration qu'un départ de tous les utilisateurs
+	if len(result.Entries) == 0 {
+		return nil, fmt.Errorf("aucun compte trouvé dans %s avec le filtre %s, synchronisation annulée", s.settings.BaseDN, s.settings.UserFilter)
+	}
+
+	report := &LDAPSyncReport{}
+	seen := make(map[string]bool, len(result.Entries))
+	for _, entry := range result.Entries {
+		identity := s.entryIdentity(entry)
+		if identity == nil {
+			log.Printf("Compte de l'annuaire %s ignoré : identifiant ou adresse e-mail manquant", entry.DN)
+			report.Skipped++
+			continue
+		}
+		seen[identity.Subject] = true
+
+		user, created, err := s.provisioner.provision(identity)
+		if err != nil {
+			log.Printf("Compte de l'annuaire %s ignoré : %v", entry.DN, err)
+			report.Skipped++
+			continue
+		}
+		switch {
+		case created:
+			report.Created++
+		case !user.IsActive():
+			if err := s.userRepo.SetDeactivatedAt(user.ID, nil); err != nil {
+				return nil, err
+			}
+			report.Reactivated++
+		default:
+			report.Updated++
+		}
+	}
+
+	users, err := s.userRepo.FindByAuthProvider(models.AuthProviderLDAP)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, user := range users {
+		if seen[user.ExternalID] || !user.IsActive() {
+			continue
+		}
+		if err := s.userRepo.SetDeactivatedAt(user.ID, &now); err != nil {
+			return nil, err
+		}
+		if _, err := s.sessionRepo.RevokeUserSessions(user.ID); err != nil {
+			return nil, err
+		}
+		report.Deactivated++
+	}
+	return report, nil
+}
+
+// connect ouvre une connexion à l'annuaire authentifiée avec le compte de service
+func (s *LDAPService) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(s.settings.URL, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}))
+	if err != nil {
+		return nil, fmt.Errorf("annuaire LDAP injoignable : %w", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if s.settings.StartTLS {
+		host := s.settings.URL
+		if parsed, err := url.Parse(s.settings.URL); err == nil {
+			host = parsed.Hostname()
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("StartTLS refusé par l'annuaire LDAP : %w", err)
+		}
+	}
+
+	if s.settings.BindDN != "" {
+		if err := conn.Bind(s.settings.BindDN, s.settings.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("connexion du compte de service LDAP refusée : %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (s *LDAPService) attributes() []string {
+	return []string{
+		s.settings.IDAttribute,
+		s.settings.EmailAttribute,
+		s.settings.FirstNameAttribute,
+		s.settings.LastNameAttribute,
+		s.settings.GroupAttribute,
+	}
+}
+
+// entryIdentity lit l'identité d'un compte de l'annuaire, ou nil s'il n'a pas d'identifiant ou d'adresse e-mail.
+// Les noms d'attributs ne tiennent pas compte de la casse ; les groupes sont désignés par leur CN.
+func (s *LDAPService) entryIdentity(entry *ldap.Entry) *externalIdentity {
+	subject := ldapIdentifier(entry.GetEqualFoldRawAttributeValue(s.settings.IDAttribute))
+	email := strings.ToLower(strings.TrimSpace(entry.GetEqualFoldAttributeValue(s.settings.EmailAttribute)))
+	if subject == "" || email == "" {
+		return nil
+	}
+
+	var groups []string
+	for _, groupDN := range entry.GetEqualFoldAttributeValues(s.settings.GroupAttribute) {
+		dn, err := ldap.ParseDN(groupDN)
+		if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+			continue
+		}
+		groups = append(groups, dn.RDNs[0].Attributes[0].Value)
+	}
+
+	return &externalIdentity{
+		Provider: models.AuthProviderLDAP,
+		Subject:  subject,
+		Email:    email,
+		// L'adresse est gérée par les administrateurs de l'annuaire
+		EmailVerified: true,
+		FirstName:     entry.GetEqualFoldAttributeValue(s.settings.FirstNameAttribute),
+		LastName:      entry.GetEqualFoldAttributeValue(s.settings.LastNameAttribute),
+		Groups:        groups,
+	}
+}
+
+// ldapIdentifier conserve tel quel un identifiant textuel (entryUUID) et code en hexadécimal
+// un identifiant binaire (objectGUID d'Active Directory)
+func ldapIdentifier(raw []byte) string {
+	if utf8.Valid(raw) && !strings.ContainsFunc(string(raw), func(r rune) bool { return !unicode.IsPrint(r) }) {
+		return string(raw)
+	}
+	return hex.EncodeToString(raw)
+}
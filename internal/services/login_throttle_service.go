@@ -0,0 +1,175 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+)
+
+// maxLoginDelay plafonne le délai progressif entre deux tentatives de connexion
+const maxLoginDelay = time.Minute
+
+// LoginThrottleSettings règle la protection contre les attaques par force brute
+type LoginThrottleSettings struct {
+	DelayAfter         int           // Nombre d'échecs à partir duquel chaque tentative est retardée
+	BaseDelay          time.Duration // Délai après DelayAfter échecs, doublé à chaque échec suivant
+	MaxAccountFailures int           // Nombre d'échecs avant verrouillage d'un compte
+	MaxIPFailures      int           // Nombre d'échecs avant blocage d'une adresse IP
+	LockoutDuration    time.Duration // Durée du verrouillage, et fenêtre au-delà de laquelle les échecs sont oubliés
+}
+
+// LoginThrottledError indique qu'une tentative de connexion est refusée avant même de vérifier le mot de passe
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // Verrouillage temporaire (sinon simple délai progressif)
+}
+
+func (e *LoginThrottledError) Error() string {
+	seconds := int(e.RetryAfter.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, account temporarily locked, retry in %d seconds", seconds)
+	}
+	return fmt.Sprintf("too many failed login attempts, retry in %d seconds", seconds)
+}
+
+type LoginThrottleService struct {
+	throttleRepo    *repositories.LoginThrottleRepository
+	userRepo        *repositories.UserRepository
+	auditLogService *AuditLogService
+	settings        LoginThrottleSettings
+}
+
+func NewLoginThrottleService(
+	throttleRepo *repositories.LoginThrottleRepository,
+	userRepo *repositories.UserRepository,
+	auditLogService *AuditLogService,
+	settings LoginThrottleSettings,
+) *LoginThrottleService {
+	return &LoginThrottleService{
+		throttleRepo:    throttleRepo,
+		userRepo:        userRepo,
+		auditLogService: auditLogService,
+		settings:        settings,
+	}
+}
+
+// CheckAllowed retourne une LoginThrottledError si le compte ou l'adresse IP est verrouillé
+// ou si le délai progressif depuis le dernier échec n'est pas écoulé
+func (s *LoginThrottleService) CheckAllowed(email, ipAddress string) error {
+	now := time.Now()
+	for _, key := range loginThrottleKeys(email, ipAddress) {
+		throttle, err := s.throttleRepo.GetThrottle(key.scope, key.identifier)
+		if err != nil {
+			return err
+		}
+		if throttle == nil {
+			continue
+		}
+		if throttle.IsLocked(now) {
+			return &LoginThrottledError{RetryAfter: throttle.LockedUntil.Sub(now), Locked: true}
+		}
+		// Les échecs trop anciens sont oubliés
+		if now.Sub(throttle.LastFailureAt) > s.settings.LockoutDuration {
+			continue
+		}
+		if next := throttle.LastFailureAt.Add(s.delay(throttle.Failures)); next.After(now) {
+			return &LoginThrottledError{RetryAfter: next.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// RecordFailure enregistre un échec de connexion et verrouille le compte ou l'adresse IP au-delà du seuil.
+// Le verrouillage d'un compte existant est tracé dans le journal d'audit.
+func (s *LoginThrottleService) RecordFailure(email, ipAddress, userAgent string) error {
+	now := time.Now()
+	lockedUntil := now.Add(s.settings.LockoutDuration)
+
+	for _, key := range loginThrottleKeys(email, ipAddress) {
+		threshold := s.settings.MaxAccountFailures
+		if key.scope == models.ThrottleScopeIP {
+			threshold = s.settings.MaxIPFailures
+		}
+
+		throttle, err := s.throttleRepo.RecordFailure(key.scope, key.identifier, now, s.settings.LockoutDuration, func(failures int) *time.Time {
+			if failures >= threshold {
+				return &lockedUntil
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if throttle.Failures != threshold {
+			continue
+		}
+
+		if key.scope == models.ThrottleScopeIP {
+			log.Printf("Adresse IP %s bloquée jusqu'à %s après %d échecs de connexion", key.identifier, lockedUntil.Format(time.RFC3339), throttle.Failures)
+			continue
+		}
+		if user, err := s.userRepo.FindByEmail(email); err == nil {
+			if err := s.auditLogService.LogAccountLocked(user, lockedUntil, ipAddress, userAgent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RecordSuccess efface les échecs du compte. Ceux de l'adresse IP sont conservés : une connexion réussie
+// sur un compte ne doit pas permettre de continuer à en attaquer d'autres depuis la même adresse.
+func (s *LoginThrottleService) RecordSuccess(email string) error {
+	return s.throttleRepo.ResetThrottle(models.ThrottleScopeAccount, normalizeLoginEmail(email))
+}
+
+// UnlockAccount lève le verrouillage d'un compte et trace l'opération au nom de l'administrateur
+func (s *LoginThrottleService) UnlockAccount(userID, adminID uint, adminEmail, adminRole, ipAddress, userAgent string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.throttleRepo.ResetThrottle(models.ThrottleScopeAccount, normalizeLoginEmail(user.Email)); err != nil {
+		return err
+	}
+	return s.auditLogService.LogAccountUnlocked(adminID, adminEmail, adminRole, user, ipAddress, userAgent)
+}
+
+// delay retourne le délai à respecter après failures échecs consécutifs
+func (s *LoginThrottleService) delay(failures int) time.Duration {
+	if failures < s.settings.DelayAfter {
+		return 0
+	}
+	delay := s.settings.BaseDelay
+	for i := s.settings.DelayAfter; i < failures && delay < maxLoginDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxLoginDelay {
+		delay = maxLoginDelay
+	}
+	return delay
+}
+
+type loginThrottleKey struct {
+	scope      string
+	identifier string
+}
+
+func loginThrottleKeys(email, ipAddress string) []loginThrottleKey {
+	keys := []loginThrottleKey{{models.ThrottleScopeAccount, normalizeLoginEmail(email)}}
+	if ipAddress != "" {
+		keys = append(keys, loginThrottleKey{models.ThrottleScopeIP, ipAddress})
+	}
+	return keys
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
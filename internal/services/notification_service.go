@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+)
+
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+	groupRepo        *repositories.GroupRepository
+}
+
+func NewNotificationService(notificationRepo *repositories.NotificationRepository, groupRepo *repositories.GroupRepository) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		groupRepo:        groupRepo,
+	}
+}
+
+// NotifyCourseCancelled prévient l'enseignant et les étudiants des groupes de l'annulation d'un cours
+func (s *NotificationService) NotifyCourseCancelled(course *models.Course, reason string) error {
+	title := fmt.Sprintf("Cours annulé : %s", course.Name)
+	message := fmt.Sprintf("Le cours \"%s\" du %s est annulé. Motif : %s",
+		course.Name, course.StartTime.Format("02/01/2006 15:04"), reason)
+
+	return s.notifyCourseParticipants(course, models.NotificationCourseCancelled, title, message)
+}
+
+// NotifyCourseRescheduled prévient l'enseignant et les étudiants des groupes du report d'un cours
+func (s *NotificationService) NotifyCourseRescheduled(original, replacement *models.Course, reason string) error {
+	title := fmt.Sprintf("Cours déplacé : %s", original.Name)
+	message := fmt.Sprintf("Le cours \"%s\" du %s est déplacé au %s. Motif : %s",
+		original.Name, original.StartTime.Format("02/01/2006 15:04"), replacement.StartTime.Format("02/01/2006 15:04"), reason)
+
+	return s.notifyCourseParticipants(replacement, models.NotificationCourseRescheduled, title, message)
+}
+
+// NotifySubstituteAssigned prévient l'enseignant remplaçant de son affectation à une occurrence
+func (s *NotificationService) NotifySubstituteAssigned(course *models.Course, substituteID uint) error {
+	courseID := course.ID
+	return s.notificationRepo.CreateNotifications([]models.Notification{{
+		UserID:   substituteID,
+		Type:     models.NotificationCourseSubstitute,
+		Title:    fmt.Sprintf("Remplacement : %s", course.Name),
+		Message:  fmt.Sprintf("Vous assurez le cours \"%s\" du %s en remplacement.", course.Name, course.StartTime.Format("02/01/2006 15:04")),
+		CourseID: &courseID,
+	}})
+}
+
+// NotifyRoomBookingReviewed prévient le demandeur de la décision prise sur sa réservation
+func (s *NotificationService) NotifyRoomBookingReviewed(booking *models.RoomBooking) error {
+	decision := "refusée"
+	if booking.Status == models.RoomBookingStatusApproved {
+		decision = "validée"
+	}
+
+	message := fmt.Sprintf("Votre réservation \"%s\" du %s a été %s.", booking.Title, booking.StartTime.Format("02/01/2006 15:04"), decision)
+	if booking.ReviewComment != "" {
+		message += " " + booking.ReviewComment
+	}
+
+	return s.notificationRepo.CreateNotifications([]models.Notification{{
+		UserID:  booking.RequesterID,
+		Type:    models.NotificationRoomBooking,
+		Title:   fmt.Sprintf("Réservation %s : %s", decision, booking.Title),
+		Message: message,
+	}})
+}
+
+// GetUserNotifications récupère les notifications d'un utilisateur
+func (s *NotificationService) GetUserNotifications(userID uint, unreadOnly bool) ([]models.NotificationResponse, error) {
+	notifications, err := s.notificationRepo.GetNotificationsByUser(userID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = notification.ToNotificationResponse()
+	}
+
+	return responses, nil
+}
+
+// MarkAsRead marque une notification comme lue
+func (s *NotificationService) MarkAsRead(id, userID uint) error {
+	return s.notificationRepo.MarkAsRead(id, userID)
+}
+
+// notifyCourseParticipants crée une notification pour l'enseignant et chaque étudiant des groupes du cours
+func (s *NotificationService) notifyCourseParticipants(course *models.Course, notificationType, title, message string) error {
+	studentIDs, err := s.groupRepo.GetStudentIDsByGroupIDs(course.GroupIDs())
+	if err != nil {
+		return err
+	}
+
+	recipients := map[uint]bool{course.TeacherID: true}
+	for _, studentID := range studentIDs {
+		recipients[studentID] = true
+	}
+
+	courseID := course.ID
+	notifications := make([]models.Notification, 0, len(recipients))
+	for userID := range recipients {
+		notifications = append(notifications, models.Notification{
+			UserID:   userID,
+			Type:     notificationType,
+			Title:    title,
+			Message:  message,
+			CourseID: &courseID,
+		})
+	}
+
+	return s.notificationRepo.CreateNotifications(notifications)
+}
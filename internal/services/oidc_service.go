@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/utils"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	// oidcStateTTL est la durée laissée pour s'authentifier chez le fournisseur d'identité
+	oidcStateTTL = 10 * time.Minute
+	// oidcHTTPTimeout borne chaque requête adressée au fournisseur d'identité
+	oidcHTTPTimeout = 10 * time.Second
+)
+
+// ErrOIDCDisabled indique que la connexion par fournisseur d'identité n'est pas configurée
+var ErrOIDCDisabled = errors.New("single sign-on is not configured")
+
+// OIDCSettings décrit le fournisseur d'identité OpenID Connect et la correspondance de ses groupes
+type OIDCSettings struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // Page du frontend qui reçoit le code d'autorisation
+	Scopes       []string
+	GroupsClaim  string            // Revendication contenant les groupes de l'utilisateur
+	RoleMapping  map[string]string // Groupe du fournisseur -> rôle EduQR
+	GroupMapping map[string]string // Groupe du fournisseur -> nom d'un groupe d'étudiants EduQR
+	DefaultRole  string            // Rôle des utilisateurs créés sans groupe associé à un rôle
+}
+
+type OIDCService struct {
+	settings    OIDCSettings
+	oidcRepo    *repositories.OIDCRepository
+	provisioner *userProvisioner
+	authService *AuthService
+	httpClient  *http.Client
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(
+	settings OIDCSettings,
+	oidcRepo *repositories.OIDCRepository,
+	userRepo *repositories.UserRepository,
+	groupRepo *repositories.GroupRepository,
+	sessionRepo *repositories.SessionRepository,
+	authService *AuthService,
+) *OIDCService {
+	return &OIDCService{
+		settings: settings,
+		oidcRepo: oidcRepo,
+		provisioner: &userProvisioner{
+			userRepo:     userRepo,
+			groupRepo:    groupRepo,
+			sessionRepo:  sessionRepo,
+			roleMapping:  settings.RoleMapping,
+			groupMapping: settings.GroupMapping,
+			defaultRole:  settings.DefaultRole,
+		},
+		authService: authService,
+		httpClient:  &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+// Enabled indique si un fournisseur d'identité est configuré
+func (s *OIDCService) Enabled() bool {
+	return s.settings.IssuerURL != "" && s.settings.ClientID != ""
+}
+
+// AuthorizationURL prépare une connexion et retourne l'URL du fournisseur d'identité vers laquelle
+// rediriger l'utilisateur. Le code d'autorisation est protégé par PKCE et le jeton d'identité par un nonce.
+// Le secret navigateur retourné doit être déposé en cookie : il lie la connexion au navigateur qui l'a
+// démarrée, pour qu'un attaquant ne puisse pas faire terminer sa propre connexion par sa victime.
+func (s *OIDCService) AuthorizationURL() (authorizationURL, browserSecret string, err error) {
+	if !s.Enabled() {
+		return "", "", ErrOIDCDisabled
+	}
+	provider, err := s.getProvider()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.GenerateRandomToken(refreshTokenSize)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateRandomToken(refreshTokenSize)
+	if err != nil {
+		return "", "", err
+	}
+	browserSecret, err = utils.GenerateRandomToken(refreshTokenSize)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	if err := s.oidcRepo.CreateState(&models.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		BrowserHash:  utils.HashToken(browserSecret),
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}); err != nil {
+		return "", "", err
+	}
+
+	return s.oauth2Config(provider).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), browserSecret, nil
+}
+
+// Login termine une connexion avec le code d'autorisation reçu par la page de retour : le code est échangé,
+// le jeton d'identité vérifié, puis l'utilisateur est créé ou mis à jour avant l'ouverture de la session.
+// browserSecret est le secret lu dans le cookie déposé par AuthorizationURL.
+// Comme AuthService.Login, retourne une TwoFactorRequiredError si la double authentification s'applique.
+func (s *OIDCService) Login(ctx context.Context, req *models.OIDCCallbackRequest, browserSecret string, client models.SessionClient) (*models.AuthTokens, *models.UserResponse, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrOIDCDisabled
+	}
+
+	state, err := s.oidcRepo.ConsumeState(utils.HashToken(req.State))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("état de connexion invalide ou expiré")
+		}
+		return nil, nil, err
+	}
+	if browserSecret == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(browserSecret)), []byte(state.BrowserHash)) != 1 {
+		return nil, nil, fmt.Errorf("la connexion n'a pas été démarrée depuis ce navigateur")
+	}
+
+	identity, err := s.exchange(ctx, req.Code, state)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, _, err := s.provisioner.provision(identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.Device != "" {
+		client.Device = req.Device
+	}
+	return s.authService.LoginWithIdentity(user, client)
+}
+
+// exchange échange le code d'autorisation et retourne l'identité portée par le jeton d'identité vérifié,
+// complétée si besoin par le point d'accès userinfo
+func (s *OIDCService) exchange(ctx context.Context, code string, state *models.OIDCLoginState) (*externalIdentity, error) {
+	provider, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+	ctx = oidc.ClientContext(ctx, s.httpClient)
+
+	token, err := s.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("code d'autorisation refusé par le fournisseur d'identité")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("le fournisseur d'identité n'a pas retourné de jeton d'identité")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: s.settings.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("jeton d'identité invalide : %w", err)
+	}
+	if idToken.Nonce != state.Nonce {
+		return nil, fmt.Errorf("jeton d'identité invalide : nonce inattendu")
+	}
+
+	claims := map[string]interface{}{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	// Certains fournisseurs ne placent l'adresse e-mail ou les groupes que dans userinfo
+	_, hasEmail := claims["email"]
+	_, hasGroups := claims[s.settings.GroupsClaim]
+	if (!hasEmail || !hasGroups) && provider.UserInfoEndpoint() != "" {
+		userInfo, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return nil, fmt.Errorf("lecture du profil auprès du fournisseur d'identité impossible : %w", err)
+		}
+		if userInfo.Subject != idToken.Subject {
+			return nil, fmt.Errorf("profil du fournisseur d'identité incohérent avec le jeton d'identité")
+		}
+		extra := map[string]interface{}{}
+		if err := userInfo.Claims(&extra); err != nil {
+			return nil, err
+		}
+		for key, value := range extra {
+			if _, ok := claims[key]; !ok {
+				claims[key] = value
+			}
+		}
+	}
+
+	identity := &externalIdentity{
+		Provider:      models.AuthProviderOIDC,
+		Subject:       idToken.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claimString(claims, "email"))),
+		EmailVerified: claimBool(claims, "email_verified"),
+		FirstName:     claimString(claims, "given_name"),
+		LastName:      claimString(claims, "family_name"),
+		Groups:        claimStrings(claims, s.settings.GroupsClaim),
+	}
+	if identity.FirstName == "" && identity.LastName == "" {
+		identity.FirstName = claimString(claims, "name")
+	}
+	return identity, nil
+}
+
+// getProvider lit la configuration du fournisseur d'identité à la première utilisation, pour que l'API
+// démarre même s'il est momentanément injoignable
+func (s *OIDCService) getProvider() (*oidc.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+	// Le contexte sert aussi au téléchargement ultérieur des clés : il ne doit pas être annulé
+	provider, err := oidc.NewProvider(oidc.ClientContext(context.Background(), s.httpClient), s.settings.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("fournisseur d'identité injoignable : %w", err)
+	}
+	s.provider = provider
+	return provider, nil
+}
+
+func (s *OIDCService) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     s.settings.ClientID,
+		ClientSecret: s.settings.ClientSecret,
+		RedirectURL:  s.settings.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       s.settings.Scopes,
+	}
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimBool accepte aussi "true" : certains fournisseurs transmettent email_verified sous forme de chaîne
+func claimBool(claims map[string]interface{}, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+// claimStrings lit une revendication multivaluée, transmise sous forme de liste ou de chaîne unique
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/mail"
+	"eduqr-backend/pkg/utils"
+)
+
+const (
+	// resetTokenSize est la taille en octets des jetons de réinitialisation
+	resetTokenSize = 32
+	// resetTokenTTL est la durée de validité d'un lien de réinitialisation
+	resetTokenTTL = time.Hour
+	// resetMailWorkers est le nombre d'envois d'e-mails de réinitialisation menés en parallèle
+	resetMailWorkers = 2
+	// resetMailQueueSize est le nombre de demandes en attente au-delà duquel les suivantes sont ignorées
+	resetMailQueueSize = 100
+)
+
+type PasswordResetService struct {
+	userRepo        *repositories.UserRepository
+	resetRepo       *repositories.PasswordResetRepository
+	sessionRepo     *repositories.SessionRepository
+	auditLogService *AuditLogService
+	mailSender      mail.Sender
+	resetURL        string
+
+	// mailQueue transmet les demandes aux workers d'envoi, pending suit celles non encore traitées
+	mailQueue chan *models.User
+	pending   sync.WaitGroup
+}
+
+func NewPasswordResetService(
+	userRepo *repositories.UserRepository,
+	resetRepo *repositories.PasswordResetRepository,
+	sessionRepo *repositories.SessionRepository,
+	auditLogService *AuditLogService,
+	mailSender mail.Sender,
+	resetURL string,
+) *PasswordResetService {
+	s := &PasswordResetService{
+		userRepo:        userRepo,
+		resetRepo:       resetRepo,
+		sessionRepo:     sessionRepo,
+		auditLogService: auditLogService,
+		mailSender:      mailSender,
+		resetURL:        resetURL,
+		mailQueue:       make(chan *models.User, resetMailQueueSize),
+	}
+	for i := 0; i < resetMailWorkers; i++ {
+		go s.mailWorker()
+	}
+	return s
+}
+
+// RequestReset envoie un lien de réinitialisation à l'adresse de contact de l'utilisateur.
+// Une adresse inconnue ne produit pas d'erreur, pour ne pas révéler quels comptes existent.
+// Le jeton et l'e-mail sont préparés en arrière-plan : la réponse ne dépend pas du temps
+// d'envoi SMTP, qui trahirait sinon l'existence du compte.
+func (s *PasswordResetService) RequestReset(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	s.pending.Add(1)
+	select {
+	case s.mailQueue <- user:
+	default:
+		// File pleine : la demande est abandonnée plutôt que de bloquer la réponse
+		s.pending.Done()
+		log.Printf("File des liens de réinitialisation pleine, demande de l'utilisateur %d ignorée", user.ID)
+	}
+	return nil
+}
+
+// Wait attend la fin des envois d'e-mails en attente
+func (s *PasswordResetService) Wait() {
+	s.pending.Wait()
+}
+
+// mailWorker envoie les liens de réinitialisation placés dans la file
+func (s *PasswordResetService) mailWorker() {
+	for user := range s.mailQueue {
+		if err := s.sendResetMail(user); err != nil {
+			// L'échec n'est pas renvoyé au client : il révélerait que le compte existe
+			log.Printf("Envoi du lien de réinitialisation à l'utilisateur %d impossible : %v", user.ID, err)
+		}
+		s.pending.Done()
+	}
+}
+
+// sendResetMail crée un jeton et l'envoie par e-mail. Le mot de passe d'un compte LDAP ou OIDC
+// est géré par le fournisseur d'identité : l'utilisateur en est informé et aucun jeton n'est créé.
+func (s *PasswordResetService) sendResetMail(user *models.User) error {
+	// Sans adresse de contact, l'adresse de connexion est utilisée
+	to := user.ContactEmail
+	if to == "" {
+		to = user.Email
+	}
+
+	if externalPassword(user) {
+		return s.mailSender.Send(mail.Message{
+			To:      to,
+			Subject: "Réinitialisation de votre mot de passe EduQR",
+			Body: fmt.Sprintf(
+				"Bonjour %s,\n\nUne réinitialisation du mot de passe de votre compte %s a été demandée.\n"+
+					"Ce compte se connecte via l'annuaire ou le fournisseur d'identité de votre établissement : "+
+					"son mot de passe ne peut pas être changé dans EduQR. Adressez-vous au service informatique de l'établissement.\n\n"+
+					"Si vous n'êtes pas à l'origine de cette demande, ignorez cet e-mail.",
+				user.FirstName, user.Email,
+			),
+		})
+	}
+
+	plain, err := utils.GenerateRandomToken(resetTokenSize)
+	if err != nil {
+		return err
+	}
+	token := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(plain),
+		ExpiresAt: time.Now().Add(resetTokenTTL),
+	}
+	if err := s.resetRepo.ReplaceToken(token); err != nil {
+		return err
+	}
+
+	return s.mailSender.Send(mail.Message{
+		To:      to,
+		Subject: "Réinitialisation de votre mot de passe EduQR",
+		Body: fmt.Sprintf(
+			"Bonjour %s,\n\nUne réinitialisation du mot de passe de votre compte %s a été demandée.\n"+
+				"Pour choisir un nouveau mot de passe, ouvrez le lien suivant (valable %d minutes) :\n\n%s?token=%s\n\n"+
+				"Si vous n'êtes pas à l'origine de cette demande, ignorez cet e-mail.",
+			user.FirstName, user.Email, int(resetTokenTTL.Minutes()), s.resetURL, plain,
+		),
+	})
+}
+
+// ResetPassword change le mot de passe avec un jeton reçu par e-mail, ferme toutes les sessions
+// de l'utilisateur et trace la réinitialisation dans le journal d'audit.
+// La robustesse du mot de passe est vérifiée par l'appelant avec ValidatePasswordStrength.
+func (s *PasswordResetService) ResetPassword(req *models.ResetPasswordRequest, ipAddress, userAgent string) error {
+	token, err := s.resetRepo.GetTokenByHash(utils.HashToken(req.Token))
+	if err != nil {
+		return fmt.Errorf("lien de réinitialisation invalide")
+	}
+	if token.UsedAt != nil {
+		return fmt.Errorf("lien de réinitialisation déjà utilisé")
+	}
+	if token.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("lien de réinitialisation expiré")
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return fmt.Errorf("utilisateur non trouvé")
+	}
+	// Le compte a pu passer sous LDAP ou OIDC depuis la demande
+	if externalPassword(user) {
+		return fmt.Errorf("le mot de passe de ce compte est géré par le fournisseur d'identité")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.resetRepo.ConsumeToken(token, hashedPassword); err != nil {
+		if errors.Is(err, repositories.ErrResetTokenUsed) {
+			return fmt.Errorf("lien de réinitialisation déjà utilisé")
+		}
+		return err
+	}
+
+	// Les sessions ouvertes avec l'ancien mot de passe ne doivent pas survivre à la réinitialisation
+	if _, err := s.sessionRepo.RevokeUserSessions(user.ID); err != nil {
+		return err
+	}
+
+	return s.auditLogService.LogPasswordReset(user.ID, user.Email, user.Role, ipAddress, userAgent)
+}
+
+// externalPassword indique si le mot de passe du compte est géré par l'annuaire LDAP ou le fournisseur OIDC
+func externalPassword(user *models.User) bool {
+	return user.AuthProvider == models.AuthProviderLDAP || user.AuthProvider == models.AuthProviderOIDC
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+)
+
+// Champs visibles d'un compte selon la permission du lecteur sur son rôle
+var (
+	userFieldsManage      = []string{"id", "email", "contact_email", "first_name", "last_name", "phone", "address", "avatar", "role", "created_at", "updated_at"}
+	userFieldsViewDetails = []string{"id", "first_name", "last_name", "role", "created_at"}
+	userFieldsView        = []string{"id", "first_name", "last_name"}
+)
+
+// PermissionService attribue les permissions du registre aux rôles. Les permissions effectives sont gardées
+// en mémoire : les modifications faites par le super admin s'appliquent aussitôt sur cette instance de l'API.
+type PermissionService struct {
+	permissionRepo *repositories.PermissionRepository
+
+	mu     sync.RWMutex
+	grants map[string]map[string]bool // Rôle -> permissions effectives
+}
+
+func NewPermissionService(permissionRepo *repositories.PermissionRepository) *PermissionService {
+	return &PermissionService{
+		permissionRepo: permissionRepo,
+		grants:         buildGrants(nil),
+	}
+}
+
+// Load applique les modifications enregistrées aux permissions par défaut
+func (s *PermissionService) Load() error {
+	overrides, err := s.permissionRepo.GetOverrides()
+	if err != nil {
+		return err
+	}
+	grants := buildGrants(overrides)
+
+	s.mu.Lock()
+	s.grants = grants
+	s.mu.Unlock()
+	return nil
+}
+
+// HasPermission indique si le rôle dispose de la permission
+func (s *PermissionService) HasPermission(role, permission string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.grants[role][permission]
+}
+
+// HasAnyPermission indique si le rôle dispose d'au moins une des permissions
+func (s *PermissionService) HasAnyPermission(role string, permissions ...string) bool {
+	for _, permission := range permissions {
+		if s.HasPermission(role, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageRole indique si un utilisateur peut gérer les comptes ayant le rôle cible
+func (s *PermissionService) CanManageRole(managerRole, targetRole string) bool {
+	return s.HasPermission(managerRole, models.UserPermission(models.UserActionManage, targetRole))
+}
+
+// CanViewRole indique si un utilisateur peut consulter les comptes ayant le rôle cible
+func (s *PermissionService) CanViewRole(viewerRole, targetRole string) bool {
+	return s.HasPermission(viewerRole, models.UserPermission(models.UserActionView, targetRole))
+}
+
+// CanDeleteRole indique si un utilisateur peut supprimer les comptes ayant le rôle cible
+func (s *PermissionService) CanDeleteRole(currentRole, targetRole string) bool {
+	return s.HasPermission(currentRole, models.UserPermission(models.UserActionDelete, targetRole))
+}
+
+// GetViewableFields retourne les champs d'un compte ayant le rôle cible visibles par l'utilisateur
+func (s *PermissionService) GetViewableFields(viewerRole, targetRole string) []string {
+	switch {
+	case s.HasPermission(viewerRole, models.UserPermission(models.UserActionManage, targetRole)):
+		return userFieldsManage
+	case s.HasPermission(viewerRole, models.UserPermission(models.UserActionViewDetails, targetRole)):
+		return userFieldsViewDetails
+	case s.HasPermission(viewerRole, models.UserPermission(models.UserActionView, targetRole)):
+		return userFieldsView
+	}
+	return []string{}
+}
+
+// GetRegistry retourne le registre des permissions et les permissions effectives de chaque rôle
+func (s *PermissionService) GetRegistry() *models.PermissionRegistryResponse {
+	response := &models.PermissionRegistryResponse{Permissions: models.PermissionRegistry()}
+	for _, role := range models.ValidRoles {
+		response.Roles = append(response.Roles, *s.rolePermissions(role))
+	}
+	return response
+}
+
+// UpdateRolePermissions remplace les permissions d'un rôle. Le super admin conserve toujours
+// la gestion des permissions, pour que personne ne puisse plus les modifier.
+func (s *PermissionService) UpdateRolePermissions(role string, permissions []string) (*models.RolePermissionsResponse, error) {
+	if !models.IsValidRole(role) {
+		return nil, fmt.Errorf("rôle invalide : %s", role)
+	}
+	wanted := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		if !models.IsValidPermission(permission) {
+			return nil, fmt.Errorf("permission inconnue : %s", permission)
+		}
+		wanted[permission] = true
+	}
+	if role == models.RoleSuperAdmin && !wanted[models.PermPermissionManageAny] {
+		return nil, fmt.Errorf("le super admin doit conserver la permission %s", models.PermPermissionManageAny)
+	}
+
+	// Seuls les écarts avec les permissions par défaut sont enregistrés
+	defaults := make(map[string]bool)
+	for _, permission := range models.DefaultRolePermissions[role] {
+		defaults[permission] = true
+	}
+	var overrides []models.RolePermissionOverride
+	for _, definition := range models.PermissionRegistry() {
+		if wanted[definition.Name] != defaults[definition.Name] {
+			overrides = append(overrides, models.RolePermissionOverride{
+				Role:       role,
+				Permission: definition.Name,
+				Granted:    wanted[definition.Name],
+			})
+		}
+	}
+	if err := s.permissionRepo.ReplaceRoleOverrides(role, overrides); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.grants[role] = wanted
+	s.mu.Unlock()
+	return s.rolePermissions(role), nil
+}
+
+func (s *PermissionService) rolePermissions(role string) *models.RolePermissionsResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	permissions := make([]string, 0, len(s.grants[role]))
+	for permission, granted := range s.grants[role] {
+		if granted {
+			permissions = append(permissions, permission)
+		}
+	}
+	sort.Strings(permissions)
+	return &models.RolePermissionsResponse{Role: role, Permissions: permissions}
+}
+
+// buildGrants calcule les permissions effectives de chaque rôle à partir des permissions par défaut
+func buildGrants(overrides []models.RolePermissionOverride) map[string]map[string]bool {
+	grants := make(map[string]map[string]bool, len(models.ValidRoles))
+	for _, role := range models.ValidRoles {
+		grants[role] = make(map[string]bool)
+		for _, permission := range models.DefaultRolePermissions[role] {
+			grants[role][permission] = true
+		}
+	}
+	for _, override := range overrides {
+		// Une permission retirée du registre n'est plus accordée
+		if grants[override.Role] == nil || !models.IsValidPermission(override.Permission) {
+			continue
+		}
+		if override.Granted {
+			grants[override.Role][override.Permission] = true
+		} else {
+			delete(grants[override.Role], override.Permission)
+		}
+	}
+	return grants
+}
@@ -11,16 +11,18 @@ import (
 )
 
 type PresenceService struct {
-	presenceRepo *repositories.PresenceRepository
-	courseRepo   *repositories.CourseRepository
-	userRepo     *repositories.UserRepository
+	presenceRepo      *repositories.PresenceRepository
+	courseRepo        *repositories.CourseRepository
+	userRepo          *repositories.UserRepository
+	permissionService *PermissionService
 }
 
-func NewPresenceService(presenceRepo *repositories.PresenceRepository, courseRepo *repositories.CourseRepository, userRepo *repositories.UserRepository) *PresenceService {
+func NewPresenceService(presenceRepo *repositories.PresenceRepository, courseRepo *repositories.CourseRepository, userRepo *repositories.UserRepository, permissionService *PermissionService) *PresenceService {
 	return &PresenceService{
-		presenceRepo: presenceRepo,
-		courseRepo:   courseRepo,
-		userRepo:     userRepo,
+		presenceRepo:      presenceRepo,
+		courseRepo:        courseRepo,
+		userRepo:          userRepo,
+		permissionService: permissionService,
 	}
 }
 
@@ -32,6 +34,11 @@ func (s *PresenceService) GenerateQRCode(courseID uint) (string, error) {
 		return "", fmt.Errorf("cours non trouvé: %v", err)
 	}
 
+	// Les cours annulés ou déplacés ne donnent pas lieu à un relevé de présence
+	if !course.IsActive() {
+		return "", fmt.Errorf("le cours a été annulé ou déplacé")
+	}
+
 	// Vérifier que le cours est en cours ou va bientôt commencer
 	now := time.Now()
 	if now.Before(course.StartTime.Add(-15 * time.Minute)) {
@@ -94,7 +101,7 @@ func (s *PresenceService) ValidateQRCode(qrCodeData string) (*models.QRCodeInfo,
 
 	// Vérifier que le cours est en cours
 	now := time.Now()
-	isValid := now.After(course.StartTime) && now.Before(course.EndTime)
+	isValid := course.IsActive() && now.After(course.StartTime) && now.Before(course.EndTime)
 
 	// Créer les informations du QR code
 	qrInfo := &models.QRCodeInfo{
@@ -121,7 +128,7 @@ func (s *PresenceService) ScanQRCode(qrCodeData string, studentID uint) (*models
 	}
 
 	if !qrInfo.IsValid {
-		return nil, fmt.Errorf("le QR code n'est plus valide (cours terminé ou pas encore commencé)")
+		return nil, fmt.Errorf("le QR code n'est plus valide (cours annulé, terminé ou pas encore commencé)")
 	}
 
 	// Vérifier que l'utilisateur est un étudiant
@@ -240,6 +247,14 @@ func (s *PresenceService) GetPresencesWithFilters(filters map[string]interface{}
 
 // CreatePresenceForAllStudents crée des enregistrements de présence pour tous les étudiants d'un cours
 func (s *PresenceService) CreatePresenceForAllStudents(courseID uint) error {
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return fmt.Errorf("cours non trouvé: %v", err)
+	}
+	if !course.IsActive() {
+		return fmt.Errorf("le cours a été annulé ou déplacé")
+	}
+
 	return s.presenceRepo.CreatePresenceForAllStudents(courseID)
 }
 
@@ -255,47 +270,33 @@ func (s *PresenceService) generateUniqueToken() (string, error) {
 
 // CanViewQRCode vérifie si l'utilisateur peut voir le QR code
 func (s *PresenceService) CanViewQRCode(userID uint, courseID uint) (bool, error) {
-	user, err := s.userRepo.FindByID(userID)
-	if err != nil {
-		return false, err
-	}
-
-	// Les admins et super admins peuvent voir tous les QR codes
-	if user.Role == models.RoleAdmin || user.Role == models.RoleSuperAdmin {
-		return true, nil
-	}
-
-	// Les professeurs peuvent voir les QR codes de leurs cours
-	if user.Role == models.RoleProfesseur {
-		course, err := s.courseRepo.GetCourseByID(courseID)
-		if err != nil {
-			return false, err
-		}
-		return course.TeacherID == userID, nil
-	}
-
-	return false, nil
+	// Par défaut, les admins et super admins voient tous les QR codes et les professeurs ceux de leurs cours
+	return s.hasCoursePermission(userID, courseID, models.PermQRCodeReadAny, models.PermQRCodeReadOwn)
 }
 
 // CanRegenerateQRCode vérifie si l'utilisateur peut régénérer un QR code
 func (s *PresenceService) CanRegenerateQRCode(userID uint, courseID uint) (bool, error) {
+	return s.hasCoursePermission(userID, courseID, models.PermQRCodeRegenerateAny, models.PermQRCodeRegenerateOwn)
+}
+
+// hasCoursePermission vérifie si le rôle de l'utilisateur dispose de la permission sur tous les cours, ou sur
+// ses propres cours s'il en est l'enseignant, y compris ceux qu'il assure en remplacement
+func (s *PresenceService) hasCoursePermission(userID, courseID uint, anyPermission, ownPermission string) (bool, error) {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return false, err
 	}
 
-	// Les admins et super admins peuvent régénérer tous les QR codes
-	if user.Role == models.RoleAdmin || user.Role == models.RoleSuperAdmin {
+	if s.permissionService.HasPermission(user.Role, anyPermission) {
 		return true, nil
 	}
 
-	// Les professeurs peuvent régénérer les QR codes de leurs cours
-	if user.Role == models.RoleProfesseur {
+	if s.permissionService.HasPermission(user.Role, ownPermission) {
 		course, err := s.courseRepo.GetCourseByID(courseID)
 		if err != nil {
 			return false, err
 		}
-		return course.TeacherID == userID, nil
+		return course.HasTeachingRights(userID), nil
 	}
 
 	return false, nil
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+)
+
+type ReportService struct {
+	courseRepo   *repositories.CourseRepository
+	userRepo     *repositories.UserRepository
+	roomRepo     *repositories.RoomRepository
+	presenceRepo *repositories.PresenceRepository
+}
+
+func NewReportService(
+	courseRepo *repositories.CourseRepository,
+	userRepo *repositories.UserRepository,
+	roomRepo *repositories.RoomRepository,
+	presenceRepo *repositories.PresenceRepository,
+) *ReportService {
+	return &ReportService{
+		courseRepo:   courseRepo,
+		userRepo:     userRepo,
+		roomRepo:     roomRepo,
+		presenceRepo: presenceRepo,
+	}
+}
+
+// Horaires d'ouverture des salles par défaut pour le rapport d'occupation
+const (
+	defaultOpeningHour = 8
+	defaultClosingHour = 20
+)
+
+// GetTeacherWorkload calcule la charge d'enseignement des professeurs entre deux dates.
+// Si teacherID vaut 0, tous les professeurs sont inclus.
+func (s *ReportService) GetTeacherWorkload(startDate, endDate time.Time, teacherID uint) ([]models.TeacherWorkloadResponse, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("la date de fin doit être après la date de début")
+	}
+
+	workloads := map[uint]*models.TeacherWorkloadResponse{}
+	addTeacher := func(teacher models.User) {
+		if _, ok := workloads[teacher.ID]; !ok {
+			workloads[teacher.ID] = &models.TeacherWorkloadResponse{
+				TeacherID:   teacher.ID,
+				TeacherName: teacher.FirstName + " " + teacher.LastName,
+				StartDate:   startDate,
+				EndDate:     endDate,
+			}
+		}
+	}
+
+	if teacherID != 0 {
+		teacher, err := s.userRepo.FindByID(teacherID)
+		if err != nil {
+			return nil, fmt.Errorf("enseignant non trouvé")
+		}
+		if teacher.Role != models.RoleProfesseur {
+			return nil, fmt.Errorf("l'utilisateur sélectionné n'est pas un enseignant")
+		}
+		addTeacher(*teacher)
+	} else {
+		users, err := s.userRepo.FindAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			if user.Role == models.RoleProfesseur {
+				addTeacher(user)
+			}
+		}
+	}
+
+	courses, err := s.courseRepo.GetCoursesByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, course := range courses {
+		if !course.IsActive() {
+			continue
+		}
+
+		owner, hasOwner := workloads[course.TeacherID]
+		if course.SubstituteTeacherID == nil {
+			if hasOwner {
+				owner.CourseCount++
+				owner.CourseMinutes += course.Duration
+			}
+			continue
+		}
+
+		if hasOwner {
+			owner.ReplacedCount++
+			owner.ReplacedMinutes += course.Duration
+		}
+		if substitute, ok := workloads[*course.SubstituteTeacherID]; ok {
+			substitute.SubstituteCount++
+			substitute.SubstituteMinutes += course.Duration
+		}
+	}
+
+	responses := make([]models.TeacherWorkloadResponse, 0, len(workloads))
+	for _, workload := range workloads {
+		workload.TotalMinutes = workload.CourseMinutes + workload.SubstituteMinutes
+		responses = append(responses, *workload)
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].TeacherID < responses[j].TeacherID
+	})
+
+	return responses, nil
+}
+
+// timeRange est un intervalle de temps [start, end[
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// roomUsage accumule l'occupation d'une salle simple ou d'une sous-salle
+type roomUsage struct {
+	ranges  []timeRange
+	courses map[uint]bool
+}
+
+// GetRoomOccupancy calcule les heures occupées par les cours au regard des heures d'ouverture,
+// par salle, par étage et par bâtiment.
+func (s *ReportService) GetRoomOccupancy(startDate, endDate time.Time, filter *models.RoomOccupancyFilter) (*models.RoomOccupancyReport, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("la date de fin doit être après la date de début")
+	}
+
+	openingHour, closingHour := defaultOpeningHour, defaultClosingHour
+	if filter.OpeningHour != nil {
+		openingHour = *filter.OpeningHour
+	}
+	if filter.ClosingHour != nil {
+		closingHour = *filter.ClosingHour
+	}
+	if openingHour < 0 || closingHour > 24 || openingHour >= closingHour {
+		return nil, fmt.Errorf("horaires d'ouverture invalides")
+	}
+
+	rooms, err := s.roomRepo.GetAllRooms(&models.RoomFilter{
+		Building:        filter.Building,
+		Floor:           filter.Floor,
+		IncludeSubRooms: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Name < rooms[j].Name })
+
+	roomsByID := make(map[uint]*models.Room, len(rooms))
+	for i := range rooms {
+		roomsByID[rooms[i].ID] = &rooms[i]
+	}
+
+	// Les salles découpées sont suivies au niveau de leurs sous-salles
+	usages := make(map[uint]*roomUsage)
+	for _, room := range rooms {
+		if len(room.Children) == 0 {
+			usages[room.ID] = &roomUsage{courses: map[uint]bool{}}
+		}
+	}
+
+	courses, err := s.courseRepo.GetCoursesByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var courseIDs []uint
+	capacities := make(map[uint]int)
+	for _, course := range courses {
+		if !course.IsActive() {
+			continue
+		}
+
+		touched := false
+		for _, id := range course.RoomIDs() {
+			room, ok := roomsByID[id]
+			if !ok {
+				continue
+			}
+			leafIDs := []uint{id}
+			if len(room.Children) > 0 {
+				leafIDs = leafIDs[:0]
+				for _, child := range room.Children {
+					leafIDs = append(leafIDs, child.ID)
+				}
+			}
+			for _, leafID := range leafIDs {
+				if usage, ok := usages[leafID]; ok {
+					usage.ranges = append(usage.ranges, timeRange{course.StartTime, course.EndTime})
+					usage.courses[course.ID] = true
+					touched = true
+				}
+			}
+		}
+		if touched {
+			courseIDs = append(courseIDs, course.ID)
+			capacities[course.ID] = spaceCapacity(course.RoomIDs(), roomsByID)
+		}
+	}
+
+	attended, err := s.presenceRepo.GetAttendedCountsByCourses(courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := openingWindows(startDate, endDate, openingHour, closingHour, filter.IncludeWeekends)
+	windowHours := 0.0
+	for _, window := range windows {
+		windowHours += window.end.Sub(window.start).Hours()
+	}
+
+	report := &models.RoomOccupancyReport{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		OpeningHour: openingHour,
+		ClosingHour: closingHour,
+		Rooms:       make([]models.RoomOccupancy, 0, len(rooms)),
+		Floors:      []models.OccupancySummary{},
+		Buildings:   []models.OccupancySummary{},
+	}
+	floors := make(map[[2]string]*models.OccupancySummary)
+	buildings := make(map[string]*models.OccupancySummary)
+	bookedHours := make(map[uint]float64)
+
+	for _, room := range rooms {
+		usage, isLeaf := usages[room.ID]
+		if !isLeaf {
+			continue
+		}
+		booked := bookedWithin(usage.ranges, windows)
+		bookedHours[room.ID] = booked
+
+		floorKey := [2]string{room.Building, room.Floor}
+		if floors[floorKey] == nil {
+			floors[floorKey] = &models.OccupancySummary{Building: room.Building, Floor: room.Floor}
+		}
+		if buildings[room.Building] == nil {
+			buildings[room.Building] = &models.OccupancySummary{Building: room.Building}
+		}
+		for _, summary := range []*models.OccupancySummary{floors[floorKey], buildings[room.Building], &report.Total} {
+			summary.RoomCount++
+			summary.AvailableHours += windowHours
+			summary.BookedHours += booked
+		}
+	}
+
+	for _, room := range rooms {
+		occupancy := models.RoomOccupancy{
+			RoomID:       room.ID,
+			RoomName:     room.Name,
+			Building:     room.Building,
+			Floor:        room.Floor,
+			ParentID:     room.ParentID,
+			SubRoomCount: len(room.Children),
+			Capacity:     room.Capacity,
+		}
+
+		leafIDs := []uint{room.ID}
+		if len(room.Children) > 0 {
+			leafIDs = leafIDs[:0]
+			for _, child := range room.Children {
+				if _, ok := usages[child.ID]; ok {
+					leafIDs = append(leafIDs, child.ID)
+				}
+			}
+		}
+
+		roomCourses := make(map[uint]bool)
+		for _, leafID := range leafIDs {
+			occupancy.AvailableHours += windowHours
+			occupancy.BookedHours += bookedHours[leafID]
+			for courseID := range usages[leafID].courses {
+				roomCourses[courseID] = true
+			}
+		}
+		occupancy.CourseCount = len(roomCourses)
+		occupancy.UtilisationRate = utilisationRate(occupancy.BookedHours, occupancy.AvailableHours)
+		occupancy.AvailableHours = roundTwoDecimals(occupancy.AvailableHours)
+		occupancy.BookedHours = roundTwoDecimals(occupancy.BookedHours)
+
+		// Fréquentation : présents ou en retard, rapportés à la capacité de l'espace réellement utilisé
+		totalAttended, fillSum, fillCount := 0, 0.0, 0
+		for courseID := range roomCourses {
+			count, ok := attended[courseID]
+			if !ok {
+				continue
+			}
+			occupancy.AttendedCourses++
+			totalAttended += count
+			if capacity := capacities[courseID]; capacity > 0 {
+				fillSum += float64(count) / float64(capacity) * 100
+				fillCount++
+			}
+		}
+		if occupancy.AttendedCourses > 0 {
+			average := roundTwoDecimals(float64(totalAttended) / float64(occupancy.AttendedCourses))
+			occupancy.AverageAttendance = &average
+		}
+		if fillCount > 0 {
+			average := roundTwoDecimals(fillSum / float64(fillCount))
+			occupancy.AverageFillRate = &average
+		}
+
+		report.Rooms = append(report.Rooms, occupancy)
+	}
+
+	for _, summary := range floors {
+		report.Floors = append(report.Floors, finalizeSummary(*summary))
+	}
+	sort.Slice(report.Floors, func(i, j int) bool {
+		if report.Floors[i].Building != report.Floors[j].Building {
+			return report.Floors[i].Building < report.Floors[j].Building
+		}
+		return report.Floors[i].Floor < report.Floors[j].Floor
+	})
+	for _, summary := range buildings {
+		report.Buildings = append(report.Buildings, finalizeSummary(*summary))
+	}
+	sort.Slice(report.Buildings, func(i, j int) bool {
+		return report.Buildings[i].Building < report.Buildings[j].Building
+	})
+	report.Total = finalizeSummary(report.Total)
+
+	return report, nil
+}
+
+// RoomOccupancyCSV exporte le rapport d'occupation au format CSV : une ligne par salle,
+// puis les cumuls par étage, par bâtiment et global
+func (s *ReportService) RoomOccupancyCSV(report *models.RoomOccupancyReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+	formatOptional := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return formatFloat(*value)
+	}
+
+	records := [][]string{{
+		"level", "building", "floor", "room_id", "room_name", "parent_id", "capacity",
+		"available_hours", "booked_hours", "utilisation_rate",
+		"course_count", "attended_courses", "average_attendance", "average_fill_rate",
+	}}
+	for _, room := range report.Rooms {
+		parentID := ""
+		if room.ParentID != nil {
+			parentID = strconv.FormatUint(uint64(*room.ParentID), 10)
+		}
+		records = append(records, []string{
+			"room", room.Building, room.Floor, strconv.FormatUint(uint64(room.RoomID), 10), room.RoomName, parentID, strconv.Itoa(room.Capacity),
+			formatFloat(room.AvailableHours), formatFloat(room.BookedHours), formatFloat(room.UtilisationRate),
+			strconv.Itoa(room.CourseCount), strconv.Itoa(room.AttendedCourses), formatOptional(room.AverageAttendance), formatOptional(room.AverageFillRate),
+		})
+	}
+	summaryRecord := func(level string, summary models.OccupancySummary) []string {
+		return []string{
+			level, summary.Building, summary.Floor, "", "", "", "",
+			formatFloat(summary.AvailableHours), formatFloat(summary.BookedHours), formatFloat(summary.UtilisationRate),
+			"", "", "", "",
+		}
+	}
+	for _, floor := range report.Floors {
+		records = append(records, summaryRecord("floor", floor))
+	}
+	for _, building := range report.Buildings {
+		records = append(records, summaryRecord("building", building))
+	}
+	records = append(records, summaryRecord("total", report.Total))
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// openingWindows découpe la période en plages d'ouverture quotidiennes
+func openingWindows(startDate, endDate time.Time, openingHour, closingHour int, includeWeekends bool) []timeRange {
+	var windows []timeRange
+	day := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	for ; day.Before(endDate); day = day.AddDate(0, 0, 1) {
+		if !includeWeekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+		window := timeRange{
+			start: day.Add(time.Duration(openingHour) * time.Hour),
+			end:   day.Add(time.Duration(closingHour) * time.Hour),
+		}
+		if window.start.Before(startDate) {
+			window.start = startDate
+		}
+		if window.end.After(endDate) {
+			window.end = endDate
+		}
+		if window.end.After(window.start) {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+// bookedWithin calcule les heures occupées pendant les plages d'ouverture,
+// sans compter deux fois les cours qui se chevauchent
+func bookedWithin(ranges []timeRange, windows []timeRange) float64 {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start.Before(ranges[j].start) })
+
+	var merged []timeRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && !r.start.After(merged[n-1].end) {
+			if r.end.After(merged[n-1].end) {
+				merged[n-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	total := 0.0
+	for _, r := range merged {
+		for _, window := range windows {
+			start, end := r.start, r.end
+			if window.start.After(start) {
+				start = window.start
+			}
+			if window.end.Before(end) {
+				end = window.end
+			}
+			if end.After(start) {
+				total += end.Sub(start).Hours()
+			}
+		}
+	}
+	return total
+}
+
+// spaceCapacity retourne la capacité cumulée des salles utilisées par un cours (0 si l'une est inconnue)
+func spaceCapacity(roomIDs []uint, roomsByID map[uint]*models.Room) int {
+	capacity := 0
+	for _, id := range roomIDs {
+		room, ok := roomsByID[id]
+		if !ok || room.Capacity == 0 {
+			return 0
+		}
+		capacity += room.Capacity
+	}
+	return capacity
+}
+
+func finalizeSummary(summary models.OccupancySummary) models.OccupancySummary {
+	summary.UtilisationRate = utilisationRate(summary.BookedHours, summary.AvailableHours)
+	summary.AvailableHours = roundTwoDecimals(summary.AvailableHours)
+	summary.BookedHours = roundTwoDecimals(summary.BookedHours)
+	return summary
+}
+
+func utilisationRate(booked, available float64) float64 {
+	if available == 0 {
+		return 0
+	}
+	return roundTwoDecimals(booked / available * 100)
+}
+
+// roundTwoDecimals arrondit à deux décimales (heures, taux, moyennes)
+func roundTwoDecimals(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+)
+
+type RoomBookingService struct {
+	bookingRepo         *repositories.RoomBookingRepository
+	roomRepo            *repositories.RoomRepository
+	courseRepo          *repositories.CourseRepository
+	notificationService *NotificationService
+	permissionService   *PermissionService
+}
+
+func NewRoomBookingService(
+	bookingRepo *repositories.RoomBookingRepository,
+	roomRepo *repositories.RoomRepository,
+	courseRepo *repositories.CourseRepository,
+	notificationService *NotificationService,
+	permissionService *PermissionService,
+) *RoomBookingService {
+	return &RoomBookingService{
+		bookingRepo:         bookingRepo,
+		roomRepo:            roomRepo,
+		courseRepo:          courseRepo,
+		notificationService: notificationService,
+		permissionService:   permissionService,
+	}
+}
+
+// CreateBooking enregistre une demande de réservation, en attente de validation par un administrateur
+func (s *RoomBookingService) CreateBooking(req *models.CreateRoomBookingRequest, requesterID uint) (*models.RoomBookingResponse, error) {
+	if !req.EndTime.After(req.StartTime) {
+		return nil, fmt.Errorf("la fin de la réservation doit être après le début")
+	}
+	if req.StartTime.Before(time.Now()) {
+		return nil, fmt.Errorf("impossible de réserver une salle dans le passé")
+	}
+
+	if _, err := s.roomRepo.GetRoomByID(req.RoomID); err != nil {
+		return nil, fmt.Errorf("salle non trouvée")
+	}
+	mergedRooms, err := resolveMergedRooms(s.roomRepo, req.RoomID, req.MergedRoomIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inutile de soumettre une demande qui ne pourra pas être validée
+	if err := s.ensureRoomFree(models.OccupiedRoomIDs(req.RoomID, mergedRooms), req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	purpose := req.Purpose
+	if purpose == "" {
+		purpose = models.RoomBookingPurposeOther
+	}
+
+	booking := &models.RoomBooking{
+		RoomID:      req.RoomID,
+		MergedRooms: mergedRooms,
+		RequesterID: requesterID,
+		Title:       req.Title,
+		Purpose:     purpose,
+		Description: req.Description,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Status:      models.RoomBookingStatusPending,
+	}
+	if err := s.bookingRepo.CreateBooking(booking); err != nil {
+		return nil, err
+	}
+
+	return s.getBookingResponse(booking.ID)
+}
+
+// GetBookings récupère les réservations ; sans la permission de traiter les demandes (un administrateur),
+// seules les demandes de l'utilisateur sont visibles
+func (s *RoomBookingService) GetBookings(filter *models.RoomBookingFilter, userID uint, userRole string) ([]models.RoomBookingResponse, error) {
+	if !s.permissionService.HasPermission(userRole, models.PermRoomBookingReviewAny) {
+		filter.RequesterID = &userID
+	}
+
+	bookings, err := s.bookingRepo.GetBookings(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.RoomBookingResponse, len(bookings))
+	for i, booking := range bookings {
+		responses[i] = booking.ToRoomBookingResponse()
+	}
+	return responses, nil
+}
+
+// GetBookingByID récupère une réservation par son ID
+func (s *RoomBookingService) GetBookingByID(id uint) (*models.RoomBookingResponse, error) {
+	return s.getBookingResponse(id)
+}
+
+// CanAccessBooking indique si l'utilisateur peut consulter ou annuler la réservation (demandeur ou
+// utilisateur autorisé à traiter les demandes)
+func (s *RoomBookingService) CanAccessBooking(id, userID uint, userRole string) (bool, error) {
+	booking, err := s.bookingRepo.GetBookingByID(id)
+	if err != nil {
+		return false, err
+	}
+	return s.permissionService.HasPermission(userRole, models.PermRoomBookingReviewAny) || booking.RequesterID == userID, nil
+}
+
+// ReviewBooking valide ou refuse une demande en attente. La validation revérifie l'occupation de la salle.
+func (s *RoomBookingService) ReviewBooking(id uint, req *models.ReviewRoomBookingRequest, reviewerID uint) (*models.RoomBookingResponse, error) {
+	booking, err := s.bookingRepo.GetBookingByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("réservation non trouvée")
+	}
+	if booking.Status != models.RoomBookingStatusPending {
+		return nil, fmt.Errorf("cette réservation a déjà été traitée")
+	}
+
+	if req.Status == models.RoomBookingStatusApproved {
+		if err := s.ensureRoomFree(booking.RoomIDs(), booking.StartTime, booking.EndTime); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.bookingRepo.ReviewBooking(id, req.Status, reviewerID, req.ReviewComment); err != nil {
+		return nil, fmt.Errorf("erreur lors du traitement de la réservation")
+	}
+
+	booking.Status = req.Status
+	booking.ReviewComment = req.ReviewComment
+	// Une notification manquée ne doit pas empêcher la décision
+	if err := s.notificationService.NotifyRoomBookingReviewed(booking); err != nil {
+		log.Printf("Erreur lors de la notification de la réservation %d: %v", booking.ID, err)
+	}
+
+	return s.getBookingResponse(id)
+}
+
+// CancelBooking annule une réservation en attente ou validée
+func (s *RoomBookingService) CancelBooking(id uint) (*models.RoomBookingResponse, error) {
+	booking, err := s.bookingRepo.GetBookingByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("réservation non trouvée")
+	}
+	if !booking.IsActive() {
+		return nil, fmt.Errorf("cette réservation ne peut plus être annulée")
+	}
+
+	if err := s.bookingRepo.UpdateBookingStatus(id, models.RoomBookingStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	return s.getBookingResponse(id)
+}
+
+// GetRoomTimeline retrace, sur la période, la configuration de la salle à chaque instant.
+// Pour une salle modulable (ou l'une de ses sous-salles), la chronologie couvre la salle parente
+// et toutes ses sous-salles : salle entière, sous-salles séparées ou réunies.
+func (s *RoomBookingService) GetRoomTimeline(roomID uint, start, end time.Time) (*models.RoomTimelineResponse, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("la date de fin doit être après la date de début")
+	}
+
+	room, err := s.roomRepo.GetRoomByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.ParentID != nil {
+		if room, err = s.roomRepo.GetRoomByID(*room.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	roomIDs := []uint{room.ID}
+	names := map[uint]string{room.ID: room.Name}
+	children := append([]models.Room(nil), room.Children...)
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	for _, child := range children {
+		roomIDs = append(roomIDs, child.ID)
+		names[child.ID] = child.Name
+	}
+
+	activities, err := s.getRoomActivities(roomIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Points de changement : bornes de la période et début/fin de chaque occupation
+	points := []time.Time{start, end}
+	for _, activity := range activities {
+		if activity.StartTime.After(start) {
+			points = append(points, activity.StartTime)
+		}
+		if activity.EndTime.Before(end) {
+			points = append(points, activity.EndTime)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+
+	segments := []models.RoomTimelineSegment{}
+	for i := 0; i+1 < len(points); i++ {
+		segStart, segEnd := points[i], points[i+1]
+		if !segEnd.After(segStart) {
+			continue
+		}
+		var current []models.RoomActivity
+		for _, activity := range activities {
+			if activity.StartTime.Before(segEnd) && activity.EndTime.After(segStart) {
+				current = append(current, activity)
+			}
+		}
+		spaces := timelineSpaces(room, children, current, names)
+		segments = append(segments, models.RoomTimelineSegment{
+			Start:         segStart,
+			End:           segEnd,
+			Configuration: timelineConfiguration(spaces),
+			Spaces:        spaces,
+		})
+	}
+
+	return &models.RoomTimelineResponse{
+		Room:     room.ToRoomResponse(),
+		Start:    start,
+		End:      end,
+		Segments: segments,
+	}, nil
+}
+
+// getRoomActivities rassemble les cours actifs et les réservations validées occupant les salles
+func (s *RoomBookingService) getRoomActivities(roomIDs []uint, start, end time.Time) ([]models.RoomActivity, error) {
+	courses, err := s.courseRepo.GetActiveCoursesInRooms(roomIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.bookingRepo.GetApprovedBookingsInRooms(roomIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var activities []models.RoomActivity
+	for _, course := range courses {
+		activities = append(activities, models.RoomActivity{
+			Type:      models.RoomActivityCourse,
+			ID:        course.ID,
+			Title:     course.Name,
+			StartTime: course.StartTime,
+			EndTime:   course.EndTime,
+			RoomIDs:   course.RoomIDs(),
+		})
+	}
+	for _, booking := range bookings {
+		activities = append(activities, models.RoomActivity{
+			Type:      models.RoomActivityBooking,
+			ID:        booking.ID,
+			Title:     booking.Title,
+			StartTime: booking.StartTime,
+			EndTime:   booking.EndTime,
+			RoomIDs:   booking.RoomIDs(),
+		})
+	}
+	return activities, nil
+}
+
+// timelineSpaces découpe la salle en espaces selon les occupations en cours
+func timelineSpaces(room *models.Room, children []models.Room, current []models.RoomActivity, names map[uint]string) []models.RoomTimelineSpace {
+	// Salle entière occupée, ou salle sans sous-salles
+	for i := range current {
+		for _, id := range current[i].RoomIDs {
+			if id == room.ID {
+				return []models.RoomTimelineSpace{newTimelineSpace([]uint{room.ID}, names, &current[i])}
+			}
+		}
+	}
+	if len(children) == 0 {
+		return []models.RoomTimelineSpace{newTimelineSpace([]uint{room.ID}, names, nil)}
+	}
+
+	var spaces []models.RoomTimelineSpace
+	used := make(map[uint]bool)
+	for _, child := range children {
+		if used[child.ID] {
+			continue
+		}
+		var activity *models.RoomActivity
+		ids := []uint{child.ID}
+		for i := range current {
+			if containsRoomID(current[i].RoomIDs, child.ID) {
+				activity = &current[i]
+				ids = nil
+				for _, sibling := range children {
+					if containsRoomID(current[i].RoomIDs, sibling.ID) {
+						ids = append(ids, sibling.ID)
+					}
+				}
+				break
+			}
+		}
+		for _, id := range ids {
+			used[id] = true
+		}
+		spaces = append(spaces, newTimelineSpace(ids, names, activity))
+	}
+	return spaces
+}
+
+func newTimelineSpace(ids []uint, names map[uint]string, activity *models.RoomActivity) models.RoomTimelineSpace {
+	space := models.RoomTimelineSpace{
+		RoomIDs:  ids,
+		Merged:   len(ids) > 1,
+		Activity: activity,
+	}
+	for _, id := range ids {
+		space.RoomNames = append(space.RoomNames, names[id])
+	}
+	return space
+}
+
+// timelineConfiguration résume la configuration, ex. « Amphi A+Amphi B | Amphi C »
+func timelineConfiguration(spaces []models.RoomTimelineSpace) string {
+	parts := make([]string, 0, len(spaces))
+	for _, space := range spaces {
+		parts = append(parts, strings.Join(space.RoomNames, "+"))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func containsRoomID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureRoomFree refuse un créneau où l'une des salles (ou une salle liée) est occupée par un cours ou une réservation validée
+func (s *RoomBookingService) ensureRoomFree(roomIDs []uint, startTime, endTime time.Time) error {
+	conflicts, err := s.courseRepo.CheckRoomConflicts(roomIDs, startTime, endTime)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		conflict := conflicts[0]
+		return fmt.Errorf("la salle %s est déjà occupée par \"%s\" du %s au %s",
+			conflict.RoomName, conflict.CourseName, conflict.StartTime.Format("02/01/2006 15:04"), conflict.EndTime.Format("02/01/2006 15:04"))
+	}
+	return nil
+}
+
+func (s *RoomBookingService) getBookingResponse(id uint) (*models.RoomBookingResponse, error) {
+	booking, err := s.bookingRepo.GetBookingByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := booking.ToRoomBookingResponse()
+	return &response, nil
+}
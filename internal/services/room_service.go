@@ -58,6 +58,7 @@ func (s *RoomService) CreateRoom(req *models.CreateRoomRequest) (*models.RoomRes
 		Building:  req.Building,
 		Floor:     req.Floor,
 		IsModular: req.IsModular,
+		Capacity:  req.Capacity,
 	}
 
 	err = s.roomRepo.CreateRoom(room)
@@ -65,6 +66,12 @@ func (s *RoomService) CreateRoom(req *models.CreateRoomRequest) (*models.RoomRes
 		return nil, err
 	}
 
+	if len(req.Equipment) > 0 {
+		if err := s.roomRepo.ReplaceEquipment(room, req.Equipment); err != nil {
+			return nil, err
+		}
+	}
+
 	// Si la salle est modulable et qu'on a spécifié un nombre de sous-salles
 	if req.IsModular && req.SubRoomsCount >= 2 {
 		err = s.createSubRooms(room.ID, req.Name, req.SubRoomsCount, req.Building, req.Floor)
@@ -105,12 +112,19 @@ func (s *RoomService) UpdateRoom(id uint, req *models.UpdateRoomRequest) (*model
 	room.Building = req.Building
 	room.Floor = req.Floor
 	room.IsModular = req.IsModular
+	room.Capacity = req.Capacity
 
 	err = s.roomRepo.UpdateRoom(room)
 	if err != nil {
 		return nil, err
 	}
 
+	if req.Equipment != nil {
+		if err := s.roomRepo.ReplaceEquipment(room, req.Equipment); err != nil {
+			return nil, err
+		}
+	}
+
 	// Récupérer la salle mise à jour
 	updatedRoom, err := s.roomRepo.GetRoomByID(id)
 	if err != nil {
@@ -181,3 +195,48 @@ func (s *RoomService) GetModularRooms() ([]models.RoomResponse, error) {
 
 	return responses, nil
 }
+
+// GetAllEquipment récupère les étiquettes d'équipement connues
+func (s *RoomService) GetAllEquipment() ([]string, error) {
+	equipment, err := s.roomRepo.GetAllEquipment()
+	if err != nil {
+		return nil, err
+	}
+	return models.EquipmentNames(equipment), nil
+}
+
+// resolveMergedRooms vérifie que les sous-salles à réunir sont des sœurs de la salle principale
+// (même salle modulable parente) et les retourne dans l'ordre demandé
+func resolveMergedRooms(roomRepo *repositories.RoomRepository, roomID uint, mergedRoomIDs []uint) ([]models.Room, error) {
+	if len(mergedRoomIDs) == 0 {
+		return nil, nil
+	}
+
+	room, err := roomRepo.GetRoomByID(roomID)
+	if err != nil {
+		return nil, errors.New("salle non trouvée")
+	}
+	if room.ParentID == nil {
+		return nil, fmt.Errorf("seules les sous-salles d'une salle modulable peuvent être réunies, %s n'en est pas une", room.Name)
+	}
+
+	seen := map[uint]bool{roomID: true}
+	merged := make([]models.Room, 0, len(mergedRoomIDs))
+	for _, id := range mergedRoomIDs {
+		if seen[id] {
+			return nil, errors.New("une sous-salle ne peut être réunie qu'une seule fois")
+		}
+		seen[id] = true
+
+		sibling, err := roomRepo.GetRoomByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("salle %d non trouvée", id)
+		}
+		if sibling.ParentID == nil || *sibling.ParentID != *room.ParentID {
+			return nil, fmt.Errorf("%s n'est pas une sous-salle voisine de %s", sibling.Name, room.Name)
+		}
+		merged = append(merged, *sibling)
+	}
+
+	return merged, nil
+}
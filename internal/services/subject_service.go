@@ -4,14 +4,16 @@ import (
 	"eduqr-backend/internal/models"
 	"eduqr-backend/internal/repositories"
 	"errors"
+	"fmt"
 )
 
 type SubjectService struct {
 	subjectRepo *repositories.SubjectRepository
+	userRepo    *repositories.UserRepository
 }
 
-func NewSubjectService(subjectRepo *repositories.SubjectRepository) *SubjectService {
-	return &SubjectService{subjectRepo: subjectRepo}
+func NewSubjectService(subjectRepo *repositories.SubjectRepository, userRepo *repositories.UserRepository) *SubjectService {
+	return &SubjectService{subjectRepo: subjectRepo, userRepo: userRepo}
 }
 
 // GetAllSubjects récupère toutes les matières
@@ -63,6 +65,12 @@ func (s *SubjectService) CreateSubject(req *models.CreateSubjectRequest) (*model
 		return nil, err
 	}
 
+	if len(req.RequiredEquipment) > 0 {
+		if err := s.subjectRepo.ReplaceRequiredEquipment(subject, req.RequiredEquipment); err != nil {
+			return nil, err
+		}
+	}
+
 	response := subject.ToSubjectResponse()
 	return &response, nil
 }
@@ -94,6 +102,12 @@ func (s *SubjectService) UpdateSubject(id uint, req *models.UpdateSubjectRequest
 		return nil, err
 	}
 
+	if req.RequiredEquipment != nil {
+		if err := s.subjectRepo.ReplaceRequiredEquipment(subject, req.RequiredEquipment); err != nil {
+			return nil, err
+		}
+	}
+
 	response := subject.ToSubjectResponse()
 	return &response, nil
 }
@@ -117,3 +131,149 @@ func (s *SubjectService) DeleteSubject(id uint) error {
 
 	return s.subjectRepo.DeleteSubject(id)
 }
+
+// GetSubjectTeachers récupère les enseignants qualifiés pour une matière, responsable signalé
+func (s *SubjectService) GetSubjectTeachers(id uint) ([]models.SubjectTeacherResponse, error) {
+	subject, err := s.subjectRepo.GetSubjectByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	teachers, err := s.subjectRepo.GetSubjectTeachers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.SubjectTeacherResponse, len(teachers))
+	for i, teacher := range teachers {
+		responses[i] = models.SubjectTeacherResponse{
+			Teacher: models.UserToUserResponse(teacher),
+			IsHead:  subject.HeadTeacherID != nil && *subject.HeadTeacherID == teacher.ID,
+		}
+	}
+	return responses, nil
+}
+
+// GetTeacherSubjects récupère les matières qu'un enseignant est qualifié pour enseigner
+func (s *SubjectService) GetTeacherSubjects(teacherID uint) ([]models.TeacherSubjectResponse, error) {
+	if _, err := s.findTeacher(teacherID); err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.subjectRepo.GetTeacherSubjects(teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.TeacherSubjectResponse, len(subjects))
+	for i, subject := range subjects {
+		responses[i] = models.TeacherSubjectResponse{
+			Subject: subject.ToSubjectResponse(),
+			IsHead:  subject.HeadTeacherID != nil && *subject.HeadTeacherID == teacherID,
+		}
+	}
+	return responses, nil
+}
+
+// AddTeacher qualifie un enseignant pour une matière
+func (s *SubjectService) AddTeacher(id, teacherID uint) error {
+	subject, err := s.subjectRepo.GetSubjectByID(id)
+	if err != nil {
+		return err
+	}
+	teacher, err := s.findTeacher(teacherID)
+	if err != nil {
+		return err
+	}
+
+	linked, err := s.subjectRepo.IsTeacherLinked(id, teacherID)
+	if err != nil {
+		return err
+	}
+	if linked {
+		return errors.New("cet enseignant est déjà qualifié pour cette matière")
+	}
+
+	return s.subjectRepo.AddTeacher(subject, teacher)
+}
+
+// RemoveTeacher retire la qualification d'un enseignant (et la responsabilité de la matière s'il l'avait)
+func (s *SubjectService) RemoveTeacher(id, teacherID uint) error {
+	subject, err := s.subjectRepo.GetSubjectByID(id)
+	if err != nil {
+		return err
+	}
+
+	linked, err := s.subjectRepo.IsTeacherLinked(id, teacherID)
+	if err != nil {
+		return err
+	}
+	if !linked {
+		return errors.New("cet enseignant n'est pas qualifié pour cette matière")
+	}
+
+	return s.subjectRepo.RemoveTeacher(subject, &models.User{ID: teacherID})
+}
+
+// SetHeadTeacher désigne le responsable d'une matière, qualifié d'office, ou le retire si teacherID est nil
+func (s *SubjectService) SetHeadTeacher(id uint, teacherID *uint) (*models.SubjectResponse, error) {
+	subject, err := s.subjectRepo.GetSubjectByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if teacherID != nil {
+		teacher, err := s.findTeacher(*teacherID)
+		if err != nil {
+			return nil, err
+		}
+		linked, err := s.subjectRepo.IsTeacherLinked(id, teacher.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !linked {
+			if err := s.subjectRepo.AddTeacher(subject, teacher); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.subjectRepo.SetHeadTeacher(id, teacherID); err != nil {
+		return nil, err
+	}
+
+	return s.GetSubjectByID(id)
+}
+
+// findTeacher vérifie que l'utilisateur existe et est un professeur
+func (s *SubjectService) findTeacher(teacherID uint) (*models.User, error) {
+	teacher, err := s.userRepo.FindByID(teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("enseignant non trouvé")
+	}
+	if teacher.Role != models.RoleProfesseur {
+		return nil, fmt.Errorf("l'utilisateur sélectionné n'est pas un enseignant")
+	}
+	return teacher, nil
+}
+
+// checkTeacherQualified vérifie qu'un enseignant est qualifié pour une matière.
+// Une matière sans aucun enseignant qualifié reste ouverte à tous les professeurs.
+func checkTeacherQualified(subjectRepo *repositories.SubjectRepository, subjectID, teacherID uint) error {
+	count, err := subjectRepo.CountSubjectTeachers(subjectID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	linked, err := subjectRepo.IsTeacherLinked(subjectID, teacherID)
+	if err != nil {
+		return err
+	}
+	if !linked {
+		return fmt.Errorf("l'enseignant n'est pas qualifié pour enseigner cette matière")
+	}
+	return nil
+}
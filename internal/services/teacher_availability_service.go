@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+)
+
+type TeacherAvailabilityService struct {
+	availabilityRepo  *repositories.TeacherAvailabilityRepository
+	userRepo          *repositories.UserRepository
+	permissionService *PermissionService
+}
+
+func NewTeacherAvailabilityService(availabilityRepo *repositories.TeacherAvailabilityRepository, userRepo *repositories.UserRepository, permissionService *PermissionService) *TeacherAvailabilityService {
+	return &TeacherAvailabilityService{
+		availabilityRepo:  availabilityRepo,
+		userRepo:          userRepo,
+		permissionService: permissionService,
+	}
+}
+
+// GetTeacherAvailability récupère les disponibilités et indisponibilités d'un enseignant
+func (s *TeacherAvailabilityService) GetTeacherAvailability(teacherID uint) (*models.TeacherAvailabilityResponse, error) {
+	if _, err := s.findTeacher(teacherID); err != nil {
+		return nil, err
+	}
+
+	availabilities, err := s.availabilityRepo.GetAvailabilities(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	unavailabilities, err := s.availabilityRepo.GetUnavailabilities(teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TeacherAvailabilityResponse{
+		TeacherID:        teacherID,
+		Availabilities:   availabilities,
+		Unavailabilities: unavailabilities,
+	}, nil
+}
+
+// AddAvailability ajoute une plage de disponibilité hebdomadaire
+func (s *TeacherAvailabilityService) AddAvailability(teacherID uint, req *models.CreateTeacherAvailabilityRequest) (*models.TeacherAvailability, error) {
+	if _, err := s.findTeacher(teacherID); err != nil {
+		return nil, err
+	}
+
+	start, err := models.ParseClock(req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := models.ParseClock(req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, fmt.Errorf("l'heure de fin doit être après l'heure de début")
+	}
+
+	availability := &models.TeacherAvailability{
+		TeacherID: teacherID,
+		DayOfWeek: req.DayOfWeek,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+	if err := s.availabilityRepo.CreateAvailability(availability); err != nil {
+		return nil, fmt.Errorf("erreur lors de l'ajout de la disponibilité: %v", err)
+	}
+
+	return availability, nil
+}
+
+// DeleteAvailability supprime une plage de disponibilité
+func (s *TeacherAvailabilityService) DeleteAvailability(teacherID, availabilityID uint) error {
+	if _, err := s.findTeacher(teacherID); err != nil {
+		return err
+	}
+
+	availability, err := s.availabilityRepo.GetAvailabilityByID(availabilityID)
+	if err != nil || availability.TeacherID != teacherID {
+		return fmt.Errorf("disponibilité non trouvée")
+	}
+
+	return s.availabilityRepo.DeleteAvailability(availabilityID)
+}
+
+// AddUnavailability ajoute une période d'indisponibilité
+func (s *TeacherAvailabilityService) AddUnavailability(teacherID uint, req *models.CreateTeacherUnavailabilityRequest) (*models.TeacherUnavailability, error) {
+	if _, err := s.findTeacher(teacherID); err != nil {
+		return nil, err
+	}
+
+	if !req.EndDate.After(req.StartDate) {
+		return nil, fmt.Errorf("la date de fin doit être après la date de début")
+	}
+
+	unavailability := &models.TeacherUnavailability{
+		TeacherID: teacherID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Reason:    req.Reason,
+	}
+	if err := s.availabilityRepo.CreateUnavailability(unavailability); err != nil {
+		return nil, fmt.Errorf("erreur lors de l'ajout de l'indisponibilité: %v", err)
+	}
+
+	return unavailability, nil
+}
+
+// DeleteUnavailability supprime une période d'indisponibilité
+func (s *TeacherAvailabilityService) DeleteUnavailability(teacherID, unavailabilityID uint) error {
+	if _, err := s.findTeacher(teacherID); err != nil {
+		return err
+	}
+
+	unavailability, err := s.availabilityRepo.GetUnavailabilityByID(unavailabilityID)
+	if err != nil || unavailability.TeacherID != teacherID {
+		return fmt.Errorf("indisponibilité non trouvée")
+	}
+
+	return s.availabilityRepo.DeleteUnavailability(unavailabilityID)
+}
+
+// CanEditAvailability vérifie si l'utilisateur peut modifier les disponibilités de l'enseignant :
+// par défaut, l'enseignant lui-même ou un administrateur
+func (s *TeacherAvailabilityService) CanEditAvailability(userID uint, userRole string, teacherID uint) bool {
+	if s.permissionService.HasPermission(userRole, models.PermAvailabilityUpdateAny) {
+		return true
+	}
+	return s.permissionService.HasPermission(userRole, models.PermAvailabilityUpdateOwn) && userID == teacherID
+}
+
+// findTeacher vérifie que l'utilisateur existe et est un enseignant
+func (s *TeacherAvailabilityService) findTeacher(teacherID uint) (*models.User, error) {
+	teacher, err := s.userRepo.FindByID(teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("enseignant non trouvé")
+	}
+	if teacher.Role != models.RoleProfesseur {
+		return nil, fmt.Errorf("l'utilisateur sélectionné n'est pas un enseignant")
+	}
+	return teacher, nil
+}
@@ -0,0 +1,433 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/scheduler"
+)
+
+const (
+	defaultTimetableDayStart        = "08:00"
+	defaultTimetableDayEnd          = "18:00"
+	defaultTimetableStep            = 30
+	defaultTimetableSessionDuration = 120
+)
+
+var defaultTimetableDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+
+type TimetableService struct {
+	courseService    *CourseService
+	courseRepo       *repositories.CourseRepository
+	subjectRepo      *repositories.SubjectRepository
+	userRepo         *repositories.UserRepository
+	roomRepo         *repositories.RoomRepository
+	groupRepo        *repositories.GroupRepository
+	availabilityRepo *repositories.TeacherAvailabilityRepository
+}
+
+func NewTimetableService(
+	courseService *CourseService,
+	courseRepo *repositories.CourseRepository,
+	subjectRepo *repositories.SubjectRepository,
+	userRepo *repositories.UserRepository,
+	roomRepo *repositories.RoomRepository,
+	groupRepo *repositories.GroupRepository,
+	availabilityRepo *repositories.TeacherAvailabilityRepository,
+) *TimetableService {
+	return &TimetableService{
+		courseService:    courseService,
+		courseRepo:       courseRepo,
+		subjectRepo:      subjectRepo,
+		userRepo:         userRepo,
+		roomRepo:         roomRepo,
+		groupRepo:        groupRepo,
+		availabilityRepo: availabilityRepo,
+	}
+}
+
+// timetableContext conserve les entités chargées pour construire la réponse
+type timetableContext struct {
+	subjects map[uint]*models.Subject
+	teachers map[uint]*models.User
+	groups   map[uint]*models.Group
+	rooms    map[uint]*models.Room
+}
+
+// GenerateTimetable propose un emploi du temps hebdomadaire sans rien enregistrer
+func (s *TimetableService) GenerateTimetable(req *models.GenerateTimetableRequest) (*models.TimetableProposalResponse, error) {
+	_, response, err := s.solve(req)
+	return response, err
+}
+
+// ApplyTimetable génère l'emploi du temps puis crée les cours récurrents correspondants.
+// Si la création d'un cours échoue, les cours déjà créés sont supprimés ; les suppressions
+// qui échouent sont ajoutées à l'erreur retournée.
+func (s *TimetableService) ApplyTimetable(req *models.GenerateTimetableRequest) (*models.TimetableProposalResponse, error) {
+	tc, response, err := s.solve(req)
+	if err != nil {
+		return nil, err
+	}
+
+	endDate := req.EndDate
+	var created []models.CourseResponse
+	for _, session := range response.Sessions {
+		pattern := fmt.Sprintf(`{"days":["%s"]}`, session.Day)
+		courseReq := &models.CreateCourseRequest{
+			Name:              fmt.Sprintf("%s - %s", session.SubjectName, session.GroupName),
+			SubjectID:         session.SubjectID,
+			TeacherID:         session.TeacherID,
+			RoomID:            session.RoomID,
+			StartTime:         session.StartTime,
+			Duration:          session.Duration,
+			Description:       tc.subjects[session.SubjectID].Description,
+			IsRecurring:       true,
+			RecurrencePattern: &pattern,
+			RecurrenceEndDate: &endDate,
+			GroupIDs:          []uint{session.GroupID},
+		}
+
+		course, err := s.courseService.CreateCourse(courseReq)
+		if err != nil {
+			createErr := fmt.Errorf("erreur lors de la création du cours %s (%s %s): %v",
+				courseReq.Name, session.Day, session.StartTime.Format("15:04"), err)
+			if rollbackErr := s.deleteCourses(created); rollbackErr != nil {
+				return nil, errors.Join(createErr, fmt.Errorf("annulation incomplète, cours à supprimer manuellement: %w", rollbackErr))
+			}
+			return nil, createErr
+		}
+		created = append(created, *course)
+	}
+
+	response.Courses = created
+	return response, nil
+}
+
+// deleteCourses supprime les cours créés par une application interrompue et retourne les suppressions en échec
+func (s *TimetableService) deleteCourses(courses []models.CourseResponse) error {
+	var errs []error
+	for _, course := range courses {
+		if err := s.courseService.DeleteCourse(course.ID); err != nil {
+			errs = append(errs, fmt.Errorf("cours %d: %w", course.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// solve charge les données, construit le problème et lance le solveur
+func (s *TimetableService) solve(req *models.GenerateTimetableRequest) (*timetableContext, *models.TimetableProposalResponse, error) {
+	if !req.EndDate.After(req.StartDate) {
+		return nil, nil, fmt.Errorf("la date de fin doit être après la date de début")
+	}
+
+	problem, err := s.baseProblem(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tc, err := s.loadEntities(req, problem)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.loadBusySlots(req.StartDate, req.EndDate, problem); err != nil {
+		return nil, nil, err
+	}
+
+	solution, err := scheduler.Solve(*problem)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response := &models.TimetableProposalResponse{
+		Sessions:    make([]models.TimetableSession, 0, len(solution.Assignments)),
+		Unsatisfied: make([]models.TimetableUnsatisfied, 0, len(solution.Unsatisfied)),
+	}
+
+	for _, assignment := range solution.Assignments {
+		startTime := occurrenceInWeek(req.StartDate, assignment.Window.Day, assignment.Window.Start)
+		duration := assignment.Window.End - assignment.Window.Start
+		teacher := tc.teachers[assignment.TeacherID]
+
+		response.Sessions = append(response.Sessions, models.TimetableSession{
+			SubjectID:   assignment.SubjectID,
+			SubjectName: tc.subjects[assignment.SubjectID].Name,
+			TeacherID:   assignment.TeacherID,
+			TeacherName: teacher.FirstName + " " + teacher.LastName,
+			GroupID:     assignment.GroupID,
+			GroupName:   tc.groups[assignment.GroupID].Name,
+			RoomID:      assignment.RoomID,
+			RoomName:    tc.rooms[assignment.RoomID].Name,
+			Day:         assignment.Window.Day.String(),
+			StartTime:   startTime,
+			EndTime:     startTime.Add(time.Duration(duration) * time.Minute),
+			Duration:    duration,
+		})
+	}
+
+	for _, unsatisfied := range solution.Unsatisfied {
+		response.Unsatisfied = append(response.Unsatisfied, models.TimetableUnsatisfied{
+			SubjectID:      unsatisfied.SubjectID,
+			SubjectName:    tc.subjects[unsatisfied.SubjectID].Name,
+			TeacherID:      unsatisfied.TeacherID,
+			GroupID:        unsatisfied.GroupID,
+			GroupName:      tc.groups[unsatisfied.GroupID].Name,
+			MissingMinutes: unsatisfied.MissingMinutes,
+			Reason:         unsatisfied.Reason,
+		})
+	}
+
+	return tc, response, nil
+}
+
+// baseProblem construit la grille hebdomadaire à partir de la requête
+func (s *TimetableService) baseProblem(req *models.GenerateTimetableRequest) (*scheduler.Problem, error) {
+	dayNames := req.Days
+	if len(dayNames) == 0 {
+		dayNames = defaultTimetableDays
+	}
+
+	problem := &scheduler.Problem{Step: req.Step}
+	if problem.Step == 0 {
+		problem.Step = defaultTimetableStep
+	}
+
+	for _, name := range dayNames {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("jour invalide: %s", name)
+		}
+		problem.Days = append(problem.Days, day)
+	}
+
+	dayStart, dayEnd := req.DayStart, req.DayEnd
+	if dayStart == "" {
+		dayStart = defaultTimetableDayStart
+	}
+	if dayEnd == "" {
+		dayEnd = defaultTimetableDayEnd
+	}
+
+	var err error
+	if problem.DayStart, err = models.ParseClock(dayStart); err != nil {
+		return nil, err
+	}
+	if problem.DayEnd, err = models.ParseClock(dayEnd); err != nil {
+		return nil, err
+	}
+
+	return problem, nil
+}
+
+// loadEntities vérifie et charge les matières, enseignants, groupes et salles de la requête
+func (s *TimetableService) loadEntities(req *models.GenerateTimetableRequest, problem *scheduler.Problem) (*timetableContext, error) {
+	tc := &timetableContext{
+		subjects: map[uint]*models.Subject{},
+		teachers: map[uint]*models.User{},
+		groups:   map[uint]*models.Group{},
+		rooms:    map[uint]*models.Room{},
+	}
+
+	for _, r := range req.Requirements {
+		if _, ok := tc.subjects[r.SubjectID]; !ok {
+			subject, err := s.subjectRepo.GetSubjectByID(r.SubjectID)
+			if err != nil {
+				return nil, fmt.Errorf("matière %d non trouvée", r.SubjectID)
+			}
+			tc.subjects[r.SubjectID] = subject
+		}
+
+		if _, ok := tc.teachers[r.TeacherID]; !ok {
+			teacher, err := s.userRepo.FindByID(r.TeacherID)
+			if err != nil {
+				return nil, fmt.Errorf("enseignant %d non trouvé", r.TeacherID)
+			}
+			if teacher.Role != models.RoleProfesseur {
+				return nil, fmt.Errorf("l'utilisateur %d n'est pas un enseignant", r.TeacherID)
+			}
+			availability, err := s.teacherWindows(teacher.ID)
+			if err != nil {
+				return nil, err
+			}
+			tc.teachers[r.TeacherID] = teacher
+			problem.Teachers = append(problem.Teachers, scheduler.Teacher{
+				ID:           teacher.ID,
+				Name:         teacher.FirstName + " " + teacher.LastName,
+				Availability: availability,
+			})
+		}
+
+		if _, ok := tc.groups[r.GroupID]; !ok {
+			group, err := s.groupRepo.GetGroupByID(r.GroupID)
+			if err != nil {
+				return nil, fmt.Errorf("groupe %d non trouvé", r.GroupID)
+			}
+			size, err := s.groupRepo.CountStudents(group.ID)
+			if err != nil {
+				return nil, err
+			}
+			tc.groups[r.GroupID] = group
+			problem.Groups = append(problem.Groups, scheduler.Group{ID: group.ID, Name: group.Name, Size: int(size)})
+		}
+
+		sessionDuration := r.SessionDuration
+		if sessionDuration == 0 {
+			sessionDuration = defaultTimetableSessionDuration
+		}
+		problem.Requirements = append(problem.Requirements, scheduler.Requirement{
+			SubjectID:       r.SubjectID,
+			SubjectName:     tc.subjects[r.SubjectID].Name,
+			GroupID:         r.GroupID,
+			TeacherID:       r.TeacherID,
+			WeeklyMinutes:   int(math.Round(r.WeeklyHours * 60)),
+			SessionDuration: sessionDuration,
+		})
+	}
+
+	var rooms []models.Room
+	if len(req.RoomIDs) > 0 {
+		for _, roomID := range req.RoomIDs {
+			room, err := s.roomRepo.GetRoomByID(roomID)
+			if err != nil {
+				return nil, fmt.Errorf("salle %d non trouvée", roomID)
+			}
+			rooms = append(rooms, *room)
+		}
+	} else {
+		// Salles principales uniquement, pour ne pas réserver une salle modulable et ses sous-salles en même temps
+		var err error
+		if rooms, err = s.roomRepo.GetAllRooms(&models.RoomFilter{}); err != nil {
+			return nil, err
+		}
+	}
+	for i := range rooms {
+		tc.rooms[rooms[i].ID] = &rooms[i]
+		problem.Rooms = append(problem.Rooms, scheduler.Room{ID: rooms[i].ID, Name: rooms[i].Name, Capacity: rooms[i].Capacity})
+	}
+
+	return tc, nil
+}
+
+// teacherWindows convertit les plages de disponibilité d'un enseignant pour le solveur
+func (s *TimetableService) teacherWindows(teacherID uint) ([]scheduler.Window, error) {
+	availabilities, err := s.availabilityRepo.GetAvailabilities(teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]scheduler.Window, 0, len(availabilities))
+	for _, availability := range availabilities {
+		day, ok := parseWeekday(availability.DayOfWeek)
+		if !ok {
+			continue
+		}
+		start, end, err := availability.Minutes()
+		if err != nil {
+			continue
+		}
+		windows = append(windows, scheduler.Window{Day: day, Start: start, End: end})
+	}
+
+	return windows, nil
+}
+
+// loadBusySlots reporte dans la grille les cours déjà planifiés et les indisponibilités des enseignants
+// sur toute la période de récurrence. Les séances se répétant chaque semaine, un créneau occupé
+// une seule semaine est considéré occupé toutes les semaines.
+func (s *TimetableService) loadBusySlots(periodStart, periodEnd time.Time, problem *scheduler.Problem) error {
+	location := periodStart.Location()
+	courses, err := s.courseRepo.GetCoursesByDateRange(periodStart, periodEnd)
+	if err != nil {
+		return err
+	}
+
+	for _, teacher := range problem.Teachers {
+		unavailabilities, err := s.availabilityRepo.GetUnavailabilitiesInRange(teacher.ID, periodStart, periodEnd)
+		if err != nil {
+			return err
+		}
+		seen := make(map[scheduler.Window]bool)
+		for _, unavailability := range unavailabilities {
+			for _, window := range periodWindows(periodStart, periodEnd, unavailability.StartDate, unavailability.EndDate) {
+				// Une indisponibilité de plusieurs semaines produit plusieurs fois la même fenêtre
+				if seen[window] {
+					continue
+				}
+				seen[window] = true
+				problem.Busy = append(problem.Busy, scheduler.Busy{TeacherID: teacher.ID, Window: window})
+			}
+		}
+	}
+
+	for _, course := range courses {
+		if !course.IsActive() {
+			continue
+		}
+
+		start := course.StartTime.In(location)
+		end := course.EndTime.In(location)
+		window := scheduler.Window{
+			Day:   start.Weekday(),
+			Start: start.Hour()*60 + start.Minute(),
+			End:   end.Hour()*60 + end.Minute(),
+		}
+		if end.YearDay() != start.YearDay() {
+			window.End = 24 * 60
+		}
+
+		problem.Busy = append(problem.Busy, scheduler.Busy{TeacherID: course.TeacherID, RoomID: course.RoomID, Window: window})
+		for _, groupID := range course.GroupIDs() {
+			problem.Busy = append(problem.Busy, scheduler.Busy{GroupID: groupID, Window: window})
+		}
+	}
+
+	return nil
+}
+
+// periodWindows découpe une période en fenêtres journalières limitées à [periodStart, periodEnd)
+func periodWindows(periodStart, periodEnd, start, end time.Time) []scheduler.Window {
+	location := periodStart.Location()
+	if start.Before(periodStart) {
+		start = periodStart
+	}
+	if end.After(periodEnd) {
+		end = periodEnd
+	}
+
+	var windows []scheduler.Window
+	for day := start.In(location); day.Before(end); {
+		nextDay := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, location)
+		window := scheduler.Window{Day: day.Weekday(), Start: day.Hour()*60 + day.Minute(), End: 24 * 60}
+		if end.Before(nextDay) {
+			last := end.In(location)
+			window.End = last.Hour()*60 + last.Minute()
+		}
+		if window.End > window.Start {
+			windows = append(windows, window)
+		}
+		day = nextDay
+	}
+
+	return windows
+}
+
+// occurrenceInWeek retourne la date du premier jour donné à partir de weekStart, à l'heure indiquée
+func occurrenceInWeek(weekStart time.Time, day time.Weekday, minutes int) time.Time {
+	offset := (int(day) - int(weekStart.Weekday()) + 7) % 7
+	date := weekStart.AddDate(0, 0, offset)
+	return time.Date(date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, weekStart.Location())
+}
+
+// parseWeekday convertit un nom de jour anglais ("Monday") en time.Weekday
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day.String() == name {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
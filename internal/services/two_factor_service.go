@@ -0,0 +1,241 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/totp"
+	"eduqr-backend/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// recoveryCodeCount est le nombre de codes de secours remis à l'activation
+	recoveryCodeCount = 10
+	// totpSkew tolère un décalage d'horloge d'une période avant ou après
+	totpSkew = 1
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorService struct {
+	twoFactorRepo *repositories.TwoFactorRepository
+	userRepo      *repositories.UserRepository
+	issuer        string
+	requiredRoles map[string]bool
+}
+
+// NewTwoFactorService crée le service de double authentification. requiredRoles liste les rôles
+// pour lesquels la double authentification est obligatoire.
+func NewTwoFactorService(twoFactorRepo *repositories.TwoFactorRepository, userRepo *repositories.UserRepository, issuer string, requiredRoles []string) *TwoFactorService {
+	required := make(map[string]bool, len(requiredRoles))
+	for _, role := range requiredRoles {
+		required[role] = true
+	}
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		issuer:        issuer,
+		requiredRoles: required,
+	}
+}
+
+// IsRequired indique si la double authentification est obligatoire pour un rôle
+func (s *TwoFactorService) IsRequired(role string) bool {
+	return s.requiredRoles[role]
+}
+
+// IsEnabled indique si l'utilisateur a activé la double authentification
+func (s *TwoFactorService) IsEnabled(userID uint) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return twoFactor.IsEnabled(), nil
+}
+
+// GetStatus retourne l'état de la double authentification d'un utilisateur
+func (s *TwoFactorService) GetStatus(userID uint) (*models.TwoFactorStatusResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatusResponse{
+		Enabled:  enabled,
+		Required: s.IsRequired(user.Role),
+	}
+	if enabled {
+		if status.RecoveryCodesLeft, err = s.twoFactorRepo.CountRecoveryCodes(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// BeginSetup génère un nouveau secret à confirmer avec Enable
+func (s *TwoFactorService) BeginSetup(userID uint) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, fmt.Errorf("la double authentification est déjà activée")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.SavePending(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable active la double authentification avec un premier code de l'application
+// et retourne les codes de secours, qui ne seront plus affichés
+func (s *TwoFactorService) Enable(userID uint, code string) ([]string, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("aucun enrôlement en cours")
+		}
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, fmt.Errorf("la double authentification est déjà activée")
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, fmt.Errorf("code de vérification invalide")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(twoFactor, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify vérifie un code TOTP ou consomme un code de secours. Un code TOTP ne sert qu'une fois.
+func (s *TwoFactorService) Verify(userID uint, code string) error {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("la double authentification n'est pas activée")
+		}
+		return err
+	}
+	if !twoFactor.IsEnabled() {
+		return fmt.Errorf("la double authentification n'est pas activée")
+	}
+
+	if step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), totpSkew); ok {
+		used, err := s.twoFactorRepo.UseStep(twoFactor.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return fmt.Errorf("code de vérification déjà utilisé")
+		}
+		return nil
+	}
+
+	used, err := s.twoFactorRepo.UseRecoveryCode(userID, utils.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return fmt.Errorf("code de vérification invalide")
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes remplace les codes de secours après vérification d'un code
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable désactive la double authentification, sauf si le rôle de l'utilisateur l'impose
+func (s *TwoFactorService) Disable(userID uint, password, code string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if s.IsRequired(user.Role) {
+		return fmt.Errorf("la double authentification est obligatoire pour le rôle %s", user.Role)
+	}
+	if !utils.CheckPassword(password, user.Password) {
+		return fmt.Errorf("mot de passe incorrect")
+	}
+	if err := s.Verify(user.ID, code); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.DeleteByUserID(user.ID)
+}
+
+// Reset supprime la double authentification d'un utilisateur ayant perdu son appareil (administrateur).
+// Si son rôle l'impose, l'utilisateur devra s'enrôler à nouveau à sa prochaine connexion.
+func (s *TwoFactorService) Reset(userID uint) error {
+	return s.twoFactorRepo.DeleteByUserID(userID)
+}
+
+// generateRecoveryCodes génère des codes de secours au format "xxxxx-xxxxx" et leurs empreintes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		bytes := make([]byte, 7)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(bytes))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = utils.HashToken(raw)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
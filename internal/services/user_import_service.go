@@ -0,0 +1,397 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"path/filepath"
+	"strings"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/utils"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// temporaryPasswordLength longueur des mots de passe générés pour les comptes importés
+const temporaryPasswordLength = 12
+
+// userImportHeaders associe les en-têtes reconnus (normalisés) aux colonnes d'import
+var userImportHeaders = map[string]string{
+	"email":          models.UserImportColumnEmail,
+	"e_mail":         models.UserImportColumnEmail,
+	"mail":           models.UserImportColumnEmail,
+	"courriel":       models.UserImportColumnEmail,
+	"adresse_email":  models.UserImportColumnEmail,
+	"first_name":     models.UserImportColumnFirstName,
+	"firstname":      models.UserImportColumnFirstName,
+	"prenom":         models.UserImportColumnFirstName,
+	"last_name":      models.UserImportColumnLastName,
+	"lastname":       models.UserImportColumnLastName,
+	"nom":            models.UserImportColumnLastName,
+	"nom_de_famille": models.UserImportColumnLastName,
+	"role":           models.UserImportColumnRole,
+	"profil":         models.UserImportColumnRole,
+	"group":          models.UserImportColumnGroup,
+	"groups":         models.UserImportColumnGroup,
+	"groupe":         models.UserImportColumnGroup,
+	"groupes":        models.UserImportColumnGroup,
+	"promotion":      models.UserImportColumnGroup,
+	"classe":         models.UserImportColumnGroup,
+	"phone":          models.UserImportColumnPhone,
+	"telephone":      models.UserImportColumnPhone,
+	"tel":            models.UserImportColumnPhone,
+	"portable":       models.UserImportColumnPhone,
+	"full_name":      userImportColumnFullName,
+	"name":           userImportColumnFullName,
+	"names":          userImportColumnFullName,
+	"nom_complet":    userImportColumnFullName,
+}
+
+// userImportColumnFullName colonne "Prénom Nom" découpée sur le premier espace
+const userImportColumnFullName = "full_name"
+
+// userImportRoles associe les libellés de rôle acceptés aux rôles de l'application
+var userImportRoles = map[string]string{
+	"etudiant":       models.RoleEtudiant,
+	"student":        models.RoleEtudiant,
+	"professeur":     models.RoleProfesseur,
+	"enseignant":     models.RoleProfesseur,
+	"teacher":        models.RoleProfesseur,
+	"admin":          models.RoleAdmin,
+	"administrateur": models.RoleAdmin,
+	"super_admin":    models.RoleSuperAdmin,
+}
+
+type UserImportService struct {
+	userRepo          *repositories.UserRepository
+	groupRepo         *repositories.GroupRepository
+	permissionService *PermissionService
+}
+
+func NewUserImportService(userRepo *repositories.UserRepository, groupRepo *repositories.GroupRepository, permissionService *PermissionService) *UserImportService {
+	return &UserImportService{
+		userRepo:          userRepo,
+		groupRepo:         groupRepo,
+		permissionService: permissionService,
+	}
+}
+
+// ImportUsers valide chaque ligne d'un fichier CSV ou XLSX et crée les comptes valides en une seule transaction.
+// Les lignes invalides sont ignorées et détaillées dans le rapport ; en mode dry_run rien n'est créé.
+func (s *UserImportService) ImportUsers(filename string, r io.Reader, importerRole string, opts *models.UserImportOptions) (*models.UserImportReport, error) {
+	records, err := readTabularFile(filename, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("le fichier doit contenir une ligne d'en-tête et au moins une ligne de données")
+	}
+
+	columns, err := mapUserImportColumns(records[0], opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultRole := models.RoleEtudiant
+	if opts.DefaultRole != "" {
+		role, ok := normalizeImportRole(opts.DefaultRole)
+		if !ok {
+			return nil, fmt.Errorf("rôle par défaut invalide")
+		}
+		defaultRole = role
+	}
+
+	report := &models.UserImportReport{DryRun: opts.DryRun}
+	seenEmails := map[string]int{}
+	groupCache := map[string]*models.Group{}
+
+	var users []*models.User
+	var userGroups [][]uint
+	var validRows []int
+
+	for i, record := range records[1:] {
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		row, groupIDs := s.validateRow(i+2, record, columns, defaultRole, importerRole, seenEmails, groupCache)
+		report.Total++
+
+		if len(row.Errors) > 0 {
+			row.Status = models.UserImportRowInvalid
+			report.Invalid++
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+
+		row.Status = models.UserImportRowValid
+		report.Valid++
+
+		if !opts.DryRun {
+			password, err := utils.GenerateTemporaryPassword(temporaryPasswordLength)
+			if err != nil {
+				return nil, fmt.Errorf("erreur lors de la génération des mots de passe")
+			}
+			hashedPassword, err := utils.HashPassword(password)
+			if err != nil {
+				return nil, err
+			}
+			row.TemporaryPassword = password
+
+			users = append(users, &models.User{
+				Email:        row.Email,
+				ContactEmail: row.Email,
+				Password:     hashedPassword,
+				FirstName:    row.FirstName,
+				LastName:     row.LastName,
+				Phone:        row.Phone,
+				Avatar:       "/assets/images/avatars/default-avatar.png",
+				Role:         row.Role,
+			})
+			userGroups = append(userGroups, groupIDs)
+			validRows = append(validRows, len(report.Rows))
+		}
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	if opts.DryRun || len(users) == 0 {
+		return report, nil
+	}
+
+	if err := s.userRepo.CreateUsersWithGroups(users, userGroups); err != nil {
+		return nil, fmt.Errorf("erreur lors de la création des utilisateurs, aucun compte n'a été créé: %v", err)
+	}
+
+	for i, rowIndex := range validRows {
+		report.Rows[rowIndex].Status = models.UserImportRowCreated
+		report.Rows[rowIndex].UserID = users[i].ID
+	}
+	report.Created = len(users)
+
+	return report, nil
+}
+
+// validateRow valide une ligne et retourne les groupes à associer
+func (s *UserImportService) validateRow(number int, record []string, columns map[string]int, defaultRole, importerRole string, seenEmails map[string]int, groupCache map[string]*models.Group) (models.UserImportRow, []uint) {
+	value := func(column string) string {
+		index, ok := columns[column]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	row := models.UserImportRow{
+		Row:       number,
+		Email:     strings.ToLower(value(models.UserImportColumnEmail)),
+		FirstName: value(models.UserImportColumnFirstName),
+		LastName:  value(models.UserImportColumnLastName),
+		Phone:     value(models.UserImportColumnPhone),
+	}
+
+	if fullName := value(userImportColumnFullName); fullName != "" && row.FirstName == "" && row.LastName == "" {
+		row.FirstName, row.LastName, _ = strings.Cut(fullName, " ")
+		row.LastName = strings.TrimSpace(row.LastName)
+	}
+
+	// Email
+	if row.Email == "" {
+		row.Errors = append(row.Errors, "email manquant")
+	} else if !isValidEmail(row.Email) {
+		row.Errors = append(row.Errors, "format d'email invalide")
+	} else if previous, ok := seenEmails[row.Email]; ok {
+		row.Errors = append(row.Errors, fmt.Sprintf("email en double dans le fichier (ligne %d)", previous))
+	} else {
+		seenEmails[row.Email] = number
+		if existing, _ := s.userRepo.FindByEmail(row.Email); existing != nil {
+			row.Errors = append(row.Errors, "un utilisateur avec cet email existe déjà")
+		}
+	}
+
+	// Nom et prénom
+	if row.FirstName == "" {
+		row.Errors = append(row.Errors, "prénom manquant")
+	}
+	if row.LastName == "" {
+		row.Errors = append(row.Errors, "nom manquant")
+	}
+
+	// Rôle
+	row.Role = defaultRole
+	if rawRole := value(models.UserImportColumnRole); rawRole != "" {
+		role, ok := normalizeImportRole(rawRole)
+		if !ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("rôle \"%s\" invalide", rawRole))
+		}
+		row.Role = role
+	}
+	if row.Role != "" && !s.permissionService.CanManageRole(importerRole, row.Role) {
+		row.Errors = append(row.Errors, fmt.Sprintf("permissions insuffisantes pour créer un utilisateur avec le rôle %s", row.Role))
+	}
+
+	// Groupes (séparés par des points-virgules)
+	var groupIDs []uint
+	if rawGroups := value(models.UserImportColumnGroup); rawGroups != "" {
+		for _, name := range strings.Split(rawGroups, ";") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			row.Groups = append(row.Groups, name)
+
+			group, cached := groupCache[name]
+			if !cached {
+				group, _ = s.groupRepo.GetGroupByName(name)
+				groupCache[name] = group
+			}
+			if group == nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("groupe \"%s\" introuvable", name))
+				continue
+			}
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+
+	return row, groupIDs
+}
+
+// mapUserImportColumns associe chaque colonne d'import à son index dans le fichier
+func mapUserImportColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	columns := map[string]int{}
+	normalized := make([]string, len(header))
+	for i, name := range header {
+		normalized[i] = normalizeHeader(name)
+		if column, ok := userImportHeaders[normalized[i]]; ok {
+			if _, exists := columns[column]; !exists {
+				columns[column] = i
+			}
+		}
+	}
+
+	// Le mapping explicite prime sur la détection automatique
+	for column, headerName := range mapping {
+		switch column {
+		case models.UserImportColumnEmail, models.UserImportColumnFirstName, models.UserImportColumnLastName,
+			models.UserImportColumnRole, models.UserImportColumnGroup, models.UserImportColumnPhone, userImportColumnFullName:
+		default:
+			return nil, fmt.Errorf("colonne de mapping inconnue: %s", column)
+		}
+
+		found := false
+		for i, name := range normalized {
+			if name == normalizeHeader(headerName) {
+				columns[column] = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("en-tête \"%s\" introuvable dans le fichier", headerName)
+		}
+	}
+
+	if _, ok := columns[models.UserImportColumnEmail]; !ok {
+		return nil, fmt.Errorf("colonne email introuvable dans l'en-tête")
+	}
+	_, hasFullName := columns[userImportColumnFullName]
+	_, hasFirstName := columns[models.UserImportColumnFirstName]
+	_, hasLastName := columns[models.UserImportColumnLastName]
+	if !hasFullName && (!hasFirstName || !hasLastName) {
+		return nil, fmt.Errorf("colonnes nom et prénom introuvables dans l'en-tête")
+	}
+
+	return columns, nil
+}
+
+// readTabularFile lit un fichier CSV (séparateur virgule ou point-virgule) ou XLSX (première feuille)
+func readTabularFile(filename string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("fichier XLSX invalide: %v", err)
+		}
+		defer file.Close()
+
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("le fichier XLSX ne contient aucune feuille")
+		}
+		rows, err := file.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("fichier XLSX invalide: %v", err)
+		}
+		return rows, nil
+	case ".csv", "":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.Comma = detectCSVDelimiter(data)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("fichier CSV invalide: %v", err)
+		}
+		return records, nil
+	default:
+		return nil, fmt.Errorf("format de fichier non pris en charge (CSV ou XLSX attendu)")
+	}
+}
+
+// detectCSVDelimiter choisit entre virgule et point-virgule d'après la ligne d'en-tête
+func detectCSVDelimiter(data []byte) rune {
+	header, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		return ';'
+	}
+	return ','
+}
+
+// normalizeHeader met un en-tête sous forme comparable (minuscules, sans accents, séparateurs en _)
+func normalizeHeader(header string) string {
+	replacer := strings.NewReplacer(
+		"é", "e", "è", "e", "ê", "e", "ë", "e",
+		"à", "a", "â", "a", "î", "i", "ï", "i",
+		"ô", "o", "ù", "u", "û", "u", "ç", "c",
+		" ", "_", "-", "_", ".", "_",
+	)
+	return replacer.Replace(strings.ToLower(strings.TrimSpace(header)))
+}
+
+// normalizeImportRole convertit un libellé de rôle en rôle de l'application
+func normalizeImportRole(role string) (string, bool) {
+	normalized, ok := userImportRoles[normalizeHeader(role)]
+	return normalized, ok
+}
+
+// isValidEmail vérifie qu'une valeur est une adresse email simple (sans nom d'affichage)
+func isValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return false
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	return strings.Contains(domain, ".")
+}
+
+// isEmptyRecord indique si une ligne ne contient aucune valeur
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrAccountAlreadyLinked indique que l'adresse e-mail de l'identité appartient à un compte déjà rattaché
+// à un autre fournisseur d'identité ou à un autre identifiant
+var ErrAccountAlreadyLinked = errors.New("an account with this email is already linked to another identity provider")
+
+// externalIdentity décrit un utilisateur authentifié par un fournisseur d'identité externe (OIDC, LDAP)
+type externalIdentity struct {
+	Provider      string // models.AuthProviderOIDC ou models.AuthProviderLDAP
+	Subject       string // Identifiant stable de l'utilisateur chez le fournisseur
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Groups        []string
+}
+
+// userProvisioner crée ou met à jour les comptes EduQR des utilisateurs d'un fournisseur d'identité,
+// en associant leurs groupes aux rôles et aux groupes d'étudiants EduQR
+type userProvisioner struct {
+	userRepo     *repositories.UserRepository
+	groupRepo    *repositories.GroupRepository
+	sessionRepo  *repositories.SessionRepository
+	roleMapping  map[string]string // Groupe du fournisseur -> rôle EduQR
+	groupMapping map[string]string // Groupe du fournisseur -> nom d'un groupe d'étudiants EduQR
+	defaultRole  string            // Rôle des utilisateurs créés sans groupe associé à un rôle
+}
+
+// provision retrouve l'utilisateur de l'identité, par son identifiant chez le fournisseur puis par son adresse
+// e-mail si elle est vérifiée, ou le crée. Seul un compte local peut être rattaché par son adresse e-mail :
+// un compte déjà rattaché à un fournisseur produit ErrAccountAlreadyLinked. Le rôle et les groupes d'étudiants suivent la correspondance configurée :
+// un utilisateur existant dont aucun groupe n'a de rôle associé conserve le sien. Si son rôle change,
+// ses sessions sont révoquées pour que ses jetons ne portent plus l'ancien rôle.
+// Le booléen retourné indique une création.
+func (p *userProvisioner) provision(identity *externalIdentity) (*models.User, bool, error) {
+	role := p.mappedRole(identity.Groups)
+	created := false
+
+	user, err := p.userRepo.FindByExternalID(identity.Provider, identity.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	if user == nil {
+		if identity.Email == "" {
+			return nil, false, fmt.Errorf("le fournisseur d'identité n'a pas transmis d'adresse e-mail")
+		}
+		user, err = p.userRepo.FindByEmail(identity.Email)
+		switch {
+		case err == nil:
+			// Rattacher un compte existant à une adresse non vérifiée permettrait de s'en emparer
+			if !identity.EmailVerified {
+				return nil, false, fmt.Errorf("un compte existe déjà pour %s mais le fournisseur d'identité n'a pas vérifié cette adresse", identity.Email)
+			}
+			// Un compte rattaché à un fournisseur ne passe pas silencieusement à un autre
+			if user.AuthProvider != models.AuthProviderLocal {
+				return nil, false, ErrAccountAlreadyLinked
+			}
+			user.AuthProvider = identity.Provider
+			user.ExternalID = identity.Subject
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if user, err = p.createUser(identity, role); err != nil {
+				return nil, false, err
+			}
+			created = true
+		default:
+			return nil, false, err
+		}
+	}
+
+	if identity.FirstName != "" || identity.LastName != "" {
+		user.FirstName = identity.FirstName
+		user.LastName = identity.LastName
+	}
+	roleChanged := false
+	if role != "" && role != user.Role {
+		roleChanged = !created
+		user.Role = role
+	}
+	if err := p.userRepo.Update(user); err != nil {
+		return nil, false, err
+	}
+	if roleChanged {
+		if _, err := p.sessionRepo.RevokeUserSessions(user.ID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := p.syncGroups(user, identity.Groups); err != nil {
+		return nil, false, err
+	}
+	return user, created, nil
+}
+
+// createUser crée l'utilisateur d'une première connexion. Son mot de passe est aléatoire et inconnu :
+// il se connecte par le fournisseur d'identité.
+func (p *userProvisioner) createUser(identity *externalIdentity, role string) (*models.User, error) {
+	if role == "" {
+		role = p.defaultRole
+	}
+	password, err := utils.GenerateRandomToken(refreshTokenSize)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:        identity.Email,
+		ContactEmail: identity.Email,
+		Password:     hashedPassword,
+		FirstName:    identity.FirstName,
+		LastName:     identity.LastName,
+		Role:         role,
+		AuthProvider: identity.Provider,
+		ExternalID:   identity.Subject,
+	}
+	if user.FirstName == "" && user.LastName == "" {
+		user.FirstName = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	if err := p.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// mappedRole retourne le rôle le plus élevé associé aux groupes, ou une chaîne vide si aucun ne l'est
+func (p *userProvisioner) mappedRole(groups []string) string {
+	role := ""
+	for _, group := range groups {
+		mapped, ok := p.roleMapping[group]
+		if ok && models.RoleHierarchy[mapped] > models.RoleHierarchy[role] {
+			role = mapped
+		}
+	}
+	return role
+}
+
+// syncGroups aligne les groupes d'un étudiant sur ses groupes chez le fournisseur. Seuls les groupes EduQR
+// présents dans la correspondance sont gérés ; les autres appartenances restent administrées dans EduQR.
+func (p *userProvisioner) syncGroups(user *models.User, groups []string) error {
+	if user.Role != models.RoleEtudiant || len(p.groupMapping) == 0 {
+		return nil
+	}
+
+	groupIDs := make(map[string]uint, len(p.groupMapping))
+	var managed []uint
+	for _, name := range p.groupMapping {
+		if _, ok := groupIDs[name]; ok {
+			continue
+		}
+		group, err := p.groupRepo.GetGroupByName(name)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Groupe %q de la correspondance %s introuvable, ignoré", name, user.AuthProvider)
+				continue
+			}
+			return err
+		}
+		groupIDs[name] = group.ID
+		managed = append(managed, group.ID)
+	}
+
+	var wanted []uint
+	for _, group := range groups {
+		if id, ok := groupIDs[p.groupMapping[group]]; ok {
+			wanted = append(wanted, id)
+		}
+	}
+	return p.groupRepo.SyncMemberships(user.ID, managed, wanted)
+}
+
+// ParseClaimMapping lit une correspondance de groupes au format "groupe=valeur,groupe=valeur"
+func ParseClaimMapping(value string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("correspondance invalide %q : format attendu groupe=valeur", entry)
+		}
+		mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return mapping, nil
+}
@@ -5,20 +5,15 @@ import (
 	"eduqr-backend/internal/repositories"
 	"eduqr-backend/pkg/utils"
 	"errors"
-	"time"
 )
 
 type UserService struct {
-	userRepo      *repositories.UserRepository
-	jwtSecret     string
-	jwtExpiration time.Duration
+	userRepo *repositories.UserRepository
 }
 
-func NewUserService(userRepo *repositories.UserRepository, jwtSecret string, jwtExpiration time.Duration) *UserService {
+func NewUserService(userRepo *repositories.UserRepository) *UserService {
 	return &UserService{
-		userRepo:      userRepo,
-		jwtSecret:     jwtSecret,
-		jwtExpiration: jwtExpiration,
+		userRepo: userRepo,
 	}
 }
 
@@ -54,27 +49,6 @@ func (s *UserService) Register(req *models.RegisterRequest) (*models.UserRespons
 	return s.toUserResponse(user), nil
 }
 
-func (s *UserService) Login(req *models.LoginRequest) (string, *models.UserResponse, error) {
-	// Find user by email
-	user, err := s.userRepo.FindByEmail(req.Email)
-	if err != nil {
-		return "", nil, errors.New("invalid credentials")
-	}
-
-	// Check password
-	if !utils.CheckPassword(req.Password, user.Password) {
-		return "", nil, errors.New("invalid credentials")
-	}
-
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Role, s.jwtSecret, s.jwtExpiration)
-	if err != nil {
-		return "", nil, err
-	}
-
-	return token, s.toUserResponse(user), nil
-}
-
 func (s *UserService) GetUserByID(id uint) (*models.UserResponse, error) {
 	user, err := s.userRepo.FindByID(id)
 	if err != nil {
@@ -0,0 +1,172 @@
+// Package ical produit et lit des calendriers au format iCalendar (RFC 5545).
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Statuts VEVENT utilisés par l'application
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const (
+	dateTimeFormat = "20060102T150405Z"
+	maxLineLength  = 75
+)
+
+// Event représente un VEVENT
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	Categories   []string
+	LastModified time.Time
+	AllDay       bool        // DTSTART de type DATE (lecture uniquement)
+	RRule        string      // Règle de récurrence brute, voir ParseRecurrence
+	ExDates      []time.Time // Occurrences exclues (lecture uniquement)
+}
+
+// Calendar représente un VCALENDAR
+type Calendar struct {
+	Name    string
+	Stamp   time.Time // DTSTAMP des événements (heure de génération)
+	Events  []Event
+	ProdID  string
+	Refresh time.Duration // Intervalle de rafraîchissement suggéré aux clients
+}
+
+// Encode écrit le calendrier au format iCalendar
+func (c *Calendar) Encode(w io.Writer) error {
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	prodID := c.ProdID
+	if prodID == "" {
+		prodID = "-//EduQR//EduQR Calendar//FR"
+	}
+
+	var buf bytes.Buffer
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+prodID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	if c.Refresh > 0 {
+		writeLine(&buf, fmt.Sprintf("REFRESH-INTERVAL;VALUE=DURATION:PT%dM", int(c.Refresh.Minutes())))
+		writeLine(&buf, fmt.Sprintf("X-PUBLISHED-TTL:PT%dM", int(c.Refresh.Minutes())))
+	}
+
+	for _, event := range c.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+event.UID)
+		writeLine(&buf, "DTSTAMP:"+formatTime(stamp))
+		writeLine(&buf, "DTSTART:"+formatTime(event.Start))
+		writeLine(&buf, "DTEND:"+formatTime(event.End))
+		writeLine(&buf, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Location != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(event.Location))
+		}
+		if len(event.Categories) > 0 {
+			escaped := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				escaped[i] = escapeText(category)
+			}
+			writeLine(&buf, "CATEGORIES:"+strings.Join(escaped, ","))
+		}
+		if event.RRule != "" {
+			writeLine(&buf, "RRULE:"+event.RRule)
+		}
+		status := event.Status
+		if status == "" {
+			status = StatusConfirmed
+		}
+		writeLine(&buf, "STATUS:"+status)
+		if !event.LastModified.IsZero() {
+			writeLine(&buf, "LAST-MODIFIED:"+formatTime(event.LastModified))
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Bytes retourne le calendrier encodé
+func (c *Calendar) Bytes() []byte {
+	var buf bytes.Buffer
+	// L'écriture dans un bytes.Buffer ne peut pas échouer
+	_ = c.Encode(&buf)
+	return buf.Bytes()
+}
+
+// formatTime formate une date en UTC selon la RFC 5545
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// escapeText échappe une valeur de type TEXT
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	)
+	return replacer.Replace(value)
+}
+
+// writeLine écrit une ligne terminée par CRLF en la repliant à 75 octets
+func writeLine(buf *bytes.Buffer, line string) {
+	first := true
+	for len(line) > 0 {
+		limit := maxLineLength
+		if !first {
+			// L'espace de continuation compte dans la longueur de la ligne
+			limit--
+		}
+		if len(line) <= limit {
+			if !first {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(line)
+			break
+		}
+
+		// Ne pas couper au milieu d'un caractère UTF-8
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		if !first {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n")
+		line = line[cut:]
+		first = false
+	}
+	buf.WriteString("\r\n")
+}
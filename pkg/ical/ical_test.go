@@ -0,0 +1,65 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode_CancelledEvent(t *testing.T) {
+	cal := &Calendar{
+		Name:  "Cours, TD; examens",
+		Stamp: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		Events: []Event{{
+			UID:     "course-1@eduqr",
+			Summary: "Mathématiques",
+			Start:   time.Date(2024, 1, 8, 10, 0, 0, 0, time.FixedZone("CET", 3600)),
+			End:     time.Date(2024, 1, 8, 12, 0, 0, 0, time.FixedZone("CET", 3600)),
+			Status:  StatusCancelled,
+		}},
+	}
+
+	out := string(cal.Bytes())
+
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		`X-WR-CALNAME:Cours\, TD\; examens` + "\r\n",
+		"DTSTART:20240108T090000Z\r\n",
+		"DTEND:20240108T110000Z\r\n",
+		"STATUS:CANCELLED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("sortie sans %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestEncode_FoldsLongLines(t *testing.T) {
+	cal := &Calendar{
+		Stamp: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		Events: []Event{{
+			UID:         "event-1@eduqr",
+			Summary:     "Réunion",
+			Description: strings.Repeat("é", 100),
+			Start:       time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC),
+			End:         time.Date(2024, 1, 8, 11, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	out := string(cal.Bytes())
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("ligne de %d octets: %q", len(line), line)
+		}
+		if !strings.HasPrefix(line, " ") && strings.ContainsRune(line, '\uFFFD') {
+			t.Errorf("caractère UTF-8 coupé: %q", line)
+		}
+	}
+
+	// Le dépliage doit restituer la description d'origine
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+strings.Repeat("é", 100)+"\r\n") {
+		t.Errorf("description mal repliée:\n%s", out)
+	}
+}
@@ -0,0 +1,405 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fréquences RRULE
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// Recurrence représente une règle RRULE décodée
+type Recurrence struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    *time.Time
+	ByDay    []time.Weekday
+	// Unsupported liste les parties de la règle non interprétées (BYMONTHDAY, BYSETPOS...)
+	Unsupported []string
+}
+
+// property représente une ligne de contenu décodée
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse lit un calendrier iCalendar et retourne ses VEVENT
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{}
+	var current *Event
+	inCalendar := false
+	// Profondeur des composants imbriqués dans un VEVENT (VALARM...)
+	nested := 0
+
+	for number, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("ligne %d: %v", number+1, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && prop.value == "VCALENDAR":
+			inCalendar = true
+		case prop.name == "END" && prop.value == "VCALENDAR":
+			inCalendar = false
+		case !inCalendar:
+			continue
+		case prop.name == "BEGIN" && prop.value == "VEVENT":
+			current = &Event{}
+		case prop.name == "END" && prop.value == "VEVENT":
+			if current == nil {
+				return nil, fmt.Errorf("ligne %d: END:VEVENT sans BEGIN:VEVENT", number+1)
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("ligne %d: VEVENT sans DTSTART", number+1)
+			}
+			if current.End.IsZero() {
+				// Sans DTEND ni DURATION : un jour pour une date, instantané sinon (RFC 5545 §3.6.1)
+				current.End = current.Start
+				if current.AllDay {
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+			}
+			calendar.Events = append(calendar.Events, *current)
+			current = nil
+		case current == nil:
+			if prop.name == "X-WR-CALNAME" {
+				calendar.Name = unescapeText(prop.value)
+			}
+		case prop.name == "BEGIN":
+			nested++
+		case prop.name == "END":
+			nested--
+		case nested > 0:
+			continue
+		default:
+			if err := current.setProperty(prop); err != nil {
+				return nil, fmt.Errorf("ligne %d: %v", number+1, err)
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("VEVENT non terminé")
+	}
+
+	return calendar, nil
+}
+
+// ParseRecurrence décode une valeur RRULE (ex. FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20240630T000000Z)
+func ParseRecurrence(value string) (*Recurrence, error) {
+	rule := &Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("partie RRULE invalide: %s", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("INTERVAL invalide: %s", val)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("COUNT invalide: %s", val)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, _, err := parseDateTime(val, nil)
+			if err != nil {
+				return nil, fmt.Errorf("UNTIL invalide: %s", val)
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					// Les formes ordinales (1MO, -1FR) ne sont pas prises en charge
+					rule.Unsupported = append(rule.Unsupported, "BYDAY="+day)
+					continue
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "WKST":
+			// Sans effet pour des règles hebdomadaires d'intervalle 1
+		default:
+			rule.Unsupported = append(rule.Unsupported, part)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ manquant")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("COUNT et UNTIL ne peuvent pas être combinés")
+	}
+
+	return rule, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// setProperty renseigne un champ de l'événement à partir d'une propriété
+func (e *Event) setProperty(prop property) error {
+	switch prop.name {
+	case "UID":
+		e.UID = prop.value
+	case "SUMMARY":
+		e.Summary = unescapeText(prop.value)
+	case "DESCRIPTION":
+		e.Description = unescapeText(prop.value)
+	case "LOCATION":
+		e.Location = unescapeText(prop.value)
+	case "STATUS":
+		e.Status = strings.ToUpper(prop.value)
+	case "CATEGORIES":
+		for _, category := range splitText(prop.value) {
+			e.Categories = append(e.Categories, unescapeText(category))
+		}
+	case "DTSTART":
+		start, allDay, err := parseDateTime(prop.value, prop.params)
+		if err != nil {
+			return fmt.Errorf("DTSTART invalide: %v", err)
+		}
+		e.Start = start
+		e.AllDay = allDay
+	case "DTEND":
+		end, _, err := parseDateTime(prop.value, prop.params)
+		if err != nil {
+			return fmt.Errorf("DTEND invalide: %v", err)
+		}
+		e.End = end
+	case "DURATION":
+		if e.Start.IsZero() {
+			return fmt.Errorf("DURATION avant DTSTART")
+		}
+		duration, err := parseDuration(prop.value)
+		if err != nil {
+			return fmt.Errorf("DURATION invalide: %v", err)
+		}
+		e.End = e.Start.Add(duration)
+	case "RRULE":
+		e.RRule = prop.value
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exdate, _, err := parseDateTime(value, prop.params)
+			if err != nil {
+				return fmt.Errorf("EXDATE invalide: %v", err)
+			}
+			e.ExDates = append(e.ExDates, exdate)
+		}
+	case "LAST-MODIFIED":
+		if modified, _, err := parseDateTime(prop.value, prop.params); err == nil {
+			e.LastModified = modified
+		}
+	}
+	return nil
+}
+
+// unfold lit les lignes de contenu en dépliant les lignes de continuation
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// parseProperty décode une ligne NOM;PARAM=VAL:valeur
+func parseProperty(line string) (property, error) {
+	// Le séparateur de valeur est le premier ':' hors guillemets
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("ligne de contenu invalide")
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  value,
+	}
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return prop, nil
+}
+
+// parseDateTime décode une valeur DATE ou DATE-TIME (UTC, TZID ou flottante)
+func parseDateTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.UTC)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeFormat, value)
+		return t, false, err
+	}
+
+	location := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+// parseDuration décode une durée RFC 5545 (ex. PT1H30M, P1D)
+func parseDuration(value string) (time.Duration, error) {
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimLeft(value, "+-")
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("format de durée invalide")
+	}
+	value = value[1:]
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+		case r == 'T':
+			inTime = true
+		default:
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("format de durée invalide")
+			}
+			number = ""
+			switch {
+			case r == 'W' && !inTime:
+				total += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D' && !inTime:
+				total += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				total += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				total += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				total += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("format de durée invalide")
+			}
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("format de durée invalide")
+	}
+
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// splitText découpe une liste de valeurs TEXT sur les virgules non échappées
+func splitText(value string) []string {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(parts, current.String())
+}
+
+// unescapeText décode une valeur de type TEXT
+func unescapeText(value string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			switch r {
+			case 'n', 'N':
+				b.WriteRune('\n')
+			default:
+				b.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
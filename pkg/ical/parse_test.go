@@ -0,0 +1,100 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//Test//FR\r\n" +
+	"X-WR-CALNAME:Département\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Paris\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19701025T030000\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:abc@test\r\n" +
+	"SUMMARY:Analyse\\, TD\r\n" +
+	"DESCRIPTION:Ligne 1\\nLigne 2 avec une description suffisamment longue pour\r\n" +
+	"  être repliée\r\n" +
+	"LOCATION:Salle Normale B\r\n" +
+	"CATEGORIES:Mathématiques,TD\r\n" +
+	"DTSTART;TZID=Europe/Paris:20240108T100000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20240131T235959Z\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Rappel\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:day@test\r\n" +
+	"SUMMARY:Journée portes ouvertes\r\n" +
+	"DTSTART;VALUE=DATE:20240203\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	cal, err := Parse(strings.NewReader(sampleCalendar))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cal.Name != "Département" {
+		t.Errorf("nom = %q", cal.Name)
+	}
+	if len(cal.Events) != 2 {
+		t.Fatalf("%d événements, attendu 2", len(cal.Events))
+	}
+
+	event := cal.Events[0]
+	if event.Summary != "Analyse, TD" {
+		t.Errorf("SUMMARY = %q", event.Summary)
+	}
+	if event.Description != "Ligne 1\nLigne 2 avec une description suffisamment longue pour être repliée" {
+		t.Errorf("DESCRIPTION = %q", event.Description)
+	}
+	if len(event.Categories) != 2 || event.Categories[0] != "Mathématiques" {
+		t.Errorf("CATEGORIES = %v", event.Categories)
+	}
+	if !event.Start.Equal(time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("DTSTART = %v", event.Start)
+	}
+	if event.End.Sub(event.Start) != 90*time.Minute {
+		t.Errorf("durée = %v", event.End.Sub(event.Start))
+	}
+	if event.RRule == "" {
+		t.Error("RRULE manquant")
+	}
+
+	allDay := cal.Events[1]
+	if !allDay.AllDay || allDay.End.Sub(allDay.Start) != 24*time.Hour {
+		t.Errorf("événement journée: %+v", allDay)
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	rule, err := ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6;BYSETPOS=1")
+	if err != nil {
+		t.Fatalf("ParseRecurrence: %v", err)
+	}
+	if rule.Freq != FreqWeekly || rule.Count != 6 || rule.Interval != 1 {
+		t.Errorf("règle = %+v", rule)
+	}
+	if len(rule.ByDay) != 2 || rule.ByDay[0] != time.Monday || rule.ByDay[1] != time.Wednesday {
+		t.Errorf("BYDAY = %v", rule.ByDay)
+	}
+	if len(rule.Unsupported) != 1 {
+		t.Errorf("parties non prises en charge = %v", rule.Unsupported)
+	}
+
+	if _, err := ParseRecurrence("BYDAY=MO"); err == nil {
+		t.Error("FREQ manquant non détecté")
+	}
+	if _, err := ParseRecurrence("FREQ=DAILY;COUNT=2;UNTIL=20240101T000000Z"); err == nil {
+		t.Error("COUNT et UNTIL combinés non détectés")
+	}
+}
@@ -0,0 +1,78 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+// Message est un e-mail texte à envoyer
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender envoie des e-mails. L'implémentation est choisie au démarrage selon la configuration.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// LogSender écrit les e-mails dans les logs au lieu de les envoyer (développement)
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(msg Message) error {
+	log.Printf("E-mail pour %s : %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// SMTPSender envoie les e-mails via un serveur SMTP
+type SMTPSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPSender(host, port, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *SMTPSender) Send(msg Message) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	if err := smtp.SendMail(s.host+":"+s.port, auth, s.from, []string{msg.To}, s.format(msg)); err != nil {
+		return fmt.Errorf("envoi de l'e-mail impossible : %w", err)
+	}
+	return nil
+}
+
+// format construit le message au format RFC 5322
+func (s *SMTPSender) format(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	// Les en-têtes sont en ASCII : le sujet accentué est encodé selon la RFC 2047
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
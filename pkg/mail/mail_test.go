@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestFormat_EncodesAccentedSubject(t *testing.T) {
+	sender := NewSMTPSender("localhost", "25", "", "", "noreply@eduqr.com")
+	raw := sender.format(Message{
+		To:      "prof@eduqr.com",
+		Subject: "Réinitialisation de votre mot de passe EduQR",
+		Body:    "Bonjour,\nà bientôt",
+	})
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("message illisible : %v", err)
+	}
+
+	// L'en-tête brut ne contient que de l'ASCII
+	subject := msg.Header.Get("Subject")
+	for _, r := range subject {
+		if r > 127 {
+			t.Fatalf("sujet non encodé : %q", subject)
+		}
+	}
+
+	decoded, err := new(mime.WordDecoder).DecodeHeader(subject)
+	if err != nil {
+		t.Fatalf("sujet non décodable : %v", err)
+	}
+	if decoded != "Réinitialisation de votre mot de passe EduQR" {
+		t.Errorf("sujet décodé = %q", decoded)
+	}
+}
+
+func TestFormat_KeepsASCIISubject(t *testing.T) {
+	sender := NewSMTPSender("localhost", "25", "", "", "noreply@eduqr.com")
+	raw := string(sender.format(Message{To: "prof@eduqr.com", Subject: "EduQR", Body: "ok"}))
+
+	if !strings.Contains(raw, "Subject: EduQR\r\n") {
+		t.Errorf("sujet ASCII modifié : %q", raw)
+	}
+}
@@ -0,0 +1,81 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret des vecteurs de test SHA1 de la RFC 6238 ("12345678901234567890")
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// Les vecteurs de la RFC ont 8 chiffres : les 6 derniers correspondent aux codes à 6 chiffres
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, v := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", v.unix, err)
+		}
+		if code != v.code {
+			t.Errorf("Code(%d) = %s, want %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestValidate_Skew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	old, _ := Code(rfcSecret, Step(now)-2)
+
+	step, ok := Validate(rfcSecret, previous, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("code de la période précédente refusé (ok=%v, step=%d)", ok, step)
+	}
+	if _, ok := Validate(rfcSecret, old, now, 1); ok {
+		t.Error("code trop ancien accepté")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now, 1); ok {
+		t.Error("code de longueur incorrecte accepté")
+	}
+}
+
+func TestGenerateSecret_RoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Code(secret, Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Validate(secret, code, time.Now(), 1); !ok {
+		t.Error("code généré refusé")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("EduQR", "admin@eduqr.com", "JBSWY3DPEHPK3PXP")
+	for _, expected := range []string{
+		"otpauth://totp/EduQR:admin@eduqr.com?",
+		"secret=JBSWY3DPEHPK3PXP",
+		"issuer=EduQR",
+		"digits=6",
+		"period=30",
+	} {
+		if !strings.Contains(uri, expected) {
+			t.Errorf("URI %q ne contient pas %q", uri, expected)
+		}
+	}
+}
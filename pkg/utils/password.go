@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
+	"math/big"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -13,3 +16,46 @@ func CheckPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+const (
+	passwordUppercase = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordLowercase = "abcdefghijkmnopqrstuvwxyz"
+	passwordDigits    = "23456789"
+	passwordSpecials  = "!@#$%&*?"
+)
+
+// GenerateTemporaryPassword génère un mot de passe temporaire contenant au moins
+// une majuscule, une minuscule, un chiffre et un caractère spécial
+func GenerateTemporaryPassword(length int) (string, error) {
+	if length < 8 {
+		length = 8
+	}
+
+	all := passwordUppercase + passwordLowercase + passwordDigits + passwordSpecials
+	sets := []string{passwordUppercase, passwordLowercase, passwordDigits, passwordSpecials}
+
+	password := make([]byte, length)
+	for i := range password {
+		charset := all
+		if i < len(sets) {
+			charset = sets[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = charset[n.Int64()]
+	}
+
+	// Mélanger pour ne pas révéler la position des caractères obligatoires
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
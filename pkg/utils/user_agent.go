@@ -0,0 +1,64 @@
+package utils
+
+import "strings"
+
+// userAgentBrowsers liste les navigateurs reconnus, du plus spécifique au plus générique :
+// la plupart des user agents mentionnent aussi Chrome ou Safari.
+var userAgentBrowsers = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// userAgentSystems liste les systèmes d'exploitation reconnus, dans le même esprit
+var userAgentSystems = []struct {
+	token string
+	name  string
+}{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DescribeUserAgent retourne une description lisible de l'appareil d'un user agent,
+// par exemple "Firefox sur Windows". Retourne "Appareil inconnu" si rien n'est reconnu.
+func DescribeUserAgent(userAgent string) string {
+	browser := ""
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	system := ""
+	for _, s := range userAgentSystems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " sur " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	case userAgent != "":
+		// Client non navigateur (application, script) : on garde le nom du produit
+		return strings.SplitN(strings.SplitN(userAgent, " ", 2)[0], "/", 2)[0]
+	default:
+		return "Appareil inconnu"
+	}
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestAuthService crée le service d'authentification sur la base de test
+func newTestAuthService(settings services.LoginThrottleSettings) *services.AuthService {
+	userRepo := repositories.NewUserRepository()
+	loginThrottle := services.NewLoginThrottleService(
+		repositories.NewLoginThrottleRepository(testDB),
+		userRepo,
+		services.NewAuditLogService(repositories.NewAuditLogRepository()),
+		settings,
+	)
+	twoFactor := services.NewTwoFactorService(repositories.NewTwoFactorRepository(testDB), userRepo, "EduQR", nil)
+	return services.NewAuthService(userRepo, repositories.NewSessionRepository(testDB), loginThrottle, twoFactor, nil, "test-secret", 15*time.Minute, 24*time.Hour)
+}
+
+func TestAuthSessions(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newAuthService := func() *services.AuthService {
+		return newTestAuthService(services.LoginThrottleSettings{
+			DelayAfter:         10,
+			BaseDelay:          time.Second,
+			MaxAccountFailures: 10,
+			MaxIPFailures:      50,
+			LockoutDuration:    15 * time.Minute,
+		})
+	}
+
+	client := models.SessionClient{
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0",
+		IPAddress: "127.0.0.1",
+	}
+
+	createLoginUser := func(email string) *models.User {
+		hash, err := utils.HashPassword("Password123!")
+		assert.NoError(t, err)
+		user := &models.User{
+			Email:     email,
+			FirstName: "Test",
+			LastName:  "Session",
+			Password:  hash,
+			Role:      models.RoleEtudiant,
+		}
+		testDB.Create(user)
+		return user
+	}
+
+	t.Run("Login_ReturnsTokens", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("session@eduqr.com")
+
+		tokens, response, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, response.ID)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.Equal(t, int64(900), tokens.ExpiresIn)
+
+		claims, err := utils.ValidateToken(tokens.AccessToken, "test-secret")
+		assert.NoError(t, err)
+		assert.Equal(t, tokens.SessionID, claims.SessionID)
+
+		// Seule l'empreinte du jeton est stockée
+		var count int64
+		testDB.Model(&models.RefreshToken{}).Where("token_hash = ?", tokens.RefreshToken).Count(&count)
+		assert.Equal(t, int64(0), count)
+
+		_, _, err = service.Login(&models.LoginRequest{Email: user.Email, Password: "mauvais"}, client)
+		assert.Error(t, err)
+	})
+
+	t.Run("Refresh_RotatesToken", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("rotation@eduqr.com")
+
+		tokens, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+
+		refreshed, err := service.Refresh(tokens.RefreshToken, "127.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, tokens.SessionID, refreshed.SessionID)
+		assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+		// Le nouveau jeton reste utilisable
+		_, err = service.Refresh(refreshed.RefreshToken, "127.0.0.1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Refresh_ReuseRevokesSession", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("rejeu@eduqr.com")
+
+		tokens, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+
+		refreshed, err := service.Refresh(tokens.RefreshToken, "127.0.0.1")
+		assert.NoError(t, err)
+
+		// Rejouer l'ancien jeton révoque la session entière
+		_, err = service.Refresh(tokens.RefreshToken, "127.0.0.1")
+		assert.Error(t, err)
+
+		_, err = service.Refresh(refreshed.RefreshToken, "127.0.0.1")
+		assert.Error(t, err)
+
+		var session models.Session
+		testDB.First(&session, tokens.SessionID)
+		assert.NotNil(t, session.RevokedAt)
+	})
+
+	t.Run("Logout_RevokesSession", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("logout@eduqr.com")
+		other := createLoginUser("autre@eduqr.com")
+
+		tokens, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+
+		// Un autre utilisateur ne peut pas fermer cette session
+		err = service.Logout(other.ID, 0, tokens.RefreshToken)
+		assert.Error(t, err)
+
+		err = service.Logout(user.ID, tokens.SessionID, "")
+		assert.NoError(t, err)
+
+		_, err = service.Refresh(tokens.RefreshToken, "127.0.0.1")
+		assert.Error(t, err)
+	})
+
+	t.Run("RevokeAllSessions", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("multi@eduqr.com")
+
+		first, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+		second, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+
+		revoked, err := service.RevokeAllSessions(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), revoked)
+
+		_, err = service.Refresh(first.RefreshToken, "127.0.0.1")
+		assert.Error(t, err)
+		_, err = service.Refresh(second.RefreshToken, "127.0.0.1")
+		assert.Error(t, err)
+	})
+
+	t.Run("GetUserSessions_RecordsClient", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("appareils@eduqr.com")
+
+		first, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+		_, _, err = service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!", Device: "Tablette salle B12"}, client)
+		assert.NoError(t, err)
+
+		sessions, err := service.GetUserSessions(user.ID, first.SessionID)
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 2)
+
+		devices := map[string]bool{}
+		for _, session := range sessions {
+			devices[session.Device] = true
+			assert.Equal(t, "127.0.0.1", session.IPAddress)
+			assert.Equal(t, session.ID == first.SessionID, session.Current)
+		}
+		assert.True(t, devices["Firefox sur Windows"])
+		assert.True(t, devices["Tablette salle B12"])
+	})
+
+	t.Run("RevokeUserSession_OnlyOwnSessions", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("proprietaire@eduqr.com")
+		other := createLoginUser("intrus@eduqr.com")
+
+		tokens, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+		assert.NoError(t, service.ValidateSession(tokens.SessionID, "10.0.0.1"))
+
+		err = service.RevokeUserSession(other.ID, tokens.SessionID)
+		assert.Error(t, err)
+
+		err = service.RevokeUserSession(user.ID, tokens.SessionID)
+		assert.NoError(t, err)
+
+		// Le jeton d'accès de la session révoquée n'est plus accepté
+		assert.Error(t, service.ValidateSession(tokens.SessionID, "10.0.0.1"))
+
+		sessions, err := service.GetUserSessions(user.ID, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+
+	t.Run("ValidateSession_TracksLastSeen", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newAuthService()
+		user := createLoginUser("activite@eduqr.com")
+
+		tokens, _, err := service.Login(&models.LoginRequest{Email: user.Email, Password: "Password123!"}, client)
+		assert.NoError(t, err)
+
+		// Une activité depuis une nouvelle adresse est enregistrée immédiatement
+		assert.NoError(t, service.ValidateSession(tokens.SessionID, "10.0.0.2"))
+
+		var session models.Session
+		testDB.First(&session, tokens.SessionID)
+		assert.Equal(t, "10.0.0.2", session.IPAddress)
+	})
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAvailableRooms(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	// createModularRoom crée une salle modulable « Amphi » et ses sous-salles « Amphi A » et « Amphi B »
+	createModularRoom := func() *models.RoomResponse {
+		room, err := services.NewRoomService(repositories.NewRoomRepository(testDB)).CreateRoom(&models.CreateRoomRequest{
+			Name:          "Amphi",
+			Building:      "A",
+			IsModular:     true,
+			SubRoomsCount: 2,
+		})
+		assert.NoError(t, err)
+		return room
+	}
+
+	names := func(rooms []models.RoomResponse) []string {
+		result := make([]string, len(rooms))
+		for i, room := range rooms {
+			result[i] = room.Name
+		}
+		return result
+	}
+
+	// Créneau du cours créé par createTestCourse
+	slot := &models.AvailableRoomFilter{
+		Start: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("SubRoomBusy_BlocksModularParent", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		createModularRoom()
+		subRoom, err := repositories.NewRoomRepository(testDB).GetRoomByName("Amphi A")
+		assert.NoError(t, err)
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		createTestCourse(teacher.ID, subject.ID, subRoom.ID)
+
+		rooms, err := service.FindAvailableRooms(slot)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Amphi B"}, names(rooms))
+	})
+
+	t.Run("ParentBusy_BlocksSubRooms", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		modular := createModularRoom()
+		other := createTestRoom()
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		createTestCourse(teacher.ID, subject.ID, modular.ID)
+
+		rooms, err := service.FindAvailableRooms(slot)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{other.Name}, names(rooms))
+	})
+
+	t.Run("CancelledCourse_FreesRoom", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		room := createTestRoom()
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+		testDB.Model(course).Update("status", models.CourseStatusCancelled)
+
+		rooms, err := service.FindAvailableRooms(slot)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{room.Name}, names(rooms))
+	})
+
+	t.Run("Filters_BuildingAndCapacity", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+		roomService := services.NewRoomService(repositories.NewRoomRepository(testDB))
+
+		_, err := roomService.CreateRoom(&models.CreateRoomRequest{Name: "A101", Building: "A", Capacity: 30})
+		assert.NoError(t, err)
+		_, err = roomService.CreateRoom(&models.CreateRoomRequest{Name: "A102", Building: "A", Capacity: 60})
+		assert.NoError(t, err)
+		_, err = roomService.CreateRoom(&models.CreateRoomRequest{Name: "B101", Building: "B", Capacity: 60})
+		assert.NoError(t, err)
+
+		minCapacity := 50
+		rooms, err := service.FindAvailableRooms(&models.AvailableRoomFilter{Start: slot.Start, End: slot.End, Building: "A", MinCapacity: &minCapacity})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"A102"}, names(rooms))
+
+		_, err = service.FindAvailableRooms(&models.AvailableRoomFilter{Start: slot.End, End: slot.Start})
+		assert.Error(t, err)
+	})
+}
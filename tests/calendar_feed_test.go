@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newFeedService := func() *services.CalendarFeedService {
+		return services.NewCalendarFeedService(
+			repositories.NewCalendarFeedRepository(testDB),
+			repositories.NewCourseRepository(testDB),
+			repositories.NewGroupRepository(testDB),
+			repositories.NewEventRepository(),
+			repositories.NewUserRepository(),
+			repositories.NewRoomRepository(testDB),
+		)
+	}
+
+	t.Run("StudentFeed_ContainsGroupCoursesAndEvents", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newFeedService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		student := createTestUser(models.RoleEtudiant)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		group := &models.Group{Name: "L1 Info"}
+		testDB.Create(group)
+		addTestGroupMember(group.ID, student.ID)
+
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+		course := &models.Course{
+			Name:      "Algorithmique",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: start,
+			EndTime:   start.Add(2 * time.Hour),
+			Duration:  120,
+			Groups:    []models.Group{*group},
+		}
+		testDB.Create(course)
+
+		cancelled := &models.Course{
+			Name:         "Cours annulé",
+			SubjectID:    subject.ID,
+			TeacherID:    teacher.ID,
+			RoomID:       room.ID,
+			StartTime:    start.Add(48 * time.Hour),
+			EndTime:      start.Add(50 * time.Hour),
+			Duration:     120,
+			Status:       models.CourseStatusCancelled,
+			StatusReason: "Grève",
+			Groups:       []models.Group{*group},
+		}
+		testDB.Create(cancelled)
+
+		testDB.Create(&models.Event{
+			Title:     "Rendez-vous personnel",
+			StartTime: start.Add(4 * time.Hour),
+			EndTime:   start.Add(5 * time.Hour),
+			UserID:    student.ID,
+		})
+
+		_, token, err := service.CreateFeed(student.ID, &models.CreateCalendarFeedRequest{FeedType: models.CalendarFeedUser})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+
+		content, err := service.RenderFeed(token)
+		assert.NoError(t, err)
+
+		feed := string(content)
+		assert.Contains(t, feed, "BEGIN:VCALENDAR")
+		assert.Contains(t, feed, "SUMMARY:Algorithmique")
+		assert.Contains(t, feed, "SUMMARY:Rendez-vous personnel")
+		assert.Contains(t, feed, "SUMMARY:Cours annulé")
+		assert.Contains(t, feed, "STATUS:CANCELLED")
+	})
+
+	t.Run("RoomFeed_ContainsRoomCourses", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newFeedService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+		testDB.Create(&models.Course{
+			Name:      "Physique",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: start,
+			EndTime:   start.Add(time.Hour),
+			Duration:  60,
+		})
+
+		_, _, err := service.CreateFeed(teacher.ID, &models.CreateCalendarFeedRequest{FeedType: models.CalendarFeedRoom, TargetID: room.ID + 1000})
+		assert.Error(t, err)
+
+		_, token, err := service.CreateFeed(teacher.ID, &models.CreateCalendarFeedRequest{FeedType: models.CalendarFeedRoom, TargetID: room.ID})
+		assert.NoError(t, err)
+
+		content, err := service.RenderFeed(token)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "SUMMARY:Physique")
+		assert.Contains(t, string(content), "LOCATION:Test Room")
+	})
+
+	t.Run("RevokedFeed_IsRejected", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newFeedService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		other := createTestUser(models.RoleEtudiant)
+
+		feed, token, err := service.CreateFeed(teacher.ID, &models.CreateCalendarFeedRequest{FeedType: models.CalendarFeedUser})
+		assert.NoError(t, err)
+
+		// Un autre utilisateur ne peut pas révoquer le flux
+		assert.Error(t, service.RevokeFeed(feed.ID, other.ID))
+
+		assert.NoError(t, service.RevokeFeed(feed.ID, teacher.ID))
+
+		_, err = service.RenderFeed(token)
+		assert.Error(t, err)
+	})
+}
@@ -3,7 +3,6 @@ package tests
 import (
 	"eduqr-backend/internal/models"
 	"eduqr-backend/internal/repositories"
-	"eduqr-backend/internal/services"
 	"testing"
 	"time"
 
@@ -250,7 +249,9 @@ func TestCourseRepository(t *testing.T) {
 
 		conflicts, err := repo.CheckConflicts(newCourse)
 		assert.NoError(t, err)
-		assert.Len(t, conflicts, 1) // Doit y avoir un conflit
+		assert.Len(t, conflicts, 2) // Même salle et même enseignant
+		assert.Equal(t, models.ConflictTypeRoom, conflicts[0].Type)
+		assert.Equal(t, models.ConflictTypeTeacher, conflicts[1].Type)
 	})
 
 	t.Run("CheckConflicts_NoConflict", func(t *testing.T) {
@@ -297,6 +298,97 @@ func TestCourseRepository(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, conflicts, 0) // Ne doit pas y avoir de conflit
 	})
+
+	t.Run("CheckConflicts_TeacherDoubleBooking", func(t *testing.T) {
+		repo := repositories.NewCourseRepository(testDB)
+
+		teacher := createTestUser("teacher")
+		subject := createTestSubject()
+		room := createTestRoom()
+		otherRoom := &models.Room{Name: "Other Room"}
+		testDB.Create(otherRoom)
+
+		// Cours existant dans la première salle
+		createTestCourse(teacher.ID, subject.ID, room.ID)
+
+		// Même enseignant, autre salle, créneau qui se chevauche
+		newCourse := &models.Course{
+			Name:      "Teacher Conflict Course",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    otherRoom.ID,
+			StartTime: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+			Duration:  120,
+		}
+
+		conflicts, err := repo.CheckConflicts(newCourse)
+		assert.NoError(t, err)
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, models.ConflictTypeTeacher, conflicts[0].Type)
+	})
+
+	t.Run("CheckConflicts_GroupOverlap", func(t *testing.T) {
+		repo := repositories.NewCourseRepository(testDB)
+
+		teacher := createTestUser("teacher")
+		otherTeacher := &models.User{Email: "other-teacher@eduqr.com", FirstName: "Other", LastName: "Teacher", Password: "x", Role: models.RoleProfesseur}
+		testDB.Create(otherTeacher)
+		subject := createTestSubject()
+		room := createTestRoom()
+		otherRoom := &models.Room{Name: "Group Room"}
+		testDB.Create(otherRoom)
+		group := &models.Group{Name: "L1 - TD1"}
+		testDB.Create(group)
+
+		existingCourse := createTestCourse(teacher.ID, subject.ID, room.ID)
+		testDB.Model(existingCourse).Association("Groups").Append(group)
+
+		// Autre enseignant, autre salle, mais même groupe
+		newCourse := &models.Course{
+			Name:      "Group Conflict Course",
+			SubjectID: subject.ID,
+			TeacherID: otherTeacher.ID,
+			RoomID:    otherRoom.ID,
+			StartTime: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+			Duration:  120,
+			Groups:    []models.Group{{ID: group.ID}},
+		}
+
+		conflicts, err := repo.CheckConflicts(newCourse)
+		assert.NoError(t, err)
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, models.ConflictTypeGroup, conflicts[0].Type)
+		assert.Equal(t, "L1 - TD1", conflicts[0].GroupName)
+	})
+
+	t.Run("CheckConflicts_IgnoresCancelledCourses", func(t *testing.T) {
+		repo := repositories.NewCourseRepository(testDB)
+
+		teacher := createTestUser("teacher")
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		existingCourse := createTestCourse(teacher.ID, subject.ID, room.ID)
+		err := repo.UpdateCourseStatus(existingCourse, models.CourseStatusCancelled, "Grève", teacher.ID)
+		assert.NoError(t, err)
+
+		// Même salle, même enseignant, même créneau : le cours annulé libère le créneau
+		newCourse := &models.Course{
+			Name:      "Replacement Course",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: existingCourse.StartTime,
+			EndTime:   existingCourse.EndTime,
+			Duration:  120,
+		}
+
+		conflicts, err := repo.CheckConflicts(newCourse)
+		assert.NoError(t, err)
+		assert.Len(t, conflicts, 0)
+	})
 }
 
 func TestCourseService(t *testing.T) {
@@ -304,11 +396,7 @@ func TestCourseService(t *testing.T) {
 	cleanupTestDatabase()
 
 	t.Run("CreateCourse_Success", func(t *testing.T) {
-		courseRepo := repositories.NewCourseRepository(testDB)
-		subjectRepo := repositories.NewSubjectRepository()
-		userRepo := repositories.NewUserRepository()
-		roomRepo := repositories.NewRoomRepository(testDB)
-		service := services.NewCourseService(courseRepo, subjectRepo, userRepo, roomRepo)
+		service := newTestCourseService()
 
 		// Créer les dépendances
 		teacher := createTestUser("teacher")
@@ -339,11 +427,7 @@ func TestCourseService(t *testing.T) {
 	})
 
 	t.Run("GetCourseByID_Success", func(t *testing.T) {
-		courseRepo := repositories.NewCourseRepository(testDB)
-		subjectRepo := repositories.NewSubjectRepository()
-		userRepo := repositories.NewUserRepository()
-		roomRepo := repositories.NewRoomRepository(testDB)
-		service := services.NewCourseService(courseRepo, subjectRepo, userRepo, roomRepo)
+		service := newTestCourseService()
 
 		// Créer un cours
 		teacher := createTestUser("teacher")
@@ -362,11 +446,7 @@ func TestCourseService(t *testing.T) {
 	})
 
 	t.Run("UpdateCourse_Success", func(t *testing.T) {
-		courseRepo := repositories.NewCourseRepository(testDB)
-		subjectRepo := repositories.NewSubjectRepository()
-		userRepo := repositories.NewUserRepository()
-		roomRepo := repositories.NewRoomRepository(testDB)
-		service := services.NewCourseService(courseRepo, subjectRepo, userRepo, roomRepo)
+		service := newTestCourseService()
 
 		// Créer un cours
 		teacher := createTestUser("teacher")
@@ -391,11 +471,7 @@ func TestCourseService(t *testing.T) {
 	})
 
 	t.Run("DeleteCourse_Success", func(t *testing.T) {
-		courseRepo := repositories.NewCourseRepository(testDB)
-		subjectRepo := repositories.NewSubjectRepository()
-		userRepo := repositories.NewUserRepository()
-		roomRepo := repositories.NewRoomRepository(testDB)
-		service := services.NewCourseService(courseRepo, subjectRepo, userRepo, roomRepo)
+		service := newTestCourseService()
 
 		// Créer un cours
 		teacher := createTestUser("teacher")
@@ -411,6 +487,74 @@ func TestCourseService(t *testing.T) {
 		_, err = service.GetCourseByID(course.ID)
 		assert.Error(t, err)
 	})
+
+	t.Run("CancelCourse_KeepsRecordAndNotifies", func(t *testing.T) {
+		notificationRepo := repositories.NewNotificationRepository(testDB)
+		service := newTestCourseService()
+
+		teacher := createTestUser("teacher")
+		student := &models.User{Email: "cancel-student@eduqr.com", FirstName: "Cancel", LastName: "Student", Password: "x", Role: models.RoleEtudiant}
+		testDB.Create(student)
+		group := &models.Group{Name: "Cancel Group"}
+		testDB.Create(group)
+		addTestGroupMember(group.ID, student.ID)
+		subject := createTestSubject()
+		room := createTestRoom()
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+		testDB.Model(course).Association("Groups").Append(group)
+
+		response, err := service.CancelCourse(course.ID, &models.CancelCourseRequest{Reason: "Enseignant malade"}, teacher.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CourseStatusCancelled, response.Status)
+		assert.Equal(t, "Enseignant malade", response.StatusReason)
+
+		// Le cours existe toujours
+		stored, err := service.GetCourseByID(course.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CourseStatusCancelled, stored.Status)
+
+		// L'historique garde la trace du changement
+		history, err := service.GetCourseHistory(course.ID)
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+		assert.Equal(t, models.CourseStatusCancelled, history[0].ToStatus)
+
+		// L'étudiant du groupe est notifié
+		notifications, err := notificationRepo.GetNotificationsByUser(student.ID, true)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		assert.Equal(t, models.NotificationCourseCancelled, notifications[0].Type)
+
+		// Un cours annulé ne peut pas être annulé une seconde fois
+		_, err = service.CancelCourse(course.ID, &models.CancelCourseRequest{Reason: "Doublon"}, teacher.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("RescheduleCourse_LinksReplacement", func(t *testing.T) {
+		service := newTestCourseService()
+
+		teacher := createTestUser("teacher")
+		subject := createTestSubject()
+		room := createTestRoom()
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+
+		req := &models.RescheduleCourseRequest{
+			StartTime: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC),
+			Reason:    "Salle indisponible",
+		}
+
+		replacement, err := service.RescheduleCourse(course.ID, req, teacher.ID)
+		assert.NoError(t, err)
+		assert.NotEqual(t, course.ID, replacement.ID)
+		assert.Equal(t, models.CourseStatusScheduled, replacement.Status)
+		assert.Equal(t, course.ID, *replacement.RescheduledFromID)
+		assert.Equal(t, course.Duration, replacement.Duration)
+
+		original, err := service.GetCourseByID(course.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CourseStatusRescheduled, original.Status)
+		assert.Equal(t, replacement.ID, *original.RescheduledToID)
+	})
 }
 
 func TestCourseValidation(t *testing.T) {
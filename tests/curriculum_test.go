@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurriculumService(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newCurriculumService := func() *services.CurriculumService {
+		return services.NewCurriculumService(
+			repositories.NewSubjectQuotaRepository(testDB),
+			repositories.NewSubjectRepository(),
+			repositories.NewGroupRepository(testDB),
+			repositories.NewCourseRepository(testDB),
+		)
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 10, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("Progress_HighlightsSubjectsFallingBehind", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newCurriculumService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		room := createTestRoom()
+		maths := createTestSubject()
+		english := &models.Subject{Name: "Anglais", Code: "ANG"}
+		testDB.Create(english)
+
+		promo := &models.Group{Name: "L1"}
+		testDB.Create(promo)
+		td := &models.Group{Name: "L1 - TD1", ParentID: &promo.ID}
+		testDB.Create(td)
+
+		addCourse := func(subjectID uint, group *models.Group, start time.Time, status string) {
+			testDB.Create(&models.Course{
+				Name:      "Cours",
+				SubjectID: subjectID,
+				TeacherID: teacher.ID,
+				RoomID:    room.ID,
+				StartTime: start,
+				EndTime:   start.Add(2 * time.Hour),
+				Duration:  120,
+				Status:    status,
+				Groups:    []models.Group{*group},
+			})
+		}
+		// Cours de la promotion (concerne le TD), cours du TD, cours annulé et cours d'anglais
+		addCourse(maths.ID, promo, day(2), models.CourseStatusCompleted)
+		addCourse(maths.ID, td, day(20), models.CourseStatusScheduled)
+		addCourse(maths.ID, td, day(3), models.CourseStatusCancelled)
+		addCourse(english.ID, td, day(4), models.CourseStatusCompleted)
+
+		for _, req := range []models.CreateSubjectQuotaRequest{
+			{SubjectID: maths.ID, GroupID: td.ID, Term: "2024 T1", StartDate: "2024-01-01", EndDate: "2024-01-31", RequiredHours: 10},
+			{SubjectID: english.ID, GroupID: td.ID, Term: "2024 T1", StartDate: "2024-01-01", EndDate: "2024-01-31", RequiredHours: 2},
+		} {
+			_, err := service.CreateQuota(&req)
+			assert.NoError(t, err)
+		}
+
+		progress, err := service.GetProgress(&models.SubjectQuotaFilter{GroupID: &td.ID}, day(16))
+		assert.NoError(t, err)
+		if !assert.Len(t, progress, 2) {
+			return
+		}
+
+		// Les matières en retard sont en tête
+		assert.Equal(t, maths.ID, progress[0].Quota.Subject.ID)
+		assert.Equal(t, 4.0, progress[0].ScheduledHours)
+		assert.Equal(t, 2.0, progress[0].DeliveredHours)
+		assert.Equal(t, 8.0, progress[0].RemainingHours)
+		assert.Equal(t, models.QuotaStatusBehind, progress[0].Status)
+		assert.True(t, progress[0].Behind)
+
+		assert.Equal(t, models.QuotaStatusCompleted, progress[1].Status)
+		assert.False(t, progress[1].Behind)
+
+		behind, err := service.GetProgress(&models.SubjectQuotaFilter{BehindOnly: true}, day(16))
+		assert.NoError(t, err)
+		assert.Len(t, behind, 1)
+	})
+
+	t.Run("CreateQuota_RefusesDuplicatesAndInvalidPeriods", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newCurriculumService()
+
+		subject := createTestSubject()
+		group := &models.Group{Name: "L2"}
+		testDB.Create(group)
+
+		req := models.CreateSubjectQuotaRequest{SubjectID: subject.ID, GroupID: group.ID, Term: "S1", StartDate: "2024-09-01", EndDate: "2025-01-31", RequiredHours: 30}
+		_, err := service.CreateQuota(&req)
+		assert.NoError(t, err)
+
+		_, err = service.CreateQuota(&req)
+		assert.Error(t, err)
+
+		req.Term = "S2"
+		req.StartDate, req.EndDate = "2025-06-30", "2025-02-01"
+		_, err = service.CreateQuota(&req)
+		assert.Error(t, err)
+	})
+}
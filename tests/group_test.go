@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupService(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newGroupService := func() *services.GroupService {
+		return services.NewGroupService(
+			repositories.NewGroupRepository(testDB),
+			repositories.NewUserRepository(),
+			repositories.NewCourseRepository(testDB),
+		)
+	}
+
+	t.Run("UpdateGroup_RefusesHierarchyCycle", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newGroupService()
+
+		promo, err := service.CreateGroup(&models.CreateGroupRequest{Name: "L1"})
+		assert.NoError(t, err)
+		td, err := service.CreateGroup(&models.CreateGroupRequest{Name: "L1 TD1", ParentID: &promo.ID})
+		assert.NoError(t, err)
+		tp, err := service.CreateGroup(&models.CreateGroupRequest{Name: "L1 TD1 TP1", ParentID: &td.ID})
+		assert.NoError(t, err)
+
+		_, err = service.UpdateGroup(promo.ID, &models.UpdateGroupRequest{ParentID: &tp.ID})
+		assert.Error(t, err)
+
+		_, err = service.UpdateGroup(promo.ID, &models.UpdateGroupRequest{ParentID: &promo.ID})
+		assert.Error(t, err)
+
+		// Un groupe avec des sous-groupes ne peut pas être supprimé
+		assert.Error(t, service.DeleteGroup(td.ID))
+		assert.NoError(t, service.DeleteGroup(tp.ID))
+	})
+
+	t.Run("MoveMember_KeepsHistoryAtEffectiveDate", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newGroupService()
+
+		student := createTestUser(models.RoleEtudiant)
+		from, _ := service.CreateGroup(&models.CreateGroupRequest{Name: "TD1"})
+		to, _ := service.CreateGroup(&models.CreateGroupRequest{Name: "TD2"})
+
+		start := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+		_, err := service.AddMembers(from.ID, &models.AddGroupMembersRequest{UserIDs: []uint{student.ID}, StartDate: &start})
+		assert.NoError(t, err)
+
+		moveDate := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+		_, err = service.MoveMember(from.ID, &models.MoveGroupMemberRequest{UserID: student.ID, ToGroupID: to.ID, EffectiveDate: &moveDate})
+		assert.NoError(t, err)
+
+		before := time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)
+		members, err := service.GetMembers(from.ID, &before)
+		assert.NoError(t, err)
+		assert.Len(t, members, 1)
+
+		members, err = service.GetMembers(from.ID, &moveDate)
+		assert.NoError(t, err)
+		assert.Len(t, members, 0)
+
+		members, err = service.GetMembers(to.ID, &moveDate)
+		assert.NoError(t, err)
+		assert.Len(t, members, 1)
+
+		// L'historique complet du groupe d'origine est conservé
+		history, err := service.GetMembers(from.ID, nil)
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+		assert.NotNil(t, history[0].EndDate)
+	})
+
+	t.Run("AddMembers_RefusesNonStudents", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newGroupService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		group, _ := service.CreateGroup(&models.CreateGroupRequest{Name: "TD1"})
+
+		_, err := service.AddMembers(group.ID, &models.AddGroupMembersRequest{UserIDs: []uint{teacher.ID}})
+		assert.Error(t, err)
+	})
+
+	t.Run("GetGroupTimetable_IncludesParentCourses", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newGroupService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		promo, _ := service.CreateGroup(&models.CreateGroupRequest{Name: "L1"})
+		td, _ := service.CreateGroup(&models.CreateGroupRequest{Name: "L1 TD1", ParentID: &promo.ID})
+
+		lecture := createTestCourse(teacher.ID, subject.ID, room.ID)
+		testDB.Model(lecture).Association("Groups").Append(&models.Group{ID: promo.ID})
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2024, 1, 7, 23, 59, 59, 0, time.UTC)
+
+		courses, err := service.GetGroupTimetable(td.ID, start, end, false)
+		assert.NoError(t, err)
+		assert.Len(t, courses, 1)
+		assert.Equal(t, lecture.ID, courses[0].ID)
+	})
+
+	t.Run("CheckConflicts_ParentAndSubgroup", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newGroupService()
+		courseRepo := repositories.NewCourseRepository(testDB)
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		promo, _ := service.CreateGroup(&models.CreateGroupRequest{Name: "L1"})
+		td, _ := service.CreateGroup(&models.CreateGroupRequest{Name: "L1 TD1", ParentID: &promo.ID})
+
+		lecture := createTestCourse(teacher.ID, subject.ID, room.ID)
+		testDB.Model(lecture).Association("Groups").Append(&models.Group{ID: promo.ID})
+
+		otherRoom := &models.Room{Name: "Other Room", Building: "B", Floor: "1"}
+		testDB.Create(otherRoom)
+
+		// Un TD du sous-groupe en même temps que le cours magistral de la promotion
+		candidate := &models.Course{
+			TeacherID: teacher.ID + 1000,
+			RoomID:    otherRoom.ID,
+			StartTime: lecture.StartTime,
+			EndTime:   lecture.EndTime,
+			Groups:    []models.Group{{ID: td.ID}},
+		}
+		conflicts, err := courseRepo.CheckConflicts(candidate)
+		assert.NoError(t, err)
+
+		found := false
+		for _, conflict := range conflicts {
+			if conflict.Type == models.ConflictTypeGroup {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+}
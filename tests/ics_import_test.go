@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const importCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:serie@test\r\n" +
+	"SUMMARY:Cours hebdomadaire\r\n" +
+	"LOCATION:Test Room\r\n" +
+	"DTSTART:20240108T080000Z\r\n" +
+	"DTEND:20240108T100000Z\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=3\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:conflit@test\r\n" +
+	"SUMMARY:Cours en conflit\r\n" +
+	"LOCATION:Test Room\r\n" +
+	"DTSTART:20240101T100000Z\r\n" +
+	"DTEND:20240101T110000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestICSImport(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newImportService := func() *services.ICSImportService {
+		groupRepo := repositories.NewGroupRepository(testDB)
+		courseService := newTestCourseService()
+		return services.NewICSImportService(
+			courseService,
+			services.NewEventService(repositories.NewEventRepository()),
+			repositories.NewSubjectRepository(),
+			repositories.NewUserRepository(),
+			repositories.NewRoomRepository(testDB),
+			groupRepo,
+		)
+	}
+
+	t.Run("ImportCourses_DryRunReportsConflictsWithoutCreating", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newImportService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+		// Cours existant le 1er janvier de 10h à 12h dans la même salle
+		createTestCourse(teacher.ID, subject.ID, room.ID)
+
+		report, err := service.ImportCourses(strings.NewReader(importCalendar), &models.ICSImportOptions{
+			DryRun:    true,
+			TeacherID: teacher.ID,
+			SubjectID: subject.ID,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Total)
+		assert.Equal(t, 1, report.Importable)
+		assert.Equal(t, 0, report.Created)
+
+		series := report.Items[0]
+		assert.True(t, series.IsRecurring)
+		assert.Equal(t, room.ID, series.RoomID)
+		assert.Equal(t, 3, series.Occurrences)
+		assert.Contains(t, *series.RecurrencePattern, "Monday")
+
+		assert.NotEmpty(t, report.Items[1].Conflicts)
+
+		var count int64
+		testDB.Model(&models.Course{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("ImportCourses_CreatesRecurringSeries", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newImportService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		createTestRoom()
+
+		report, err := service.ImportCourses(strings.NewReader(importCalendar), &models.ICSImportOptions{
+			TeacherID: teacher.ID,
+			SubjectID: subject.ID,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Created)
+
+		// 3 occurrences pour la série + 1 cours ponctuel
+		var count int64
+		testDB.Model(&models.Course{}).Count(&count)
+		assert.Equal(t, int64(4), count)
+	})
+
+	t.Run("ImportCourses_RequiresTeacher", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newImportService()
+
+		_, err := service.ImportCourses(strings.NewReader(importCalendar), &models.ICSImportOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("ImportEvents_ExpandsRecurrence", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newImportService()
+
+		student := createTestUser(models.RoleEtudiant)
+
+		report, err := service.ImportEvents(strings.NewReader(importCalendar), student.ID, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Created)
+		assert.Len(t, report.Items[0].CreatedIDs, 3)
+
+		var count int64
+		testDB.Model(&models.Event{}).Where("user_id = ?", student.ID).Count(&count)
+		assert.Equal(t, int64(4), count)
+	})
+}
@@ -0,0 +1,439 @@
+package tests
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/utils"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Opérations et codes de résultat LDAP (RFC 4511) utilisés par l'annuaire de test
+const (
+	ldapBindRequest    = 0
+	ldapBindResponse   = 1
+	ldapUnbindRequest  = 2
+	ldapSearchRequest  = 3
+	ldapSearchEntry    = 4
+	ldapSearchDone     = 5
+	ldapSuccess        = 0
+	ldapInvalidCreds   = 49
+	ldapInsufficient   = 50
+	ldapUnwillingToAct = 53
+)
+
+const (
+	mockLDAPBaseDN          = "dc=ecole,dc=test"
+	mockLDAPServiceDN       = "cn=eduqr,ou=services,dc=ecole,dc=test"
+	mockLDAPServicePassword = "service-secret"
+	mockLDAPGroupTeachersDN = "cn=Enseignants,ou=groupes,dc=ecole,dc=test"
+	mockLDAPGroupL3DN       = "cn=L3-INFO,ou=groupes,dc=ecole,dc=test"
+	mockLDAPGroupUnmappedDN = "cn=Bibliotheque,ou=groupes,dc=ecole,dc=test"
+)
+
+// mockLDAPServer est un annuaire LDAP en mémoire qui comprend le bind simple et la recherche
+// avec les filtres &, |, !, égalité et présence : assez pour LDAPService
+type mockLDAPServer struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	entries map[string]map[string][]string // DN -> attribut (en minuscules) -> valeurs
+}
+
+func newMockLDAPServer(t *testing.T) *mockLDAPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &mockLDAPServer{listener: listener, entries: map[string]map[string][]string{}}
+	server.AddEntry(mockLDAPServiceDN, map[string][]string{"objectClass": {"applicationProcess"}, "userPassword": {mockLDAPServicePassword}})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *mockLDAPServer) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// AddEntry ajoute ou remplace une entrée de l'annuaire
+func (s *mockLDAPServer) AddEntry(dn string, attributes map[string][]string) {
+	normalized := make(map[string][]string, len(attributes))
+	for name, values := range attributes {
+		normalized[strings.ToLower(name)] = values
+	}
+	s.mu.Lock()
+	s.entries[strings.ToLower(dn)] = normalized
+	s.mu.Unlock()
+}
+
+// RemoveEntry retire une entrée de l'annuaire
+func (s *mockLDAPServer) RemoveEntry(dn string) {
+	s.mu.Lock()
+	delete(s.entries, strings.ToLower(dn))
+	s.mu.Unlock()
+}
+
+func (s *mockLDAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	bound := false
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+
+		switch request.Tag {
+		case ldapBindRequest:
+			dn := request.Children[1].Value.(string)
+			password := request.Children[2].Data.String()
+			code := ldapInvalidCreds
+			if password == "" {
+				code = ldapUnwillingToAct
+			} else if s.checkPassword(dn, password) {
+				code = ldapSuccess
+			}
+			bound = code == ldapSuccess
+			s.respond(conn, messageID, ldapBindResponse, code)
+		case ldapUnbindRequest:
+			return
+		case ldapSearchRequest:
+			if !bound {
+				s.respond(conn, messageID, ldapSearchDone, ldapInsufficient)
+				continue
+			}
+			baseDN := strings.ToLower(request.Children[0].Value.(string))
+			filter := request.Children[6]
+			var attributes []string
+			for _, attribute := range request.Children[7].Children {
+				attributes = append(attributes, strings.ToLower(attribute.Value.(string)))
+			}
+			for dn, entry := range s.snapshot() {
+				if strings.HasSuffix(dn, baseDN) && matchLDAPFilter(filter, entry) {
+					s.sendEntry(conn, messageID, dn, entry, attributes)
+				}
+			}
+			s.respond(conn, messageID, ldapSearchDone, ldapSuccess)
+		default:
+			return
+		}
+	}
+}
+
+func (s *mockLDAPServer) checkPassword(dn, password string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[strings.ToLower(dn)]
+	if !ok {
+		return false
+	}
+	for _, value := range entry["userpassword"] {
+		if value == password {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *mockLDAPServer) snapshot() map[string]map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make(map[string]map[string][]string, len(s.entries))
+	for dn, entry := range s.entries {
+		entries[dn] = entry
+	}
+	return entries
+}
+
+func (s *mockLDAPServer) respond(w io.Writer, messageID int64, application ber.Tag, code int) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, application, nil, "Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic message"))
+	s.write(w, messageID, response)
+}
+
+func (s *mockLDAPServer) sendEntry(w io.Writer, messageID int64, dn string, entry map[string][]string, attributes []string) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldapSearchEntry, nil, "Search result entry")
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "Object name"))
+	list := ber.NewSequence("Attributes")
+	for _, name := range attributes {
+		values, ok := entry[name]
+		if !ok {
+			continue
+		}
+		attribute := ber.NewSequence("Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		list.AppendChild(attribute)
+	}
+	response.AppendChild(list)
+	s.write(w, messageID, response)
+}
+
+func (s *mockLDAPServer) write(w io.Writer, messageID int64, response *ber.Packet) {
+	envelope := ber.NewSequence("LDAP message")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	envelope.AppendChild(response)
+	w.Write(envelope.Bytes())
+}
+
+// matchLDAPFilter évalue un filtre de recherche sur une entrée
+func matchLDAPFilter(filter *ber.Packet, entry map[string][]string) bool {
+	switch filter.Tag {
+	case 0: // &
+		for _, child := range filter.Children {
+			if !matchLDAPFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case 1: // |
+		for _, child := range filter.Children {
+			if matchLDAPFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case 2: // !
+		return !matchLDAPFilter(filter.Children[0], entry)
+	case 3: // égalité
+		name := strings.ToLower(filter.Children[0].Data.String())
+		expected := filter.Children[1].Data.String()
+		for _, value := range entry[name] {
+			if strings.EqualFold(value, expected) {
+				return true
+			}
+		}
+		return false
+	case 7: // présence
+		_, ok := entry[strings.ToLower(filter.Data.String())]
+		return ok
+	}
+	return false
+}
+
+func TestLDAP(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	directory := newMockLDAPServer(t)
+	client := models.SessionClient{IPAddress: "127.0.0.1", UserAgent: "test"}
+
+	aliceDN := "uid=alice,ou=etudiants,dc=ecole,dc=test"
+	bobDN := "uid=bob,ou=personnels,dc=ecole,dc=test"
+	addAlice := func() {
+		directory.AddEntry(aliceDN, map[string][]string{
+			"objectClass":  {"person"},
+			"entryUUID":    {"uuid-alice"},
+			"mail":         {"Alice@Ecole.test"},
+			"givenName":    {"Alice"},
+			"sn":           {"Martin"},
+			"memberOf":     {mockLDAPGroupL3DN, mockLDAPGroupUnmappedDN},
+			"userPassword": {"alice-password"},
+		})
+	}
+	addBob := func() {
+		directory.AddEntry(bobDN, map[string][]string{
+			"objectClass":  {"person"},
+			"entryUUID":    {"uuid-bob"},
+			"mail":         {"bob@ecole.test"},
+			"givenName":    {"Bob"},
+			"sn":           {"Durand"},
+			"memberOf":     {mockLDAPGroupTeachersDN},
+			"userPassword": {"bob-password"},
+		})
+	}
+
+	newLDAPService := func() *services.LDAPService {
+		return services.NewLDAPService(services.LDAPSettings{
+			URL:                directory.URL(),
+			BindDN:             mockLDAPServiceDN,
+			BindPassword:       mockLDAPServicePassword,
+			BaseDN:             mockLDAPBaseDN,
+			UserFilter:         "(objectClass=person)",
+			IDAttribute:        "entryUUID",
+			EmailAttribute:     "mail",
+			FirstNameAttribute: "givenName",
+			LastNameAttribute:  "sn",
+			GroupAttribute:     "memberOf",
+			RoleMapping:        map[string]string{"Enseignants": models.RoleProfesseur},
+			GroupMapping:       map[string]string{"L3-INFO": "L3 Informatique"},
+			DefaultRole:        models.RoleEtudiant,
+		}, repositories.NewUserRepository(), repositories.NewGroupRepository(testDB), repositories.NewSessionRepository(testDB))
+	}
+
+	newAuthService := func(directory *services.LDAPService) *services.AuthService {
+		userRepo := repositories.NewUserRepository()
+		loginThrottle := services.NewLoginThrottleService(
+			repositories.NewLoginThrottleRepository(testDB),
+			userRepo,
+			services.NewAuditLogService(repositories.NewAuditLogRepository()),
+			services.LoginThrottleSettings{DelayAfter: 10, BaseDelay: time.Second, MaxAccountFailures: 10, MaxIPFailures: 50, LockoutDuration: 15 * time.Minute},
+		)
+		twoFactor := services.NewTwoFactorService(repositories.NewTwoFactorRepository(testDB), userRepo, "EduQR", nil)
+		return services.NewAuthService(userRepo, repositories.NewSessionRepository(testDB), loginThrottle, twoFactor, directory, "test-secret", 15*time.Minute, 24*time.Hour)
+	}
+
+	login := func(service *services.AuthService, email, password string) (*models.UserResponse, error) {
+		_, user, err := service.Login(&models.LoginRequest{Email: email, Password: password}, client)
+		return user, err
+	}
+
+	createLocalUser := func(email, role string) *models.User {
+		hash, err := utils.HashPassword("Password123!")
+		require.NoError(t, err)
+		user := &models.User{Email: email, FirstName: "Local", LastName: "User", Password: hash, Role: role}
+		testDB.Create(user)
+		return user
+	}
+
+	findUser := func(email string) *models.User {
+		var user models.User
+		require.NoError(t, testDB.Where("email = ?", email).First(&user).Error)
+		return &user
+	}
+
+	t.Run("LoginThroughDirectory", func(t *testing.T) {
+		cleanupTestDatabase()
+		addAlice()
+		addBob()
+		l3 := models.Group{Name: "L3 Informatique"}
+		testDB.Create(&l3)
+		authService := newAuthService(newLDAPService())
+
+		user, err := login(authService, "alice@ecole.test", "alice-password")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice@ecole.test", user.Email)
+		assert.Equal(t, "Martin", user.LastName)
+		assert.Equal(t, models.RoleEtudiant, user.Role)
+		assert.Equal(t, models.AuthProviderLDAP, user.AuthProvider)
+		groupIDs, err := repositories.NewGroupRepository(testDB).GetGroupIDsByStudent(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{l3.ID}, groupIDs)
+
+		user, err = login(authService, "bob@ecole.test", "bob-password")
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleProfesseur, user.Role)
+
+		_, err = login(authService, "alice@ecole.test", "mauvais")
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+		_, err = login(authService, "inconnu@ecole.test", "alice-password")
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+		var throttle models.LoginThrottle
+		assert.NoError(t, testDB.Where("scope = ? AND identifier = ?", models.ThrottleScopeAccount, "alice@ecole.test").First(&throttle).Error)
+		assert.Equal(t, 1, throttle.Failures)
+	})
+
+	t.Run("LocalAccountsKeepTheirPassword", func(t *testing.T) {
+		cleanupTestDatabase()
+		authService := newAuthService(newLDAPService())
+		createLocalUser("local@eduqr.com", models.RoleAdmin)
+
+		_, err := login(authService, "local@eduqr.com", "Password123!")
+		assert.NoError(t, err)
+	})
+
+	t.Run("UnreachableDirectoryIsNotAFailedAttempt", func(t *testing.T) {
+		cleanupTestDatabase()
+		unreachable := services.NewLDAPService(services.LDAPSettings{URL: "ldap://127.0.0.1:1", UserFilter: "(objectClass=person)", EmailAttribute: "mail"},
+			repositories.NewUserRepository(), repositories.NewGroupRepository(testDB), repositories.NewSessionRepository(testDB))
+
+		_, err := login(newAuthService(unreachable), "alice@ecole.test", "alice-password")
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, services.ErrInvalidCredentials))
+
+		var count int64
+		testDB.Model(&models.LoginThrottle{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("SyncDeactivatesRemovedUsers", func(t *testing.T) {
+		cleanupTestDatabase()
+		addAlice()
+		addBob()
+		ldapService := newLDAPService()
+		authService := newAuthService(ldapService)
+
+		report, err := ldapService.Sync()
+		assert.NoError(t, err)
+		assert.Equal(t, services.LDAPSyncReport{Created: 2}, *report)
+		assert.Equal(t, models.RoleProfesseur, findUser("bob@ecole.test").Role)
+
+		// Alice quitte l'annuaire alors qu'elle est connectée
+		_, err = login(authService, "alice@ecole.test", "alice-password")
+		assert.NoError(t, err)
+		directory.RemoveEntry(aliceDN)
+
+		report, err = ldapService.Sync()
+		assert.NoError(t, err)
+		assert.Equal(t, services.LDAPSyncReport{Updated: 1, Deactivated: 1}, *report)
+
+		alice := findUser("alice@ecole.test")
+		assert.NotNil(t, alice.DeactivatedAt)
+		sessions, err := repositories.NewSessionRepository(testDB).GetActiveUserSessions(alice.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+
+		// Elle revient dans l'annuaire : le même compte est réactivé
+		addAlice()
+		report, err = ldapService.Sync()
+		assert.NoError(t, err)
+		assert.Equal(t, services.LDAPSyncReport{Updated: 1, Reactivated: 1}, *report)
+		assert.Nil(t, findUser("alice@ecole.test").DeactivatedAt)
+		assert.Equal(t, alice.ID, findUser("alice@ecole.test").ID)
+	})
+
+	t.Run("DeactivatedLocalAccountCannotLogin", func(t *testing.T) {
+		cleanupTestDatabase()
+		authService := newAuthService(nil)
+		user := createLocalUser("parti@eduqr.com", models.RoleProfesseur)
+		now := time.Now()
+		assert.NoError(t, repositories.NewUserRepository().SetDeactivatedAt(user.ID, &now))
+
+		_, err := login(authService, "parti@eduqr.com", "Password123!")
+		assert.ErrorIs(t, err, services.ErrAccountDeactivated)
+	})
+
+	t.Run("SyncAbortsOnEmptyResult", func(t *testing.T) {
+		cleanupTestDatabase()
+		addBob()
+		ldapService := newLDAPService()
+		_, err := ldapService.Sync()
+		assert.NoError(t, err)
+
+		directory.RemoveEntry(aliceDN)
+		directory.RemoveEntry(bobDN)
+		_, err = ldapService.Sync()
+		assert.Error(t, err)
+		assert.Nil(t, findUser("bob@ecole.test").DeactivatedAt)
+	})
+}
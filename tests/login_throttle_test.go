@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	createLoginUser := func(email string) *models.User {
+		hash, err := utils.HashPassword("Password123!")
+		assert.NoError(t, err)
+		user := &models.User{
+			Email:     email,
+			FirstName: "Test",
+			LastName:  "Throttle",
+			Password:  hash,
+			Role:      models.RoleEtudiant,
+		}
+		testDB.Create(user)
+		return user
+	}
+
+	login := func(service *services.AuthService, email, password, ip string) error {
+		_, _, err := service.Login(&models.LoginRequest{Email: email, Password: password}, models.SessionClient{IPAddress: ip})
+		return err
+	}
+
+	// ageFailures fait comme si le dernier échec datait d'il y a d ; le délai progressif est alors écoulé
+	ageFailures := func(d time.Duration) {
+		testDB.Model(&models.LoginThrottle{}).Where("1 = 1").Update("last_failure_at", time.Now().Add(-d))
+	}
+
+	asThrottled := func(err error) *services.LoginThrottledError {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			return throttled
+		}
+		return nil
+	}
+
+	t.Run("ProgressiveDelay", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestAuthService(services.LoginThrottleSettings{
+			DelayAfter:         2,
+			BaseDelay:          10 * time.Second,
+			MaxAccountFailures: 10,
+			MaxIPFailures:      50,
+			LockoutDuration:    15 * time.Minute,
+		})
+		user := createLoginUser("delai@eduqr.com")
+
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.1"))
+		assert.Nil(t, asThrottled(login(service, user.Email, "mauvais", "10.0.0.1")))
+
+		// Après deux échecs, même le bon mot de passe doit attendre le délai
+		throttled := asThrottled(login(service, user.Email, "Password123!", "10.0.0.1"))
+		if assert.NotNil(t, throttled) {
+			assert.False(t, throttled.Locked)
+			assert.LessOrEqual(t, throttled.RetryAfter, 10*time.Second)
+		}
+
+		ageFailures(11 * time.Second)
+		assert.NoError(t, login(service, user.Email, "Password123!", "10.0.0.1"))
+
+		// La connexion réussie efface les échecs du compte
+		var count int64
+		testDB.Model(&models.LoginThrottle{}).Where("scope = ?", models.ThrottleScopeAccount).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("AccountLockoutAndUnlock", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestAuthService(services.LoginThrottleSettings{
+			DelayAfter:         10,
+			BaseDelay:          time.Second,
+			MaxAccountFailures: 3,
+			MaxIPFailures:      50,
+			LockoutDuration:    15 * time.Minute,
+		})
+		user := createLoginUser("verrou@eduqr.com")
+		admin := createTestUser(models.RoleAdmin)
+
+		// Les échecs viennent d'adresses différentes : le compte est verrouillé quand même
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.1"))
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.2"))
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.3"))
+
+		throttled := asThrottled(login(service, user.Email, "Password123!", "10.0.0.4"))
+		if assert.NotNil(t, throttled) {
+			assert.True(t, throttled.Locked)
+		}
+
+		var logs int64
+		testDB.Model(&models.AuditLog{}).Where("action = ? AND resource_id = ?", models.ActionAccountLocked, user.ID).Count(&logs)
+		assert.Equal(t, int64(1), logs)
+
+		assert.NoError(t, service.UnlockAccount(user.ID, admin.ID, admin.Email, admin.Role, "127.0.0.1", "test"))
+		assert.NoError(t, login(service, user.Email, "Password123!", "10.0.0.4"))
+
+		testDB.Model(&models.AuditLog{}).Where("action = ? AND user_id = ? AND resource_id = ?", models.ActionAccountUnlocked, admin.ID, user.ID).Count(&logs)
+		assert.Equal(t, int64(1), logs)
+	})
+
+	t.Run("IPLockoutAcrossAccounts", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestAuthService(services.LoginThrottleSettings{
+			DelayAfter:         10,
+			BaseDelay:          time.Second,
+			MaxAccountFailures: 10,
+			MaxIPFailures:      3,
+			LockoutDuration:    15 * time.Minute,
+		})
+		user := createLoginUser("cible@eduqr.com")
+
+		// Une même adresse essaie plusieurs comptes, existants ou non
+		assert.Error(t, login(service, "a@eduqr.com", "mauvais", "10.0.0.9"))
+		assert.Error(t, login(service, "b@eduqr.com", "mauvais", "10.0.0.9"))
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.9"))
+
+		throttled := asThrottled(login(service, user.Email, "Password123!", "10.0.0.9"))
+		if assert.NotNil(t, throttled) {
+			assert.True(t, throttled.Locked)
+		}
+
+		// Le compte reste accessible depuis une autre adresse
+		assert.NoError(t, login(service, user.Email, "Password123!", "10.0.0.10"))
+	})
+
+	t.Run("OldFailuresAreForgotten", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestAuthService(services.LoginThrottleSettings{
+			DelayAfter:         10,
+			BaseDelay:          time.Second,
+			MaxAccountFailures: 3,
+			MaxIPFailures:      50,
+			LockoutDuration:    15 * time.Minute,
+		})
+		user := createLoginUser("oubli@eduqr.com")
+
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.1"))
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.1"))
+		ageFailures(time.Hour)
+
+		// Le compteur repart de zéro : un échec de plus ne verrouille pas
+		assert.Error(t, login(service, user.Email, "mauvais", "10.0.0.1"))
+		assert.NoError(t, login(service, user.Email, "Password123!", "10.0.0.1"))
+	})
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergedRooms(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newBookingService := func() *services.RoomBookingService {
+		groupRepo := repositories.NewGroupRepository(testDB)
+		return services.NewRoomBookingService(
+			repositories.NewRoomBookingRepository(testDB),
+			repositories.NewRoomRepository(testDB),
+			repositories.NewCourseRepository(testDB),
+			services.NewNotificationService(repositories.NewNotificationRepository(testDB), groupRepo),
+			newPermissionService(),
+		)
+	}
+
+	// createModularRoom crée une salle modulable « Amphi » et ses sous-salles « Amphi A », « Amphi B » et « Amphi C »
+	createModularRoom := func() map[string]uint {
+		roomService := services.NewRoomService(repositories.NewRoomRepository(testDB))
+		room, err := roomService.CreateRoom(&models.CreateRoomRequest{Name: "Amphi", IsModular: true, SubRoomsCount: 3})
+		assert.NoError(t, err)
+
+		ids := map[string]uint{"Amphi": room.ID}
+		roomRepo := repositories.NewRoomRepository(testDB)
+		for _, name := range []string{"Amphi A", "Amphi B", "Amphi C"} {
+			subRoom, err := roomRepo.GetRoomByName(name)
+			assert.NoError(t, err)
+			ids[name] = subRoom.ID
+		}
+		return ids
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	t.Run("MergedCourse_BlocksEachPartAndParent", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		rooms := createModularRoom()
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+
+		course, err := service.CreateCourse(&models.CreateCourseRequest{
+			Name:          "Conférence",
+			SubjectID:     subject.ID,
+			TeacherID:     teacher.ID,
+			RoomID:        rooms["Amphi A"],
+			MergedRoomIDs: []uint{rooms["Amphi B"]},
+			StartTime:     tomorrow,
+			Duration:      120,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, course.MergedRooms, 1)
+
+		conflictsIn := func(roomID uint) []models.ConflictInfo {
+			conflicts, err := service.CheckConflicts(&models.CreateCourseRequest{
+				SubjectID: subject.ID,
+				TeacherID: createTestUser(models.RoleAdmin).ID,
+				RoomID:    roomID,
+				StartTime: tomorrow.Add(time.Hour),
+				Duration:  60,
+			})
+			assert.NoError(t, err)
+			return conflicts
+		}
+
+		assert.NotEmpty(t, conflictsIn(rooms["Amphi B"]))
+		assert.NotEmpty(t, conflictsIn(rooms["Amphi"]))
+		assert.Empty(t, conflictsIn(rooms["Amphi C"]))
+	})
+
+	t.Run("CreateCourse_RefusesNonSiblingRooms", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		rooms := createModularRoom()
+		other := createTestRoom()
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+
+		_, err := service.CreateCourse(&models.CreateCourseRequest{
+			Name:          "Conférence",
+			SubjectID:     subject.ID,
+			TeacherID:     teacher.ID,
+			RoomID:        rooms["Amphi A"],
+			MergedRoomIDs: []uint{other.ID},
+			StartTime:     tomorrow,
+			Duration:      60,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("RoomTimeline_ShowsActiveConfiguration", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newBookingService()
+
+		rooms := createModularRoom()
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		admin := createTestUser(models.RoleAdmin)
+
+		// 8h-10h : Amphi B+C réunies pour un cours ; 10h-11h : réservation de la salle entière
+		course := &models.Course{
+			Name:        "TP réunis",
+			TeacherID:   teacher.ID,
+			SubjectID:   subject.ID,
+			RoomID:      rooms["Amphi B"],
+			MergedRooms: []models.Room{{ID: rooms["Amphi C"]}},
+			StartTime:   tomorrow,
+			EndTime:     tomorrow.Add(2 * time.Hour),
+			Duration:    120,
+		}
+		assert.NoError(t, testDB.Create(course).Error)
+
+		booking, err := service.CreateBooking(&models.CreateRoomBookingRequest{
+			RoomID:    rooms["Amphi"],
+			Title:     "Assemblée générale",
+			StartTime: tomorrow.Add(2 * time.Hour),
+			EndTime:   tomorrow.Add(3 * time.Hour),
+		}, admin.ID)
+		assert.NoError(t, err)
+		_, err = service.ReviewBooking(booking.ID, &models.ReviewRoomBookingRequest{Status: models.RoomBookingStatusApproved}, admin.ID)
+		assert.NoError(t, err)
+
+		timeline, err := service.GetRoomTimeline(rooms["Amphi A"], tomorrow, tomorrow.Add(4*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, "Amphi", timeline.Room.Name)
+		if !assert.Len(t, timeline.Segments, 3) {
+			return
+		}
+
+		assert.Equal(t, "Amphi A | Amphi B+Amphi C", timeline.Segments[0].Configuration)
+		assert.Nil(t, timeline.Segments[0].Spaces[0].Activity)
+		assert.True(t, timeline.Segments[0].Spaces[1].Merged)
+		assert.Equal(t, course.ID, timeline.Segments[0].Spaces[1].Activity.ID)
+
+		assert.Equal(t, "Amphi", timeline.Segments[1].Configuration)
+		assert.Equal(t, models.RoomActivityBooking, timeline.Segments[1].Spaces[0].Activity.Type)
+
+		assert.Equal(t, "Amphi A | Amphi B | Amphi C", timeline.Segments[2].Configuration)
+		assert.True(t, timeline.Segments[2].End.Equal(tomorrow.Add(4*time.Hour)))
+	})
+}
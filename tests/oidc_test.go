@@ -0,0 +1,417 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"eduqr-backend/internal/controllers"
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockOIDCClientID     = "eduqr"
+	mockOIDCClientSecret = "eduqr-secret"
+)
+
+// mockOIDCProvider est un fournisseur d'identité OpenID Connect minimal : découverte, clés publiques
+// et point d'accès jeton avec vérification PKCE. L'authentification de l'utilisateur est simulée par Authorize.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	nonce         string
+	codeChallenge string
+	claims        jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	provider := &mockOIDCProvider{key: key, codes: map[string]mockAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := provider.server.URL
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"token_endpoint":                        issuer + "/token",
+			"jwks_uri":                              issuer + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", provider.token)
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+// Authorize simule l'authentification de l'utilisateur sur l'URL retournée par EduQR : retourne le code
+// et le state que le fournisseur transmettrait à la page de retour
+func (p *mockOIDCProvider) Authorize(t *testing.T, authorizationURL string, claims jwt.MapClaims) (string, string) {
+	parsed, err := url.Parse(authorizationURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, p.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, mockOIDCClientID, query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	code, err := utils.GenerateRandomToken(16)
+	require.NoError(t, err)
+	p.mu.Lock()
+	p.codes[code] = mockAuthorization{nonce: query.Get("nonce"), codeChallenge: query.Get("code_challenge"), claims: claims}
+	p.mu.Unlock()
+	return code, query.Get("state")
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	if clientID != mockOIDCClientID || clientSecret != mockOIDCClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	p.mu.Lock()
+	authorization, ok := p.codes[r.FormValue("code")]
+	delete(p.codes, r.FormValue("code"))
+	p.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(challenge[:]) != authorization.codeChallenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   mockOIDCClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": authorization.nonce,
+	}
+	for name, value := range authorization.claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access-" + r.FormValue("code"),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func TestOIDC(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	provider := newMockOIDCProvider(t)
+	client := models.SessionClient{IPAddress: "127.0.0.1", UserAgent: "test"}
+
+	// newOIDCService crée le service avec une double authentification imposée aux rôles requiredRoles
+	newOIDCService := func(requiredRoles []string) *services.OIDCService {
+		userRepo := repositories.NewUserRepository()
+		loginThrottle := services.NewLoginThrottleService(
+			repositories.NewLoginThrottleRepository(testDB),
+			userRepo,
+			services.NewAuditLogService(repositories.NewAuditLogRepository()),
+			services.LoginThrottleSettings{DelayAfter: 10, BaseDelay: time.Second, MaxAccountFailures: 10, MaxIPFailures: 50, LockoutDuration: 15 * time.Minute},
+		)
+		twoFactor := services.NewTwoFactorService(repositories.NewTwoFactorRepository(testDB), userRepo, "EduQR", requiredRoles)
+		authService := services.NewAuthService(userRepo, repositories.NewSessionRepository(testDB), loginThrottle, twoFactor, nil, "test-secret", 15*time.Minute, 24*time.Hour)
+		return services.NewOIDCService(services.OIDCSettings{
+			IssuerURL:    provider.server.URL,
+			ClientID:     mockOIDCClientID,
+			ClientSecret: mockOIDCClientSecret,
+			RedirectURL:  "http://localhost:3000/auth/oidc/callback",
+			Scopes:       []string{"openid", "profile", "email", "groups"},
+			GroupsClaim:  "groups",
+			RoleMapping:  map[string]string{"staff": models.RoleProfesseur, "it": models.RoleAdmin},
+			GroupMapping: map[string]string{"l3-info": "L3 Informatique", "l3-math": "L3 Mathématiques"},
+			DefaultRole:  models.RoleEtudiant,
+		}, repositories.NewOIDCRepository(testDB), userRepo, repositories.NewGroupRepository(testDB), repositories.NewSessionRepository(testDB), authService)
+	}
+
+	// login déroule une connexion complète pour l'utilisateur décrit par claims
+	login := func(service *services.OIDCService, claims jwt.MapClaims) (*models.UserResponse, error) {
+		authorizationURL, browserSecret, err := service.AuthorizationURL()
+		require.NoError(t, err)
+		code, state := provider.Authorize(t, authorizationURL, claims)
+		tokens, user, err := service.Login(context.Background(), &models.OIDCCallbackRequest{Code: code, State: state}, browserSecret, client)
+		if err == nil {
+			assert.NotEmpty(t, tokens.AccessToken)
+		}
+		return user, err
+	}
+
+	activeGroupIDs := func(userID uint) []uint {
+		ids, err := repositories.NewGroupRepository(testDB).GetGroupIDsByStudent(userID)
+		assert.NoError(t, err)
+		return ids
+	}
+
+	t.Run("JustInTimeProvisioning", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService(nil)
+		l3Info := models.Group{Name: "L3 Informatique"}
+		l3Math := models.Group{Name: "L3 Mathématiques"}
+		other := models.Group{Name: "Option Théâtre"}
+		testDB.Create(&l3Info)
+		testDB.Create(&l3Math)
+		testDB.Create(&other)
+
+		user, err := login(service, jwt.MapClaims{
+			"sub":            "etu-1",
+			"email":          "Alice.Martin@univ.fr",
+			"email_verified": true,
+			"given_name":     "Alice",
+			"family_name":    "Martin",
+			"groups":         []string{"l3-info", "students"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "alice.martin@univ.fr", user.Email)
+		assert.Equal(t, "Alice", user.FirstName)
+		assert.Equal(t, models.RoleEtudiant, user.Role)
+		assert.Equal(t, models.AuthProviderOIDC, user.AuthProvider)
+		assert.ElementsMatch(t, []uint{l3Info.ID}, activeGroupIDs(user.ID))
+
+		// Un groupe hors correspondance n'est pas touché par la synchronisation
+		testDB.Create(&models.GroupMembership{GroupID: other.ID, UserID: user.ID, StartDate: time.Now().Add(-time.Hour)})
+
+		// Seconde connexion : même compte, groupes réalignés sur le fournisseur
+		again, err := login(service, jwt.MapClaims{
+			"sub":    "etu-1",
+			"email":  "alice.martin@univ.fr",
+			"groups": "l3-math",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, again.ID)
+		assert.ElementsMatch(t, []uint{l3Math.ID, other.ID}, activeGroupIDs(user.ID))
+
+		var count int64
+		testDB.Model(&models.User{}).Where("email = ?", "alice.martin@univ.fr").Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("RoleMapping", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService(nil)
+
+		// Le rôle le plus élevé l'emporte
+		user, err := login(service, jwt.MapClaims{"sub": "staff-1", "email": "prof@univ.fr", "name": "Prof Dupont", "groups": []string{"staff", "it"}})
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleAdmin, user.Role)
+		assert.Equal(t, "Prof Dupont", user.FirstName)
+
+		user, err = login(service, jwt.MapClaims{"sub": "staff-1", "email": "prof@univ.fr", "groups": []string{"staff"}})
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleProfesseur, user.Role)
+
+		// Sans groupe associé à un rôle, le rôle existant est conservé
+		user, err = login(service, jwt.MapClaims{"sub": "staff-1", "email": "prof@univ.fr"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleProfesseur, user.Role)
+
+		// La session ouverte avec le rôle administrateur a été révoquée au changement de rôle,
+		// celle ouverte ensuite avec le même rôle est conservée
+		var active int64
+		testDB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&active)
+		assert.Equal(t, int64(2), active)
+	})
+
+	t.Run("ExistingAccountLinking", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService(nil)
+		existing := &models.User{Email: "bob@univ.fr", FirstName: "Bob", LastName: "Local", Password: "x", Role: models.RoleProfesseur}
+		testDB.Create(existing)
+
+		_, err := login(service, jwt.MapClaims{"sub": "bob", "email": "bob@univ.fr", "email_verified": false})
+		assert.Error(t, err)
+
+		user, err := login(service, jwt.MapClaims{"sub": "bob", "email": "bob@univ.fr", "email_verified": true})
+		assert.NoError(t, err)
+		assert.Equal(t, existing.ID, user.ID)
+		assert.Equal(t, models.RoleProfesseur, user.Role)
+		assert.Equal(t, models.AuthProviderOIDC, user.AuthProvider)
+
+		// Une autre identité du fournisseur ne peut pas reprendre un compte déjà rattaché
+		_, err = login(service, jwt.MapClaims{"sub": "bob-2", "email": "bob@univ.fr", "email_verified": true})
+		assert.ErrorIs(t, err, services.ErrAccountAlreadyLinked)
+	})
+
+	t.Run("AccountLinkedToLDAPIsNotTakenOver", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService(nil)
+		existing := &models.User{Email: "carol@univ.fr", FirstName: "Carol", LastName: "Annuaire", Password: "x", Role: models.RoleProfesseur, AuthProvider: models.AuthProviderLDAP, ExternalID: "carol-guid"}
+		testDB.Create(existing)
+
+		_, err := login(service, jwt.MapClaims{"sub": "carol", "email": "carol@univ.fr", "email_verified": true})
+		assert.ErrorIs(t, err, services.ErrAccountAlreadyLinked)
+
+		var user models.User
+		testDB.First(&user, existing.ID)
+		assert.Equal(t, models.AuthProviderLDAP, user.AuthProvider)
+		assert.Equal(t, "carol-guid", user.ExternalID)
+	})
+
+	t.Run("StateIsSingleUse", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService(nil)
+
+		authorizationURL, browserSecret, err := service.AuthorizationURL()
+		require.NoError(t, err)
+		code, state := provider.Authorize(t, authorizationURL, jwt.MapClaims{"sub": "etu-2", "email": "etu2@univ.fr"})
+
+		_, _, err = service.Login(context.Background(), &models.OIDCCallbackRequest{Code: code, State: "inconnu"}, browserSecret, client)
+		assert.Error(t, err)
+
+		_, _, err = service.Login(context.Background(), &models.OIDCCallbackRequest{Code: code, State: state}, browserSecret, client)
+		assert.NoError(t, err)
+
+		_, _, err = service.Login(context.Background(), &models.OIDCCallbackRequest{Code: code, State: state}, browserSecret, client)
+		assert.Error(t, err)
+	})
+
+	t.Run("StateIsBoundToBrowser", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService(nil)
+
+		// L'attaquant démarre une connexion et tente de la faire terminer par le navigateur de sa victime
+		authorizationURL, _, err := service.AuthorizationURL()
+		require.NoError(t, err)
+		code, state := provider.Authorize(t, authorizationURL, jwt.MapClaims{"sub": "attaquant", "email": "attaquant@univ.fr"})
+		_, victimSecret, err := service.AuthorizationURL()
+		require.NoError(t, err)
+
+		_, _, err = service.Login(context.Background(), &models.OIDCCallbackRequest{Code: code, State: state}, victimSecret, client)
+		assert.Error(t, err)
+
+		authorizationURL, _, err = service.AuthorizationURL()
+		require.NoError(t, err)
+		code, state = provider.Authorize(t, authorizationURL, jwt.MapClaims{"sub": "attaquant", "email": "attaquant@univ.fr"})
+		_, _, err = service.Login(context.Background(), &models.OIDCCallbackRequest{Code: code, State: state}, "", client)
+		assert.Error(t, err)
+
+		var count int64
+		testDB.Model(&models.User{}).Where("email = ?", "attaquant@univ.fr").Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("CallbackRequiresBrowserCookie", func(t *testing.T) {
+		cleanupTestDatabase()
+		gin.SetMode(gin.TestMode)
+		controller := controllers.NewOIDCController(newOIDCService(nil))
+		engine := gin.New()
+		engine.GET("/api/v1/auth/oidc/login", controller.Authorize)
+		engine.POST("/api/v1/auth/oidc/callback", controller.Callback)
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+		assert.Equal(t, "/api/v1/auth/oidc", cookies[0].Path)
+
+		var response models.OIDCAuthorizationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		callback := func(code, state string, cookie *http.Cookie) int {
+			body, _ := json.Marshal(models.OIDCCallbackRequest{Code: code, State: state})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/oidc/callback", strings.NewReader(string(body)))
+			req.Header.Set("Content-Type", "application/json")
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		// Sans le cookie, l'état est refusé
+		code, state := provider.Authorize(t, response.AuthorizationURL, jwt.MapClaims{"sub": "etu-4", "email": "etu4@univ.fr"})
+		assert.Equal(t, http.StatusUnauthorized, callback(code, state, nil))
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login", nil))
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		code, state = provider.Authorize(t, response.AuthorizationURL, jwt.MapClaims{"sub": "etu-4", "email": "etu4@univ.fr"})
+		assert.Equal(t, http.StatusOK, callback(code, state, w.Result().Cookies()[0]))
+	})
+
+	t.Run("RejectsForgedIDToken", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService(nil)
+
+		// Jeton émis pour un autre client
+		_, err := login(service, jwt.MapClaims{"sub": "etu-3", "email": "etu3@univ.fr", "aud": "autre-application"})
+		assert.Error(t, err)
+
+		// Jeton rejoué d'une autre connexion
+		_, err = login(service, jwt.MapClaims{"sub": "etu-3", "email": "etu3@univ.fr", "nonce": "ancien"})
+		assert.Error(t, err)
+
+		var count int64
+		testDB.Model(&models.User{}).Where("email = ?", "etu3@univ.fr").Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("TwoFactorStillApplies", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newOIDCService([]string{models.RoleAdmin})
+
+		_, err := login(service, jwt.MapClaims{"sub": "admin-1", "email": "admin@univ.fr", "groups": []string{"it"}})
+		var required *services.TwoFactorRequiredError
+		assert.ErrorAs(t, err, &required)
+		assert.True(t, required.Setup)
+	})
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/mail"
+	"eduqr-backend/pkg/utils"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSender conserve les e-mails envoyés au lieu de les transmettre
+type recordingSender struct {
+	messages []mail.Message
+}
+
+func (s *recordingSender) Send(msg mail.Message) error {
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+var resetTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+func TestPasswordReset(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newServices := func(sender mail.Sender) (*services.PasswordResetService, *services.AuthService) {
+		userRepo := repositories.NewUserRepository()
+		sessionRepo := repositories.NewSessionRepository(testDB)
+		resetService := services.NewPasswordResetService(
+			userRepo,
+			repositories.NewPasswordResetRepository(testDB),
+			sessionRepo,
+			services.NewAuditLogService(repositories.NewAuditLogRepository()),
+			sender,
+			"http://localhost:3000/reset-password",
+		)
+		authService := newTestAuthService(services.LoginThrottleSettings{
+			DelayAfter:         10,
+			BaseDelay:          time.Second,
+			MaxAccountFailures: 10,
+			MaxIPFailures:      50,
+			LockoutDuration:    15 * time.Minute,
+		})
+		return resetService, authService
+	}
+
+	createResetUser := func(email, contactEmail string) *models.User {
+		hash, err := utils.HashPassword("Ancien123!")
+		assert.NoError(t, err)
+		user := &models.User{
+			Email:        email,
+			ContactEmail: contactEmail,
+			FirstName:    "Test",
+			LastName:     "Reset",
+			Password:     hash,
+			Role:         models.RoleProfesseur,
+		}
+		testDB.Create(user)
+		return user
+	}
+
+	sentToken := func(t *testing.T, sender *recordingSender) string {
+		if !assert.NotEmpty(t, sender.messages) {
+			return ""
+		}
+		match := resetTokenPattern.FindStringSubmatch(sender.messages[len(sender.messages)-1].Body)
+		if !assert.Len(t, match, 2) {
+			return ""
+		}
+		return match[1]
+	}
+
+	t.Run("RequestReset_SendsToContactEmail", func(t *testing.T) {
+		cleanupTestDatabase()
+		sender := &recordingSender{}
+		service, _ := newServices(sender)
+		user := createResetUser("prof@eduqr.com", "prof.perso@example.com")
+
+		assert.NoError(t, service.RequestReset(user.Email))
+		service.Wait()
+		assert.Len(t, sender.messages, 1)
+		assert.Equal(t, "prof.perso@example.com", sender.messages[0].To)
+
+		// Seule l'empreinte du jeton est stockée
+		token := sentToken(t, sender)
+		var count int64
+		testDB.Model(&models.PasswordResetToken{}).Where("token_hash = ?", utils.HashToken(token)).Count(&count)
+		assert.Equal(t, int64(1), count)
+		testDB.Model(&models.PasswordResetToken{}).Where("token_hash = ?", token).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("RequestReset_UnknownEmail", func(t *testing.T) {
+		cleanupTestDatabase()
+		sender := &recordingSender{}
+		service, _ := newServices(sender)
+
+		// Aucune erreur pour ne pas révéler l'existence des comptes, et aucun e-mail
+		assert.NoError(t, service.RequestReset("inconnu@eduqr.com"))
+		service.Wait()
+		assert.Empty(t, sender.messages)
+	})
+
+	t.Run("RequestReset_ExternalAccount", func(t *testing.T) {
+		for _, provider := range []string{models.AuthProviderLDAP, models.AuthProviderOIDC} {
+			cleanupTestDatabase()
+			sender := &recordingSender{}
+			service, _ := newServices(sender)
+			user := createResetUser(provider+"@eduqr.com", "")
+			testDB.Model(user).Update("auth_provider", provider)
+
+			// L'utilisateur est informé que son mot de passe est géré ailleurs, sans lien de réinitialisation
+			assert.NoError(t, service.RequestReset(user.Email))
+			service.Wait()
+			assert.Len(t, sender.messages, 1, provider)
+			assert.NotRegexp(t, resetTokenPattern, sender.messages[0].Body)
+			var count int64
+			testDB.Model(&models.PasswordResetToken{}).Where("user_id = ?", user.ID).Count(&count)
+			assert.Equal(t, int64(0), count, provider)
+		}
+	})
+
+	t.Run("ResetPassword_SingleUseAndRevokesSessions", func(t *testing.T) {
+		cleanupTestDatabase()
+		sender := &recordingSender{}
+		service, authService := newServices(sender)
+		user := createResetUser("reset@eduqr.com", "")
+
+		tokens, _, err := authService.Login(&models.LoginRequest{Email: user.Email, Password: "Ancien123!"}, models.SessionClient{})
+		assert.NoError(t, err)
+
+		assert.NoError(t, service.RequestReset(user.Email))
+		service.Wait()
+		assert.Equal(t, user.Email, sender.messages[0].To)
+		token := sentToken(t, sender)
+
+		req := &models.ResetPasswordRequest{Token: token, NewPassword: "Nouveau123!", ConfirmPassword: "Nouveau123!"}
+		assert.NoError(t, service.ResetPassword(req, "127.0.0.1", "test"))
+
+		// Le nouveau mot de passe est actif, l'ancien ne l'est plus
+		_, _, err = authService.Login(&models.LoginRequest{Email: user.Email, Password: "Nouveau123!"}, models.SessionClient{})
+		assert.NoError(t, err)
+		_, _, err = authService.Login(&models.LoginRequest{Email: user.Email, Password: "Ancien123!"}, models.SessionClient{})
+		assert.Error(t, err)
+
+		// La session ouverte avant la réinitialisation est révoquée
+		assert.Error(t, authService.ValidateSession(tokens.SessionID, "127.0.0.1"))
+
+		// Le jeton ne sert qu'une fois
+		assert.Error(t, service.ResetPassword(req, "127.0.0.1", "test"))
+
+		var logs int64
+		testDB.Model(&models.AuditLog{}).Where("action = ? AND resource_id = ?", models.ActionPasswordReset, user.ID).Count(&logs)
+		assert.Equal(t, int64(1), logs)
+	})
+
+	t.Run("ResetPassword_ExpiredOrReplacedToken", func(t *testing.T) {
+		cleanupTestDatabase()
+		sender := &recordingSender{}
+		service, _ := newServices(sender)
+		user := createResetUser("expire@eduqr.com", "")
+
+		assert.NoError(t, service.RequestReset(user.Email))
+		service.Wait()
+		first := sentToken(t, sender)
+		assert.NoError(t, service.RequestReset(user.Email))
+		service.Wait()
+		second := sentToken(t, sender)
+
+		// Une nouvelle demande invalide le lien précédent
+		req := &models.ResetPasswordRequest{Token: first, NewPassword: "Nouveau123!", ConfirmPassword: "Nouveau123!"}
+		assert.Error(t, service.ResetPassword(req, "127.0.0.1", "test"))
+
+		testDB.Model(&models.PasswordResetToken{}).Where("user_id = ?", user.ID).Update("expires_at", time.Now().Add(-time.Minute))
+		req.Token = second
+		assert.Error(t, service.ResetPassword(req, "127.0.0.1", "test"))
+	})
+}
@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"eduqr-backend/internal/controllers"
+	"eduqr-backend/internal/middlewares"
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	roles := []string{models.RoleSuperAdmin, models.RoleAdmin, models.RoleProfesseur, models.RoleEtudiant}
+	allFields := []string{"id", "email", "contact_email", "first_name", "last_name", "phone", "address", "avatar", "role", "created_at", "updated_at"}
+
+	t.Run("DefaultsMatchRoleRules", func(t *testing.T) {
+		cleanupTestDatabase()
+		permissionService := newPermissionService()
+
+		// Règles historiques : le super admin gère tout le monde, l'admin les professeurs et les étudiants
+		canManage := map[string][]string{
+			models.RoleSuperAdmin: roles,
+			models.RoleAdmin:      {models.RoleProfesseur, models.RoleEtudiant},
+		}
+		canView := map[string][]string{
+			models.RoleSuperAdmin: roles,
+			models.RoleAdmin:      {models.RoleProfesseur, models.RoleEtudiant},
+			models.RoleProfesseur: {models.RoleProfesseur, models.RoleEtudiant},
+			models.RoleEtudiant:   {models.RoleEtudiant},
+		}
+		canDelete := map[string][]string{
+			models.RoleSuperAdmin: {models.RoleAdmin, models.RoleProfesseur, models.RoleEtudiant},
+			models.RoleAdmin:      {models.RoleProfesseur, models.RoleEtudiant},
+		}
+
+		for _, actor := range roles {
+			for _, target := range roles {
+				assert.Equal(t, containsRole(canManage[actor], target), permissionService.CanManageRole(actor, target), "manage %s -> %s", actor, target)
+				assert.Equal(t, containsRole(canView[actor], target), permissionService.CanViewRole(actor, target), "view %s -> %s", actor, target)
+				assert.Equal(t, containsRole(canDelete[actor], target), permissionService.CanDeleteRole(actor, target), "delete %s -> %s", actor, target)
+			}
+		}
+
+		assert.Equal(t, allFields, permissionService.GetViewableFields(models.RoleSuperAdmin, models.RoleAdmin))
+		assert.Equal(t, allFields, permissionService.GetViewableFields(models.RoleAdmin, models.RoleEtudiant))
+		assert.Empty(t, permissionService.GetViewableFields(models.RoleAdmin, models.RoleAdmin))
+		assert.Equal(t, []string{"id", "first_name", "last_name", "role", "created_at"}, permissionService.GetViewableFields(models.RoleProfesseur, models.RoleEtudiant))
+		assert.Equal(t, []string{"id", "first_name", "last_name"}, permissionService.GetViewableFields(models.RoleEtudiant, models.RoleEtudiant))
+		assert.Empty(t, permissionService.GetViewableFields(models.RoleEtudiant, models.RoleProfesseur))
+
+		assert.True(t, permissionService.HasPermission(models.RoleAdmin, models.PermAbsenceReviewAny))
+		assert.True(t, permissionService.HasPermission(models.RoleProfesseur, models.PermAbsenceReviewOwn))
+		assert.False(t, permissionService.HasPermission(models.RoleProfesseur, models.PermAbsenceReviewAny))
+		assert.False(t, permissionService.HasPermission(models.RoleEtudiant, models.PermRoomSearchAny))
+		assert.True(t, permissionService.HasPermission(models.RoleSuperAdmin, models.PermPermissionManageAny))
+		assert.False(t, permissionService.HasPermission(models.RoleAdmin, models.PermPermissionManageAny))
+
+		assert.True(t, permissionService.HasPermission(models.RoleAdmin, models.PermCourseCreateAny))
+		assert.True(t, permissionService.HasPermission(models.RoleProfesseur, models.PermCourseCreateAny))
+		assert.False(t, permissionService.HasPermission(models.RoleEtudiant, models.PermCourseCreateAny))
+		assert.True(t, permissionService.HasPermission(models.RoleProfesseur, models.PermCourseDeleteOwn))
+		assert.False(t, permissionService.HasPermission(models.RoleProfesseur, models.PermCourseDeleteAny))
+		assert.True(t, permissionService.HasPermission(models.RoleProfesseur, models.PermAbsenceReadOwn))
+		assert.False(t, permissionService.HasPermission(models.RoleEtudiant, models.PermAbsenceReadOwn))
+		assert.True(t, permissionService.HasPermission(models.RoleAdmin, models.PermAbsenceDeleteAny))
+		assert.True(t, permissionService.HasPermission(models.RoleAdmin, models.PermAvailabilityUpdateAny))
+		assert.True(t, permissionService.HasPermission(models.RoleProfesseur, models.PermAvailabilityUpdateOwn))
+		assert.False(t, permissionService.HasPermission(models.RoleEtudiant, models.PermAvailabilityUpdateOwn))
+	})
+
+	t.Run("UpdateRolePermissions_PersistsOnlyChanges", func(t *testing.T) {
+		cleanupTestDatabase()
+		permissionService := newPermissionService()
+
+		permissions := append([]string{models.PermReportReadAny}, models.DefaultRolePermissions[models.RoleProfesseur]...)
+		permissions = withoutPermission(permissions, models.PermRoomSearchAny)
+		response, err := permissionService.UpdateRolePermissions(models.RoleProfesseur, permissions)
+		assert.NoError(t, err)
+		assert.Contains(t, response.Permissions, models.PermReportReadAny)
+		assert.NotContains(t, response.Permissions, models.PermRoomSearchAny)
+
+		var overrides []models.RolePermissionOverride
+		testDB.Order("permission").Find(&overrides)
+		assert.Len(t, overrides, 2)
+		assert.Equal(t, models.PermReportReadAny, overrides[0].Permission)
+		assert.True(t, overrides[0].Granted)
+		assert.Equal(t, models.PermRoomSearchAny, overrides[1].Permission)
+		assert.False(t, overrides[1].Granted)
+
+		// Une autre instance relit les modifications
+		reloaded := newPermissionService()
+		assert.True(t, reloaded.HasPermission(models.RoleProfesseur, models.PermReportReadAny))
+		assert.False(t, reloaded.HasPermission(models.RoleProfesseur, models.PermRoomSearchAny))
+		assert.True(t, reloaded.HasPermission(models.RoleProfesseur, models.PermCourseUpdateOwn))
+		assert.True(t, reloaded.HasPermission(models.RoleAdmin, models.PermRoomSearchAny))
+
+		// Revenir aux permissions par défaut supprime les modifications
+		_, err = permissionService.UpdateRolePermissions(models.RoleProfesseur, models.DefaultRolePermissions[models.RoleProfesseur])
+		assert.NoError(t, err)
+		var count int64
+		testDB.Model(&models.RolePermissionOverride{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("UpdateRolePermissions_Validation", func(t *testing.T) {
+		cleanupTestDatabase()
+		permissionService := newPermissionService()
+
+		_, err := permissionService.UpdateRolePermissions("directeur", nil)
+		assert.Error(t, err)
+		_, err = permissionService.UpdateRolePermissions(models.RoleAdmin, []string{"course:fly:any"})
+		assert.Error(t, err)
+
+		// Le super admin ne peut pas se retirer la gestion des permissions
+		_, err = permissionService.UpdateRolePermissions(models.RoleSuperAdmin, withoutPermission(models.DefaultRolePermissions[models.RoleSuperAdmin], models.PermPermissionManageAny))
+		assert.Error(t, err)
+		assert.True(t, permissionService.HasPermission(models.RoleSuperAdmin, models.PermPermissionManageAny))
+	})
+
+	t.Run("RequirePermission", func(t *testing.T) {
+		cleanupTestDatabase()
+		permissionService := newPermissionService()
+		authMiddleware := middlewares.NewAuthMiddleware("secret", nil, permissionService)
+
+		request := func(role string) int {
+			engine := gin.New()
+			engine.GET("/reports", func(c *gin.Context) {
+				// Simule AuthMiddleware
+				if role != "" {
+					c.Set("user_role", role)
+				}
+				c.Next()
+			}, authMiddleware.RequirePermission(models.PermReportReadAny), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports", nil))
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, request(models.RoleAdmin))
+		assert.Equal(t, http.StatusOK, request(models.RoleSuperAdmin))
+		assert.Equal(t, http.StatusForbidden, request(models.RoleProfesseur))
+		assert.Equal(t, http.StatusUnauthorized, request(""))
+
+		// Les modifications du super admin s'appliquent immédiatement
+		_, err := permissionService.UpdateRolePermissions(models.RoleProfesseur, append([]string{models.PermReportReadAny}, models.DefaultRolePermissions[models.RoleProfesseur]...))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, request(models.RoleProfesseur))
+	})
+}
+
+func TestPermissionChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("AvailabilityFollowsRegistry", func(t *testing.T) {
+		cleanupTestDatabase()
+		permissionService := newPermissionService()
+		availabilityService := services.NewTeacherAvailabilityService(repositories.NewTeacherAvailabilityRepository(testDB), repositories.NewUserRepository(), permissionService)
+
+		assert.True(t, availabilityService.CanEditAvailability(1, models.RoleProfesseur, 1))
+		assert.True(t, availabilityService.CanEditAvailability(2, models.RoleAdmin, 1))
+
+		// Sans la permission, le professeur ne modifie plus ses propres disponibilités
+		_, err := permissionService.UpdateRolePermissions(models.RoleProfesseur, withoutPermission(models.DefaultRolePermissions[models.RoleProfesseur], models.PermAvailabilityUpdateOwn))
+		assert.NoError(t, err)
+		assert.False(t, availabilityService.CanEditAvailability(1, models.RoleProfesseur, 1))
+	})
+
+	t.Run("CourseUpdateAndDeleteRequirePermission", func(t *testing.T) {
+		cleanupTestDatabase()
+		courseController := controllers.NewCourseController(nil, newPermissionService())
+
+		request := func(method string, handler gin.HandlerFunc) int {
+			engine := gin.New()
+			engine.Handle(method, "/courses/:id", func(c *gin.Context) {
+				// Simule AuthMiddleware
+				c.Set("user_id", uint(1))
+				c.Set("user_role", models.RoleEtudiant)
+				c.Next()
+			}, handler)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(method, "/courses/1", nil))
+			return w.Code
+		}
+
+		// Un étudiant n'a ni la permission sur tous les cours ni sur les siens
+		assert.Equal(t, http.StatusForbidden, request(http.MethodPut, courseController.UpdateCourse))
+		assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, courseController.DeleteCourse))
+	})
+
+	t.Run("CourseStatusChangesAllowSubstitute", func(t *testing.T) {
+		cleanupTestDatabase()
+		courseController := controllers.NewCourseController(newTestCourseService(), newPermissionService())
+
+		teacher := createTestUser(models.RoleProfesseur)
+		course := createTestCourse(teacher.ID, createTestSubject().ID, createTestRoom().ID)
+		substitute := &models.User{Email: "remplacant@eduqr.com", FirstName: "Remplaçant", LastName: "Test", Password: "$2a$10$testpassword", Role: models.RoleProfesseur}
+		testDB.Create(substitute)
+		testDB.Model(course).Update("substitute_teacher_id", substitute.ID)
+
+		request := func(method, suffix string, handler gin.HandlerFunc) int {
+			engine := gin.New()
+			engine.Handle(method, "/courses/:id"+suffix, func(c *gin.Context) {
+				// Simule AuthMiddleware
+				c.Set("user_id", substitute.ID)
+				c.Set("user_role", models.RoleProfesseur)
+				c.Next()
+			}, handler)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(method, fmt.Sprintf("/courses/%d%s", course.ID, suffix), strings.NewReader("{}")))
+			return w.Code
+		}
+
+		// Le remplaçant ne modifie ni ne supprime la série, mais peut valider l'occurrence qu'il assure
+		assert.Equal(t, http.StatusForbidden, request(http.MethodPut, "", courseController.UpdateCourse))
+		assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, "", courseController.DeleteCourse))
+		assert.Equal(t, http.StatusOK, request(http.MethodPost, "/complete", courseController.CompleteCourse))
+	})
+}
+
+func containsRole(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutPermission(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"eduqr-backend/internal/middlewares"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newEngine := func(limit string, userID uint) *gin.Engine {
+		parsed, err := middlewares.ParseRateLimit(limit)
+		assert.NoError(t, err)
+		rateLimit := middlewares.NewRateLimitMiddleware(middlewares.NewMemoryRateLimitStore(), map[string]middlewares.RateLimit{
+			middlewares.RateLimitScan: parsed,
+		})
+
+		engine := gin.New()
+		engine.POST("/scan", func(c *gin.Context) {
+			// Simule AuthMiddleware
+			if userID != 0 {
+				c.Set("user_id", userID)
+			}
+			c.Next()
+		}, rateLimit.Limit(middlewares.RateLimitScan), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return engine
+	}
+
+	request := func(engine *gin.Engine, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/scan", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ParseRateLimit", func(t *testing.T) {
+		limit, err := middlewares.ParseRateLimit("20/m")
+		assert.NoError(t, err)
+		assert.Equal(t, middlewares.RateLimit{Requests: 20, Per: time.Minute}, limit)
+
+		limit, err = middlewares.ParseRateLimit("0")
+		assert.NoError(t, err)
+		assert.True(t, limit.IsZero())
+
+		_, err = middlewares.ParseRateLimit("20/j")
+		assert.Error(t, err)
+		_, err = middlewares.ParseRateLimit("vingt/m")
+		assert.Error(t, err)
+	})
+
+	t.Run("BurstThen429WithRetryAfter", func(t *testing.T) {
+		engine := newEngine("2/m", 0)
+
+		assert.Equal(t, http.StatusOK, request(engine, "10.0.0.1").Code)
+		assert.Equal(t, http.StatusOK, request(engine, "10.0.0.1").Code)
+
+		w := request(engine, "10.0.0.1")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		// Un jeton revient toutes les 30 secondes
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+		// Une autre adresse IP a son propre seau
+		assert.Equal(t, http.StatusOK, request(engine, "10.0.0.2").Code)
+	})
+
+	t.Run("SpoofedForwardedForIgnored", func(t *testing.T) {
+		spoofed := func(engine *gin.Engine, ip, forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodPost, "/scan", nil)
+			req.RemoteAddr = ip + ":1234"
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		// Sans proxy de confiance, changer X-Forwarded-For ne donne pas un nouveau seau
+		engine := newEngine("1/m", 0)
+		assert.NoError(t, engine.SetTrustedProxies(nil))
+		assert.Equal(t, http.StatusOK, spoofed(engine, "10.0.0.1", "203.0.113.1"))
+		assert.Equal(t, http.StatusTooManyRequests, spoofed(engine, "10.0.0.1", "203.0.113.2"))
+
+		// Derrière un proxy de confiance, chaque client transmis a son propre seau
+		engine = newEngine("1/m", 0)
+		assert.NoError(t, engine.SetTrustedProxies([]string{"10.0.0.1"}))
+		assert.Equal(t, http.StatusOK, spoofed(engine, "10.0.0.1", "203.0.113.1"))
+		assert.Equal(t, http.StatusOK, spoofed(engine, "10.0.0.1", "203.0.113.2"))
+		assert.Equal(t, http.StatusTooManyRequests, spoofed(engine, "10.0.0.1", "203.0.113.1"))
+
+		// Un client qui n'est pas un proxy de confiance ne peut pas se faire passer pour un autre
+		assert.Equal(t, http.StatusOK, spoofed(engine, "10.0.0.9", "203.0.113.3"))
+		assert.Equal(t, http.StatusTooManyRequests, spoofed(engine, "10.0.0.9", "203.0.113.4"))
+	})
+
+	t.Run("KeyedByUserWhenAuthenticated", func(t *testing.T) {
+		engine := newEngine("1/m", 42)
+
+		assert.Equal(t, http.StatusOK, request(engine, "10.0.0.1").Code)
+		// Même utilisateur depuis une autre adresse : même seau
+		assert.Equal(t, http.StatusTooManyRequests, request(engine, "10.0.0.2").Code)
+	})
+
+	t.Run("DisabledLimit", func(t *testing.T) {
+		engine := newEngine("0", 0)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, request(engine, "10.0.0.1").Code)
+		}
+	})
+
+	t.Run("MemoryStoreRefills", func(t *testing.T) {
+		store := middlewares.NewMemoryRateLimitStore()
+		limit := middlewares.RateLimit{Requests: 1, Per: time.Second}
+		now := time.Now()
+
+		allowed, _ := store.Take("cle", limit, now)
+		assert.True(t, allowed)
+		allowed, retryAfter := store.Take("cle", limit, now)
+		assert.False(t, allowed)
+		assert.Equal(t, time.Second, retryAfter)
+
+		allowed, _ = store.Take("cle", limit, now.Add(time.Second))
+		assert.True(t, allowed)
+	})
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomBookingService(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newBookingService := func() *services.RoomBookingService {
+		groupRepo := repositories.NewGroupRepository(testDB)
+		return services.NewRoomBookingService(
+			repositories.NewRoomBookingRepository(testDB),
+			repositories.NewRoomRepository(testDB),
+			repositories.NewCourseRepository(testDB),
+			services.NewNotificationService(repositories.NewNotificationRepository(testDB), groupRepo),
+			newPermissionService(),
+		)
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	t.Run("ApprovedBooking_ConflictsWithCourses", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newBookingService()
+		courseRepo := repositories.NewCourseRepository(testDB)
+
+		student := createTestUser(models.RoleEtudiant)
+		admin := createTestUser(models.RoleAdmin)
+		room := createTestRoom()
+
+		booking, err := service.CreateBooking(&models.CreateRoomBookingRequest{
+			RoomID:    room.ID,
+			Title:     "Réunion du BDE",
+			Purpose:   models.RoomBookingPurposeClub,
+			StartTime: tomorrow,
+			EndTime:   tomorrow.Add(2 * time.Hour),
+		}, student.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoomBookingStatusPending, booking.Status)
+
+		candidate := &models.Course{RoomID: room.ID, StartTime: tomorrow.Add(time.Hour), EndTime: tomorrow.Add(3 * time.Hour)}
+
+		// Une demande en attente ne bloque pas la salle
+		conflicts, err := courseRepo.CheckConflicts(candidate)
+		assert.NoError(t, err)
+		assert.Empty(t, conflicts)
+
+		booking, err = service.ReviewBooking(booking.ID, &models.ReviewRoomBookingRequest{Status: models.RoomBookingStatusApproved}, admin.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoomBookingStatusApproved, booking.Status)
+
+		conflicts, err = courseRepo.CheckConflicts(candidate)
+		assert.NoError(t, err)
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, models.ConflictTypeRoomBooking, conflicts[0].Type)
+		assert.Equal(t, booking.ID, conflicts[0].BookingID)
+
+		// Le demandeur est notifié de la décision
+		var count int64
+		testDB.Model(&models.Notification{}).Where("user_id = ? AND type = ?", student.ID, models.NotificationRoomBooking).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("ReviewBooking_RefusesOverlapWithApprovedBooking", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newBookingService()
+
+		student := createTestUser(models.RoleEtudiant)
+		admin := createTestUser(models.RoleAdmin)
+		room := createTestRoom()
+
+		req := &models.CreateRoomBookingRequest{RoomID: room.ID, Title: "Examen", StartTime: tomorrow, EndTime: tomorrow.Add(time.Hour)}
+		first, err := service.CreateBooking(req, student.ID)
+		assert.NoError(t, err)
+		second, err := service.CreateBooking(req, admin.ID)
+		assert.NoError(t, err)
+
+		_, err = service.ReviewBooking(first.ID, &models.ReviewRoomBookingRequest{Status: models.RoomBookingStatusApproved}, admin.ID)
+		assert.NoError(t, err)
+
+		_, err = service.ReviewBooking(second.ID, &models.ReviewRoomBookingRequest{Status: models.RoomBookingStatusApproved}, admin.ID)
+		assert.Error(t, err)
+
+		// Une nouvelle demande sur le même créneau est refusée d'emblée
+		_, err = service.CreateBooking(req, student.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("CreateBooking_RefusesSlotTakenByCourse", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newBookingService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+		testDB.Model(course).Updates(map[string]interface{}{"start_time": tomorrow, "end_time": tomorrow.Add(2 * time.Hour)})
+
+		_, err := service.CreateBooking(&models.CreateRoomBookingRequest{
+			RoomID:    room.ID,
+			Title:     "Réunion pédagogique",
+			StartTime: tomorrow.Add(time.Hour),
+			EndTime:   tomorrow.Add(3 * time.Hour),
+		}, teacher.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("CanAccessBooking_RequesterOrAdmin", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newBookingService()
+
+		student := createTestUser(models.RoleEtudiant)
+		teacher := createTestUser(models.RoleProfesseur)
+		admin := createTestUser(models.RoleAdmin)
+		room := createTestRoom()
+
+		booking, err := service.CreateBooking(&models.CreateRoomBookingRequest{RoomID: room.ID, Title: "Club photo", StartTime: tomorrow, EndTime: tomorrow.Add(time.Hour)}, student.ID)
+		assert.NoError(t, err)
+
+		canAccess, err := service.CanAccessBooking(booking.ID, student.ID, models.RoleEtudiant)
+		assert.NoError(t, err)
+		assert.True(t, canAccess)
+
+		canAccess, err = service.CanAccessBooking(booking.ID, teacher.ID, models.RoleProfesseur)
+		assert.NoError(t, err)
+		assert.False(t, canAccess)
+
+		canAccess, err = service.CanAccessBooking(booking.ID, admin.ID, models.RoleAdmin)
+		assert.NoError(t, err)
+		assert.True(t, canAccess)
+
+		cancelled, err := service.CancelBooking(booking.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoomBookingStatusCancelled, cancelled.Status)
+	})
+}
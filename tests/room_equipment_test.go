@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomEquipment(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	t.Run("GetAllRooms_FiltersByCapacityAndEquipment", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := services.NewRoomService(repositories.NewRoomRepository(testDB))
+
+		_, err := service.CreateRoom(&models.CreateRoomRequest{Name: "Amphi A", Capacity: 200, Equipment: []string{"Projector", "accessibility"}})
+		assert.NoError(t, err)
+		_, err = service.CreateRoom(&models.CreateRoomRequest{Name: "Salle info", Capacity: 30, Equipment: []string{"computers", "projector"}})
+		assert.NoError(t, err)
+		_, err = service.CreateRoom(&models.CreateRoomRequest{Name: "Salle 101", Capacity: 40})
+		assert.NoError(t, err)
+
+		minCapacity := 35
+		rooms, err := service.GetAllRooms(&models.RoomFilter{MinCapacity: &minCapacity})
+		assert.NoError(t, err)
+		assert.Len(t, rooms, 2)
+
+		rooms, err = service.GetAllRooms(&models.RoomFilter{Equipment: "projector"})
+		assert.NoError(t, err)
+		assert.Len(t, rooms, 2)
+
+		rooms, err = service.GetAllRooms(&models.RoomFilter{Equipment: "projector, computers"})
+		assert.NoError(t, err)
+		assert.Len(t, rooms, 1)
+		assert.Equal(t, "Salle info", rooms[0].Name)
+		assert.Equal(t, []string{"computers", "projector"}, rooms[0].Equipment)
+	})
+
+	t.Run("UpdateRoom_NilEquipmentKeepsTags", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := services.NewRoomService(repositories.NewRoomRepository(testDB))
+
+		room, err := service.CreateRoom(&models.CreateRoomRequest{Name: "Labo", Capacity: 20, Equipment: []string{"lab benches"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{models.EquipmentLabBenches}, room.Equipment)
+
+		room, err = service.UpdateRoom(room.ID, &models.UpdateRoomRequest{Name: "Labo", Capacity: 24})
+		assert.NoError(t, err)
+		assert.Equal(t, 24, room.Capacity)
+		assert.Equal(t, []string{models.EquipmentLabBenches}, room.Equipment)
+
+		room, err = service.UpdateRoom(room.ID, &models.UpdateRoomRequest{Name: "Labo", Capacity: 24, Equipment: []string{}})
+		assert.NoError(t, err)
+		assert.Empty(t, room.Equipment)
+	})
+
+	t.Run("CreateCourse_WarnsOnCapacityAndMissingEquipment", func(t *testing.T) {
+		cleanupTestDatabase()
+		roomService := services.NewRoomService(repositories.NewRoomRepository(testDB))
+		subjectService := services.NewSubjectService(repositories.NewSubjectRepository(), repositories.NewUserRepository())
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject, err := subjectService.CreateSubject(&models.CreateSubjectRequest{Name: "Chimie", RequiredEquipment: []string{"lab_benches"}})
+		assert.NoError(t, err)
+		room, err := roomService.CreateRoom(&models.CreateRoomRequest{Name: "Petite salle", Capacity: 1})
+		assert.NoError(t, err)
+
+		group := &models.Group{Name: "L1"}
+		testDB.Create(group)
+		for _, email := range []string{"etudiant-1@eduqr.com", "etudiant-2@eduqr.com"} {
+			student := &models.User{Email: email, FirstName: "Etudiant", LastName: "Test", Password: "x", Role: models.RoleEtudiant}
+			testDB.Create(student)
+			addTestGroupMember(group.ID, student.ID)
+		}
+
+		response, err := newTestCourseService().CreateCourse(&models.CreateCourseRequest{
+			Name:      "TP Chimie",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC),
+			Duration:  120,
+			GroupIDs:  []uint{group.ID},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, response.Warnings, 2)
+		assert.True(t, strings.Contains(strings.Join(response.Warnings, "\n"), "lab_benches"))
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomOccupancyReport(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newReportService := func() *services.ReportService {
+		return services.NewReportService(
+			repositories.NewCourseRepository(testDB),
+			repositories.NewUserRepository(),
+			repositories.NewRoomRepository(testDB),
+			repositories.NewPresenceRepository(testDB),
+		)
+	}
+
+	// Lundi 1er janvier 2024, fin de journée incluse comme pour parseReportPeriod
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endOfDay := day.Add(24*time.Hour - time.Second)
+
+	// prepareRooms crée « Test Room » (20 places, cours de 10h à 12h avec 2 présents sur 3)
+	// et la salle modulable « Amphi » du même étage, occupée entière de 14h à 16h puis « Amphi A » de 15h à 17h
+	prepareRooms := func() map[string]uint {
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+
+		room := createTestRoom()
+		testDB.Model(room).Update("capacity", 20)
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+		for i, status := range []string{models.StatusPresent, models.StatusLate, models.StatusAbsent} {
+			student := &models.User{Email: "student" + string(rune('a'+i)) + "@eduqr.com", Password: "x", FirstName: "S", LastName: "T", Role: models.RoleEtudiant}
+			testDB.Create(student)
+			testDB.Create(&models.Presence{StudentID: student.ID, CourseID: course.ID, Status: status})
+		}
+
+		amphi, err := services.NewRoomService(repositories.NewRoomRepository(testDB)).CreateRoom(&models.CreateRoomRequest{
+			Name:          "Amphi",
+			Building:      room.Building,
+			Floor:         room.Floor,
+			IsModular:     true,
+			SubRoomsCount: 2,
+		})
+		assert.NoError(t, err)
+		amphiA, err := repositories.NewRoomRepository(testDB).GetRoomByName("Amphi A")
+		assert.NoError(t, err)
+
+		for _, slot := range []struct {
+			roomID uint
+			start  int
+		}{{amphi.ID, 14}, {amphiA.ID, 15}} {
+			testDB.Create(&models.Course{
+				Name:      "Cours Amphi",
+				TeacherID: teacher.ID,
+				SubjectID: subject.ID,
+				RoomID:    slot.roomID,
+				StartTime: day.Add(time.Duration(slot.start) * time.Hour),
+				EndTime:   day.Add(time.Duration(slot.start+2) * time.Hour),
+				Duration:  120,
+			})
+		}
+
+		return map[string]uint{"Test Room": room.ID, "Amphi": amphi.ID, "Amphi A": amphiA.ID}
+	}
+
+	findRoom := func(report *models.RoomOccupancyReport, id uint) models.RoomOccupancy {
+		for _, room := range report.Rooms {
+			if room.RoomID == id {
+				return room
+			}
+		}
+		t.Fatalf("salle %d absente du rapport", id)
+		return models.RoomOccupancy{}
+	}
+
+	t.Run("Occupancy_DoesNotDoubleCountModularRooms", func(t *testing.T) {
+		cleanupTestDatabase()
+		rooms := prepareRooms()
+
+		report, err := newReportService().GetRoomOccupancy(day, endOfDay, &models.RoomOccupancyFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, report.Rooms, 4)
+
+		simple := findRoom(report, rooms["Test Room"])
+		assert.Equal(t, 12.0, simple.AvailableHours)
+		assert.Equal(t, 2.0, simple.BookedHours)
+		assert.Equal(t, 1, simple.AttendedCourses)
+		if assert.NotNil(t, simple.AverageFillRate) {
+			assert.Equal(t, 10.0, *simple.AverageFillRate)
+		}
+
+		// Amphi A : 14h-17h malgré le chevauchement ; la salle modulable cumule ses deux sous-salles
+		assert.Equal(t, 3.0, findRoom(report, rooms["Amphi A"]).BookedHours)
+		amphi := findRoom(report, rooms["Amphi"])
+		assert.Equal(t, 24.0, amphi.AvailableHours)
+		assert.Equal(t, 5.0, amphi.BookedHours)
+		assert.Equal(t, 2, amphi.CourseCount)
+
+		// Seules les salles simples et les sous-salles entrent dans les cumuls
+		assert.Equal(t, 3, report.Total.RoomCount)
+		assert.Equal(t, 36.0, report.Total.AvailableHours)
+		assert.Equal(t, 7.0, report.Total.BookedHours)
+		if assert.Len(t, report.Buildings, 1) {
+			assert.Equal(t, report.Total.BookedHours, report.Buildings[0].BookedHours)
+		}
+	})
+
+	t.Run("Occupancy_CSVExport", func(t *testing.T) {
+		cleanupTestDatabase()
+		prepareRooms()
+		service := newReportService()
+
+		report, err := service.GetRoomOccupancy(day, endOfDay, &models.RoomOccupancyFilter{})
+		assert.NoError(t, err)
+
+		content, err := service.RoomOccupancyCSV(report)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		assert.True(t, strings.HasPrefix(lines[0], "level,building,floor,room_id"))
+		// En-tête, 4 salles, 1 étage, 1 bâtiment et le total
+		assert.Len(t, lines, 8)
+		assert.True(t, strings.HasPrefix(lines[len(lines)-1], "total,"))
+	})
+
+	t.Run("Occupancy_RejectsInvalidOpeningHours", func(t *testing.T) {
+		opening, closing := 18, 9
+		_, err := newReportService().GetRoomOccupancy(day, endOfDay, &models.RoomOccupancyFilter{OpeningHour: &opening, ClosingHour: &closing})
+		assert.Error(t, err)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"eduqr-backend/config"
 	"eduqr-backend/internal/database"
 	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
 	"log"
 	"os"
 	"testing"
@@ -59,11 +61,36 @@ func setupTestDatabase() error {
 	// Supprimer toutes les tables existantes
 	tables := []string{
 		"audit_logs",
+		"calendar_feed_tokens",
+		"room_booking_merged_rooms",
+		"room_bookings",
+		"events",
 		"presences",
 		"absences",
+		"notifications",
+		"course_status_histories",
+		"course_merged_rooms",
+		"course_groups",
+		"teacher_availabilities",
+		"teacher_unavailabilities",
+		"subject_quotas",
+		"group_memberships",
 		"courses",
+		"groups",
+		"subject_teachers",
+		"subject_equipment",
+		"room_equipment",
 		"subjects",
 		"rooms",
+		"equipment",
+		"two_factor_recovery_codes",
+		"oidc_login_states",
+		"role_permission_overrides",
+		"two_factors",
+		"login_throttles",
+		"password_reset_tokens",
+		"refresh_tokens",
+		"sessions",
 		"users",
 	}
 
@@ -76,9 +103,28 @@ func setupTestDatabase() error {
 	// Auto-migrer les modèles
 	models := []interface{}{
 		&models.User{},
+		&models.Equipment{},
 		&models.Room{},
 		&models.Subject{},
+		&models.Group{},
+		&models.GroupMembership{},
+		&models.SubjectQuota{},
+		&models.TeacherAvailability{},
+		&models.TeacherUnavailability{},
 		&models.Course{},
+		&models.CourseStatusHistory{},
+		&models.Notification{},
+		&models.CalendarFeedToken{},
+		&models.RoomBooking{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.LoginThrottle{},
+		&models.TwoFactor{},
+		&models.TwoFactorRecoveryCode{},
+		&models.OIDCLoginState{},
+		&models.RolePermissionOverride{},
+		&models.Event{},
 		&models.Absence{},
 		&models.Presence{},
 		&models.AuditLog{},
@@ -97,11 +143,36 @@ func setupTestDatabase() error {
 func cleanupTestDatabase() error {
 	tables := []string{
 		"audit_logs",
+		"calendar_feed_tokens",
+		"room_booking_merged_rooms",
+		"room_bookings",
+		"events",
 		"presences",
 		"absences",
+		"notifications",
+		"course_status_histories",
+		"course_merged_rooms",
+		"course_groups",
+		"teacher_availabilities",
+		"teacher_unavailabilities",
+		"subject_quotas",
+		"group_memberships",
 		"courses",
+		"groups",
+		"subject_teachers",
+		"subject_equipment",
+		"room_equipment",
 		"subjects",
 		"rooms",
+		"equipment",
+		"two_factor_recovery_codes",
+		"oidc_login_states",
+		"role_permission_overrides",
+		"two_factors",
+		"login_throttles",
+		"password_reset_tokens",
+		"refresh_tokens",
+		"sessions",
 		"users",
 	}
 
@@ -175,3 +246,38 @@ func createTestCourse(teacherID, subjectID, roomID uint) *models.Course {
 	testDB.Create(course)
 	return course
 }
+
+// addTestGroupMember inscrit un étudiant dans un groupe depuis le 1er janvier 2000
+func addTestGroupMember(groupID, userID uint) *models.GroupMembership {
+	membership := &models.GroupMembership{
+		GroupID:   groupID,
+		UserID:    userID,
+		StartDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	testDB.Create(membership)
+	return membership
+}
+
+// newTestCourseService crée le service des cours avec les dépôts de la base de test
+func newTestCourseService() *services.CourseService {
+	groupRepo := repositories.NewGroupRepository(testDB)
+	return services.NewCourseService(
+		repositories.NewCourseRepository(testDB),
+		repositories.NewSubjectRepository(),
+		repositories.NewUserRepository(),
+		repositories.NewRoomRepository(testDB),
+		groupRepo,
+		repositories.NewTeacherAvailabilityRepository(testDB),
+		services.NewNotificationService(repositories.NewNotificationRepository(testDB), groupRepo),
+	)
+}
+
+// newPermissionService crée le registre des permissions avec les modifications enregistrées en base
+func newPermissionService() *services.PermissionService {
+	permissionService := services.NewPermissionService(repositories.NewPermissionRepository(testDB))
+	if err := permissionService.Load(); err != nil {
+		log.Fatalf("Failed to load role permissions: %v", err)
+	}
+	return permissionService
+}
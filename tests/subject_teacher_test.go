@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubjectTeachers(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newSubjectService := func() *services.SubjectService {
+		return services.NewSubjectService(repositories.NewSubjectRepository(), repositories.NewUserRepository())
+	}
+
+	createTeacher := func(email string) *models.User {
+		teacher := &models.User{
+			Email:     email,
+			FirstName: "Prof",
+			LastName:  "Test",
+			Password:  "$2a$10$testpassword",
+			Role:      models.RoleProfesseur,
+		}
+		testDB.Create(teacher)
+		return teacher
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	t.Run("CreateCourse_RequiresQualifiedTeacher", func(t *testing.T) {
+		cleanupTestDatabase()
+		courseService := newTestCourseService()
+		subjectService := newSubjectService()
+
+		qualified := createTeacher("qualifie@eduqr.com")
+		other := createTeacher("autre@eduqr.com")
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		req := &models.CreateCourseRequest{
+			Name:      "Cours",
+			SubjectID: subject.ID,
+			TeacherID: other.ID,
+			RoomID:    room.ID,
+			StartTime: tomorrow,
+			Duration:  60,
+		}
+
+		// Sans enseignant qualifié, la matière reste ouverte à tous
+		_, err := courseService.CreateCourse(req)
+		assert.NoError(t, err)
+
+		assert.NoError(t, subjectService.AddTeacher(subject.ID, qualified.ID))
+		req.StartTime = tomorrow.Add(3 * time.Hour)
+
+		_, err = courseService.CreateCourse(req)
+		assert.Error(t, err)
+
+		req.TeacherID = qualified.ID
+		course, err := courseService.CreateCourse(req)
+		assert.NoError(t, err)
+
+		// Le remplaçant doit lui aussi être qualifié
+		_, err = courseService.AssignSubstitute(course.ID, &models.AssignSubstituteRequest{TeacherID: other.ID})
+		assert.Error(t, err)
+
+		assert.NoError(t, subjectService.AddTeacher(subject.ID, other.ID))
+		_, err = courseService.AssignSubstitute(course.ID, &models.AssignSubstituteRequest{TeacherID: other.ID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("SetHeadTeacher_QualifiesAndListsSubjects", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newSubjectService()
+
+		head := createTeacher("responsable@eduqr.com")
+		subject := createTestSubject()
+
+		response, err := service.SetHeadTeacher(subject.ID, &head.ID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, response.HeadTeacher) {
+			assert.Equal(t, head.ID, response.HeadTeacher.ID)
+		}
+
+		teachers, err := service.GetSubjectTeachers(subject.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, teachers, 1) {
+			assert.True(t, teachers[0].IsHead)
+		}
+
+		subjects, err := service.GetTeacherSubjects(head.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, subjects, 1) {
+			assert.Equal(t, subject.ID, subjects[0].Subject.ID)
+			assert.True(t, subjects[0].IsHead)
+		}
+
+		// Retirer la qualification retire aussi la responsabilité
+		assert.NoError(t, service.RemoveTeacher(subject.ID, head.ID))
+		updated, err := service.GetSubjectByID(subject.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, updated.HeadTeacherID)
+
+		// Un étudiant ne peut pas être qualifié
+		student := createTestUser(models.RoleEtudiant)
+		assert.Error(t, service.AddTeacher(subject.ID, student.ID))
+	})
+
+	t.Run("DeleteSubject_DetachesTeachers", func(t *testing.T) {
+		cleanupTestDatabase()
+		subjectService := newSubjectService()
+		deletionService := services.NewDeletionService(
+			repositories.NewUserRepository(),
+			repositories.NewCourseRepository(testDB),
+			repositories.NewRoomRepository(testDB),
+			repositories.NewSubjectRepository(),
+			newPermissionService(),
+		)
+
+		teacher := createTeacher("qualifie@eduqr.com")
+		subject := createTestSubject()
+		_, err := subjectService.SetHeadTeacher(subject.ID, &teacher.ID)
+		assert.NoError(t, err)
+
+		response, err := deletionService.DeleteSubject(subject.ID)
+		assert.NoError(t, err)
+		assert.True(t, response.Success)
+		assert.Len(t, response.Warnings, 2)
+
+		subjects, err := subjectService.GetTeacherSubjects(teacher.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, subjects)
+	})
+}
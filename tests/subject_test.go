@@ -130,8 +130,6 @@ func TestSubjectRepository(t *testing.T) {
 		assert.Len(t, subjects, 3)
 	})
 
-
-
 	t.Run("UpdateSubject_Success", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
 
@@ -216,7 +214,7 @@ func TestSubjectService(t *testing.T) {
 
 	t.Run("CreateSubject_Success", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
-		service := services.NewSubjectService(repo)
+		service := services.NewSubjectService(repo, repositories.NewUserRepository())
 
 		req := &models.CreateSubjectRequest{
 			Name:        "Service Test Subject",
@@ -234,7 +232,7 @@ func TestSubjectService(t *testing.T) {
 
 	t.Run("CreateSubject_EmptyName_ShouldFail", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
-		service := services.NewSubjectService(repo)
+		service := services.NewSubjectService(repo, repositories.NewUserRepository())
 
 		req := &models.CreateSubjectRequest{
 			Name:        "",
@@ -248,7 +246,7 @@ func TestSubjectService(t *testing.T) {
 
 	t.Run("CreateSubject_EmptyCode_ShouldFail", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
-		service := services.NewSubjectService(repo)
+		service := services.NewSubjectService(repo, repositories.NewUserRepository())
 
 		req := &models.CreateSubjectRequest{
 			Name:        "Valid Name",
@@ -262,7 +260,7 @@ func TestSubjectService(t *testing.T) {
 
 	t.Run("GetSubjectByID_Success", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
-		service := services.NewSubjectService(repo)
+		service := services.NewSubjectService(repo, repositories.NewUserRepository())
 
 		// Créer une matière
 		subject := createTestSubject()
@@ -277,7 +275,7 @@ func TestSubjectService(t *testing.T) {
 
 	t.Run("UpdateSubject_Success", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
-		service := services.NewSubjectService(repo)
+		service := services.NewSubjectService(repo, repositories.NewUserRepository())
 
 		// Créer une matière
 		subject := createTestSubject()
@@ -297,7 +295,7 @@ func TestSubjectService(t *testing.T) {
 
 	t.Run("GetAllSubjects_Success", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
-		service := services.NewSubjectService(repo)
+		service := services.NewSubjectService(repo, repositories.NewUserRepository())
 
 		// Créer plusieurs matières
 		createTestSubject()
@@ -312,7 +310,7 @@ func TestSubjectService(t *testing.T) {
 
 	t.Run("DeleteSubject_Success", func(t *testing.T) {
 		repo := repositories.NewSubjectRepository()
-		service := services.NewSubjectService(repo)
+		service := services.NewSubjectService(repo, repositories.NewUserRepository())
 
 		// Créer une matière
 		subject := createTestSubject()
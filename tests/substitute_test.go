@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstituteTeacher(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	// createSubstitute crée un second professeur (l'email de createTestUser est unique par rôle)
+	createSubstitute := func() *models.User {
+		substitute := &models.User{
+			Email:     "remplacant@eduqr.com",
+			FirstName: "Remplaçant",
+			LastName:  "Test",
+			Password:  "$2a$10$testpassword",
+			Role:      models.RoleProfesseur,
+		}
+		testDB.Create(substitute)
+		return substitute
+	}
+
+	t.Run("AssignSubstitute_GrantsQRCodeRights", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+		presenceService := services.NewPresenceService(repositories.NewPresenceRepository(testDB), repositories.NewCourseRepository(testDB), repositories.NewUserRepository(), newPermissionService())
+
+		teacher := createTestUser(models.RoleProfesseur)
+		substitute := createSubstitute()
+		subject := createTestSubject()
+		room := createTestRoom()
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+
+		canView, err := presenceService.CanViewQRCode(substitute.ID, course.ID)
+		assert.NoError(t, err)
+		assert.False(t, canView)
+
+		response, err := service.AssignSubstitute(course.ID, &models.AssignSubstituteRequest{TeacherID: substitute.ID})
+		assert.NoError(t, err)
+		assert.NotNil(t, response.SubstituteTeacher)
+		assert.Equal(t, substitute.ID, response.SubstituteTeacher.ID)
+		// Le propriétaire de la série reste inchangé
+		assert.Equal(t, teacher.ID, response.Teacher.ID)
+
+		canView, err = presenceService.CanViewQRCode(substitute.ID, course.ID)
+		assert.NoError(t, err)
+		assert.True(t, canView)
+
+		canRegenerate, err := presenceService.CanRegenerateQRCode(substitute.ID, course.ID)
+		assert.NoError(t, err)
+		assert.True(t, canRegenerate)
+
+		// Le retrait du remplaçant supprime ses droits
+		_, err = service.RemoveSubstitute(course.ID)
+		assert.NoError(t, err)
+
+		canView, err = presenceService.CanViewQRCode(substitute.ID, course.ID)
+		assert.NoError(t, err)
+		assert.False(t, canView)
+	})
+
+	t.Run("AssignSubstitute_RefusesBusyTeacher", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		substitute := createSubstitute()
+		subject := createTestSubject()
+		room := createTestRoom()
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+
+		// Le remplaçant donne déjà un cours sur le même créneau
+		otherRoom := &models.Room{Name: "Autre salle", Building: "Test Building", Floor: "1st Floor"}
+		testDB.Create(otherRoom)
+		createTestCourse(substitute.ID, subject.ID, otherRoom.ID)
+
+		_, err := service.AssignSubstitute(course.ID, &models.AssignSubstituteRequest{TeacherID: substitute.ID})
+		assert.Error(t, err)
+
+		// Le propriétaire ne peut pas être son propre remplaçant
+		_, err = service.AssignSubstitute(course.ID, &models.AssignSubstituteRequest{TeacherID: teacher.ID})
+		assert.Error(t, err)
+	})
+
+	t.Run("TeacherWorkload_CountsSubstitutions", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+		reportService := services.NewReportService(
+			repositories.NewCourseRepository(testDB),
+			repositories.NewUserRepository(),
+			repositories.NewRoomRepository(testDB),
+			repositories.NewPresenceRepository(testDB),
+		)
+
+		teacher := createTestUser(models.RoleProfesseur)
+		substitute := createSubstitute()
+		subject := createTestSubject()
+		room := createTestRoom()
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+
+		_, err := service.AssignSubstitute(course.ID, &models.AssignSubstituteRequest{TeacherID: substitute.ID})
+		assert.NoError(t, err)
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		workloads, err := reportService.GetTeacherWorkload(start, end, teacher.ID)
+		assert.NoError(t, err)
+		assert.Len(t, workloads, 1)
+		assert.Equal(t, 0, workloads[0].CourseCount)
+		assert.Equal(t, 1, workloads[0].ReplacedCount)
+
+		workloads, err = reportService.GetTeacherWorkload(start, end, substitute.ID)
+		assert.NoError(t, err)
+		assert.Len(t, workloads, 1)
+		assert.Equal(t, 1, workloads[0].SubstituteCount)
+		assert.Equal(t, 120, workloads[0].SubstituteMinutes)
+		assert.Equal(t, 120, workloads[0].TotalMinutes)
+	})
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeacherAvailability(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	availabilityService := services.NewTeacherAvailabilityService(repositories.NewTeacherAvailabilityRepository(testDB), repositories.NewUserRepository(), newPermissionService())
+
+	t.Run("CanEditAvailability_Permissions", func(t *testing.T) {
+		assert.True(t, availabilityService.CanEditAvailability(1, models.RoleProfesseur, 1))
+		assert.False(t, availabilityService.CanEditAvailability(2, models.RoleProfesseur, 1))
+		assert.True(t, availabilityService.CanEditAvailability(2, models.RoleAdmin, 1))
+		assert.False(t, availabilityService.CanEditAvailability(1, models.RoleEtudiant, 1))
+	})
+
+	t.Run("Covers_UsesSchoolTimeZone", func(t *testing.T) {
+		defer func(location *time.Location) { models.SchoolLocation = location }(models.SchoolLocation)
+		models.SchoolLocation = time.FixedZone("UTC+1", 3600)
+
+		availability := &models.TeacherAvailability{DayOfWeek: "Monday", StartTime: "08:00", EndTime: "10:00"}
+
+		// 07:30-08:30 UTC correspond à 08:30-09:30 dans le fuseau de l'établissement
+		assert.True(t, availability.Covers(time.Date(2024, 1, 1, 7, 30, 0, 0, time.UTC), time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)))
+		assert.False(t, availability.Covers(time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC), time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)))
+	})
+
+	t.Run("CreateCourse_RefusedDuringUnavailability", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		_, err := availabilityService.AddUnavailability(teacher.ID, &models.CreateTeacherUnavailabilityRequest{
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			Reason:    "Congé",
+		})
+		assert.NoError(t, err)
+
+		_, err = service.CreateCourse(&models.CreateCourseRequest{
+			Name:      "Cours pendant le congé",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC),
+			Duration:  60,
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "indisponible")
+	})
+
+	t.Run("UpdateCourse_UnavailabilityOnlyCheckedWhenScheduleChanges", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		course, err := service.CreateCourse(&models.CreateCourseRequest{
+			Name:      "Cours planifié avant le congé",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC),
+			Duration:  60,
+		})
+		assert.NoError(t, err)
+
+		_, err = availabilityService.AddUnavailability(teacher.ID, &models.CreateTeacherUnavailabilityRequest{
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			Reason:    "Congé",
+		})
+		assert.NoError(t, err)
+
+		// Changer le titre reste possible
+		_, err = service.UpdateCourse(course.ID, &models.UpdateCourseRequest{Name: "Nouveau titre"})
+		assert.NoError(t, err)
+
+		// Déplacer le cours dans la période d'indisponibilité est refusé
+		_, err = service.UpdateCourse(course.ID, &models.UpdateCourseRequest{StartTime: time.Date(2024, 1, 9, 10, 0, 0, 0, time.UTC)})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "indisponible")
+	})
+
+	t.Run("CreateCourse_WarnsOutsideAvailability", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		// L'enseignant n'enseigne que le mardi
+		_, err := availabilityService.AddAvailability(teacher.ID, &models.CreateTeacherAvailabilityRequest{
+			DayOfWeek: "Tuesday",
+			StartTime: "08:00",
+			EndTime:   "18:00",
+		})
+		assert.NoError(t, err)
+
+		// Lundi : créé mais avec un avertissement
+		response, err := service.CreateCourse(&models.CreateCourseRequest{
+			Name:      "Cours du lundi",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			Duration:  60,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, response.Warnings, 1)
+
+		// Mardi : aucun avertissement
+		response, err = service.CreateCourse(&models.CreateCourseRequest{
+			Name:      "Cours du mardi",
+			SubjectID: subject.ID,
+			TeacherID: teacher.ID,
+			RoomID:    room.ID,
+			StartTime: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+			Duration:  60,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, response.Warnings)
+	})
+
+	t.Run("GenerateRecurringCourses_SkipsUnavailableOccurrences", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestCourseService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+
+		_, err := availabilityService.AddUnavailability(teacher.ID, &models.CreateTeacherUnavailabilityRequest{
+			StartDate: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC),
+		})
+		assert.NoError(t, err)
+
+		pattern := `{"days":["Monday"]}`
+		endDate := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+		response, err := service.CreateCourse(&models.CreateCourseRequest{
+			Name:              "Cours hebdomadaire",
+			SubjectID:         subject.ID,
+			TeacherID:         teacher.ID,
+			RoomID:            room.ID,
+			StartTime:         time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			Duration:          60,
+			IsRecurring:       true,
+			RecurrencePattern: &pattern,
+			RecurrenceEndDate: &endDate,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, response.Warnings, 1)
+
+		// 1er et 15 janvier seulement, le 8 est sauté
+		var count int64
+		testDB.Model(&models.Course{}).Where("teacher_id = ?", teacher.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTimetableService() *services.TimetableService {
+	courseRepo := repositories.NewCourseRepository(testDB)
+	subjectRepo := repositories.NewSubjectRepository()
+	userRepo := repositories.NewUserRepository()
+	roomRepo := repositories.NewRoomRepository(testDB)
+	groupRepo := repositories.NewGroupRepository(testDB)
+	courseService := newTestCourseService()
+
+	return services.NewTimetableService(courseService, courseRepo, subjectRepo, userRepo, roomRepo, groupRepo, repositories.NewTeacherAvailabilityRepository(testDB))
+}
+
+func TestTimetableService(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	t.Run("GenerateTimetable_AvoidsExistingCourses", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestTimetableService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+		group := &models.Group{Name: "Timetable Group"}
+		testDB.Create(group)
+
+		// Lundi 1er janvier 2024, 10h-12h : la salle et l'enseignant sont déjà pris
+		createTestCourse(teacher.ID, subject.ID, room.ID)
+
+		req := &models.GenerateTimetableRequest{
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			Days:      []string{"Monday"},
+			DayStart:  "08:00",
+			DayEnd:    "14:00",
+			Requirements: []models.TimetableRequirementRequest{
+				{SubjectID: subject.ID, GroupID: group.ID, TeacherID: teacher.ID, WeeklyHours: 4, SessionDuration: 120},
+			},
+		}
+
+		proposal, err := service.GenerateTimetable(req)
+		assert.NoError(t, err)
+		assert.Len(t, proposal.Sessions, 2)
+		assert.Equal(t, 8, proposal.Sessions[0].StartTime.Hour())
+		assert.Equal(t, 12, proposal.Sessions[1].StartTime.Hour())
+		assert.Empty(t, proposal.Unsatisfied)
+	})
+
+	t.Run("GenerateTimetable_AvoidsCoursesInLaterWeeks", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestTimetableService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		room := createTestRoom()
+		group := &models.Group{Name: "Timetable Group"}
+		testDB.Create(group)
+
+		// Lundi 15 janvier 2024, 10h-12h : seule la troisième semaine est occupée
+		course := createTestCourse(teacher.ID, subject.ID, room.ID)
+		testDB.Model(course).Updates(map[string]interface{}{
+			"start_time": course.StartTime.AddDate(0, 0, 14),
+			"end_time":   course.EndTime.AddDate(0, 0, 14),
+		})
+
+		req := &models.GenerateTimetableRequest{
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			Days:      []string{"Monday"},
+			DayStart:  "08:00",
+			DayEnd:    "14:00",
+			Requirements: []models.TimetableRequirementRequest{
+				{SubjectID: subject.ID, GroupID: group.ID, TeacherID: teacher.ID, WeeklyHours: 4, SessionDuration: 120},
+			},
+		}
+
+		proposal, err := service.GenerateTimetable(req)
+		assert.NoError(t, err)
+		assert.Len(t, proposal.Sessions, 2)
+		assert.Equal(t, 8, proposal.Sessions[0].StartTime.Hour())
+		assert.Equal(t, 12, proposal.Sessions[1].StartTime.Hour())
+	})
+
+	t.Run("GenerateTimetable_ExplainsUnsatisfied", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestTimetableService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		createTestRoom()
+		group := &models.Group{Name: "Overloaded Group"}
+		testDB.Create(group)
+
+		req := &models.GenerateTimetableRequest{
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			Days:      []string{"Monday"},
+			DayStart:  "08:00",
+			DayEnd:    "10:00",
+			Requirements: []models.TimetableRequirementRequest{
+				{SubjectID: subject.ID, GroupID: group.ID, TeacherID: teacher.ID, WeeklyHours: 4, SessionDuration: 120},
+			},
+		}
+
+		proposal, err := service.GenerateTimetable(req)
+		assert.NoError(t, err)
+		assert.Len(t, proposal.Sessions, 1)
+		assert.Len(t, proposal.Unsatisfied, 1)
+		assert.Equal(t, 120, proposal.Unsatisfied[0].MissingMinutes)
+		assert.NotEmpty(t, proposal.Unsatisfied[0].Reason)
+	})
+
+	t.Run("ApplyTimetable_CreatesRecurringCourses", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newTestTimetableService()
+
+		teacher := createTestUser(models.RoleProfesseur)
+		subject := createTestSubject()
+		createTestRoom()
+		group := &models.Group{Name: "Applied Group"}
+		testDB.Create(group)
+
+		req := &models.GenerateTimetableRequest{
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC),
+			Days:      []string{"Monday", "Tuesday"},
+			Requirements: []models.TimetableRequirementRequest{
+				{SubjectID: subject.ID, GroupID: group.ID, TeacherID: teacher.ID, WeeklyHours: 2, SessionDuration: 120},
+			},
+		}
+
+		proposal, err := service.ApplyTimetable(req)
+		assert.NoError(t, err)
+		assert.Len(t, proposal.Courses, 1)
+		assert.True(t, proposal.Courses[0].IsRecurring)
+		assert.Len(t, proposal.Courses[0].Groups, 1)
+
+		// Lundis 1er, 8 et 15 janvier
+		var count int64
+		testDB.Model(&models.Course{}).Where("subject_id = ?", subject.ID).Count(&count)
+		assert.Equal(t, int64(3), count)
+	})
+}
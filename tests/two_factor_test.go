@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/totp"
+	"eduqr-backend/pkg/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactor(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newServices := func(requiredRoles []string) (*services.AuthService, *services.TwoFactorService) {
+		userRepo := repositories.NewUserRepository()
+		loginThrottle := services.NewLoginThrottleService(
+			repositories.NewLoginThrottleRepository(testDB),
+			userRepo,
+			services.NewAuditLogService(repositories.NewAuditLogRepository()),
+			services.LoginThrottleSettings{DelayAfter: 10, BaseDelay: time.Second, MaxAccountFailures: 10, MaxIPFailures: 50, LockoutDuration: 15 * time.Minute},
+		)
+		twoFactor := services.NewTwoFactorService(repositories.NewTwoFactorRepository(testDB), userRepo, "EduQR", requiredRoles)
+		authService := services.NewAuthService(userRepo, repositories.NewSessionRepository(testDB), loginThrottle, twoFactor, nil, "test-secret", 15*time.Minute, 24*time.Hour)
+		return authService, twoFactor
+	}
+
+	createLoginUser := func(email, role string) *models.User {
+		hash, err := utils.HashPassword("Password123!")
+		assert.NoError(t, err)
+		user := &models.User{
+			Email:     email,
+			FirstName: "Test",
+			LastName:  "TOTP",
+			Password:  hash,
+			Role:      role,
+		}
+		testDB.Create(user)
+		return user
+	}
+
+	// codeAt retourne le code TOTP décalé de offset périodes par rapport à maintenant
+	codeAt := func(secret string, offset int64) string {
+		code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+		assert.NoError(t, err)
+		return code
+	}
+
+	login := func(service *services.AuthService, email string) (*services.TwoFactorRequiredError, error) {
+		_, _, err := service.Login(&models.LoginRequest{Email: email, Password: "Password123!"}, models.SessionClient{IPAddress: "127.0.0.1"})
+		var required *services.TwoFactorRequiredError
+		if errors.As(err, &required) {
+			return required, nil
+		}
+		return nil, err
+	}
+
+	// enroll active la double authentification et retourne le secret et les codes de secours
+	enroll := func(twoFactor *services.TwoFactorService, userID uint) (string, []string) {
+		setup, err := twoFactor.BeginSetup(userID)
+		assert.NoError(t, err)
+		codes, err := twoFactor.Enable(userID, codeAt(setup.Secret, 0))
+		assert.NoError(t, err)
+		return setup.Secret, codes
+	}
+
+	t.Run("Enrolment", func(t *testing.T) {
+		cleanupTestDatabase()
+		_, twoFactor := newServices(nil)
+		user := createLoginUser("enrolement@eduqr.com", models.RoleAdmin)
+
+		setup, err := twoFactor.BeginSetup(user.ID)
+		assert.NoError(t, err)
+		assert.Contains(t, setup.ProvisioningURI, "otpauth://totp/EduQR:enrolement@eduqr.com?")
+
+		// Tant que l'enrôlement n'est pas confirmé, la double authentification reste inactive
+		status, err := twoFactor.GetStatus(user.ID)
+		assert.NoError(t, err)
+		assert.False(t, status.Enabled)
+
+		_, err = twoFactor.Enable(user.ID, "000000")
+		assert.Error(t, err)
+
+		codes, err := twoFactor.Enable(user.ID, codeAt(setup.Secret, 0))
+		assert.NoError(t, err)
+		assert.Len(t, codes, 10)
+
+		status, err = twoFactor.GetStatus(user.ID)
+		assert.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.False(t, status.Required)
+		assert.Equal(t, int64(10), status.RecoveryCodesLeft)
+
+		_, err = twoFactor.BeginSetup(user.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("TwoStepLogin", func(t *testing.T) {
+		cleanupTestDatabase()
+		authService, twoFactor := newServices(nil)
+		user := createLoginUser("deux-etapes@eduqr.com", models.RoleAdmin)
+		secret, _ := enroll(twoFactor, user.ID)
+
+		required, err := login(authService, user.Email)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, required) {
+			return
+		}
+		assert.False(t, required.Setup)
+
+		// Le jeton de seconde étape n'est pas un jeton d'accès
+		_, err = utils.ValidateToken(required.ChallengeToken, "test-secret")
+		assert.Error(t, err)
+
+		client := models.SessionClient{IPAddress: "127.0.0.1"}
+		_, _, err = authService.CompleteTwoFactorLogin(&models.TwoFactorChallengeRequest{ChallengeToken: required.ChallengeToken, Code: "000000"}, client)
+		assert.Error(t, err)
+
+		// Le code de la période d'activation a déjà servi : seul un code plus récent est accepté
+		_, _, err = authService.CompleteTwoFactorLogin(&models.TwoFactorChallengeRequest{ChallengeToken: required.ChallengeToken, Code: codeAt(secret, 0)}, client)
+		assert.Error(t, err)
+
+		tokens, response, err := authService.CompleteTwoFactorLogin(&models.TwoFactorChallengeRequest{ChallengeToken: required.ChallengeToken, Code: codeAt(secret, 1)}, client)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, response.ID)
+		assert.NotEmpty(t, tokens.AccessToken)
+	})
+
+	t.Run("RecoveryCodesAreSingleUse", func(t *testing.T) {
+		cleanupTestDatabase()
+		authService, twoFactor := newServices(nil)
+		user := createLoginUser("secours@eduqr.com", models.RoleProfesseur)
+		_, codes := enroll(twoFactor, user.ID)
+
+		required, err := login(authService, user.Email)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, required) {
+			return
+		}
+
+		client := models.SessionClient{IPAddress: "127.0.0.1"}
+		_, _, err = authService.CompleteTwoFactorLogin(&models.TwoFactorChallengeRequest{ChallengeToken: required.ChallengeToken, Code: codes[0]}, client)
+		assert.NoError(t, err)
+		_, _, err = authService.CompleteTwoFactorLogin(&models.TwoFactorChallengeRequest{ChallengeToken: required.ChallengeToken, Code: codes[0]}, client)
+		assert.Error(t, err)
+
+		status, err := twoFactor.GetStatus(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9), status.RecoveryCodesLeft)
+	})
+
+	t.Run("MandatoryPolicy", func(t *testing.T) {
+		cleanupTestDatabase()
+		authService, twoFactor := newServices([]string{models.RoleAdmin, models.RoleSuperAdmin})
+		admin := createLoginUser("obligatoire@eduqr.com", models.RoleAdmin)
+		student := createLoginUser("facultatif@eduqr.com", models.RoleEtudiant)
+
+		// Les rôles hors politique se connectent avec le seul mot de passe
+		required, err := login(authService, student.Email)
+		assert.NoError(t, err)
+		assert.Nil(t, required)
+
+		// Un administrateur non enrôlé doit s'enrôler pendant la connexion
+		required, err = login(authService, admin.Email)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, required) {
+			return
+		}
+		assert.True(t, required.Setup)
+
+		setup, err := authService.BeginTwoFactorLoginSetup(required.ChallengeToken)
+		assert.NoError(t, err)
+
+		tokens, _, codes, err := authService.ConfirmTwoFactorLoginSetup(
+			&models.TwoFactorChallengeRequest{ChallengeToken: required.ChallengeToken, Code: codeAt(setup.Secret, 0)},
+			models.SessionClient{IPAddress: "127.0.0.1"},
+		)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.Len(t, codes, 10)
+
+		// La politique interdit la désactivation
+		err = twoFactor.Disable(admin.ID, "Password123!", codeAt(setup.Secret, 1))
+		assert.Error(t, err)
+		enabled, err := twoFactor.IsEnabled(admin.ID)
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+	})
+
+	t.Run("DisableAndReset", func(t *testing.T) {
+		cleanupTestDatabase()
+		authService, twoFactor := newServices(nil)
+		user := createLoginUser("desactivation@eduqr.com", models.RoleProfesseur)
+		other := createLoginUser("reinitialisation@eduqr.com", models.RoleProfesseur)
+		secret, _ := enroll(twoFactor, user.ID)
+		enroll(twoFactor, other.ID)
+
+		err := twoFactor.Disable(user.ID, "mauvais", codeAt(secret, 1))
+		assert.Error(t, err)
+		err = twoFactor.Disable(user.ID, "Password123!", codeAt(secret, 1))
+		assert.NoError(t, err)
+
+		required, err := login(authService, user.Email)
+		assert.NoError(t, err)
+		assert.Nil(t, required)
+
+		// Réinitialisation par un administrateur (appareil perdu)
+		assert.NoError(t, twoFactor.Reset(other.ID))
+		required, err = login(authService, other.Email)
+		assert.NoError(t, err)
+		assert.Nil(t, required)
+	})
+}
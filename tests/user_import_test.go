@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"bytes"
+	"eduqr-backend/internal/models"
+	"eduqr-backend/internal/repositories"
+	"eduqr-backend/internal/services"
+	"eduqr-backend/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestUserImport(t *testing.T) {
+	// Nettoyer avant chaque test
+	cleanupTestDatabase()
+
+	newImportService := func() *services.UserImportService {
+		return services.NewUserImportService(repositories.NewUserRepository(), repositories.NewGroupRepository(testDB), newPermissionService())
+	}
+
+	csvContent := "Email;Prénom;Nom;Rôle;Groupe;Téléphone\n" +
+		"alice@eduqr.com;Alice;Martin;etudiant;L1 Info;0601020304\n" +
+		"bob@eduqr.com;Bob;Durand;enseignant;;\n" +
+		"pas-un-email;Chloé;Petit;etudiant;;\n" +
+		"alice@eduqr.com;Alice;Doublon;etudiant;;\n" +
+		"test-etudiant@eduqr.com;Déjà;Inscrit;etudiant;;\n" +
+		"chef@eduqr.com;Chef;Admin;admin;;\n" +
+		"david@eduqr.com;David;Leroy;etudiant;Inconnu;\n"
+
+	t.Run("ImportUsers_DryRunReportsEachRow", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newImportService()
+		createTestUser(models.RoleEtudiant)
+		testDB.Create(&models.Group{Name: "L1 Info"})
+
+		report, err := service.ImportUsers("users.csv", strings.NewReader(csvContent), models.RoleAdmin, &models.UserImportOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 7, report.Total)
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, 5, report.Invalid)
+		assert.Equal(t, 0, report.Created)
+
+		assert.Equal(t, models.UserImportRowValid, report.Rows[0].Status)
+		assert.Equal(t, models.RoleProfesseur, report.Rows[1].Role)
+		assert.Contains(t, report.Rows[2].Errors, "format d'email invalide")
+		assert.Contains(t, report.Rows[3].Errors[0], "ligne 2")
+		assert.Contains(t, report.Rows[4].Errors, "un utilisateur avec cet email existe déjà")
+		assert.Contains(t, report.Rows[5].Errors[0], "permissions insuffisantes")
+		assert.Contains(t, report.Rows[6].Errors[0], "introuvable")
+
+		_, err = repositories.NewUserRepository().FindByEmail("alice@eduqr.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("ImportUsers_CreatesValidRowsWithTemporaryPasswords", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newImportService()
+		createTestUser(models.RoleEtudiant)
+		group := &models.Group{Name: "L1 Info"}
+		testDB.Create(group)
+
+		report, err := service.ImportUsers("users.csv", strings.NewReader(csvContent), models.RoleAdmin, &models.UserImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Created)
+
+		alice := report.Rows[0]
+		assert.Equal(t, models.UserImportRowCreated, alice.Status)
+		assert.NotZero(t, alice.UserID)
+		assert.True(t, models.ValidatePasswordStrength(alice.TemporaryPassword).IsValid)
+
+		user, err := repositories.NewUserRepository().FindByEmail("alice@eduqr.com")
+		assert.NoError(t, err)
+		assert.True(t, utils.CheckPassword(alice.TemporaryPassword, user.Password))
+
+		count, err := repositories.NewGroupRepository(testDB).CountStudents(group.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("ImportUsers_ReadsXLSXWithMapping", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := newImportService()
+
+		file := excelize.NewFile()
+		sheet := file.GetSheetName(0)
+		file.SetSheetRow(sheet, "A1", &[]string{"Adresse", "Nom complet"})
+		file.SetSheetRow(sheet, "A2", &[]string{"emma@eduqr.com", "Emma Bernard"})
+		var buf bytes.Buffer
+		assert.NoError(t, file.Write(&buf))
+
+		report, err := service.ImportUsers("users.xlsx", &buf, models.RoleAdmin, &models.UserImportOptions{
+			DryRun:  true,
+			Mapping: map[string]string{models.UserImportColumnEmail: "Adresse"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Valid)
+		assert.Equal(t, "Emma", report.Rows[0].FirstName)
+		assert.Equal(t, "Bernard", report.Rows[0].LastName)
+		assert.Equal(t, models.RoleEtudiant, report.Rows[0].Role)
+	})
+}
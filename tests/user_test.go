@@ -6,6 +6,7 @@ import (
 	"eduqr-backend/internal/services"
 	"testing"
 
+	"github.com/gin-gonic/gin/binding"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,6 +15,7 @@ func TestUserRepository(t *testing.T) {
 	cleanupTestDatabase()
 
 	t.Run("CreateUser_Success", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		user := &models.User{
@@ -21,7 +23,7 @@ func TestUserRepository(t *testing.T) {
 			FirstName: "Test",
 			LastName:  "User",
 			Password:  "$2a$10$testpassword",
-			Role:      "student",
+			Role:      models.RoleEtudiant,
 			Phone:     "+1234567890",
 			Address:   "Test Address",
 		}
@@ -33,6 +35,7 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	t.Run("CreateUser_DuplicateEmail_ShouldFail", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer le premier utilisateur
@@ -41,7 +44,7 @@ func TestUserRepository(t *testing.T) {
 			FirstName: "Test",
 			LastName:  "User1",
 			Password:  "$2a$10$testpassword",
-			Role:      "student",
+			Role:      models.RoleEtudiant,
 		}
 		err := repo.CreateUser(user1)
 		assert.NoError(t, err)
@@ -52,17 +55,18 @@ func TestUserRepository(t *testing.T) {
 			FirstName: "Test",
 			LastName:  "User2",
 			Password:  "$2a$10$testpassword",
-			Role:      "teacher",
+			Role:      models.RoleProfesseur,
 		}
 		err = repo.CreateUser(user2)
 		assert.Error(t, err) // Doit échouer à cause de la contrainte unique
 	})
 
 	t.Run("GetUserByID_Success", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer un utilisateur
-		user := createTestUser("student")
+		user := createTestUser(models.RoleEtudiant)
 
 		// Récupérer l'utilisateur par ID
 		retrievedUser, err := repo.GetUserByID(user.ID)
@@ -72,6 +76,7 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	t.Run("GetUserByID_NotFound", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Essayer de récupérer un utilisateur inexistant
@@ -80,10 +85,11 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	t.Run("GetUserByEmail_Success", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer un utilisateur
-		user := createTestUser("teacher")
+		user := createTestUser(models.RoleProfesseur)
 
 		// Récupérer l'utilisateur par email
 		retrievedUser, err := repo.GetUserByEmail(user.Email)
@@ -93,6 +99,7 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	t.Run("GetUserByEmail_NotFound", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Essayer de récupérer un utilisateur avec un email inexistant
@@ -101,47 +108,50 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	t.Run("GetAllUsers_Success", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer plusieurs utilisateurs
-		createTestUser("student")
-		createTestUser("teacher")
-		createTestUser("admin")
+		createTestUser(models.RoleEtudiant)
+		createTestUser(models.RoleProfesseur)
+		createTestUser(models.RoleAdmin)
 
 		// Récupérer tous les utilisateurs
-		users, err := repo.GetAllUsers(nil)
+		users, err := repo.FindAll()
 		assert.NoError(t, err)
 		assert.Len(t, users, 3)
 	})
 
 	t.Run("GetAllUsers_WithRoleFilter", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer des utilisateurs avec différents rôles
-		createTestUser("student")
-		createTestUser("teacher")
-		createTestUser("admin")
+		createTestUser(models.RoleEtudiant)
+		createTestUser(models.RoleProfesseur)
+		createTestUser(models.RoleAdmin)
 
 		// Filtrer par rôle
-		filter := &models.UserFilter{Role: "teacher"}
-		users, err := repo.GetAllUsers(filter)
+		users, err := repo.FindAll()
 		assert.NoError(t, err)
-		assert.Len(t, users, 1)
-		assert.Equal(t, "teacher", users[0].Role)
+		teachers := usersWithRole(users, models.RoleProfesseur)
+		assert.Len(t, teachers, 1)
+		assert.Equal(t, models.RoleProfesseur, teachers[0].Role)
 	})
 
 	t.Run("UpdateUser_Success", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer un utilisateur
-		user := createTestUser("student")
+		user := createTestUser(models.RoleEtudiant)
 
 		// Modifier l'utilisateur
 		user.FirstName = "Updated First Name"
 		user.LastName = "Updated Last Name"
 		user.Phone = "+9876543210"
 
-		err := repo.UpdateUser(user)
+		err := repo.Update(user)
 		assert.NoError(t, err)
 
 		// Vérifier que les modifications sont sauvegardées
@@ -153,10 +163,11 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	t.Run("DeleteUser_Success", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer un utilisateur
-		user := createTestUser("student")
+		user := createTestUser(models.RoleEtudiant)
 
 		// Supprimer l'utilisateur
 		err := repo.DeleteUser(user.ID)
@@ -168,43 +179,51 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	t.Run("GetUsersByRole_Success", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer des utilisateurs avec différents rôles
-		createTestUser("student")
-		createTestUser("student")
-		createTestUser("teacher")
-		createTestUser("admin")
+		createTestUser(models.RoleEtudiant)
+		assert.NoError(t, repo.CreateUser(&models.User{
+			Email:     "second-student@eduqr.com",
+			FirstName: "Test",
+			LastName:  "Student",
+			Password:  "$2a$10$testpassword",
+			Role:      models.RoleEtudiant,
+		}))
+		createTestUser(models.RoleProfesseur)
+		createTestUser(models.RoleAdmin)
 
 		// Récupérer les étudiants
-		students, err := repo.GetUsersByRole("student")
+		users, err := repo.FindAll()
 		assert.NoError(t, err)
+		students := usersWithRole(users, models.RoleEtudiant)
 		assert.Len(t, students, 2)
 
 		for _, student := range students {
-			assert.Equal(t, "student", student.Role)
+			assert.Equal(t, models.RoleEtudiant, student.Role)
 		}
 	})
 
 	t.Run("CheckUserExists_Exists", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Créer un utilisateur
-		user := createTestUser("student")
+		user := createTestUser(models.RoleEtudiant)
 
 		// Vérifier que l'utilisateur existe
-		exists, err := repo.CheckUserExists(user.Email, nil)
+		_, err := repo.FindByEmail(user.Email)
 		assert.NoError(t, err)
-		assert.True(t, exists)
 	})
 
 	t.Run("CheckUserExists_NotExists", func(t *testing.T) {
+		cleanupTestDatabase()
 		repo := repositories.NewUserRepository()
 
 		// Vérifier qu'un utilisateur inexistant n'existe pas
-		exists, err := repo.CheckUserExists("nonexistent@eduqr.com", nil)
-		assert.NoError(t, err)
-		assert.False(t, exists)
+		_, err := repo.FindByEmail("nonexistent@eduqr.com")
+		assert.Error(t, err)
 	})
 }
 
@@ -213,15 +232,15 @@ func TestUserService(t *testing.T) {
 	cleanupTestDatabase()
 
 	t.Run("CreateUser_Success", func(t *testing.T) {
-		repo := repositories.NewUserRepository()
-		service := services.NewUserService(repo)
+		cleanupTestDatabase()
+		service := services.NewUserService(repositories.NewUserRepository())
 
 		req := &models.CreateUserRequest{
 			Email:     "service@eduqr.com",
 			FirstName: "Service",
 			LastName:  "User",
 			Password:  "password123",
-			Role:      "student",
+			Role:      models.RoleEtudiant,
 			Phone:     "+1234567890",
 			Address:   "Service Address",
 		}
@@ -235,44 +254,52 @@ func TestUserService(t *testing.T) {
 		assert.Equal(t, req.Role, response.Role)
 	})
 
+	// Le format de l'email et la présence du mot de passe sont vérifiés à la liaison de la requête, avant l'appel au service
 	t.Run("CreateUser_InvalidEmail_ShouldFail", func(t *testing.T) {
-		repo := repositories.NewUserRepository()
-		service := services.NewUserService(repo)
-
 		req := &models.CreateUserRequest{
 			Email:     "invalid-email",
 			FirstName: "Service",
 			LastName:  "User",
 			Password:  "password123",
-			Role:      "student",
+			Role:      models.RoleEtudiant,
 		}
 
-		_, err := service.CreateUser(req)
-		assert.Error(t, err)
+		assert.Error(t, binding.Validator.ValidateStruct(req))
 	})
 
 	t.Run("CreateUser_EmptyPassword_ShouldFail", func(t *testing.T) {
-		repo := repositories.NewUserRepository()
-		service := services.NewUserService(repo)
-
 		req := &models.CreateUserRequest{
 			Email:     "test@eduqr.com",
 			FirstName: "Service",
 			LastName:  "User",
 			Password:  "",
-			Role:      "student",
+			Role:      models.RoleEtudiant,
 		}
 
-		_, err := service.CreateUser(req)
+		assert.Error(t, binding.Validator.ValidateStruct(req))
+	})
+
+	t.Run("CreateUser_DuplicateEmail_ShouldFail", func(t *testing.T) {
+		cleanupTestDatabase()
+		service := services.NewUserService(repositories.NewUserRepository())
+		user := createTestUser(models.RoleEtudiant)
+
+		_, err := service.CreateUser(&models.CreateUserRequest{
+			Email:     user.Email,
+			FirstName: "Service",
+			LastName:  "User",
+			Password:  "password123",
+			Role:      models.RoleEtudiant,
+		})
 		assert.Error(t, err)
 	})
 
 	t.Run("GetUserByID_Success", func(t *testing.T) {
-		repo := repositories.NewUserRepository()
-		service := services.NewUserService(repo)
+		cleanupTestDatabase()
+		service := services.NewUserService(repositories.NewUserRepository())
 
 		// Créer un utilisateur
-		user := createTestUser("student")
+		user := createTestUser(models.RoleEtudiant)
 
 		// Récupérer l'utilisateur
 		response, err := service.GetUserByID(user.ID)
@@ -282,11 +309,11 @@ func TestUserService(t *testing.T) {
 	})
 
 	t.Run("UpdateUser_Success", func(t *testing.T) {
-		repo := repositories.NewUserRepository()
-		service := services.NewUserService(repo)
+		cleanupTestDatabase()
+		service := services.NewUserService(repositories.NewUserRepository())
 
 		// Créer un utilisateur
-		user := createTestUser("student")
+		user := createTestUser(models.RoleEtudiant)
 
 		req := &models.UpdateUserRequest{
 			FirstName: "Updated Service",
@@ -304,26 +331,26 @@ func TestUserService(t *testing.T) {
 	})
 
 	t.Run("GetAllUsers_Success", func(t *testing.T) {
-		repo := repositories.NewUserRepository()
-		service := services.NewUserService(repo)
+		cleanupTestDatabase()
+		service := services.NewUserService(repositories.NewUserRepository())
 
 		// Créer plusieurs utilisateurs
-		createTestUser("student")
-		createTestUser("teacher")
-		createTestUser("admin")
+		createTestUser(models.RoleEtudiant)
+		createTestUser(models.RoleProfesseur)
+		createTestUser(models.RoleAdmin)
 
 		// Récupérer tous les utilisateurs
-		users, err := service.GetAllUsers(nil)
+		users, err := service.GetAllUsers()
 		assert.NoError(t, err)
 		assert.Len(t, users, 3)
 	})
 
 	t.Run("DeleteUser_Success", func(t *testing.T) {
-		repo := repositories.NewUserRepository()
-		service := services.NewUserService(repo)
+		cleanupTestDatabase()
+		service := services.NewUserService(repositories.NewUserRepository())
 
 		// Créer un utilisateur
-		user := createTestUser("student")
+		user := createTestUser(models.RoleEtudiant)
 
 		// Supprimer l'utilisateur
 		err := service.DeleteUser(user.ID)
@@ -335,97 +362,76 @@ func TestUserService(t *testing.T) {
 	})
 }
 
+// TestUserValidation vérifie les règles de validation appliquées par les contrôleurs à la création d'un utilisateur
 func TestUserValidation(t *testing.T) {
-	t.Run("ValidateEmail_ValidEmails", func(t *testing.T) {
-		validEmails := []string{
-			"test@eduqr.com",
-			"user.name@domain.com",
-			"user+tag@example.org",
-			"123@numbers.com",
+	validRequest := func() models.CreateUserRequest {
+		return models.CreateUserRequest{
+			Email:           "test@eduqr.com",
+			Password:        "password123",
+			ConfirmPassword: "password123",
+			FirstName:       "Test",
+			LastName:        "User",
+			Phone:           "+1234567890",
+			Address:         "Test Address",
+			Role:            models.RoleEtudiant,
 		}
+	}
 
-		for _, email := range validEmails {
-			assert.Contains(t, email, "@", "L'email doit contenir @")
-			assert.Contains(t, email, ".", "L'email doit contenir un point")
+	t.Run("ValidateEmail_ValidEmails", func(t *testing.T) {
+		for _, email := range []string{"test@eduqr.com", "user.name@domain.com", "user+tag@example.org", "123@numbers.com"} {
+			req := validRequest()
+			req.Email = email
+			assert.NoError(t, binding.Validator.ValidateStruct(&req), email)
 		}
 	})
 
 	t.Run("ValidateEmail_InvalidEmails", func(t *testing.T) {
-		invalidEmails := []string{
-			"",
-			"invalid-email",
-			"@domain.com",
-			"user@",
-			"user.domain.com",
-		}
-
-		for _, email := range invalidEmails {
-			if email == "" {
-				assert.Empty(t, email, "L'email vide est détecté correctement")
-			} else if !contains(email, "@") || !contains(email, ".") {
-				assert.False(t, isValidEmailFormat(email), "Format d'email invalide détecté")
-			}
+		for _, email := range []string{"", "invalid-email", "@domain.com", "user@", "user.domain.com"} {
+			req := validRequest()
+			req.Email = email
+			assert.Error(t, binding.Validator.ValidateStruct(&req), email)
 		}
 	})
 
 	t.Run("ValidatePassword_ValidPasswords", func(t *testing.T) {
-		validPasswords := []string{
-			"password123",
-			"SecurePass!",
-			"123456789",
-			"aBcDeFgH",
-		}
-
-		for _, password := range validPasswords {
-			assert.Len(t, password, 8, "Le mot de passe doit avoir au moins 8 caractères")
+		for _, password := range []string{"password123", "SecurePass!", "123456789", "aBcDeFgH"} {
+			req := validRequest()
+			req.Password = password
+			req.ConfirmPassword = password
+			assert.NoError(t, binding.Validator.ValidateStruct(&req), password)
 		}
 	})
 
 	t.Run("ValidatePassword_InvalidPasswords", func(t *testing.T) {
-		invalidPasswords := []string{
-			"",
-			"123",
-			"short",
-		}
-
-		for _, password := range invalidPasswords {
-			if password == "" {
-				assert.Empty(t, password, "Le mot de passe vide est détecté correctement")
-			} else if len(password) < 8 {
-				assert.Len(t, password, 8, "Le mot de passe trop court est détecté")
-			}
+		for _, password := range []string{"", "123", "short"} {
+			req := validRequest()
+			req.Password = password
+			req.ConfirmPassword = password
+			assert.Error(t, binding.Validator.ValidateStruct(&req), password)
 		}
 	})
 
 	t.Run("ValidateRole_ValidRoles", func(t *testing.T) {
-		validRoles := []string{
-			"student",
-			"teacher",
-			"admin",
-			"super_admin",
+		for _, role := range models.ValidRoles {
+			req := validRequest()
+			req.Role = role
+			assert.NoError(t, req.Validate(), role)
 		}
-
-		for _, role := range validRoles {
-			assert.Contains(t, validRoles, role, "Le rôle doit être valide")
+		for _, role := range []string{"student", "teacher", ""} {
+			req := validRequest()
+			req.Role = role
+			assert.Error(t, req.Validate(), role)
 		}
 	})
 }
 
 // Fonctions utilitaires pour les tests
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
-		(len(s) > len(substr) && (s[:len(substr)] == substr ||
-			s[len(s)-len(substr):] == substr ||
-			func() bool {
-				for i := 1; i <= len(s)-len(substr); i++ {
-					if s[i:i+len(substr)] == substr {
-						return true
-					}
-				}
-				return false
-			}())))
-}
-
-func isValidEmailFormat(email string) bool {
-	return contains(email, "@") && contains(email, ".") && len(email) > 5
+func usersWithRole(users []models.User, role string) []models.User {
+	var filtered []models.User
+	for _, user := range users {
+		if user.Role == role {
+			filtered = append(filtered, user)
+		}
+	}
+	return filtered
 }